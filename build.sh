@@ -12,6 +12,7 @@ else
     echo "Directory already exists: $directory"
 fi
 
-env GOOS=linux GOARCH=amd64 go build -o="$directory/xsd2wkt-$version-linux" $PWD/src/xsd2wkt/main.go
-env GOOS=darwin GOARCH=amd64 go build -o="$directory/xsd2wkt-$version-osx" $PWD/src/xsd2wkt/main.go
-env GOOS=windows GOARCH=amd64 go build -o="$directory/xsd2wkt-$version-win.exe" $PWD/src/xsd2wkt/main.go
\ No newline at end of file
+env GOOS=linux GOARCH=amd64 go build -o="$directory/xsd2wkt-$version-linux" ./src/xsd2wkt
+env GOOS=darwin GOARCH=amd64 go build -o="$directory/xsd2wkt-$version-osx" ./src/xsd2wkt
+env GOOS=windows GOARCH=amd64 go build -o="$directory/xsd2wkt-$version-win.exe" ./src/xsd2wkt
+env GOOS=js GOARCH=wasm go build -o="$directory/xsd2wkt-$version.wasm" ./src/wasm
\ No newline at end of file
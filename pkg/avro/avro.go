@@ -0,0 +1,296 @@
+// Package avro generates an Avro record schema equivalent of a parsed XSD schema.
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Characters that are not allowed in Avro names, which must match [A-Za-z_][A-Za-z0-9_]*
+var invalidName = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Options controlling how the Avro schema is generated
+type Options struct {
+	AttributePrefix string // Prefix marking fields generated from XML attributes, made a valid Avro name
+}
+
+// Record is an Avro record schema
+type Record struct {
+	Type      string  `json:"type"`
+	Name      string  `json:"name"`
+	Namespace string  `json:"namespace,omitempty"`
+	Doc       string  `json:"doc,omitempty"`
+	Fields    []Field `json:"fields"`
+}
+
+// Field is a field of an Avro record
+type Field struct {
+	Name    string          `json:"name"`
+	Doc     string          `json:"doc,omitempty"`
+	Type    any             `json:"type"`
+	Default json.RawMessage `json:"default,omitempty"` // Literal JSON, so that a null default can be told from none
+}
+
+// Enum is an Avro enum schema
+type Enum struct {
+	Type    string   `json:"type"`
+	Name    string   `json:"name"`
+	Symbols []string `json:"symbols"`
+}
+
+// Array is an Avro array schema
+type Array struct {
+	Type  string `json:"type"`
+	Items any    `json:"items"`
+}
+
+// Logical is a primitive Avro type annotated with a logical type
+type Logical struct {
+	Type        string `json:"type"`
+	LogicalType string `json:"logicalType"`
+}
+
+// Generator of the named types of a schema, which must have unique names
+type generator struct {
+	opts  Options
+	names map[string]bool
+}
+
+// Function to generate the Avro schema of the global elements of a schema: the record of
+// the document root, or a union of records when the schema declares several roots. Child
+// elements and attributes become fields named after the XML names, made valid Avro names.
+// Records are named after the path of their element, and placed in a namespace derived
+// from the target namespace, such as com.example.orders for http://example.com/orders.
+func Generate(schema xsd.Schema, opts Options) any {
+	g := generator{opts: opts, names: make(map[string]bool)}
+	var roots []any
+	for _, element := range schema.Elements {
+		record := g.record(element, "")
+		record.Namespace = Namespace(schema.TargetNamespace)
+		roots = append(roots, record)
+	}
+	if len(roots) == 1 {
+		return roots[0]
+	}
+	return roots
+}
+
+// Function to generate the record of an element with children, attributes or alternatives,
+// named after its path from the parent record
+func (g generator) record(element xsd.Element, parent string) *Record {
+	record := &Record{Type: "record", Name: g.name(parent, element.Name), Fields: []Field{}}
+	if parent == "" {
+		record.Doc = workato.Hint(element)
+		// A simple root has its value as single field
+		if element.IsLeaf() {
+			record.Fields = append(record.Fields, g.field(element, element.Name, record.Name))
+			return record
+		}
+	}
+	// Fixed values are constants of the document rather than data
+	for _, attribute := range element.Attributes {
+		attributeElement := attribute.AsElement()
+		if attributeElement.IsFixed() {
+			continue
+		}
+		record.Fields = append(record.Fields, g.field(attributeElement, g.opts.AttributePrefix+attribute.Name, record.Name))
+	}
+	// The text next to attributes is the field named after #text, as in JSON Schema
+	if element.Text != nil {
+		record.Fields = append(record.Fields, g.field(*element.Text, "#text", record.Name))
+	}
+	for _, child := range element.Children {
+		if child.IsFixed() {
+			continue
+		}
+		record.Fields = append(record.Fields, g.field(child, child.Name, record.Name))
+	}
+	// Polymorphic elements select their concrete type with xsi:type, whose content goes
+	// into the optional field of the same name
+	if len(element.Alternatives) > 0 {
+		var symbols []string
+		for _, alternative := range element.Alternatives {
+			alternative.MinOccurs = "0"
+			record.Fields = append(record.Fields, g.field(alternative, alternative.Name, record.Name))
+			symbols = append(symbols, alternative.Name)
+		}
+		name := Name(g.opts.AttributePrefix + "xsi:type")
+		record.Fields = append(record.Fields, Field{Name: name, Type: g.enum(symbols, record.Name, name)})
+	}
+	return record
+}
+
+// Function to generate the field of a child element or attribute. Repeating elements become
+// arrays, and optional ones a union with null that defaults to null, unless the element has
+// a default value of its own.
+func (g generator) field(element xsd.Element, name, parent string) Field {
+	field := Field{Name: Name(name), Doc: workato.Hint(element)}
+
+	var valueType any
+	switch {
+	case element.IsList():
+		valueType = Array{Type: "array", Items: g.leafType(element.ListItem(), parent)}
+	case element.IsLeaf():
+		valueType = g.leafType(element, parent)
+	default:
+		valueType = g.record(element, parent)
+	}
+
+	switch {
+	case element.IsRepeating():
+		field.Type = Array{Type: "array", Items: valueType}
+		if element.IsOptional() {
+			field.Default = json.RawMessage("[]")
+		}
+	case element.Default != "" && defaultValue(element.Default, valueType) != nil:
+		field.Type = valueType
+		if element.IsOptional() {
+			// The default of a union must match its first branch
+			field.Type = []any{valueType, "null"}
+		}
+		field.Default = defaultValue(element.Default, valueType)
+	case element.IsOptional():
+		field.Type = []any{"null", valueType}
+		field.Default = json.RawMessage("null")
+	default:
+		field.Type = valueType
+	}
+	return field
+}
+
+// Function to get the Avro type of a leaf element
+func (g generator) leafType(element xsd.Element, parent string) any {
+	switch workato.MapType(element.BaseType()) {
+	case "date":
+		return Logical{Type: "int", LogicalType: "date"}
+	case "date_time", "timestamp":
+		return Logical{Type: "long", LogicalType: "timestamp-millis"}
+	case "boolean":
+		return "boolean"
+	case "integer":
+		return "long"
+	case "number":
+		return "double"
+	}
+	return g.enum(element.Enumerations(), parent, element.Name)
+}
+
+// Function to get the type of a string restricted to the given values: an enum if they are
+// all valid Avro symbols, or else a string
+func (g generator) enum(symbols []string, parent, elementName string) any {
+	if len(symbols) == 0 {
+		return "string"
+	}
+	for _, symbol := range symbols {
+		if Name(symbol) != symbol {
+			return "string"
+		}
+	}
+	return Enum{Type: "enum", Name: g.name(parent, elementName), Symbols: symbols}
+}
+
+// Function to name a record or enum after its parent and element, adding a numeric suffix
+// when names that differ in XML are the same once made valid Avro names
+func (g generator) name(parent, elementName string) string {
+	name := Name(elementName)
+	if parent != "" {
+		name = parent + "_" + name
+	}
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// Function to make a valid Avro name of an XML name, replacing the characters that are
+// not allowed with underscores, such as _id for @id or order_id for order-id
+func Name(name string) string {
+	name = invalidName.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// Function to derive an Avro namespace from an XML namespace URI: the labels of the host
+// in reverse order followed by the path segments, such as com.example.orders for
+// http://example.com/orders or example.orders for urn:example:orders
+func Namespace(namespaceURI string) string {
+	if namespaceURI == "" {
+		return ""
+	}
+	var parts []string
+	if u, err := url.Parse(namespaceURI); err == nil && u.Host != "" {
+		labels := strings.Split(strings.TrimPrefix(u.Hostname(), "www."), ".")
+		for i := len(labels) - 1; i >= 0; i-- {
+			parts = append(parts, labels[i])
+		}
+		parts = append(parts, strings.Split(u.Path, "/")...)
+	} else {
+		parts = strings.FieldsFunc(strings.TrimPrefix(namespaceURI, "urn:"), func(r rune) bool {
+			return r == ':' || r == '/'
+		})
+	}
+
+	var names []string
+	for _, part := range parts {
+		if part != "" {
+			names = append(names, Name(part))
+		}
+	}
+	return strings.Join(names, ".")
+}
+
+// Helper function to convert a default value to the JSON of its Avro type, or nil when it
+// is not a value of the type
+func defaultValue(value string, valueType any) json.RawMessage {
+	switch valueType := valueType.(type) {
+	case string:
+		switch valueType {
+		case "long":
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				return nil
+			}
+			return json.RawMessage(value)
+		case "double":
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil
+			}
+			return json.RawMessage(strconv.FormatFloat(f, 'g', -1, 64))
+		case "boolean":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil
+			}
+			return json.RawMessage(strconv.FormatBool(b))
+		}
+	case Enum:
+		if !slices.Contains(valueType.Symbols, value) {
+			return nil
+		}
+	default:
+		return nil
+	}
+	quoted, _ := json.Marshal(value)
+	return quoted
+}
+
+// Function to marshal an Avro schema to indented JSON
+func Marshal(schema any) ([]byte, error) {
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling Avro schema: %w", err)
+	}
+	return schemaJSON, nil
+}
@@ -0,0 +1,352 @@
+// Package gostructs generates Go types with xml and json tags equivalent of a parsed XSD
+// schema, for services that marshal the documents of the same contract.
+package gostructs
+
+import (
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Name of the type holding the XML content of wildcards and xs:anyType elements as is
+const anyElement = "AnyElement"
+
+// Name of the generated package when neither Options.Package nor a document root names it
+const defaultPackage = "schema"
+
+// Options controlling how the Go types are generated
+type Options struct {
+	Package         string // Name of the package of the generated file, or "" to name it after the document root, "schema" without one
+	AttributePrefix string // Prefix of the JSON names of fields generated from XML attributes
+}
+
+// Generator of the types of a schema, which must have unique names
+type generator struct {
+	opts  Options
+	names map[string]bool
+	open  map[string]string // Struct of the named types being generated, reused by recursive content
+	types []string          // Declarations of the types, roots first and each type before its children
+	any   bool              // Whether a field holds XML content as is, needing the AnyElement type
+}
+
+// Field of a generated struct
+type field struct {
+	name    string
+	goType  string
+	xmlTag  string
+	jsonTag string
+	doc     string
+}
+
+// Function to generate the Go source declaring the types of the global elements of a schema:
+// a struct for every element with children or attributes, named after its path, such as
+// OrderCustomer for Order/Customer. Attributes, text and children become fields tagged with
+// their XML names, and with the JSON names of the JSON Schema. Optional values are pointers
+// or left out when empty, and repeating elements are slices. Leaves map to bool, int64,
+// float64 or string, dates and times being kept in their lexical form. Wildcards, xs:anyType
+// elements and recursive content truncated at the maximum depth hold their XML as is.
+func Generate(schema xsd.Schema, opts Options) ([]byte, error) {
+	g := &generator{opts: opts, names: map[string]bool{anyElement: true}, open: make(map[string]string)}
+	for _, element := range schema.Elements {
+		g.root(element, schema.TargetNamespace)
+	}
+
+	packageName := opts.Package
+	if packageName == "" {
+		packageName = defaultPackage
+		if len(schema.Elements) > 0 {
+			packageName = PackageName(schema.Elements[0].Name)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("// Code generated by xsd2wkt. DO NOT EDIT.\n\n")
+	sb.WriteString("package " + packageName + "\n\n")
+	sb.WriteString("import \"encoding/xml\"\n")
+	for _, declaration := range g.types {
+		sb.WriteString("\n" + declaration)
+	}
+	if g.any {
+		sb.WriteString("\n// " + anyElement + " holds an element whose XML content is passed as is\n")
+		sb.WriteString("type " + anyElement + " struct {\n")
+		sb.WriteString("XMLName xml.Name `json:\"-\"`\n")
+		sb.WriteString("Attrs []xml.Attr `xml:\",any,attr\" json:\"-\"`\n")
+		sb.WriteString("Content string `xml:\",innerxml\" json:\"content\"`\n")
+		sb.WriteString("}\n")
+	}
+
+	source, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format Go source: %w", err)
+	}
+	return source, nil
+}
+
+// Function to generate the struct of a global element, whose XMLName names the document
+// root in the target namespace. A simple root has its value as single field.
+func (g *generator) root(element xsd.Element, namespace string) {
+	name, index := g.name("", element.Name), g.reserve()
+	fields := []field{{name: "XMLName", goType: "xml.Name", xmlTag: strings.TrimSpace(namespace + " " + element.Name), jsonTag: "-"}}
+	if element.IsLeaf() {
+		fields = append(fields, field{name: "Value", goType: g.leafType(element), xmlTag: ",chardata", jsonTag: element.Name})
+	} else {
+		fields = append(fields, g.fields(element, name)...)
+	}
+	g.types[index] = declaration(name, element, fields)
+}
+
+// Function to generate the struct of a nested element, named after its path from the
+// struct of its parent. Elements of a named type are open while their fields are generated,
+// so that descendants of the same type refer back to the struct.
+func (g *generator) nested(element xsd.Element, parent string) string {
+	name, index := g.name(parent, element.Name), g.reserve()
+	if element.Type != "" {
+		g.open[element.Type] = name
+		defer delete(g.open, element.Type)
+	}
+	g.types[index] = declaration(name, element, g.fields(element, name))
+	return name
+}
+
+// Function to reserve the place of the declaration of a struct before its fields are
+// generated, so that it comes before the structs of its children
+func (g *generator) reserve() int {
+	g.types = append(g.types, "")
+	return len(g.types) - 1
+}
+
+// Function to declare a struct, documented with the name of its element and its hint
+func declaration(name string, element xsd.Element, fields []field) string {
+	var sb strings.Builder
+	sb.WriteString(comment(name + " holds the " + element.Name + " element. " + workato.Hint(element)))
+	sb.WriteString("type " + name + " struct {\n")
+	for _, f := range fields {
+		if f.doc != "" {
+			sb.WriteString(comment(f.doc))
+		}
+		sb.WriteString(f.name + " " + f.goType + " `xml:\"" + f.xmlTag + "\" json:\"" + f.jsonTag + "\"`\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Function to generate the fields of the attributes, text and children of an element. The
+// attributes and children of the alternatives of a polymorphic element are optional fields
+// of its struct, next to the xsi:type attribute selecting the alternative.
+func (g *generator) fields(element xsd.Element, parent string) []field {
+	var fields []field
+	names := map[string]bool{"XMLName": true}
+	seen := map[string]bool{}
+	add := func(f field) {
+		f.name = uniqueField(names, f.name)
+		fields = append(fields, f)
+	}
+
+	addContent := func(content xsd.Element, optional bool) {
+		for _, attribute := range content.Attributes {
+			if seen["@"+attribute.Name] {
+				continue
+			}
+			seen["@"+attribute.Name] = true
+			attributeElement := attribute.AsElement()
+			if optional {
+				attributeElement.MinOccurs = "0"
+			}
+			add(g.attribute(attributeElement))
+		}
+		if content.Text != nil && !seen["#text"] {
+			seen["#text"] = true
+			text := *content.Text
+			f := field{name: "Value", goType: g.leafType(text), xmlTag: ",chardata", jsonTag: "#text", doc: workato.Hint(text)}
+			if content.IsMixed() {
+				f.name, f.goType = "Text", "string"
+			}
+			add(f)
+		}
+		for _, child := range content.Children {
+			if seen[child.Name] {
+				continue
+			}
+			seen[child.Name] = true
+			if optional {
+				child.MinOccurs = "0"
+			}
+			add(g.child(child, parent))
+		}
+	}
+
+	addContent(element, false)
+	if len(element.Alternatives) > 0 {
+		add(field{name: "XsiType", goType: "string", xmlTag: xsd.InstanceNamespace + " type,attr,omitempty",
+			jsonTag: g.opts.AttributePrefix + "xsi:type,omitempty", doc: "Name of the alternative type of the content, such as " + element.Alternatives[0].Name})
+		for _, alternative := range element.Alternatives {
+			addContent(alternative, true)
+		}
+	}
+	return fields
+}
+
+// Function to generate the field of an attribute
+func (g *generator) attribute(element xsd.Element) field {
+	f := field{
+		name:    Name(element.Name),
+		goType:  g.leafType(element),
+		xmlTag:  element.Name + ",attr",
+		jsonTag: g.opts.AttributePrefix + element.Name,
+		doc:     workato.Hint(element),
+	}
+	if element.IsOptional() {
+		f.goType = optional(f.goType)
+		f.xmlTag += ",omitempty"
+		f.jsonTag += ",omitempty"
+	}
+	return f
+}
+
+// Function to generate the field of a child element. Wildcards are slices of the elements
+// they accept, and elements whose content is passed as is hold it in an AnyElement, as do
+// recursive elements truncated at the maximum depth outside of the struct of their type.
+func (g *generator) child(element xsd.Element, parent string) field {
+	if element.IsWildcard() {
+		g.any = true
+		return field{name: "Any", goType: "[]" + anyElement, xmlTag: ",any", jsonTag: xsd.WildcardName + ",omitempty", doc: workato.Hint(element)}
+	}
+
+	f := field{name: Name(element.Name), xmlTag: element.Name, jsonTag: element.Name, doc: workato.Hint(element)}
+	recursive, isRecursive := g.open[element.Type]
+	switch {
+	case element.IsPassThrough():
+		g.any = true
+		f.goType = anyElement
+	case isRecursive:
+		// Recursive types need a pointer or slice to be of finite size
+		element.Truncated = false
+		f.goType, f.doc = recursive, workato.Hint(element)
+		if !element.IsRepeating() && !element.IsOptional() {
+			f.goType = "*" + f.goType
+		}
+	case element.Truncated:
+		g.any = true
+		f.goType = anyElement
+	case element.IsLeaf():
+		f.goType = g.leafType(element)
+	default:
+		f.goType = g.nested(element, parent)
+	}
+
+	switch {
+	case element.IsRepeating():
+		f.goType = "[]" + f.goType
+		if element.IsOptional() {
+			f.xmlTag += ",omitempty"
+			f.jsonTag += ",omitempty"
+		}
+	case element.IsOptional():
+		f.goType = optional(f.goType)
+		f.xmlTag += ",omitempty"
+		f.jsonTag += ",omitempty"
+	}
+	return f
+}
+
+// Function to get the Go type of a leaf element from its Workato type. Lists are written
+// as strings of space separated values, as encoding/xml has no list types.
+func (g *generator) leafType(element xsd.Element) string {
+	if element.IsList() {
+		return "string"
+	}
+	switch workato.MapType(element.BaseType()) {
+	case "boolean":
+		return "bool"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	}
+	return "string"
+}
+
+// Function to name a struct after its parent and element, adding a numeric suffix when
+// names that differ in XML are the same once made valid Go names
+func (g *generator) name(parent, elementName string) string {
+	name := parent + Name(elementName)
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// Function to make an exported Go name of an XML name, capitalizing the words separated by
+// characters that are not allowed, such as OrderId for order-id or X2ndLine for 2ndLine
+func Name(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	name = sb.String()
+	if name == "" || !unicode.IsUpper([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// Function to make a Go package name of an XML name, keeping its letters and digits in lower
+// case, such as purchaseorder for PurchaseOrder or orders05 for ORDERS05
+func PackageName(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	name = sb.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "x" + name
+	}
+	return name
+}
+
+// Helper function to make a field name unique within its struct, adding a numeric suffix
+// when names that differ in XML are the same once made valid Go names
+func uniqueField(names map[string]bool, name string) string {
+	unique := name
+	for i := 2; names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	names[unique] = true
+	return unique
+}
+
+// Helper function to get the type of an optional value: a pointer, so that an absent value
+// can be told from the zero value, but for strings, which are left out when empty
+func optional(goType string) string {
+	if goType == "string" {
+		return goType
+	}
+	return "*" + goType
+}
+
+// Helper function to write text as a line comment, or nothing when it is empty
+func comment(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	return "// " + strings.ReplaceAll(text, "\n", "\n// ") + "\n"
+}
@@ -0,0 +1,35 @@
+package gostructs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+func TestGeneratePackageName(t *testing.T) {
+	cases := []struct {
+		schema string
+		opts   Options
+		want   string
+	}{
+		{`<xs:element name="Purchase-Order" type="xs:string"/>`, Options{}, "package purchaseorder\n"},
+		{`<xs:element name="Order" type="xs:string"/>`, Options{Package: "contract"}, "package contract\n"},
+		// Without global elements, nothing names the package
+		{`<xs:complexType name="OrderType"/>`, Options{}, "package schema\n"},
+	}
+	for _, c := range cases {
+		schema, err := xsd.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">` + c.schema + `</xs:schema>`))
+		if err != nil {
+			t.Fatalf("xsd.Parse: %v", err)
+		}
+		source, err := Generate(schema, c.opts)
+		if err != nil {
+			t.Fatalf("Generate(%s): %v", c.schema, err)
+		}
+		if !strings.Contains(string(source), "\n"+c.want) {
+			t.Errorf("Generate(%s):\n%s\nwant %q", c.schema, source, c.want)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+// Package jsonschema generates a JSON Schema (draft 2020-12) equivalent of a parsed XSD schema,
+// and reads JSON Schema documents back into schema declarations.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Dialect identifying JSON Schema draft 2020-12
+const Dialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Name of the property holding the text of an element with attributes and simpleContent
+const TextProperty = "#text"
+
+// Name of the property holding the XML content matching an xs:any wildcard
+const WildcardProperty = xsd.WildcardName
+
+// Options controlling how the JSON Schema is generated
+type Options struct {
+	AttributePrefix string // Prefix marking properties generated from XML attributes
+	XML             bool   // Whether to describe the XML names of attributes and roots with OpenAPI xml objects
+}
+
+// Schema is a JSON Schema object, limited to the keywords needed to describe an XSD and
+// to read the documents converted by ToSchema
+type Schema struct {
+	Dialect     string     `json:"$schema,omitempty"`
+	Comment     string     `json:"$comment,omitempty"` // Note for the maintainers of the document, such as where it was generated from
+	Ref         string     `json:"$ref,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Type        string     `json:"type,omitempty"`
+	Format      string     `json:"format,omitempty"`
+	Enum        []any      `json:"enum,omitempty"`
+	Const       any        `json:"const,omitempty"`
+	Default     any        `json:"default,omitempty"`
+	MaxLength   int        `json:"maxLength,omitempty"`
+	Items       *Schema    `json:"items,omitempty"`
+	Properties  Properties `json:"properties,omitempty"`
+	Required    []string   `json:"required,omitempty"`
+	AllOf       []*Schema  `json:"allOf,omitempty"`
+	AnyOf       []*Schema  `json:"anyOf,omitempty"`
+	OneOf       []*Schema  `json:"oneOf,omitempty"`
+	Defs        Properties `json:"$defs,omitempty"`
+	Definitions Properties `json:"definitions,omitempty"` // Named schemas of drafts before 2019-09
+	XML         *XML       `json:"xml,omitempty"`
+
+	// Range of the values of numbers
+	Minimum          json.Number `json:"minimum,omitempty"`
+	Maximum          json.Number `json:"maximum,omitempty"`
+	ExclusiveMinimum json.Number `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum json.Number `json:"exclusiveMaximum,omitempty"`
+
+	types    []string // Types other than null, when type is a list such as ["string", "null"]
+	nullable bool     // Whether null is one of the types
+	open     bool     // Whether additionalProperties or patternProperties describe more properties
+	skipped  []string // Keywords constraining the content that ToSchema does not read, such as not
+}
+
+// XML is an OpenAPI xml object, describing how a property is written in XML when its
+// name alone does not
+type XML struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Prefix    string `json:"prefix,omitempty"`
+	Attribute bool   `json:"attribute,omitempty"`
+}
+
+// Property is a named member of the properties of an object schema
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties of an object schema, marshaled in document order
+type Properties []Property
+
+// Function to marshal the properties as a JSON object, keeping their order
+func (properties Properties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, property := range properties {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(property.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(property.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Function to unmarshal the properties of an object schema, keeping their order
+func (properties *Properties) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil {
+		return err
+	} else if token != json.Delim('{') {
+		return fmt.Errorf("properties must be an object")
+	}
+	*properties = nil
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		property := Property{Name: token.(string), Schema: &Schema{}}
+		if err := decoder.Decode(property.Schema); err != nil {
+			return err
+		}
+		*properties = append(*properties, property)
+	}
+	return nil
+}
+
+// Function to look up a property by name
+func (properties Properties) Get(name string) (*Schema, bool) {
+	for _, property := range properties {
+		if property.Name == name {
+			return property.Schema, true
+		}
+	}
+	return nil, false
+}
+
+// Function to generate a JSON Schema describing documents with the global elements of a
+// schema as top-level properties. Child elements become properties named after the XML
+// element, attributes are named after the attribute with the attribute prefix, and the
+// text next to attributes is the #text property.
+func Generate(schema xsd.Schema, opts Options) *Schema {
+	g := generator{opts: opts}
+	root := &Schema{Dialect: Dialect, Type: "object"}
+	for _, element := range schema.Elements {
+		root.Properties = append(root.Properties, Property{element.Name, g.element(element)})
+		root.Required = append(root.Required, element.Name)
+	}
+	if len(schema.Elements) == 1 {
+		root.Title = schema.Elements[0].Name
+	}
+	return root
+}
+
+// Function to generate the schemas of the global elements of a schema as named definitions,
+// such as OpenAPI components, along with the named complex types they use. Elements of
+// these types reference their definition at location, such as #/components/schemas/,
+// instead of repeating it, which also describes recursive types without truncating them.
+func Definitions(schema xsd.Schema, opts Options, location string) Properties {
+	g := generator{opts: opts, location: location, definitions: &Properties{}, types: make(map[string]string), names: make(map[string]bool)}
+	for _, element := range schema.Elements {
+		g.names[element.Name] = true
+	}
+	var definitions Properties
+	for _, element := range schema.Elements {
+		definition := g.element(element)
+		if opts.XML {
+			definition.XML = &XML{Name: element.Name, Namespace: schema.TargetNamespace, Prefix: schema.PrefixFor(schema.TargetNamespace)}
+		}
+		definitions = append(definitions, Property{element.Name, definition})
+	}
+	return append(definitions, *g.definitions...)
+}
+
+// Generator of the schemas of elements, holding the definitions of named types when they
+// are referenced rather than inlined
+type generator struct {
+	opts        Options
+	location    string            // Location of the definitions referenced by $ref
+	definitions *Properties       // Definitions of the named complex types, nil to inline them
+	types       map[string]string // Definition name of each named complex type
+	names       map[string]bool   // Definition names in use
+}
+
+// Function to generate the schema of an element. Repeating elements become arrays of
+// their item schema, and elements with children or attributes become objects.
+func (g generator) element(element xsd.Element) *Schema {
+	schema := &Schema{Description: workato.Hint(element)}
+
+	switch {
+	case element.IsList() && !element.IsRepeating():
+		schema.Type = "array"
+		schema.Items = &Schema{}
+		setType(schema.Items, element.ListItem())
+	case element.IsLeaf():
+		setType(schema, element)
+	case g.definitions != nil && element.Type != "" && (g.types[element.Type] != "" || !element.Truncated):
+		schema.Ref = g.location + g.define(element)
+	default:
+		g.object(schema, element)
+	}
+
+	// The description stays on the array rather than on its items
+	if element.IsRepeating() {
+		array := &Schema{Description: schema.Description, Type: "array", Items: schema}
+		schema.Description = ""
+		return array
+	}
+	return schema
+}
+
+// Function to get the definition name of the type of an element, generating the definition
+// the first time the type is used. It is named after the type, with a numeric suffix if
+// the name is taken.
+func (g generator) define(element xsd.Element) string {
+	if name, ok := g.types[element.Type]; ok {
+		return name
+	}
+	typeName := element.Type[strings.Index(element.Type, ":")+1:]
+	name := typeName
+	for i := 2; g.names[name]; i++ {
+		name = typeName + strconv.Itoa(i)
+	}
+	g.names[name] = true
+	g.types[element.Type] = name
+
+	// The definition is added before the types it uses, which may refer back to it
+	*g.definitions = append(*g.definitions, Property{Name: name})
+	index := len(*g.definitions) - 1
+	definition := &Schema{}
+	g.object(definition, element)
+	(*g.definitions)[index].Schema = definition
+	return name
+}
+
+// Function to describe the attributes, text, children and alternatives of an element as
+// the properties of an object schema
+func (g generator) object(schema *Schema, element xsd.Element) {
+	schema.Type = "object"
+	// Fixed values are constants of the document rather than input data
+	for _, attribute := range element.Attributes {
+		name := g.opts.AttributePrefix + attribute.Name
+		attributeElement := attribute.AsElement()
+		if attributeElement.IsFixed() {
+			continue
+		}
+		property := g.element(attributeElement)
+		if g.opts.XML {
+			property.XML = &XML{Name: attribute.Name, Attribute: true}
+		}
+		schema.Properties = append(schema.Properties, Property{name, property})
+		if !attributeElement.IsOptional() {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	if element.Text != nil {
+		schema.Properties = append(schema.Properties, Property{TextProperty, g.element(*element.Text)})
+		if !element.Text.IsOptional() {
+			schema.Required = append(schema.Required, TextProperty)
+		}
+	}
+	for _, child := range element.Children {
+		if child.IsFixed() {
+			continue
+		}
+		schema.Properties = append(schema.Properties, Property{child.Name, g.element(child)})
+		if !child.IsOptional() {
+			schema.Required = append(schema.Required, child.Name)
+		}
+	}
+	// Polymorphic elements select their concrete type with xsi:type, whose content goes
+	// into the property of the same name
+	if len(element.Alternatives) > 0 {
+		selector := &Schema{Type: "string"}
+		for _, alternative := range element.Alternatives {
+			selector.Enum = append(selector.Enum, alternative.Name)
+			schema.Properties = append(schema.Properties, Property{alternative.Name, g.element(alternative)})
+		}
+		if g.opts.XML {
+			selector.XML = &XML{Name: "type", Namespace: xsd.InstanceNamespace, Prefix: "xsi", Attribute: true}
+		}
+		name := g.opts.AttributePrefix + "xsi:type"
+		schema.Properties = append(schema.Properties, Property{name, selector})
+		schema.Required = append(schema.Required, name)
+	}
+}
+
+// Helper function to set the JSON type, format and facets of a leaf element
+func setType(schema *Schema, element xsd.Element) {
+	switch workato.MapType(element.BaseType()) {
+	case "date":
+		schema.Type = "string"
+		schema.Format = "date"
+	case "date_time", "timestamp":
+		schema.Type = "string"
+		schema.Format = "date-time"
+	case "boolean":
+		schema.Type = "boolean"
+	case "integer":
+		schema.Type = "integer"
+		setBounds(schema, element)
+	case "number":
+		schema.Type = "number"
+		setBounds(schema, element)
+	default:
+		schema.Type = "string"
+		schema.MaxLength = element.MaxLength()
+	}
+	for _, value := range element.Enumerations() {
+		schema.Enum = append(schema.Enum, enumValue(value, schema.Type))
+	}
+	if element.Default != "" {
+		schema.Default = enumValue(element.Default, schema.Type)
+	}
+}
+
+// Helper function to set the range keywords of a number from the minInclusive, minExclusive,
+// maxInclusive and maxExclusive facets of its element
+func setBounds(schema *Schema, element xsd.Element) {
+	lower, upper := element.Bounds()
+	if lower.Exclusive {
+		schema.ExclusiveMinimum = jsonNumber(lower.Value)
+	} else {
+		schema.Minimum = jsonNumber(lower.Value)
+	}
+	if upper.Exclusive {
+		schema.ExclusiveMaximum = jsonNumber(upper.Value)
+	} else {
+		schema.Maximum = jsonNumber(upper.Value)
+	}
+}
+
+// Helper function to convert a facet value to a JSON number, "" when it is not one
+func jsonNumber(value string) json.Number {
+	value = strings.TrimPrefix(value, "+")
+	if _, err := strconv.ParseFloat(value, 64); err != nil || !json.Valid([]byte(value)) {
+		return ""
+	}
+	return json.Number(value)
+}
+
+// Helper function to convert an enumeration value to the JSON type of its schema,
+// keeping the original string when it does not parse
+func enumValue(value, jsonType string) any {
+	switch jsonType {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// Function to marshal a JSON Schema to indented JSON
+func Marshal(schema *Schema) ([]byte, error) {
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling JSON Schema: %w", err)
+	}
+	return schemaJSON, nil
+}
@@ -0,0 +1,587 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Keywords constraining the content of a schema in ways that schema declarations do not describe
+var skippedKeywords = []string{"not", "if", "dependentSchemas", "dependencies", "prefixItems", "contains", "$dynamicRef"}
+
+// Locations of the named schemas that $ref may point to
+var definitionLocations = []string{"#/$defs/", "#/definitions/"}
+
+// Function to read a JSON Schema document from a file
+func ReadFile(inputFile string) (*Schema, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Function to read a JSON Schema document from r
+func Read(r io.Reader) (*Schema, error) {
+	var document Schema
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON Schema: %w", err)
+	}
+	return &document, nil
+}
+
+// Function to unmarshal a JSON Schema object. The type may also be a list of types such as
+// ["string", "null"], and the boolean schemas true and false read as empty schemas.
+func (schema *Schema) UnmarshalJSON(data []byte) error {
+	if trimmed := string(bytes.TrimSpace(data)); trimmed == "true" || trimmed == "false" {
+		*schema = Schema{}
+		return nil
+	}
+	type plain Schema
+	var keywords struct {
+		*plain
+		Type                 any             `json:"type"`
+		AdditionalProperties json.RawMessage `json:"additionalProperties"`
+		PatternProperties    json.RawMessage `json:"patternProperties"`
+		ExclusiveMinimum     json.RawMessage `json:"exclusiveMinimum"`
+		ExclusiveMaximum     json.RawMessage `json:"exclusiveMaximum"`
+	}
+	keywords.plain = (*plain)(schema)
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+
+	var types []any
+	switch value := keywords.Type.(type) {
+	case string:
+		types = []any{value}
+	case []any:
+		types = value
+	}
+	schema.Type, schema.types, schema.nullable = "", nil, false
+	for _, value := range types {
+		switch name, _ := value.(string); name {
+		case "null":
+			schema.nullable = true
+		case "":
+			return fmt.Errorf("invalid type %v", value)
+		default:
+			schema.types = append(schema.types, name)
+		}
+	}
+	if len(schema.types) > 0 {
+		schema.Type = schema.types[0]
+	}
+
+	var err error
+	if schema.ExclusiveMinimum, schema.Minimum, err = exclusiveBound(keywords.ExclusiveMinimum, schema.Minimum); err != nil {
+		return fmt.Errorf("invalid exclusiveMinimum: %w", err)
+	}
+	if schema.ExclusiveMaximum, schema.Maximum, err = exclusiveBound(keywords.ExclusiveMaximum, schema.Maximum); err != nil {
+		return fmt.Errorf("invalid exclusiveMaximum: %w", err)
+	}
+
+	// Additional properties are only more content when they are described by a schema
+	additional := string(bytes.TrimSpace(keywords.AdditionalProperties))
+	schema.open = (additional != "" && additional != "true" && additional != "false") || len(keywords.PatternProperties) > 0
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	schema.skipped = nil
+	for _, keyword := range skippedKeywords {
+		if _, ok := all[keyword]; ok {
+			schema.skipped = append(schema.skipped, keyword)
+		}
+	}
+	return nil
+}
+
+// Helper function to read exclusiveMinimum or exclusiveMaximum, a number since draft 6 and
+// before it a boolean making the minimum or maximum exclusive. Returns the exclusive and
+// the inclusive bounds.
+func exclusiveBound(keyword json.RawMessage, inclusive json.Number) (json.Number, json.Number, error) {
+	switch string(bytes.TrimSpace(keyword)) {
+	case "", "false":
+		return "", inclusive, nil
+	case "true":
+		return inclusive, "", nil
+	}
+	var exclusive json.Number
+	if err := json.Unmarshal(keyword, &exclusive); err != nil {
+		return "", "", err
+	}
+	return exclusive, inclusive, nil
+}
+
+// Function to translate a JSON Schema document into schema declarations resolved by parser,
+// so that the Workato schema and the other outputs can be generated from it. The properties
+// of the root object become global elements, nested objects complexTypes, arrays repeating
+// elements, and named schemas of $defs or definitions named complexTypes, whose recursion
+// is truncated like the one of XSD types. Properties marked with the attribute prefix
+// become attributes, a #text property next to them the simpleContent of their element,
+// and the branches of oneOf and anyOf those of a choice.
+func ToSchema(document *Schema, parser xsd.Parser, opts Options) (xsd.Schema, error) {
+	r := &reader{opts: opts, document: document, types: make(map[string]string), inlining: make(map[string]bool)}
+	r.declarations.Namespaces = map[string]string{"xs": xsd.Namespace}
+
+	var content content
+	r.content(&content, document, "the root object", true)
+	if len(content.particles) == 0 && len(content.attributes) == 0 {
+		return xsd.Schema{}, errors.New("the root of the JSON Schema must be an object with properties")
+	}
+	r.declarations.Elements = globalElements(content.particles, false)
+	for _, attribute := range content.attributes {
+		r.declarations.Elements = append(r.declarations.Elements, attribute.AsElement())
+	}
+	if len(content.unsupported) > 0 {
+		r.warnSkipped("JSON Schema additional properties of the root object are not supported and were skipped")
+	}
+
+	resolved, err := parser.Resolve(r.declarations, "")
+	if err != nil {
+		return xsd.Schema{}, err
+	}
+	resolved.Warnings = append(resolved.Warnings, r.warnings...)
+	resolved.Unsupported = append(resolved.Unsupported, r.warnings...)
+	return resolved, nil
+}
+
+// State of the translation of a JSON Schema document into schema declarations
+type reader struct {
+	opts         Options
+	document     *Schema
+	declarations xsd.Schema
+	types        map[string]string // Name of the complexType of each named schema translated, by $ref
+	inlining     map[string]bool   // Named schemas whose content is being inlined, by $ref
+	warnings     []string          // Keywords skipped, which the declarations do not cover
+}
+
+// Content of an object schema being translated
+type content struct {
+	particles   []xsd.Particle
+	attributes  []xsd.Attribute
+	text        *xsd.Element // Value of the #text property, if any
+	unsupported []xsd.Construct
+}
+
+// Function to declare content as the content model of complexType, the type of owner: a
+// simpleContent extension of the type of its text if it only has attributes besides, or
+// else a sequence of its particles, with mixed content if it has text too.
+func (r *reader) declare(complexType *xsd.ComplexType, c content, owner string) {
+	if c.text != nil && (!c.text.IsLeaf() || c.text.IsRepeating()) {
+		r.warnSkipped("JSON Schema property %s of %s is not a single value, it was skipped", TextProperty, owner)
+		c.text = nil
+	}
+	if c.text == nil || len(c.particles) > 0 {
+		complexType.Mixed = c.text != nil
+		complexType.Attributes = c.attributes
+		complexType.Sequence = &xsd.Compositor{Kind: "sequence", Particles: c.particles, Unsupported: c.unsupported}
+		return
+	}
+	// Facets are not allowed in an extension; the text keeps its built-in type
+	base := c.text.Type
+	if c.text.SimpleType != nil {
+		base = c.text.BaseType()
+	}
+	complexType.SimpleContent = &xsd.SimpleContent{Extension: &xsd.Derivation{Base: base, Attributes: c.attributes, Unsupported: c.unsupported}}
+}
+
+// Helper function to list the elements of the content of the root object as global elements.
+// The elements of the branches of a choice are optional, since global elements cannot be
+// grouped in a choice.
+func globalElements(particles []xsd.Particle, optional bool) []xsd.Element {
+	var elements []xsd.Element
+	for _, particle := range particles {
+		switch {
+		case particle.Element != nil:
+			element := *particle.Element
+			if optional {
+				element.MinOccurs = "0"
+			}
+			elements = append(elements, element)
+		case particle.Group != nil:
+			elements = append(elements, globalElements(particle.Group.Particles, optional || particle.Group.Kind == "choice")...)
+		}
+	}
+	return elements
+}
+
+// Function to add a warning about a keyword that was skipped, unless it was given already
+func (r *reader) warnSkipped(format string, args ...any) {
+	warning := fmt.Sprintf(format, args...)
+	if !slices.Contains(r.warnings, warning) {
+		r.warnings = append(r.warnings, warning)
+	}
+}
+
+// Function to look up the named schema a $ref points to, with its name
+func (r *reader) definition(ref string) (string, *Schema, bool) {
+	for _, location := range definitionLocations {
+		name, found := strings.CutPrefix(ref, location)
+		if !found {
+			continue
+		}
+		definitions := r.document.Defs
+		if location == "#/definitions/" {
+			definitions = r.document.Definitions
+		}
+		if definition, ok := definitions.Get(name); ok {
+			return name, definition, true
+		}
+	}
+	return "", nil, false
+}
+
+// Function to follow the $refs of a schema to the first schema that is an object or is not
+// a reference, keeping objects named so that their complexType may be shared
+func (r *reader) target(schema *Schema) *Schema {
+	for visited := map[string]bool{}; schema.Ref != "" && !visited[schema.Ref]; {
+		visited[schema.Ref] = true
+		_, definition, ok := r.definition(schema.Ref)
+		if !ok || r.isObject(definition) {
+			return schema
+		}
+		schema = definition
+	}
+	return schema
+}
+
+// Function to check whether a schema describes an object with properties, directly or
+// through its allOf, oneOf or anyOf branches and the named schemas they refer to
+func (r *reader) isObject(schema *Schema) bool {
+	if _, definition, ok := r.definition(schema.Ref); ok {
+		schema = definition
+	}
+	if schema.Type == "object" || len(schema.Properties) > 0 {
+		return true
+	}
+	for _, branch := range slices.Concat(schema.AllOf, schema.OneOf, schema.AnyOf) {
+		if _, definition, ok := r.definition(branch.Ref); ok {
+			branch = definition
+		}
+		if branch.Type == "object" || len(branch.Properties) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Function to translate a property into an element, named name
+func (r *reader) element(name string, schema *Schema, required bool) xsd.Element {
+	target := r.target(schema)
+	element := xsd.Element{Name: name, Nillable: schema.nullable || target.nullable}
+	if !required {
+		element.MinOccurs = "0"
+	}
+
+	value := target
+	if target.Type == "array" || (target.Type == "" && target.Items != nil) {
+		element.MaxOccurs = "unbounded"
+		value = &Schema{}
+		if target.Items != nil {
+			value = r.target(target.Items)
+		}
+		if value.Type == "array" {
+			r.warnSkipped("JSON Schema arrays of arrays of %s are not supported, the inner arrays were read as strings", name)
+			value = &Schema{}
+		}
+	}
+
+	// The description may be given by the property, by the schema it refers to or by the items
+	for _, described := range []*Schema{schema, target, value} {
+		// The facets of the value tell which sentences of the description Generate appended
+		facetsOf := *value
+		facetsOf.Description, facetsOf.Title = described.Description, described.Title
+		if element.Annotation = annotation(&facetsOf); element.Annotation != nil {
+			break
+		}
+	}
+	r.describe(&element, value, name)
+	return element
+}
+
+// Function to describe the content of an element from a schema that is not an array: the
+// complexType of an object, or the type and facets of a value
+func (r *reader) describe(element *xsd.Element, schema *Schema, owner string) {
+	for _, keyword := range schema.skipped {
+		r.warnSkipped("JSON Schema %s of %s is not supported and was skipped", keyword, owner)
+	}
+
+	if schema.Ref != "" {
+		name, definition, ok := r.definition(schema.Ref)
+		switch {
+		case !ok:
+			r.warnSkipped("JSON Schema $ref %s of %s is not supported and was skipped", schema.Ref, owner)
+			element.Type = "xs:string"
+		case r.isObject(definition):
+			element.Type = r.namedType(schema.Ref, name, definition)
+		}
+		return
+	}
+	if r.isObject(schema) {
+		var content content
+		r.content(&content, schema, owner, true)
+		element.ComplexType = &xsd.ComplexType{}
+		r.declare(element.ComplexType, content, owner)
+		return
+	}
+
+	// Values of several types are described as a union of the matching XSD types
+	if members := r.memberTypes(schema); len(members) > 1 {
+		element.SimpleType = &xsd.SimpleType{Union: &xsd.Union{MemberTypes: strings.Join(members, " ")}}
+		return
+	}
+	// A single branch of another type than null describes the value, as in oneOf string or null
+	if schema.Type == "" {
+		branches := slices.Concat(schema.OneOf, schema.AnyOf)
+		for _, branch := range branches {
+			if branch = r.target(branch); branch.nullable && branch.Type == "" {
+				element.Nillable = true
+			}
+		}
+		for _, branch := range branches {
+			if branch = r.target(branch); branch.Type != "" {
+				r.describe(element, branch, owner)
+				return
+			}
+		}
+	}
+	typeName := leafType(schema)
+	if schema.Default != nil {
+		element.Default = valueText(schema.Default)
+	}
+	restriction := facets(schema, typeName)
+	values := schema.Enum
+	if schema.Const != nil {
+		values = []any{schema.Const}
+	}
+	for _, value := range values {
+		if value != nil {
+			restriction.Enumerations = append(restriction.Enumerations, xsd.Facet{Value: valueText(value)})
+		}
+	}
+	if reflect.DeepEqual(restriction, xsd.Restriction{Base: typeName}) {
+		element.Type = typeName
+		return
+	}
+	element.SimpleType = &xsd.SimpleType{Restriction: restriction}
+}
+
+// Function to get the name of the complexType of a named object schema, declaring it the
+// first time it is referenced. The type is declared before its content is translated, so
+// that recursive references find it.
+func (r *reader) namedType(ref, name string, definition *Schema) string {
+	if typeName, ok := r.types[ref]; ok {
+		return typeName
+	}
+	typeName := name
+	for i := 2; slices.ContainsFunc(r.declarations.ComplexTypes, func(complexType xsd.ComplexType) bool { return complexType.Name == typeName }); i++ {
+		typeName = name + strconv.Itoa(i)
+	}
+	r.types[ref] = typeName
+	index := len(r.declarations.ComplexTypes)
+	r.declarations.ComplexTypes = append(r.declarations.ComplexTypes, xsd.ComplexType{Name: typeName})
+
+	var content content
+	r.content(&content, definition, "complexType "+typeName, true)
+	complexType := &r.declarations.ComplexTypes[index]
+	complexType.Annotation = annotation(definition)
+	r.declare(complexType, content, "complexType "+typeName)
+	return typeName
+}
+
+// Function to add the properties of an object schema, and those of its branches, to content.
+// Properties of oneOf and anyOf branches are optional, since another branch may be taken,
+// and references to named objects in allOf inline their properties, as an extension would.
+func (r *reader) content(c *content, schema *Schema, owner string, required bool) {
+	for _, keyword := range schema.skipped {
+		r.warnSkipped("JSON Schema %s of %s is not supported and was skipped", keyword, owner)
+	}
+	if schema.Ref != "" {
+		_, definition, ok := r.definition(schema.Ref)
+		switch {
+		case !ok:
+			r.warnSkipped("JSON Schema $ref %s of %s is not supported and was skipped", schema.Ref, owner)
+		case r.inlining[schema.Ref]:
+			r.warnSkipped("JSON Schema $ref %s of %s refers to itself and was skipped", schema.Ref, owner)
+		default:
+			r.inlining[schema.Ref] = true
+			r.content(c, definition, owner, required)
+			delete(r.inlining, schema.Ref)
+		}
+	}
+	if schema.open {
+		c.unsupported = append(c.unsupported, xsd.Construct{XMLName: xml.Name{Space: xsd.Namespace, Local: "any"}})
+	}
+
+	for _, property := range schema.Properties {
+		propertyRequired := required && slices.Contains(schema.Required, property.Name)
+		if name, ok := strings.CutPrefix(property.Name, r.opts.AttributePrefix); ok && r.opts.AttributePrefix != "" {
+			c.attributes = append(c.attributes, r.attribute(name, property.Schema, propertyRequired))
+			continue
+		}
+		if property.Name == WildcardProperty {
+			wildcard := &xsd.Any{ProcessContents: "lax"}
+			if !propertyRequired {
+				wildcard.MinOccurs = "0"
+			}
+			c.particles = append(c.particles, xsd.Particle{Any: wildcard})
+			continue
+		}
+		element := r.element(property.Name, property.Schema, propertyRequired)
+		if property.Name == TextProperty {
+			c.text = &element
+			continue
+		}
+		c.particles = append(c.particles, xsd.Particle{Element: &element})
+	}
+	for _, branch := range schema.AllOf {
+		r.content(c, branch, owner, required)
+	}
+
+	// Only one of the branches of oneOf applies, and any of anyOf
+	for _, branches := range [][]*Schema{schema.OneOf, schema.AnyOf} {
+		choice := &xsd.Compositor{Kind: "choice"}
+		for _, branch := range branches {
+			if !r.isObject(branch) {
+				continue
+			}
+			var branchContent content
+			r.content(&branchContent, branch, owner, true)
+			for _, attribute := range branchContent.attributes {
+				attribute.Use = ""
+				c.attributes = append(c.attributes, attribute)
+			}
+			c.unsupported = append(c.unsupported, branchContent.unsupported...)
+			choice.Particles = append(choice.Particles, xsd.Particle{Group: &xsd.Compositor{Kind: "sequence", Particles: branchContent.particles}})
+		}
+		if len(choice.Particles) > 0 {
+			c.particles = append(c.particles, xsd.Particle{Group: choice})
+		}
+	}
+}
+
+// Function to translate a property marked with the attribute prefix into an attribute, named name
+func (r *reader) attribute(name string, schema *Schema, required bool) xsd.Attribute {
+	leaf := r.element(name, schema, required)
+	if !leaf.IsLeaf() || leaf.IsRepeating() {
+		r.warnSkipped("JSON Schema attribute property %s%s is not a single value, it was read as a string", r.opts.AttributePrefix, name)
+		leaf.Type, leaf.SimpleType = "xs:string", nil
+	}
+	attribute := xsd.Attribute{
+		Name:       leaf.Name,
+		Type:       leaf.Type,
+		Default:    leaf.Default,
+		SimpleType: leaf.SimpleType,
+		Annotation: leaf.Annotation,
+	}
+	if required {
+		attribute.Use = "required"
+	}
+	return attribute
+}
+
+// Function to list the XSD types of a schema whose values may have several types, from its
+// list of types or from the branches of oneOf and anyOf
+func (r *reader) memberTypes(schema *Schema) []string {
+	var members []string
+	for _, name := range schema.types {
+		members = append(members, leafType(&Schema{Type: name, Format: schema.Format}))
+	}
+	for _, branch := range slices.Concat(schema.OneOf, schema.AnyOf) {
+		if branch = r.target(branch); branch.Type != "" {
+			members = append(members, leafType(branch))
+		}
+	}
+	return slices.Compact(members)
+}
+
+// Helper function to map the type and format of a value schema to an XSD built-in type
+func leafType(schema *Schema) string {
+	switch schema.Type {
+	case "integer":
+		return "xs:integer"
+	case "number":
+		return "xs:decimal"
+	case "boolean":
+		return "xs:boolean"
+	}
+	switch schema.Format {
+	case "date-time":
+		return "xs:dateTime"
+	case "date":
+		return "xs:date"
+	case "time":
+		return "xs:time"
+	}
+	return "xs:string"
+}
+
+// Helper function to get the facets of a value schema restricting typeName, from its
+// maxLength and range keywords
+func facets(schema *Schema, typeName string) xsd.Restriction {
+	restriction := xsd.Restriction{Base: typeName}
+	if schema.MaxLength > 0 {
+		restriction.MaxLength = &xsd.Facet{Value: strconv.Itoa(schema.MaxLength)}
+	}
+	bounds := []struct {
+		keyword json.Number
+		facet   **xsd.Facet
+	}{
+		{schema.Minimum, &restriction.MinInclusive},
+		{schema.ExclusiveMinimum, &restriction.MinExclusive},
+		{schema.Maximum, &restriction.MaxInclusive},
+		{schema.ExclusiveMaximum, &restriction.MaxExclusive},
+	}
+	for _, bound := range bounds {
+		if bound.keyword != "" {
+			*bound.facet = &xsd.Facet{Value: bound.keyword.String()}
+		}
+	}
+	return restriction
+}
+
+// Helper function to build the annotation of a schema from its description, or its title
+// when it has none. The sentences that Generate appends for the maxLength and range
+// keywords are left out, since the facets add them back.
+func annotation(schema *Schema) *xsd.Annotation {
+	documentation := schema.Description
+	restriction := facets(schema, "")
+	if sentences := workato.Hint(xsd.Element{SimpleType: &xsd.SimpleType{Restriction: restriction}}); sentences != "" {
+		documentation = strings.TrimSuffix(documentation, sentences)
+		documentation = strings.TrimSuffix(strings.TrimSpace(documentation), ".")
+	}
+	if documentation == "" {
+		documentation = schema.Title
+	}
+	if documentation == "" {
+		return nil
+	}
+	return &xsd.Annotation{Documentation: []string{documentation}}
+}
+
+// Helper function to write an enumeration or default value as text, such as 42 or true
+func valueText(value any) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
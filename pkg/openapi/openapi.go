@@ -0,0 +1,174 @@
+// Package openapi generates an OpenAPI 3.1 components fragment describing a parsed XSD
+// schema, for documenting XML contracts in API portals.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/jsonschema"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Location of the component schemas referenced by $ref
+const SchemasLocation = "#/components/schemas/"
+
+// Strings written as plain YAML scalars; others are quoted
+var plainScalar = regexp.MustCompile(`^[A-Za-z_/$][A-Za-z0-9_ ./$()-]*$`)
+
+// Plain YAML scalars read as something else than a string
+var reservedScalars = map[string]bool{"true": true, "false": true, "null": true, "yes": true, "no": true, "on": true, "off": true, "y": true, "n": true}
+
+// Options controlling how the fragment is generated
+type Options struct {
+	AttributePrefix string // Prefix marking properties generated from XML attributes
+}
+
+// Document is an OpenAPI fragment holding only component schemas, to be merged into an
+// API description
+type Document struct {
+	Components Components `json:"components"`
+}
+
+// Components of an OpenAPI document
+type Components struct {
+	Schemas jsonschema.Properties `json:"schemas"`
+}
+
+// Function to generate the component schemas of the global elements of a schema and of the
+// named complex types they use, which are referenced with $ref. OpenAPI 3.1 schemas are
+// JSON Schemas, with xml objects naming the attributes and the namespace of the roots.
+func Generate(schema xsd.Schema, opts Options) Document {
+	schemaOpts := jsonschema.Options{AttributePrefix: opts.AttributePrefix, XML: true}
+	return Document{Components{jsonschema.Definitions(schema, schemaOpts, SchemasLocation)}}
+}
+
+// Function to marshal an OpenAPI fragment to indented JSON
+func Marshal(document Document) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return nil, fmt.Errorf("error marshaling OpenAPI document: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Function to marshal an OpenAPI fragment to YAML, keeping the order of the properties
+func MarshalYAML(document Document) ([]byte, error) {
+	documentJSON, err := Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(documentJSON))
+	decoder.UseNumber()
+	root, err := decodeNode(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling OpenAPI document: %w", err)
+	}
+	var sb strings.Builder
+	writeMapping(&sb, root, 0, false)
+	return []byte(strings.TrimSuffix(sb.String(), "\n")), nil
+}
+
+// Node of a decoded JSON document, keeping the order of object members
+type node struct {
+	kind   json.Delim // '{' for objects, '[' for arrays, 0 for scalars
+	keys   []string   // Member names of objects
+	values []node     // Member values of objects, or items of arrays
+	scalar string     // YAML representation of scalars
+}
+
+// Function to decode the next JSON value into a node
+func decodeNode(decoder *json.Decoder) (node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return node{}, err
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		n := node{kind: token}
+		for decoder.More() {
+			if token == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return node{}, err
+				}
+				n.keys = append(n.keys, key.(string))
+			}
+			value, err := decodeNode(decoder)
+			if err != nil {
+				return node{}, err
+			}
+			n.values = append(n.values, value)
+		}
+		if _, err := decoder.Token(); err != nil && err != io.EOF {
+			return node{}, err
+		}
+		return n, nil
+	case string:
+		return node{scalar: yamlString(token)}, nil
+	case json.Number:
+		return node{scalar: token.String()}, nil
+	case bool:
+		return node{scalar: fmt.Sprint(token)}, nil
+	}
+	return node{scalar: "null"}, nil
+}
+
+// Function to write the members of an object, indented by indent spaces. The first member
+// follows on the current line when inline is set, as in the items of a sequence.
+func writeMapping(sb *strings.Builder, n node, indent int, inline bool) {
+	for i, key := range n.keys {
+		if i > 0 || !inline {
+			sb.WriteString(strings.Repeat(" ", indent))
+		}
+		sb.WriteString(yamlString(key) + ":")
+		writeValue(sb, n.values[i], indent+2)
+	}
+}
+
+// Function to write a value after a mapping key or a sequence dash: scalars and empty
+// collections on the same line, other collections on the following lines
+func writeValue(sb *strings.Builder, n node, indent int) {
+	switch {
+	case n.kind == '{' && len(n.keys) > 0:
+		sb.WriteString("\n")
+		writeMapping(sb, n, indent, false)
+	case n.kind == '[' && len(n.values) > 0:
+		sb.WriteString("\n")
+		for _, item := range n.values {
+			sb.WriteString(strings.Repeat(" ", indent) + "-")
+			if item.kind == '{' && len(item.keys) > 0 {
+				sb.WriteString(" ")
+				writeMapping(sb, item, indent+2, true)
+			} else {
+				writeValue(sb, item, indent+2)
+			}
+		}
+	case n.kind == '{':
+		sb.WriteString(" {}\n")
+	case n.kind == '[':
+		sb.WriteString(" []\n")
+	default:
+		sb.WriteString(" " + n.scalar + "\n")
+	}
+}
+
+// Helper function to write a string as a plain YAML scalar when it reads back as the same
+// string, or else as a double-quoted one
+func yamlString(value string) string {
+	if plainScalar.MatchString(value) && !reservedScalars[strings.ToLower(value)] && !strings.HasSuffix(value, " ") {
+		return value
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
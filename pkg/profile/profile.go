@@ -0,0 +1,208 @@
+// Package profile adapts parsed schemas and the Workato options to the conventions of
+// well-known families of XSDs, such as the IDoc schemas exported by SAP or the ISO 20022
+// financial messages.
+package profile
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Profile holds the conventions of a family of XSDs
+type Profile struct {
+	Name        string // Name selecting the profile, such as sap-idoc
+	Description string // One-line summary shown in the usage
+
+	apply func(schema xsd.Schema, opts workato.Options) (xsd.Schema, workato.Options)
+}
+
+// Names of the built-in profiles
+const (
+	SAPIDoc  = "sap-idoc"
+	ISO20022 = "iso20022"
+)
+
+// Built-in profiles keyed by name
+var profiles = map[string]Profile{
+	SAPIDoc: {
+		Name:        SAPIDoc,
+		Description: "SAP IDoc segments (E1*, Z1*, EDI_DC40) named after the segment, SEGMENT and BEGIN attributes written as constants",
+		apply:       applySAPIDoc,
+	},
+	ISO20022: {
+		Name:        ISO20022,
+		Description: "ISO 20022 messages (pain, pacs, camt) with amounts as currency fields and abbreviated tags spelled out in the labels",
+		apply:       applyISO20022,
+	},
+}
+
+// Function to look up a built-in profile by name
+func Lookup(name string) (Profile, error) {
+	profile, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q, must be one of %v", name, Names())
+	}
+	return profile, nil
+}
+
+// Function to list the names of the built-in profiles, sorted
+func Names() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Function to apply the profile to a resolved schema and the options it is generated with.
+// The schema passed in is left unchanged, and settings already in the options, such as the
+// overrides of a config file, take precedence over those of the profile.
+func (profile Profile) Apply(schema xsd.Schema, opts workato.Options) (xsd.Schema, workato.Options) {
+	if profile.apply == nil {
+		return schema, opts
+	}
+	return profile.apply(schema, opts)
+}
+
+// Names of IDoc segments: standard (E1, E2) and customer (Z1, Z2) segments, and the control record
+var idocSegment = regexp.MustCompile(`^([EZ][12][A-Z0-9_]+|EDI_DC40)$`)
+
+// Function to apply the SAP IDoc conventions. Segments, recognised by their name or their
+// SEGMENT attribute, are named after the segment instead of their parent, so that the
+// repeating ones become arrays such as E1EDP01 that read like the IDoc documentation. The
+// SEGMENT and BEGIN qualifiers stay attributes of the XML but are written by the template
+// as constants rather than being mapped.
+func applySAPIDoc(schema xsd.Schema, opts workato.Options) (xsd.Schema, workato.Options) {
+	overrides := make(map[string]workato.FieldOverride)
+	schema.Elements = slices.Clone(schema.Elements)
+	for i := range schema.Elements {
+		schema.Elements[i] = idocElement(schema.Elements[i], schema.Elements[i].Name, overrides)
+	}
+	opts.Overrides = mergeOverrides(overrides, opts.Overrides)
+	return schema, opts
+}
+
+// Helper function to merge the overrides of a profile with those of the options, whose
+// settings keep precedence over the ones of the profile for the same path
+func mergeOverrides(profile, config map[string]workato.FieldOverride) map[string]workato.FieldOverride {
+	for path, override := range config {
+		if override.Name == "" {
+			override.Name = profile[path].Name
+		}
+		if override.Type == "" {
+			override.Type = profile[path].Type
+		}
+		if override.ControlType == "" {
+			override.ControlType = profile[path].ControlType
+		}
+		profile[path] = override
+	}
+	return profile
+}
+
+// Function to apply the SAP IDoc conventions to the element at path and its descendants,
+// collecting the names of the segments in overrides
+func idocElement(element xsd.Element, path string, overrides map[string]workato.FieldOverride) xsd.Element {
+	element.Attributes = slices.Clone(element.Attributes)
+	segment := idocSegment.MatchString(element.Name)
+	for i, attribute := range element.Attributes {
+		if attribute.Name != "SEGMENT" && attribute.Name != "BEGIN" {
+			continue
+		}
+		segment = segment || attribute.Name == "SEGMENT"
+		if attribute.Fixed == "" {
+			// SAP declares the qualifiers as an enumeration of the single value 1
+			element.Attributes[i].Fixed = "1"
+			if values := attribute.AsElement().Enumerations(); len(values) == 1 {
+				element.Attributes[i].Fixed = values[0]
+			}
+		}
+	}
+	if segment && !element.IsLeaf() {
+		overrides[path] = workato.FieldOverride{Name: element.Name}
+	}
+
+	element.Children = slices.Clone(element.Children)
+	for i, child := range element.Children {
+		element.Children[i] = idocElement(child, workato.ChildPath(path, child.Name), overrides)
+	}
+	return element
+}
+
+// Abbreviations of the ISO 20022 XML tags, spelled out in the labels so that GrpHdr_MsgId
+// reads "Group Header Message Identification"
+var isoAbbreviations = map[string]string{
+	"Acct": "Account", "Addtl": "Additional", "Adr": "Address", "Agt": "Agent", "Amt": "Amount",
+	"Bal": "Balance", "Bk": "Bank", "Bookg": "Booking", "Br": "Branch", "Btch": "Batch",
+	"Ccy": "Currency", "Cd": "Code", "Cdt": "Credit", "Cdtr": "Creditor", "Chrg": "Charge",
+	"Chrgs": "Charges", "Cre": "Creation", "Cstmr": "Customer", "Ctrl": "Control", "Ctry": "Country",
+	"Dbt": "Debit", "Dbtr": "Debtor", "Dt": "Date", "Dtls": "Details", "Elctrnc": "Electronic",
+	"Exctn": "Execution", "Fin": "Financial", "Fr": "From", "Grp": "Group", "Hdr": "Header",
+	"Id": "Identification", "Ind": "Indicator", "Inf": "Information", "Initg": "Initiating",
+	"Initn": "Initiation", "Instd": "Instructed", "Instn": "Institution", "Instr": "Instruction",
+	"Issr": "Issuer", "Lvl": "Level", "Msg": "Message", "Mtd": "Method", "Nb": "Number", "Nm": "Name",
+	"Ntry": "Entry", "Org": "Organisation", "Orgnl": "Original", "Othr": "Other", "Pg": "Page",
+	"Pmt": "Payment", "Prtry": "Proprietary", "Prvt": "Private", "Pst": "Post", "Pstl": "Postal",
+	"Pties": "Parties", "Pty": "Party", "Purp": "Purpose", "Ref": "Reference", "Refs": "References",
+	"Reqd": "Requested", "Rltd": "Related", "Rmt": "Remittance", "Rpt": "Report", "Rsn": "Reason",
+	"Schme": "Scheme", "Seq": "Sequence", "Stmt": "Statement", "Strd": "Structured", "Strt": "Street",
+	"Sts": "Status", "Svc": "Service", "Svcr": "Servicer", "Tm": "Time", "Tp": "Type",
+	"Trf": "Transfer", "Ttl": "Total", "Twn": "Town", "Tx": "Transaction", "Txs": "Transactions",
+	"Ustrd": "Unstructured", "Val": "Value",
+}
+
+// Function to apply the ISO 20022 conventions. Amounts with a Ccy attribute become currency
+// fields next to their currency code, and the abbreviated tags are spelled out in the
+// labels. Abbreviations of the config take precedence over the ISO ones.
+func applyISO20022(schema xsd.Schema, opts workato.Options) (xsd.Schema, workato.Options) {
+	overrides := make(map[string]workato.FieldOverride)
+	schema.Elements = slices.Clone(schema.Elements)
+	for i := range schema.Elements {
+		schema.Elements[i] = isoElement(schema.Elements[i], schema.Elements[i].Name, overrides)
+	}
+	opts.Overrides = mergeOverrides(overrides, opts.Overrides)
+
+	abbreviations := maps.Clone(isoAbbreviations)
+	maps.Copy(abbreviations, opts.Abbreviations)
+	opts.Abbreviations = abbreviations
+	return schema, opts
+}
+
+// Function to apply the ISO 20022 conventions to the element at path and its descendants,
+// collecting the control types of the amounts in overrides
+func isoElement(element xsd.Element, path string, overrides map[string]workato.FieldOverride) xsd.Element {
+	element.Attributes = slices.Clone(element.Attributes)
+	for i, attribute := range element.Attributes {
+		// ActiveOrHistoricCurrencyAndAmount and the like: a decimal with its currency code
+		if attribute.Name == "Ccy" && element.Text != nil {
+			element.Attributes[i].Annotation = annotate(attribute.Annotation, "ISO 4217 currency code, such as EUR")
+			overrides[workato.TextPath(path)] = workato.FieldOverride{Type: "number", ControlType: "currency"}
+		}
+	}
+
+	element.Children = slices.Clone(element.Children)
+	for i, child := range element.Children {
+		element.Children[i] = isoElement(child, workato.ChildPath(path, child.Name), overrides)
+	}
+	return element
+}
+
+// Helper function to append sentences to the documentation of an annotation, returning a
+// new annotation so that the one of the parsed schema is left unchanged
+func annotate(annotation *xsd.Annotation, sentences ...string) *xsd.Annotation {
+	if len(sentences) == 0 {
+		return annotation
+	}
+	if text := annotation.Text(); text != "" {
+		sentences = append([]string{strings.TrimSuffix(text, ".")}, sentences...)
+	}
+	return &xsd.Annotation{Documentation: []string{strings.Join(sentences, ". ")}}
+}
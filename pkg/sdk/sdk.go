@@ -0,0 +1,244 @@
+// Package sdk generates Ruby snippets for custom connectors built with the Workato Connector
+// SDK: object_definitions describing the fields of a parsed XSD schema, and optionally an
+// action posting the XML message rendered from the Mustache template.
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/template/mustache"
+	"github.com/peaz/xsd2wkt/pkg/template/soap"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Options controlling how the snippet is generated. The embedded Workato options name the
+// fields, as in the Workato schema.
+type Options struct {
+	workato.Options
+	Action   bool         // Whether to add an action whose execute block posts the rendered template
+	SOAP     soap.Options // Envelope wrapping the template of the action, if any
+	Skeleton string       // Skeleton the template of the action is injected into, if any
+}
+
+// Ruby methods rendering the subset of Mustache used by the generated templates: variables,
+// dotted names, sections, inverted sections and comments
+const renderMethods = `  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
+`
+
+// Function to generate the snippet of the document root: members of the connector hash,
+// ready to be pasted into a connector. The object definition is named after the root in
+// snake case, and the fields are those of the Workato schema.
+func Generate(schema xsd.Schema, opts Options) (string, error) {
+	if len(schema.Elements) == 0 {
+		return "", fmt.Errorf("schema declares no global element")
+	}
+	fields, err := workato.Generate(schema, opts.Options)
+	if err != nil {
+		return "", err
+	}
+	root := schema.Elements[0]
+	name := workato.ConvertCase(root.Name, workato.CaseSnake)
+
+	var sb strings.Builder
+	sb.WriteString("  object_definitions: {\n")
+	sb.WriteString("    " + name + ": {\n")
+	sb.WriteString("      fields: lambda do |_connection, _config_fields|\n")
+	sb.WriteString("        ")
+	writeFields(&sb, fields, 8)
+	sb.WriteString("\n      end\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("  },\n")
+	if !opts.Action {
+		return sb.String(), nil
+	}
+
+	template := mustache.Generate(schema, mustache.Options{Options: opts.Options, SOAP: opts.SOAP, Skeleton: opts.Skeleton})
+	title := strings.Join(workato.SplitWords(root.Name), " ")
+	sb.WriteString("\n  actions: {\n")
+	sb.WriteString("    send_" + name + ": {\n")
+	sb.WriteString("      title: " + rubyString("Send "+title) + ",\n")
+	sb.WriteString("      input_fields: lambda do |object_definitions|\n")
+	sb.WriteString("        object_definitions[" + rubyString(name) + "]\n")
+	sb.WriteString("      end,\n")
+	sb.WriteString("      execute: lambda do |_connection, input|\n")
+	sb.WriteString("        body = call(:render_mustache, <<~'MUSTACHE', input)\n")
+	for _, line := range strings.Split(strings.TrimSuffix(template, "\n"), "\n") {
+		sb.WriteString("          " + line + "\n")
+	}
+	sb.WriteString("        MUSTACHE\n")
+	sb.WriteString("        # Replace with the endpoint of the API\n")
+	sb.WriteString("        post(\"/" + name + "\")\n")
+	sb.WriteString("          .headers(" + requestHeaders(opts.SOAP) + ")\n")
+	sb.WriteString("          .request_body(body)\n")
+	sb.WriteString("          .response_format_raw\n")
+	sb.WriteString("      end\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("  },\n\n")
+	sb.WriteString(renderMethods)
+	return sb.String(), nil
+}
+
+// Function to write fields as an array of Ruby hashes, opened on the current line and
+// closed indented by indent spaces
+func writeFields(sb *strings.Builder, fields []workato.Field, indent int) {
+	pad := strings.Repeat(" ", indent)
+	if len(fields) == 0 {
+		sb.WriteString("[]")
+		return
+	}
+	sb.WriteString("[\n")
+	for i, field := range fields {
+		writeField(sb, field, indent+2)
+		if i < len(fields)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(pad + "]")
+}
+
+// Function to write a field as a Ruby hash. Pick lists become the options of the select
+// control, which is how the SDK lists static choices.
+func writeField(sb *strings.Builder, field workato.Field, indent int) {
+	pad := strings.Repeat(" ", indent+2)
+	entries := []string{"name: " + rubyString(field.Name)}
+	if field.Label != "" {
+		entries = append(entries, "label: "+rubyString(field.Label))
+	}
+	if field.Type != "" {
+		entries = append(entries, "type: "+rubyString(field.Type))
+	}
+	if field.Of != "" {
+		entries = append(entries, "of: "+rubyString(field.Of))
+	}
+	entries = append(entries, fmt.Sprintf("optional: %t", field.Optional))
+	if field.ControlType != "" {
+		entries = append(entries, "control_type: "+rubyString(field.ControlType))
+	}
+	if field.Hint != "" {
+		entries = append(entries, "hint: "+rubyString(field.Hint))
+	}
+	if field.Default != "" {
+		entries = append(entries, "default: "+rubyString(field.Default))
+	}
+	if len(field.PickList) > 0 {
+		var options []string
+		for _, option := range field.PickList {
+			var values []string
+			for _, value := range option {
+				values = append(values, rubyString(value))
+			}
+			options = append(options, "["+strings.Join(values, ", ")+"]")
+		}
+		entries = append(entries, "options: ["+strings.Join(options, ", ")+"]")
+	}
+	if field.Sticky {
+		entries = append(entries, "sticky: true")
+	}
+	if field.ToggleHint != "" {
+		entries = append(entries, "toggle_hint: "+rubyString(field.ToggleHint))
+	}
+	if field.ToggleField != nil {
+		// The free-text variant is nested in place, without the indentation of a list item
+		var toggle strings.Builder
+		writeField(&toggle, *field.ToggleField, indent+2)
+		entries = append(entries, "toggle_field: "+strings.TrimLeft(toggle.String(), " "))
+	}
+
+	sb.WriteString(strings.Repeat(" ", indent) + "{\n")
+	sb.WriteString(pad + strings.Join(entries, ",\n"+pad))
+	if len(field.Properties) > 0 {
+		sb.WriteString(",\n" + pad + "properties: ")
+		writeFields(sb, field.Properties, indent+2)
+	}
+	sb.WriteString("\n" + strings.Repeat(" ", indent) + "}")
+}
+
+// Helper function to get the headers of the request posting the message, with the SOAP
+// action for SOAP 1.1 envelopes and as a content type parameter for SOAP 1.2
+func requestHeaders(opts soap.Options) string {
+	switch {
+	case opts.Version == soap.Version12 && opts.Action != "":
+		return "\"Content-Type\": " + rubyString("application/soap+xml; charset=utf-8; action=\""+opts.Action+"\"")
+	case opts.Version == soap.Version12:
+		return "\"Content-Type\": \"application/soap+xml; charset=utf-8\""
+	case opts.Version == soap.Version11:
+		return "\"Content-Type\": \"text/xml; charset=utf-8\", \"SOAPAction\": " + rubyString("\""+opts.Action+"\"")
+	}
+	return "\"Content-Type\": \"application/xml\""
+}
+
+// Helper function to quote a Ruby string. JSON string escapes are valid in Ruby double
+// quotes, except that # must be escaped so that it does not start an interpolation.
+func rubyString(value string) string {
+	var sb strings.Builder
+	encoder := json.NewEncoder(&sb)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+	return strings.ReplaceAll(strings.TrimSuffix(sb.String(), "\n"), "#", "\\#")
+}
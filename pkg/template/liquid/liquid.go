@@ -0,0 +1,290 @@
+// Package liquid generates Liquid XML message templates from a parsed XSD schema, with the
+// same field naming as the Mustache templates of package mustache.
+package liquid
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/template/skeleton"
+	"github.com/peaz/xsd2wkt/pkg/template/soap"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Options controlling how the template is generated. The embedded Workato options
+// name the fields the variables refer to.
+type Options struct {
+	workato.Options
+	SOAP     soap.Options // Envelope wrapping the template, if any
+	Skeleton string       // Skeleton the template is injected into, with the placeholders of package skeleton, if any
+
+	names xsd.Qualifier // Tag names of the elements, once resolved against the schema
+}
+
+// Pattern of the names that can be used as plain Liquid identifiers
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Function to generate Liquid template recursively, wrapped in a SOAP envelope if set and
+// injected into the skeleton if any
+func Generate(schema xsd.Schema, opts Options) string {
+	opts.Options = opts.Options.Resolve(schema)
+	if opts.SOAP.BodyNamespace != "" {
+		schema.TargetNamespace = opts.SOAP.BodyNamespace
+	}
+	template := soap.Wrap(generate(schema, opts), opts.SOAP, func(text string) string {
+		return "{% comment %}" + text + "{% endcomment %}"
+	})
+	return skeleton.Inject(template, opts.Skeleton)
+}
+
+// Function to generate the Liquid template of the document root
+func generate(schema xsd.Schema, opts Options) string {
+	var sb strings.Builder
+	sb.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+
+	if len(schema.Elements) == 0 {
+		return sb.String() // Return an empty template if no elements are found
+	}
+
+	// The first global element is the document root; use Schema.SelectRoot to choose another one
+	root := schema.Elements[0]
+	opts.names = schema.Qualifier()
+	rootName, xmlns := opts.names.Root()
+	rootExpression := member("", opts.FieldName(root.Name))
+	if root.IsPassThrough() {
+		sb.WriteString("<" + rootName + xmlns + ">" + rawOutput(rootExpression) + "</" + rootName + ">\n")
+		return sb.String()
+	}
+	if root.IsLeaf() {
+		sb.WriteString("<" + rootName + xmlns + ">" + output(rootExpression) + "</" + rootName + ">\n")
+		return sb.String()
+	}
+
+	writeRequired(&sb, root, root.Name, opts)
+	sb.WriteString("<" + rootName + xmlns + generateAttributes(root, rootExpression, root.Name, opts) + ">")
+	generateContent(&sb, root, rootExpression, root.Name, opts)
+	sb.WriteString("</" + rootName + ">\n")
+
+	return sb.String()
+}
+
+// Function to generate the template for the content of the element at path, whose object
+// is reached through expression, following its start tag: its text on the same line, so
+// that no whitespace is added to the value, and its children on lines of their own. The
+// text of mixed content comes before the children.
+func generateContent(sb *strings.Builder, element xsd.Element, expression, path string, opts Options) {
+	if element.Text != nil {
+		if textPath := workato.TextPath(path); !opts.Excluded(textPath) {
+			sb.WriteString(leafOutput(member(expression, opts.FieldName(textPath)), *element.Text, opts))
+		}
+		if !element.IsMixed() {
+			return
+		}
+	}
+	sb.WriteString("\n")
+	generateElement(sb, element, expression, path, opts)
+}
+
+// Recursive function to generate the template for the children of the element at path,
+// whose object is reached through expression. Repeating children are iterated with a for
+// loop whose variable is named after the child element; choice branches are only
+// rendered when their field is set.
+func generateElement(sb *strings.Builder, element xsd.Element, expression, path string, opts Options) {
+	if element.Truncated {
+		sb.WriteString("{% comment %}" + element.Name + " is recursive, its content was truncated at the maximum depth{% endcomment %}\n")
+	}
+	for _, child := range element.Children {
+		childPath := workato.ChildPath(path, child.Name)
+		if opts.Excluded(childPath) {
+			continue
+		}
+		fieldName := opts.FieldName(childPath)
+		tag := opts.names.Tag(child)
+		fieldPath := member(expression, fieldName)
+
+		choiceBlock := child.ChoiceItem && !child.IsRepeating()
+		if choiceBlock {
+			sb.WriteString("{% if " + fieldPath + " %}\n")
+		}
+
+		// Nillable elements are written as xsi:nil when their field has no value
+		nilBlock := child.NilWhenAbsent()
+		if nilBlock {
+			sb.WriteString("{% if " + fieldPath + " %}\n")
+		}
+
+		// Optional elements are only written when their field is set
+		optionalBlock := opts.OmitsAbsent(child)
+		if optionalBlock {
+			sb.WriteString("{% if " + fieldPath + " %}\n")
+		}
+
+		switch {
+		case child.IsFixed():
+			sb.WriteString("<" + tag + ">" + html.EscapeString(child.Fixed) + "</" + tag + ">\n")
+		case child.IsWildcard():
+			// The XML fragment matching the wildcard is written as is, without the escape filter
+			sb.WriteString(rawOutput(fieldPath) + "\n")
+		case child.IsPassThrough() && child.IsRepeating():
+			item := loopVariable(child.Name)
+			sb.WriteString("{% for " + item + " in " + fieldPath + " %}<" + tag + ">" + rawOutput(item) + "</" + tag + ">{% endfor %}\n")
+		case child.IsPassThrough():
+			sb.WriteString("<" + tag + ">" + rawOutput(fieldPath) + "</" + tag + ">\n")
+		case child.IsLeaf():
+			writeSkipped(sb, child)
+			if values := child.Enumerations(); len(values) > 0 {
+				sb.WriteString("{% comment %}" + fieldName +
+					" must be one of: " + strings.Join(values, ", ") + "{% endcomment %}\n")
+			}
+			expression := fieldPath
+			if child.IsList() && !child.IsRepeating() {
+				expression += " | join: ' '"
+			}
+			if child.IsRepeating() {
+				// Each value of the array is written in an element of its own
+				item := loopVariable(child.Name)
+				sb.WriteString("{% for " + item + " in " + fieldPath + " %}<" + tag + ">" + leafOutput(item, child, opts) + "</" + tag + ">{% endfor %}\n")
+				break
+			}
+			sb.WriteString("<" + tag + ">" + leafOutput(expression, child, opts) + "</" + tag + ">\n")
+		case child.IsRepeating():
+			item := loopVariable(child.Name)
+			sb.WriteString("{% for " + item + " in " + fieldPath + " %}\n")
+			writeRequired(sb, child, childPath, opts)
+			sb.WriteString("<" + tag + generateAttributes(child, item, childPath, opts) + ">")
+			generateContent(sb, child, item, childPath, opts)
+			sb.WriteString("</" + tag + ">\n")
+			sb.WriteString("{% endfor %}\n")
+		default:
+			writeRequired(sb, child, childPath, opts)
+			sb.WriteString("<" + tag + generateAttributes(child, fieldPath, childPath, opts) + ">")
+			generateContent(sb, child, fieldPath, childPath, opts)
+			sb.WriteString("</" + tag + ">\n")
+		}
+
+		if optionalBlock {
+			sb.WriteString("{% endif %}\n")
+		}
+		if nilBlock {
+			sb.WriteString("{% else %}\n")
+			sb.WriteString("<" + tag + " xmlns:xsi=\"" + xsd.InstanceNamespace + "\" xsi:nil=\"true\"/>\n")
+			sb.WriteString("{% endif %}\n")
+		}
+
+		if choiceBlock {
+			sb.WriteString("{% endif %}\n")
+		}
+	}
+
+	writeSkipped(sb, element)
+
+	// The content of a polymorphic element is that of its concrete type, chosen by populating its object
+	for _, alternative := range element.Alternatives {
+		alternativePath := workato.ChildPath(path, alternative.Name)
+		if opts.Excluded(alternativePath) {
+			continue
+		}
+		fieldPath := member(expression, opts.FieldName(alternativePath))
+		sb.WriteString("{% if " + fieldPath + " %}\n")
+		generateElement(sb, alternative, fieldPath, alternativePath, opts)
+		sb.WriteString("{% endif %}\n")
+	}
+}
+
+// Helper function to write a comment in place of the content skipped because the
+// constructs declaring it are not supported
+func writeSkipped(sb *strings.Builder, element xsd.Element) {
+	for _, construct := range element.Skipped {
+		sb.WriteString("{% comment %}xs:" + construct + " in " + element.Name + " is not supported, its content was skipped{% endcomment %}\n")
+	}
+}
+
+// Helper function to write a comment noting the required attributes of the element at path,
+// which the template writes even when their field is empty
+func writeRequired(sb *strings.Builder, element xsd.Element, path string, opts Options) {
+	if note := opts.RequiredAttributesNote(element, path); note != "" {
+		sb.WriteString("{% comment %}" + note + "{% endcomment %}\n")
+	}
+}
+
+// Function to generate the XML attributes of the start tag of the element at path, whose
+// object is reached through expression
+func generateAttributes(element xsd.Element, expression, path string, opts Options) string {
+	var sb strings.Builder
+	for _, attribute := range element.Attributes {
+		attributePath := workato.ChildPath(path, "@"+attribute.Name)
+		if opts.Excluded(attributePath) {
+			continue
+		}
+		if attribute.Fixed != "" {
+			sb.WriteString(" " + attribute.Name + "=\"" + html.EscapeString(attribute.Fixed) + "\"")
+			continue
+		}
+		fieldName := opts.FieldName(attributePath)
+		fieldPath := member(expression, fieldName)
+		if !opts.OmitsEmpty(attribute) {
+			sb.WriteString(" " + attribute.Name + "=\"" + leafOutput(fieldPath, attribute.AsElement(), opts) + "\"")
+			continue
+		}
+		// Optional attributes are only written when their field has a value
+		sb.WriteString("{% if " + fieldPath + " != blank %} " + attribute.Name + "=\"" + leafOutput(fieldPath, attribute.AsElement(), opts) + "\"{% endif %}")
+	}
+	if len(element.Alternatives) > 0 {
+		fieldPath := member(expression, opts.FieldName(workato.TypePath(path)))
+		sb.WriteString(" xmlns:xsi=\"" + xsd.InstanceNamespace + "\" xsi:type=\"" + output(fieldPath) + "\"")
+
+		// The attributes of the concrete type are written when its object is populated
+		for _, alternative := range element.Alternatives {
+			alternativePath := workato.ChildPath(path, alternative.Name)
+			if len(alternative.Attributes) == 0 || opts.Excluded(alternativePath) {
+				continue
+			}
+			fieldPath := member(expression, opts.FieldName(alternativePath))
+			sb.WriteString("{% if " + fieldPath + " %}" + generateAttributes(alternative, fieldPath, alternativePath, opts) + "{% endif %}")
+		}
+	}
+	return sb.String()
+}
+
+// Helper function to build the expression accessing field of the object at path, using
+// the bracket syntax for names that are not valid identifiers, such as attribute fields.
+// Single quotes keep the expression usable inside double-quoted XML attributes.
+func member(path, field string) string {
+	if identifier.MatchString(field) {
+		if path == "" {
+			return field
+		}
+		return path + "." + field
+	}
+	return path + "['" + field + "']"
+}
+
+// Helper function to name the loop variable iterating over a repeating element
+func loopVariable(elementName string) string {
+	if identifier.MatchString(elementName) {
+		return elementName
+	}
+	return "item"
+}
+
+// Helper function to output an expression, escaped for XML content
+func output(expression string) string {
+	return "{{ " + expression + " | escape }}"
+}
+
+// Helper function to output the value of a leaf element, escaped for XML content. Dates and
+// times are formatted in the lexical form of their XSD type with the date filter, so that
+// datapills of any date format are written as the schema expects.
+func leafOutput(expression string, element xsd.Element, opts Options) string {
+	if format, ok := opts.DateFormat(element); ok && !element.IsList() {
+		expression += " | date: '" + format.Strftime + "'"
+	}
+	return output(expression)
+}
+
+// Helper function to write the value of an expression unescaped, for XML content passed through
+func rawOutput(expression string) string {
+	return "{{ " + expression + " }}"
+}
@@ -0,0 +1,260 @@
+// Package mustache generates Mustache XML message templates from a parsed XSD schema.
+// Placeholders refer to the fields generated by package workato.
+package mustache
+
+import (
+	"html"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/template/skeleton"
+	"github.com/peaz/xsd2wkt/pkg/template/soap"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Options controlling how the template is generated. The embedded Workato options
+// name the fields the placeholders refer to.
+type Options struct {
+	workato.Options
+	SOAP     soap.Options // Envelope wrapping the template, if any
+	Skeleton string       // Skeleton the template is injected into, with the placeholders of package skeleton, if any
+
+	names xsd.Qualifier // Tag names of the elements, once resolved against the schema
+}
+
+// Function to generate Mustache template recursively, wrapped in a SOAP envelope if set and
+// injected into the skeleton if any
+func Generate(schema xsd.Schema, opts Options) string {
+	opts.Options = opts.Options.Resolve(schema)
+	if opts.SOAP.BodyNamespace != "" {
+		schema.TargetNamespace = opts.SOAP.BodyNamespace
+	}
+	template := soap.Wrap(generate(schema, opts), opts.SOAP, func(text string) string {
+		return "{{! " + text + " }}"
+	})
+	return skeleton.Inject(template, opts.Skeleton)
+}
+
+// Function to generate the Mustache template of the document root
+func generate(schema xsd.Schema, opts Options) string {
+	var sb strings.Builder
+	sb.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+
+	// Check if there are any elements
+	if len(schema.Elements) == 0 {
+		return sb.String() // Return an empty template if no elements are found
+	}
+
+	// The first global element is the document root; use Schema.SelectRoot to choose another one
+	root := schema.Elements[0]
+	opts.names = schema.Qualifier()
+	rootName, xmlns := opts.names.Root()
+	rootField := opts.FieldName(root.Name)
+	if root.IsPassThrough() {
+		sb.WriteString("<" + rootName + xmlns + ">{{{" + rootField + "}}}</" + rootName + ">\n")
+		return sb.String()
+	}
+	if root.IsLeaf() {
+		sb.WriteString("<" + rootName + xmlns + ">{{" + rootField + "}}</" + rootName + ">\n")
+		return sb.String()
+	}
+
+	rootContext := rootField + "."
+	writeRequired(&sb, root, root.Name, opts)
+	sb.WriteString("<" + rootName + xmlns + generateAttributes(root, rootContext, root.Name, opts) + ">")
+	generateContent(&sb, root, rootContext, root.Name, opts)
+	sb.WriteString("</" + rootName + ">\n")
+
+	return sb.String()
+}
+
+// Function to generate the template for the content of the element at path, following its
+// start tag: its text on the same line, so that no whitespace is added to the value, and
+// its children on lines of their own. The text of mixed content comes before the children.
+func generateContent(sb *strings.Builder, element xsd.Element, contextPath, path string, opts Options) {
+	if element.Text != nil {
+		if textPath := workato.TextPath(path); !opts.Excluded(textPath) {
+			sb.WriteString("{{" + contextPath + opts.FieldName(textPath) + "}}")
+		}
+		if !element.IsMixed() {
+			return
+		}
+	}
+	sb.WriteString("\n")
+	generateElement(sb, element, contextPath, path, opts)
+}
+
+// Recursive function to generate the template for the children of the element at path.
+// Repeating children open a Mustache section, which becomes the new lookup context;
+// non-repeating complex children are objects reached through a dotted contextPath.
+func generateElement(sb *strings.Builder, element xsd.Element, contextPath, path string, opts Options) {
+	if element.Truncated {
+		sb.WriteString("{{! " + element.Name + " is recursive, its content was truncated at the maximum depth }}\n")
+	}
+	for _, child := range element.Children {
+		childPath := workato.ChildPath(path, child.Name)
+		if opts.Excluded(childPath) {
+			continue
+		}
+		fieldName := opts.FieldName(childPath)
+		tag := opts.names.Tag(child)
+
+		// Choice branches are only rendered when their field is populated
+		choiceSection := child.ChoiceItem && !child.IsRepeating()
+		if choiceSection {
+			sb.WriteString("{{#" + contextPath + fieldName + "}}\n")
+		}
+
+		// Nillable elements are written as xsi:nil when their field has no value
+		nilSection := child.NilWhenAbsent()
+		if nilSection {
+			sb.WriteString("{{#" + contextPath + fieldName + "}}\n")
+		}
+
+		// Optional elements are only written when their field is set
+		optionalSection := opts.OmitsAbsent(child)
+		if optionalSection {
+			sb.WriteString("{{#" + contextPath + fieldName + "}}\n")
+		}
+
+		switch {
+		case child.IsFixed():
+			sb.WriteString("<" + tag + ">" + html.EscapeString(child.Fixed) + "</" + tag + ">\n")
+		case child.IsWildcard():
+			// The XML fragment matching the wildcard is written as is, with a triple mustache
+			sb.WriteString("{{{" + contextPath + fieldName + "}}}\n")
+		case child.IsPassThrough() && child.IsRepeating():
+			sb.WriteString("{{#" + contextPath + fieldName + "}}<" + tag + ">{{{.}}}</" + tag + ">{{/" + contextPath + fieldName + "}}\n")
+		case child.IsPassThrough():
+			sb.WriteString("<" + tag + ">{{{" + contextPath + fieldName + "}}}</" + tag + ">\n")
+		case child.IsLeaf():
+			writeSkipped(sb, child)
+			if values := child.Enumerations(); len(values) > 0 {
+				sb.WriteString("{{! " + fieldName + " must be one of: " + strings.Join(values, ", ") + " }}\n")
+			}
+			writeDateFormat(sb, child, fieldName, opts)
+			if child.IsList() && !child.IsRepeating() {
+				// List items are space-separated; the trailing space is collapsed by the list type
+				sb.WriteString("<" + tag + ">{{#" + contextPath + fieldName + "}}{{.}} {{/" + contextPath + fieldName + "}}</" + tag + ">\n")
+				break
+			}
+			if child.IsRepeating() {
+				// Each value of the array is written in an element of its own
+				sb.WriteString("{{#" + contextPath + fieldName + "}}<" + tag + ">{{.}}</" + tag + ">{{/" + contextPath + fieldName + "}}\n")
+				break
+			}
+			sb.WriteString("<" + tag + ">{{" + contextPath + fieldName + "}}</" + tag + ">\n")
+		case child.IsRepeating():
+			sb.WriteString("{{#" + contextPath + fieldName + "}}\n")
+			writeRequired(sb, child, childPath, opts)
+			sb.WriteString("<" + tag + generateAttributes(child, "", childPath, opts) + ">")
+			generateContent(sb, child, "", childPath, opts)
+			sb.WriteString("</" + tag + ">\n")
+			sb.WriteString("{{/" + contextPath + fieldName + "}}\n")
+		default:
+			childContext := contextPath + fieldName + "."
+			writeRequired(sb, child, childPath, opts)
+			sb.WriteString("<" + tag + generateAttributes(child, childContext, childPath, opts) + ">")
+			generateContent(sb, child, childContext, childPath, opts)
+			sb.WriteString("</" + tag + ">\n")
+		}
+
+		if optionalSection {
+			sb.WriteString("{{/" + contextPath + fieldName + "}}\n")
+		}
+		if nilSection {
+			sb.WriteString("{{/" + contextPath + fieldName + "}}\n")
+			sb.WriteString("{{^" + contextPath + fieldName + "}}\n")
+			sb.WriteString(nilElement(tag) + "\n")
+			sb.WriteString("{{/" + contextPath + fieldName + "}}\n")
+		}
+
+		if choiceSection {
+			sb.WriteString("{{/" + contextPath + fieldName + "}}\n")
+		}
+	}
+
+	writeSkipped(sb, element)
+
+	// The content of a polymorphic element is that of its concrete type, chosen by populating its object
+	for _, alternative := range element.Alternatives {
+		alternativePath := workato.ChildPath(path, alternative.Name)
+		if opts.Excluded(alternativePath) {
+			continue
+		}
+		fieldName := opts.FieldName(alternativePath)
+		sb.WriteString("{{#" + contextPath + fieldName + "}}\n")
+		generateElement(sb, alternative, contextPath+fieldName+".", alternativePath, opts)
+		sb.WriteString("{{/" + contextPath + fieldName + "}}\n")
+	}
+}
+
+// Helper function to write a comment in place of the content skipped because the
+// constructs declaring it are not supported
+func writeSkipped(sb *strings.Builder, element xsd.Element) {
+	for _, construct := range element.Skipped {
+		sb.WriteString("{{! xs:" + construct + " in " + element.Name + " is not supported, its content was skipped }}\n")
+	}
+}
+
+// Helper function to write a comment noting the required attributes of the element at path,
+// which the template writes even when their field is empty
+func writeRequired(sb *strings.Builder, element xsd.Element, path string, opts Options) {
+	if note := opts.RequiredAttributesNote(element, path); note != "" {
+		sb.WriteString("{{! " + note + " }}\n")
+	}
+}
+
+// Helper function to write a comment noting the lexical form a date or time field is written
+// in, since Mustache cannot format values: datapills of other formats are formatted with
+// strftime when mapped
+func writeDateFormat(sb *strings.Builder, element xsd.Element, fieldName string, opts Options) {
+	if format, ok := opts.DateFormat(element); ok && !element.IsList() {
+		sb.WriteString("{{! " + fieldName + " is written as " + format.Layout +
+			", format datapills of other formats with .strftime('" + format.Strftime + "') }}\n")
+	}
+}
+
+// Function to generate the XML attributes of the start tag of the element at path
+func generateAttributes(element xsd.Element, contextPath, path string, opts Options) string {
+	var sb strings.Builder
+	for _, attribute := range element.Attributes {
+		attributePath := workato.ChildPath(path, "@"+attribute.Name)
+		if opts.Excluded(attributePath) {
+			continue
+		}
+		if attribute.Fixed != "" {
+			sb.WriteString(" " + attribute.Name + "=\"" + html.EscapeString(attribute.Fixed) + "\"")
+			continue
+		}
+		fieldName := opts.FieldName(attributePath)
+		if !opts.OmitsEmpty(attribute) {
+			sb.WriteString(" " + attribute.Name + "=\"{{" + contextPath + fieldName + "}}\"")
+			continue
+		}
+		// Optional attributes are only written when their field has a value
+		sb.WriteString("{{#" + contextPath + fieldName + "}} " + attribute.Name + "=\"{{.}}\"{{/" + contextPath + fieldName + "}}")
+	}
+	if len(element.Alternatives) > 0 {
+		fieldName := opts.FieldName(workato.TypePath(path))
+		sb.WriteString(" xmlns:xsi=\"" + xsd.InstanceNamespace + "\" xsi:type=\"{{" + contextPath + fieldName + "}}\"")
+
+		// The attributes of the concrete type are written when its object is populated
+		for _, alternative := range element.Alternatives {
+			alternativePath := workato.ChildPath(path, alternative.Name)
+			if len(alternative.Attributes) == 0 || opts.Excluded(alternativePath) {
+				continue
+			}
+			fieldName := opts.FieldName(alternativePath)
+			sb.WriteString("{{#" + contextPath + fieldName + "}}" +
+				generateAttributes(alternative, contextPath+fieldName+".", alternativePath, opts) +
+				"{{/" + contextPath + fieldName + "}}")
+		}
+	}
+	return sb.String()
+}
+
+// Helper function to write an empty element marked as xsi:nil
+func nilElement(name string) string {
+	return "<" + name + " xmlns:xsi=\"" + xsd.InstanceNamespace + "\" xsi:nil=\"true\"/>"
+}
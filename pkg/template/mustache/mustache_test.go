@@ -0,0 +1,420 @@
+package mustache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/peaz/xsd2wkt/pkg/template/skeleton"
+	"github.com/peaz/xsd2wkt/pkg/template/soap"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+func TestGenerateLeafRoot(t *testing.T) {
+	schema, err := xsd.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Response" type="xs:string"/>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("xsd.Parse: %v", err)
+	}
+
+	want := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Response>{{Response}}</Response>\n"
+	if got := Generate(schema, Options{Options: workato.Options{AttributePrefix: "@"}}); got != want {
+		t.Errorf("template = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateSOAP(t *testing.T) {
+	schema, err := xsd.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="GetOrder">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Id" type="xs:string"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("xsd.Parse: %v", err)
+	}
+
+	opts := Options{
+		Options: workato.Options{AttributePrefix: "@"},
+		SOAP:    soap.Options{Version: soap.Version11, Action: "urn:GetOrder", BodyNamespace: "urn:orders"},
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+{{! Send with the headers Content-Type: text/xml; charset=utf-8 and SOAPAction: "urn:GetOrder" }}
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+<soap:Header/>
+<soap:Body>
+<tns:GetOrder xmlns:tns="urn:orders">
+<Id>{{GetOrder.GetOrder_Id}}</Id>
+</tns:GetOrder>
+</soap:Body>
+</soap:Envelope>
+`
+	template := Generate(schema, opts)
+	if template != want {
+		t.Errorf("template:\n%s\nwant:\n%s", template, want)
+	}
+
+	rendered, err := Render(template, map[string]any{"GetOrder": map[string]any{"GetOrder_Id": "42"}})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.Contains(rendered, "SOAPAction") || !strings.Contains(rendered, "<Id>42</Id>") {
+		t.Errorf("rendered:\n%s", rendered)
+	}
+
+	opts.SOAP = soap.Options{Version: soap.Version12}
+	if template := Generate(schema, opts); !strings.Contains(template, soap.Namespace12) || strings.Contains(template, "{{!") {
+		t.Errorf("SOAP 1.2 template:\n%s", template)
+	}
+}
+
+func TestGenerateSkeleton(t *testing.T) {
+	schema, err := xsd.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Id" type="xs:string"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("xsd.Parse: %v", err)
+	}
+
+	skeletonText := `{{> declaration}}
+<?routing queue="orders"?>
+<Batch>
+<Header><Sender>ACME</Sender><Batch>{{BatchId}}</Batch></Header>
+{{>body}}
+</Batch>
+`
+	if err := skeleton.Validate(skeletonText); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<?routing queue="orders"?>
+<Batch>
+<Header><Sender>ACME</Sender><Batch>{{BatchId}}</Batch></Header>
+<Order>
+<Id>{{Order.Order_Id}}</Id>
+</Order>
+</Batch>
+`
+	template := Generate(schema, Options{Options: workato.Options{AttributePrefix: "@"}, Skeleton: skeletonText})
+	if template != want {
+		t.Errorf("template:\n%s\nwant:\n%s", template, want)
+	}
+
+	// Skeletons without a declaration placeholder leave the generated one out
+	template = Generate(schema, Options{Options: workato.Options{AttributePrefix: "@"}, Skeleton: "<Wrapper>\n{{> body}}\n</Wrapper>\n"})
+	if want := "<Wrapper>\n<Order>\n<Id>{{Order.Order_Id}}</Id>\n</Order>\n</Wrapper>\n"; template != want {
+		t.Errorf("template = %q, want %q", template, want)
+	}
+
+	for _, invalid := range []string{"<Batch/>", "{{> body}}{{> body}}", "{{> declaration}}{{> declaration}}{{> body}}"} {
+		if err := skeleton.Validate(invalid); err == nil {
+			t.Errorf("Validate(%q): expected an error", invalid)
+		}
+	}
+}
+
+func TestGeneratePassThrough(t *testing.T) {
+	schema, err := xsd.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Event">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Id" type="xs:string"/>
+        <xs:element name="Data" type="xs:anyType" maxOccurs="unbounded"/>
+        <xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="unbounded"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("xsd.Parse: %v", err)
+	}
+
+	opts := workato.Options{AttributePrefix: "@"}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<Event>
+<Id>{{Event.Event_Id}}</Id>
+{{#Event.Event_Data}}<Data>{{{.}}}</Data>{{/Event.Event_Data}}
+{{{Event.Event_any}}}
+</Event>
+`
+	template := Generate(schema, Options{Options: opts})
+	if template != want {
+		t.Errorf("template:\n%s\nwant:\n%s", template, want)
+	}
+
+	// The XML content is written unescaped, and the undeclared elements match the wildcard
+	document, err := Render(template, map[string]any{"Event": map[string]any{
+		"Event_Id":   "1",
+		"Event_Data": []any{"<Line>a &amp; b</Line>"},
+		"Event_any":  `<ext:Tag xmlns:ext="urn:ext">x</ext:Tag><ext:Tag xmlns:ext="urn:ext"/>`,
+	}})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(document, "<Data><Line>a &amp; b</Line></Data>") {
+		t.Errorf("document does not pass the Data content through:\n%s", document)
+	}
+	if errs, err := xsd.Validate(schema, strings.NewReader(document)); err != nil || len(errs) > 0 {
+		t.Errorf("Validate: %v %v", err, errs)
+	}
+}
+
+func TestGenerateRepeatedScalars(t *testing.T) {
+	schema, err := xsd.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Invoice">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Tag" type="xs:string" maxOccurs="unbounded"/>
+        <xs:element name="Code" maxOccurs="3">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:maxLength value="5"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("xsd.Parse: %v", err)
+	}
+
+	// Repeated scalars are written in a section, one element per value, like repeated objects
+	opts := workato.Options{AttributePrefix: "@"}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<Invoice>
+{{#Invoice.Invoice_Tag}}<Tag>{{.}}</Tag>{{/Invoice.Invoice_Tag}}
+{{#Invoice.Invoice_Code}}<Code>{{.}}</Code>{{/Invoice.Invoice_Code}}
+</Invoice>
+`
+	template := Generate(schema, Options{Options: opts})
+	if template != want {
+		t.Errorf("template:\n%s\nwant:\n%s", template, want)
+	}
+
+	document, err := Render(template, map[string]any{"Invoice": map[string]any{
+		"Invoice_Tag":  []any{"urgent", "a & b"},
+		"Invoice_Code": []any{"X1"},
+	}})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(document, "<Tag>urgent</Tag><Tag>a &amp; b</Tag>") {
+		t.Errorf("document does not write a Tag per value:\n%s", document)
+	}
+	if errs, err := xsd.Validate(schema, strings.NewReader(document)); err != nil || len(errs) > 0 {
+		t.Errorf("Validate: %v %v", err, errs)
+	}
+}
+
+func TestGenerateNamespaces(t *testing.T) {
+	schema, err := xsd.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="urn:orders" targetNamespace="urn:orders">
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Id" type="xs:string"/>
+        <xs:element name="Note" type="xs:string" form="qualified"/>
+        <xs:element ref="tns:Channel"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+  <xs:element name="Channel" type="xs:string"/>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("xsd.Parse: %v", err)
+	}
+	schema, err = schema.SelectRoot("Order")
+	if err != nil {
+		t.Fatalf("SelectRoot: %v", err)
+	}
+
+	opts := Options{Options: workato.Options{AttributePrefix: "@"}}
+	for _, test := range []struct {
+		prefix      string
+		unqualified bool
+		want        string
+	}{
+		// Only the root is qualified by default when local elements are unqualified
+		{"", false, "<tns:Order xmlns:tns=\"urn:orders\">\n<Id>{{Order.Order_Id}}</Id>\n<Note>{{Order.Order_Note}}</Note>\n<Channel>{{Order.Order_Channel}}</Channel>\n</tns:Order>\n"},
+		// With a prefix, the elements declared qualified and the referenced global elements are prefixed as well
+		{"o", false, "<o:Order xmlns:o=\"urn:orders\">\n<Id>{{Order.Order_Id}}</Id>\n<o:Note>{{Order.Order_Note}}</o:Note>\n<o:Channel>{{Order.Order_Channel}}</o:Channel>\n</o:Order>\n"},
+		{"", true, "<Order>\n<Id>{{Order.Order_Id}}</Id>\n<Note>{{Order.Order_Note}}</Note>\n<Channel>{{Order.Order_Channel}}</Channel>\n</Order>\n"},
+	} {
+		schema.NamespacePrefix, schema.Unqualified = test.prefix, test.unqualified
+		template := Generate(schema, opts)
+		if want := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" + test.want; template != want {
+			t.Errorf("prefix %q, unqualified %v: template:\n%s\nwant:\n%s", test.prefix, test.unqualified, template, want)
+		}
+	}
+}
+
+func TestGenerateAttributeUse(t *testing.T) {
+	schema, err := xsd.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Line">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Sku" type="xs:string"/>
+      </xs:sequence>
+      <xs:attribute name="number" type="xs:int" use="required"/>
+      <xs:attribute name="unit" type="xs:string"/>
+      <xs:attribute name="gift" type="xs:boolean"/>
+      <xs:attribute name="legacy" type="xs:string" use="prohibited"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("xsd.Parse: %v", err)
+	}
+
+	opts := workato.Options{AttributePrefix: "@"}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+{{! The required attribute number of Line is written even when empty }}
+<Line number="{{Line.@Line_number}}"{{#Line.@Line_unit}} unit="{{.}}"{{/Line.@Line_unit}} gift="{{Line.@Line_gift}}">
+<Sku>{{Line.Line_Sku}}</Sku>
+</Line>
+`
+	template := Generate(schema, Options{Options: opts})
+	if template != want {
+		t.Errorf("template:\n%s\nwant:\n%s", template, want)
+	}
+
+	// Empty optional attributes are left out, while required and boolean ones are written
+	document, err := Render(template, map[string]any{"Line": map[string]any{"@Line_gift": false, "Line_Sku": "A1"}})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(document, `<Line number="" gift="false">`) {
+		t.Errorf("document:\n%s\nwant the start tag <Line number=\"\" gift=\"false\">", document)
+	}
+
+	fields, err := workato.Generate(schema, opts)
+	if err != nil {
+		t.Fatalf("workato.Generate: %v", err)
+	}
+	var optional []bool
+	for _, field := range fields[0].Properties[:3] {
+		optional = append(optional, field.Optional)
+	}
+	if len(fields[0].Properties) != 4 || optional[0] || !optional[1] || !optional[2] {
+		t.Errorf("fields = %+v, want the required number, the optional unit and gift, and no legacy", fields[0].Properties)
+	}
+}
+
+func TestGenerateOptionalElements(t *testing.T) {
+	schema, err := xsd.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Address">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="City" type="xs:string"/>
+        <xs:element name="Geo" minOccurs="0">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Coordinates">
+                <xs:complexType>
+                  <xs:sequence>
+                    <xs:element name="Latitude" type="xs:decimal"/>
+                    <xs:element name="Longitude" type="xs:decimal"/>
+                  </xs:sequence>
+                </xs:complexType>
+              </xs:element>
+            </xs:sequence>
+          </xs:complexType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("xsd.Parse: %v", err)
+	}
+	template := Generate(schema, Options{Options: workato.Options{AttributePrefix: "@"}})
+
+	// An absent optional element is left out rather than written as an empty shell
+	document, err := Render(template, map[string]any{"Address": map[string]any{"Address_City": "Oslo"}})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.Contains(document, "<Geo>") {
+		t.Errorf("document:\n%s\nwant no Geo element", document)
+	}
+	if errs, err := xsd.Validate(schema, strings.NewReader(document)); err != nil || len(errs) > 0 {
+		t.Errorf("Validate: %v %v", err, errs)
+	}
+
+	document, err = Render(template, map[string]any{"Address": map[string]any{
+		"Address_City": "Oslo",
+		"Address_Geo":  map[string]any{"Geo_Coordinates": map[string]any{"Coordinates_Latitude": "59.91", "Coordinates_Longitude": "10.75"}},
+	}})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(document, "<Latitude>59.91</Latitude>") {
+		t.Errorf("document:\n%s\nwant the Geo coordinates", document)
+	}
+	if errs, err := xsd.Validate(schema, strings.NewReader(document)); err != nil || len(errs) > 0 {
+		t.Errorf("Validate: %v %v", err, errs)
+	}
+}
+
+func TestGenerateOptionalRecursion(t *testing.T) {
+	schema, err := xsd.Parser{MaxDepth: 3}.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="CategoryType">
+    <xs:sequence>
+      <xs:element name="Name" type="xs:string"/>
+      <xs:element name="Parent" type="CategoryType" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="Category" type="CategoryType"/>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("xsd.Parse: %v", err)
+	}
+	template := Generate(schema, Options{Options: workato.Options{AttributePrefix: "@"}})
+
+	// Without a parent, none of the nested Parent levels is written down to the maximum depth
+	document, err := Render(template, map[string]any{"Category": map[string]any{"Category_Name": "Books"}})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.Contains(document, "<Parent>") {
+		t.Errorf("document:\n%s\nwant no Parent element", document)
+	}
+	if errs, err := xsd.Validate(schema, strings.NewReader(document)); err != nil || len(errs) > 0 {
+		t.Errorf("Validate: %v %v", err, errs)
+	}
+
+	// One level of recursion stops at the first Parent
+	document, err = Render(template, map[string]any{"Category": map[string]any{
+		"Category_Name":   "Fiction",
+		"Category_Parent": map[string]any{"Parent_Name": "Books"},
+	}})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.Count(document, "<Parent>") != 1 || !strings.Contains(document, "<Parent>\n<Name>Books</Name>\n</Parent>") {
+		t.Errorf("document:\n%s\nwant a single Parent named Books", document)
+	}
+	if errs, err := xsd.Validate(schema, strings.NewReader(document)); err != nil || len(errs) > 0 {
+		t.Errorf("Validate: %v %v", err, errs)
+	}
+}
@@ -0,0 +1,204 @@
+package mustache
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// Node of a parsed template: literal text, a variable or a section with its content
+type node struct {
+	kind     byte   // 0 for text, 'v' for variables, '&' for unescaped variables, '#' or '^' for sections
+	text     string // Literal text, or the name referenced by the tag
+	children []node // Content of a section
+}
+
+// Parser turning a template into a tree of nodes
+type parser struct {
+	template string
+	pos      int
+}
+
+// Function to render a Mustache template with data decoded from JSON (maps, slices and
+// scalars). Variables, dotted names, sections, inverted sections, comments and triple
+// mustaches are supported; partials and delimiter changes are not.
+func Render(template string, data any) (string, error) {
+	p := parser{template: template}
+	nodes, err := p.parse("")
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	render(&sb, nodes, []any{data})
+	return sb.String(), nil
+}
+
+// Function to parse nodes up to the closing tag of section, or to the end of the template
+// when section is empty. Standalone section and comment tags are removed with their line.
+func (p *parser) parse(section string) ([]node, error) {
+	var nodes []node
+	for {
+		start := strings.Index(p.template[p.pos:], "{{")
+		if start < 0 {
+			if section != "" {
+				return nil, fmt.Errorf("unclosed section %q", section)
+			}
+			nodes = appendText(nodes, p.template[p.pos:])
+			p.pos = len(p.template)
+			return nodes, nil
+		}
+		start += p.pos
+
+		open, closing := "{{", "}}"
+		if strings.HasPrefix(p.template[start:], "{{{") {
+			open, closing = "{{{", "}}}"
+		}
+		end := strings.Index(p.template[start+len(open):], closing)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed tag at offset %d", start)
+		}
+		end += start + len(open)
+		tag := strings.TrimSpace(p.template[start+len(open) : end])
+		after := end + len(closing)
+
+		kind := byte('v')
+		if open == "{{{" {
+			kind = '&'
+		} else if tag != "" && strings.IndexByte("#^/!&>=", tag[0]) >= 0 {
+			kind = tag[0]
+			tag = strings.TrimSpace(tag[1:])
+		}
+
+		text := p.template[p.pos:start]
+		if kind == '#' || kind == '^' || kind == '/' || kind == '!' {
+			lineStart := strings.LastIndexByte(p.template[:start], '\n') + 1
+			lineEnd := strings.IndexByte(p.template[after:], '\n')
+			if lineEnd < 0 {
+				lineEnd = len(p.template)
+			} else {
+				lineEnd += after + 1
+			}
+			if lineStart >= p.pos && isBlank(p.template[lineStart:start]) && isBlank(p.template[after:lineEnd]) {
+				text = p.template[p.pos:lineStart]
+				after = lineEnd
+			}
+		}
+		nodes = appendText(nodes, text)
+		p.pos = after
+
+		switch kind {
+		case '#', '^':
+			children, err := p.parse(tag)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node{kind: kind, text: tag, children: children})
+		case '/':
+			if tag != section {
+				return nil, fmt.Errorf("unexpected closing tag %q", tag)
+			}
+			return nodes, nil
+		case '!':
+		case '>', '=':
+			return nil, fmt.Errorf("unsupported tag %q", p.template[start:after])
+		default:
+			nodes = append(nodes, node{kind: kind, text: tag})
+		}
+	}
+}
+
+// Helper function to append literal text, skipping empty strings
+func appendText(nodes []node, text string) []node {
+	if text == "" {
+		return nodes
+	}
+	return append(nodes, node{text: text})
+}
+
+// Helper function to check whether a string only holds spaces, tabs and line breaks
+func isBlank(s string) bool {
+	return strings.Trim(s, " \t\r\n") == ""
+}
+
+// Function to render nodes against a context stack, innermost context last
+func render(sb *strings.Builder, nodes []node, stack []any) {
+	for _, n := range nodes {
+		switch n.kind {
+		case 0:
+			sb.WriteString(n.text)
+		case 'v':
+			sb.WriteString(html.EscapeString(format(lookup(stack, n.text))))
+		case '&':
+			sb.WriteString(format(lookup(stack, n.text)))
+		case '#':
+			value := lookup(stack, n.text)
+			if !truthy(value) {
+				continue
+			}
+			if items, ok := value.([]any); ok {
+				for _, item := range items {
+					render(sb, n.children, append(stack, item))
+				}
+				continue
+			}
+			render(sb, n.children, append(stack, value))
+		case '^':
+			if !truthy(lookup(stack, n.text)) {
+				render(sb, n.children, stack)
+			}
+		}
+	}
+}
+
+// Function to resolve a possibly dotted name. The first part is looked up through the
+// context stack from the innermost context outwards, the remaining parts in its value.
+func lookup(stack []any, name string) any {
+	if name == "." {
+		return stack[len(stack)-1]
+	}
+
+	parts := strings.Split(name, ".")
+	var value any
+	found := false
+	for i := len(stack) - 1; i >= 0 && !found; i-- {
+		if object, ok := stack[i].(map[string]any); ok {
+			value, found = object[parts[0]]
+		}
+	}
+	for _, part := range parts[1:] {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+	return value
+}
+
+// Helper function to check whether a section value should be rendered
+func truthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case []any:
+		return len(v) > 0
+	}
+	return true
+}
+
+// Helper function to format a scalar value for output
+func format(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
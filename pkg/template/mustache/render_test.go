@@ -0,0 +1,47 @@
+package mustache
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	data := map[string]any{
+		"Order": map[string]any{
+			"Order_Id":   int64(7),
+			"Order_Note": "a < b & c",
+			"Order_Line": []any{
+				map[string]any{"Line_Sku": "A"},
+				map[string]any{"Line_Sku": "B"},
+			},
+			"Order_Gift": false,
+		},
+	}
+
+	cases := []struct {
+		template string
+		want     string
+	}{
+		{"<Id>{{Order.Order_Id}}</Id>", "<Id>7</Id>"},
+		{"<Note>{{Order.Order_Note}}</Note>", "<Note>a &lt; b &amp; c</Note>"},
+		{"<Note>{{{Order.Order_Note}}}</Note>", "<Note>a < b & c</Note>"},
+		{"{{#Order.Order_Line}}\n<Sku>{{Line_Sku}}</Sku>\n{{/Order.Order_Line}}\n", "<Sku>A</Sku>\n<Sku>B</Sku>\n"},
+		{"{{#Order}}{{Order_Id}}{{/Order}}", "7"},
+		{"{{#Order.Order_Gift}}gift{{/Order.Order_Gift}}{{^Order.Order_Gift}}no gift{{/Order.Order_Gift}}", "no gift"},
+		{"{{! comment }}\n<Missing>{{Order.Order_Missing}}</Missing>", "<Missing></Missing>"},
+	}
+
+	for _, c := range cases {
+		got, err := Render(c.template, data)
+		if err != nil {
+			t.Errorf("Render(%q): %v", c.template, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("Render(%q) = %q, want %q", c.template, got, c.want)
+		}
+	}
+
+	for _, template := range []string{"{{#Order}}", "{{/Order}}", "{{> partial}}", "{{Order"} {
+		if _, err := Render(template, data); err == nil {
+			t.Errorf("Render(%q): expected an error", template)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package skeleton injects generated XML message templates into a skeleton supplied by the
+// user, for documents that need content of their own around the root element, such as
+// processing instructions, wrapper elements or static header fields.
+package skeleton
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Placeholders of a skeleton, written as Mustache partials whatever the template syntax
+const (
+	Body        = "{{> body}}"        // Replaced with the generated root element
+	Declaration = "{{> declaration}}" // Replaced with the generated XML declaration
+)
+
+// Pattern of the placeholders, along with the line break that follows them, since the
+// generated parts end with one of their own
+var placeholder = regexp.MustCompile(`\{\{>\s*(body|declaration)\s*\}\}\n?`)
+
+// Function to check that a skeleton has a single body placeholder and at most one
+// declaration placeholder
+func Validate(skeleton string) error {
+	counts := make(map[string]int)
+	for _, match := range placeholder.FindAllStringSubmatch(skeleton, -1) {
+		counts[match[1]]++
+	}
+	if counts["body"] != 1 {
+		return fmt.Errorf("skeleton must have exactly one %s placeholder, found %d", Body, counts["body"])
+	}
+	if counts["declaration"] > 1 {
+		return fmt.Errorf("skeleton must have at most one %s placeholder, found %d", Declaration, counts["declaration"])
+	}
+	return nil
+}
+
+// Function to inject a template into a skeleton: its XML declaration replaces the declaration
+// placeholder, or is left out when the skeleton has none, and the rest of the template replaces
+// the body placeholder. The content of the skeleton is kept as is, so that any tag it has must
+// be of the syntax of the template. An empty skeleton leaves the template unchanged.
+func Inject(template, skeleton string) string {
+	if skeleton == "" {
+		return template
+	}
+	declaration, body := "", template
+	if strings.HasPrefix(body, "<?xml") {
+		end := strings.Index(body, "?>") + len("?>")
+		declaration, body = body[:end]+"\n", strings.TrimPrefix(body[end:], "\n")
+	}
+	return placeholder.ReplaceAllStringFunc(skeleton, func(match string) string {
+		if placeholder.FindStringSubmatch(match)[1] == "declaration" {
+			return declaration
+		}
+		return body
+	})
+}
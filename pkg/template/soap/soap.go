@@ -0,0 +1,83 @@
+// Package soap wraps generated XML message templates in a SOAP envelope, for templates
+// posted to SOAP services by Workato HTTP actions.
+package soap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SOAP versions
+const (
+	Version11 = "1.1"
+	Version12 = "1.2"
+)
+
+// Envelope namespaces of the SOAP versions
+const (
+	Namespace11 = "http://schemas.xmlsoap.org/soap/envelope/"
+	Namespace12 = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// Options controlling the envelope. The zero value leaves templates unwrapped.
+type Options struct {
+	Version       string // Version11 or Version12; templates are not wrapped if empty
+	Action        string // SOAP action of the operation, noted in the template for the HTTP request
+	BodyNamespace string // Namespace of the body content, replacing the target namespace of the schema if set
+}
+
+// Function to check whether the options are valid: a known version, and no action or body
+// namespace without one
+func (opts Options) Validate() error {
+	switch opts.Version {
+	case "":
+		if opts.Action != "" || opts.BodyNamespace != "" {
+			return fmt.Errorf("the SOAP action and body namespace require a SOAP version")
+		}
+	case Version11, Version12:
+	default:
+		return fmt.Errorf("SOAP version must be one of %s or %s", Version11, Version12)
+	}
+	return nil
+}
+
+// Function to wrap a template in an envelope with an empty Header and the template content
+// as Body, keeping its XML declaration first. The SOAP action is not part of the message,
+// so it is noted in a comment of the template syntax, written by the comment function.
+func Wrap(template string, opts Options, comment func(text string) string) string {
+	if opts.Version == "" {
+		return template
+	}
+	namespace := Namespace11
+	if opts.Version == Version12 {
+		namespace = Namespace12
+	}
+
+	declaration, body := "", template
+	if strings.HasPrefix(body, "<?xml") {
+		end := strings.Index(body, "?>") + len("?>")
+		declaration, body = body[:end]+"\n", strings.TrimPrefix(body[end:], "\n")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(declaration)
+	if opts.Action != "" {
+		sb.WriteString(comment(actionNote(opts)) + "\n")
+	}
+	sb.WriteString("<soap:Envelope xmlns:soap=\"" + namespace + "\">\n")
+	sb.WriteString("<soap:Header/>\n")
+	sb.WriteString("<soap:Body>\n")
+	sb.WriteString(body)
+	sb.WriteString("</soap:Body>\n")
+	sb.WriteString("</soap:Envelope>\n")
+	return sb.String()
+}
+
+// Helper function to describe how the request carries the SOAP action, which SOAP 1.1 sends
+// as a header and SOAP 1.2 as a parameter of the content type
+func actionNote(opts Options) string {
+	if opts.Version == Version12 {
+		return "Send with the header Content-Type: application/soap+xml; charset=utf-8; action=\"" + opts.Action + "\""
+	}
+	return "Send with the headers Content-Type: text/xml; charset=utf-8 and SOAPAction: \"" + opts.Action + "\""
+}
@@ -0,0 +1,119 @@
+package workato
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// CollapseLimit selects the elements whose content is collapsed into a single field of raw
+// XML, to keep the schemas of very deep trees manageable. The zero value collapses nothing.
+type CollapseLimit struct {
+	depth   int      // Depth of the elements collapsed, the root being at depth 1, or 0
+	pattern []string // Segments of the path pattern of the elements collapsed, if any
+}
+
+// Function to build a collapse limit from a depth, such as 4, or an element path pattern in
+// the syntax of PathFilter, such as Order/*/Details or **/Extension
+func NewCollapseLimit(limit string) (CollapseLimit, error) {
+	if depth, err := strconv.Atoi(limit); err == nil {
+		if depth < 1 {
+			return CollapseLimit{}, fmt.Errorf("depth must be at least 1")
+		}
+		return CollapseLimit{depth: depth}, nil
+	}
+	patterns, err := splitPatterns([]string{limit})
+	if err != nil {
+		return CollapseLimit{}, err
+	}
+	return CollapseLimit{pattern: patterns[0]}, nil
+}
+
+// Function to collapse the content of the elements at the depth or path of the limit. Each
+// collapsed element becomes a pass-through leaf: a string field of raw XML in the Workato
+// schema, written unescaped between its tags by the template, so that prebuilt fragments
+// can be injected. The attributes of collapsed elements are left out with their content.
+// Collapsed elements stay required or optional as they were, and the required content they
+// lose is listed in the warnings of the schema, since the raw XML must include it. The
+// schema passed in is left unchanged.
+func (limit CollapseLimit) Apply(schema xsd.Schema) xsd.Schema {
+	if limit.depth == 0 && limit.pattern == nil {
+		return schema
+	}
+	elements := make([]xsd.Element, len(schema.Elements))
+	var warnings []string
+	for i, element := range schema.Elements {
+		elements[i] = limit.collapse(element, element.Name, 1, &warnings)
+	}
+	schema.Elements = elements
+	schema.Warnings = append(slices.Clip(schema.Warnings), warnings...)
+	return schema
+}
+
+// Largest number of required descendants named in the warning of a collapsed element
+const maxCollapsedRequired = 10
+
+// Function to collapse the element at path and depth if the limit selects it, or else its
+// content that the limit selects, adding a warning for the required content collapsed
+func (limit CollapseLimit) collapse(element xsd.Element, elementPath string, depth int, warnings *[]string) xsd.Element {
+	if element.IsLeaf() || element.IsWildcard() {
+		return element
+	}
+	if depth == limit.depth || (limit.pattern != nil && matchSegments(limit.pattern, strings.Split(elementPath, "/"))) {
+		if required := requiredContent(element, ""); len(required) > 0 {
+			if len(required) > maxCollapsedRequired {
+				required = append(required[:maxCollapsedRequired], fmt.Sprintf("and %d more", len(required)-maxCollapsedRequired))
+			}
+			*warnings = append(*warnings, fmt.Sprintf("%s was collapsed into raw XML, which must include its required content: %s", elementPath, strings.Join(required, ", ")))
+		}
+		element.Type = "xs:anyType"
+		element.SimpleType, element.ComplexType, element.Text = nil, nil, nil
+		element.Children, element.Attributes, element.Alternatives = nil, nil, nil
+		element.Truncated = false
+		return element
+	}
+	element.Children = limit.collapseAll(element.Children, elementPath, depth, warnings)
+	element.Alternatives = limit.collapseAll(element.Alternatives, elementPath, depth, warnings)
+	return element
+}
+
+// Helper function to collapse the children, or alternatives, of the element at path and depth
+func (limit CollapseLimit) collapseAll(elements []xsd.Element, parentPath string, depth int, warnings *[]string) []xsd.Element {
+	if len(elements) == 0 {
+		return elements
+	}
+	collapsed := make([]xsd.Element, len(elements))
+	for i, element := range elements {
+		collapsed[i] = limit.collapse(element, ChildPath(parentPath, element.Name), depth+1, warnings)
+	}
+	return collapsed
+}
+
+// Helper function to list the paths, relative to an element, of the attributes and elements
+// it requires, following required elements only
+func requiredContent(element xsd.Element, elementPath string) []string {
+	relative := func(name string) string {
+		if elementPath == "" {
+			return name
+		}
+		return ChildPath(elementPath, name)
+	}
+	var required []string
+	for _, attribute := range element.Attributes {
+		if attribute.Use == "required" {
+			required = append(required, relative("@"+attribute.Name))
+		}
+	}
+	for _, child := range element.Children {
+		if child.IsOptional() || child.IsWildcard() {
+			continue
+		}
+		childPath := relative(child.Name)
+		required = append(required, childPath)
+		required = append(required, requiredContent(child, childPath)...)
+	}
+	return required
+}
@@ -0,0 +1,149 @@
+package workato
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Rename records a field renamed because its generated name was already taken by another
+// field. Flat and path names must be unique across the schema, so that every datapill can
+// be told apart, and nested names within their object.
+type Rename struct {
+	Path    string // Element path of the renamed field
+	Name    string // Generated name, already taken
+	Renamed string // Name given to the field instead
+	Owner   string // Element path of the field keeping the name
+}
+
+// Function to describe a rename as a warning
+func (rename Rename) String() string {
+	return fmt.Sprintf("field %s renamed %s, as %s is already the name of %s", rename.Path, rename.Renamed, rename.Name, rename.Owner)
+}
+
+// Generated field name, as collected for the detection of collisions
+type namedField struct {
+	path       string
+	name       string
+	overridden bool // Whether the name comes from an override, which is never renamed
+	scope      int  // Fields may only collide with fields of the same scope
+}
+
+// Function to resolve the options against a schema: the include patterns of the filter,
+// and the names of the fields that collide with others, renamed deterministically. Every
+// generator resolves its options, so that the schema and the templates use the same names.
+func (opts Options) Resolve(schema xsd.Schema) Options {
+	opts.Filter = opts.Filter.Resolve(schema)
+	opts.renames = nil
+	var fields []namedField
+	for _, element := range schema.Elements {
+		if !opts.Excluded(element.Name) {
+			fields = opts.collectNames(fields, element, element.Name, 0)
+		}
+	}
+
+	// Overridden names are claimed first, then generated ones in document order
+	taken := make(map[int]map[string]string)
+	claim := func(field namedField, name string) {
+		if taken[field.scope] == nil {
+			taken[field.scope] = make(map[string]string)
+		}
+		taken[field.scope][name] = field.path
+	}
+	for _, field := range fields {
+		if field.overridden {
+			claim(field, field.name)
+		}
+	}
+	for _, field := range fields {
+		if field.overridden {
+			continue
+		}
+		owner, collides := taken[field.scope][field.name]
+		// Elements repeated under the same path, such as in several choice branches, share their field
+		if !collides || owner == field.path {
+			claim(field, field.name)
+			continue
+		}
+		renamed := opts.disambiguate(field, taken[field.scope])
+		claim(field, renamed)
+		opts.renames = append(opts.renames, Rename{Path: field.path, Name: field.name, Renamed: renamed, Owner: owner})
+	}
+	return opts
+}
+
+// Function to list the fields renamed to avoid collisions when generating a schema
+func Renames(schema xsd.Schema, opts Options) []Rename {
+	return opts.Resolve(schema).renames
+}
+
+// Function to append the names of the field of the element at path and of its content,
+// mirroring generateField. Nested names are scoped to their object, others to the schema.
+func (opts Options) collectNames(fields []namedField, element xsd.Element, path string, scope int) []namedField {
+	fields = append(fields, namedField{path: path, name: opts.FieldName(path), overridden: opts.Overrides[path].Name != "", scope: scope})
+	if element.IsLeaf() {
+		return fields
+	}
+
+	if opts.Naming == NamingNested {
+		scope = len(fields)
+	}
+	for _, attribute := range element.Attributes {
+		attributePath := ChildPath(path, "@"+attribute.Name)
+		if !opts.Excluded(attributePath) && !attribute.AsElement().IsFixed() {
+			fields = opts.collectNames(fields, attribute.AsElement(), attributePath, scope)
+		}
+	}
+	if textPath := TextPath(path); element.Text != nil && !opts.Excluded(textPath) {
+		fields = opts.collectNames(fields, *element.Text, textPath, scope)
+	}
+	for _, child := range element.Children {
+		childPath := ChildPath(path, child.Name)
+		if !opts.Excluded(childPath) && !child.IsFixed() {
+			fields = opts.collectNames(fields, child, childPath, scope)
+		}
+	}
+	if len(element.Alternatives) == 0 {
+		return fields
+	}
+	typePath := TypePath(path)
+	fields = append(fields, namedField{path: typePath, name: opts.FieldName(typePath), overridden: opts.Overrides[typePath].Name != "", scope: scope})
+	for _, alternative := range element.Alternatives {
+		alternativePath := ChildPath(path, alternative.Name)
+		if !opts.Excluded(alternativePath) {
+			fields = opts.collectNames(fields, alternative, alternativePath, scope)
+		}
+	}
+	return fields
+}
+
+// Function to find a free name for a colliding field: its name prefixed with one more
+// ancestor at a time, such as Billing_Address_Street for Address_Street, then suffixed
+// with a number from 2 when the path runs out
+func (opts Options) disambiguate(field namedField, taken map[string]string) string {
+	parents := strings.Count(field.path, "/")
+	for depth := opts.namingDepth(parents) + 1; depth <= parents; depth++ {
+		if name := opts.fieldName(field.path, depth); taken[name] == "" {
+			return name
+		}
+	}
+	for number := 2; ; number++ {
+		if name := field.name + "_" + strconv.Itoa(number); taken[name] == "" {
+			return name
+		}
+	}
+}
+
+// Helper function to get the number of ancestors whose names prefix the field name of an
+// element with the given number of ancestors, following the naming strategy
+func (opts Options) namingDepth(parents int) int {
+	switch opts.Naming {
+	case NamingNested:
+		return 0
+	case NamingPath:
+		return parents
+	}
+	return min(parents, 1)
+}
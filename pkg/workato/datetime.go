@@ -0,0 +1,62 @@
+package workato
+
+import "github.com/peaz/xsd2wkt/pkg/xsd"
+
+// DateFormat describes the lexical form of an XSD date, time or duration type
+type DateFormat struct {
+	Layout   string // Lexical form, such as YYYY-MM-DD
+	Example  string // Value in the lexical form, such as 2024-01-31
+	Strftime string // Pattern of strftime writing a Workato date or time in the lexical form, "" for durations
+}
+
+// Lexical forms of the built-in date, time and duration types
+var dateFormats = map[string]DateFormat{
+	"xs:date":              {"YYYY-MM-DD", "2024-01-31", "%Y-%m-%d"},
+	"xs:dateTime":          {"YYYY-MM-DDThh:mm:ss±hh:mm", "2024-01-31T13:45:00+01:00", "%Y-%m-%dT%H:%M:%S%:z"},
+	"xs:dateTimeStamp":     {"YYYY-MM-DDThh:mm:ss±hh:mm", "2024-01-31T13:45:00+01:00", "%Y-%m-%dT%H:%M:%S%:z"},
+	"xs:time":              {"hh:mm:ss", "13:45:00", "%H:%M:%S"},
+	"xs:gYear":             {"YYYY", "2024", "%Y"},
+	"xs:gYearMonth":        {"YYYY-MM", "2024-01", "%Y-%m"},
+	"xs:gMonth":            {"--MM", "--01", "--%m"},
+	"xs:gMonthDay":         {"--MM-DD", "--01-31", "--%m-%d"},
+	"xs:gDay":              {"---DD", "---31", "---%d"},
+	"xs:duration":          {"PnYnMnDTnHnMnS", "P1DT2H30M", ""},
+	"xs:dayTimeDuration":   {"PnDTnHnMnS", "P1DT2H30M", ""},
+	"xs:yearMonthDuration": {"PnYnM", "P1Y2M", ""},
+}
+
+// Function to look up the lexical form of the date, time or duration type of an element
+func LookupDateFormat(element xsd.Element) (DateFormat, bool) {
+	format, ok := dateFormats[element.BaseType()]
+	return format, ok
+}
+
+// Helper function to check whether a Workato type holds dates or times
+func isDateType(workatoType string) bool {
+	return workatoType == "date" || workatoType == "date_time" || workatoType == "timestamp"
+}
+
+// Function to get the lexical form a Workato date or time is formatted to in the document,
+// for elements whose field has a date type. Datapills of other date formats need to be
+// formatted with the strftime pattern of the lexical form before they are written.
+func (opts Options) DateFormat(element xsd.Element) (DateFormat, bool) {
+	format, ok := LookupDateFormat(element)
+	if !ok || !isDateType(opts.TypeMap.Resolve(element).Type) {
+		return DateFormat{}, false
+	}
+	return format, true
+}
+
+// Helper function to describe the lexical form of a date or time kept as a string, such as
+// "Formatted as hh:mm:ss, such as 13:45:00". Types that typeMap maps to Workato dates are
+// formatted by Workato and need no description.
+func formatHint(element xsd.Element, typeMap TypeMap) string {
+	format, ok := LookupDateFormat(element)
+	if !ok || isDateType(typeMap.Resolve(element).Type) {
+		return ""
+	}
+	if format.Strftime == "" {
+		return "ISO 8601 duration formatted as " + format.Layout + ", such as " + format.Example
+	}
+	return "Formatted as " + format.Layout + ", such as " + format.Example
+}
@@ -0,0 +1,113 @@
+package workato
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Kinds of changes between two versions of a schema
+const (
+	ChangeAdded       = "added"
+	ChangeRemoved     = "removed"
+	ChangeRetyped     = "retyped"
+	ChangeCardinality = "cardinality"
+)
+
+// Change is a difference of a single field between two versions of a schema
+type Change struct {
+	Kind string `json:"kind"`          // ChangeAdded, ChangeRemoved, ChangeRetyped or ChangeCardinality
+	Path string `json:"path"`          // Field names from the root, joined with "/"
+	Old  string `json:"old,omitempty"` // Type or cardinality before the change
+	New  string `json:"new,omitempty"` // Type or cardinality after the change
+}
+
+// Function to compare two versions of Workato schema fields. Fields are matched by name
+// under the same parent; the fields under an added or removed object are not reported
+// separately. Changes are listed in the order of the old fields, followed by additions.
+func Diff(oldFields, newFields []Field) []Change {
+	return diffFields(oldFields, newFields, "")
+}
+
+// Function to compare the fields under the parent at path
+func diffFields(oldFields, newFields []Field, path string) []Change {
+	newByName := make(map[string]Field, len(newFields))
+	for _, field := range newFields {
+		newByName[field.Name] = field
+	}
+	oldNames := make(map[string]bool, len(oldFields))
+
+	var changes []Change
+	for _, oldField := range oldFields {
+		oldNames[oldField.Name] = true
+		fieldPath := path + oldField.Name
+		newField, ok := newByName[oldField.Name]
+		if !ok {
+			changes = append(changes, Change{Kind: ChangeRemoved, Path: fieldPath, Old: describe(oldField)})
+			continue
+		}
+		if oldType, newType := itemType(oldField), itemType(newField); oldType != newType {
+			changes = append(changes, Change{Kind: ChangeRetyped, Path: fieldPath, Old: oldType, New: newType})
+		}
+		if oldCardinality, newCardinality := cardinality(oldField), cardinality(newField); oldCardinality != newCardinality {
+			changes = append(changes, Change{Kind: ChangeCardinality, Path: fieldPath, Old: oldCardinality, New: newCardinality})
+		}
+		changes = append(changes, diffFields(oldField.Properties, newField.Properties, fieldPath+"/")...)
+	}
+	for _, newField := range newFields {
+		if !oldNames[newField.Name] {
+			changes = append(changes, Change{Kind: ChangeAdded, Path: path + newField.Name, New: describe(newField)})
+		}
+	}
+	return changes
+}
+
+// Helper function to get the type of a field, or of its items for arrays
+func itemType(field Field) string {
+	if field.Type == "array" {
+		return field.Of
+	}
+	return field.Type
+}
+
+// Helper function to describe how many values a field holds: required, optional, or
+// required or optional array
+func cardinality(field Field) string {
+	description := "required"
+	if field.Optional {
+		description = "optional"
+	}
+	if field.Type == "array" {
+		description += " array"
+	}
+	return description
+}
+
+// Helper function to describe the type and cardinality of an added or removed field
+func describe(field Field) string {
+	return itemType(field) + ", " + cardinality(field)
+}
+
+// Function to print changes one per line, marked with + when added, - when removed and ~
+// when changed, followed by a summary
+func PrintDiff(w io.Writer, changes []Change) error {
+	var sb strings.Builder
+	counts := map[string]int{}
+	for _, change := range changes {
+		counts[change.Kind]++
+		switch change.Kind {
+		case ChangeAdded:
+			sb.WriteString("+ " + change.Path + ": " + change.New + "\n")
+		case ChangeRemoved:
+			sb.WriteString("- " + change.Path + ": " + change.Old + "\n")
+		case ChangeRetyped:
+			sb.WriteString("~ " + change.Path + ": type " + change.Old + " -> " + change.New + "\n")
+		case ChangeCardinality:
+			sb.WriteString("~ " + change.Path + ": " + change.Old + " -> " + change.New + "\n")
+		}
+	}
+	fmt.Fprintf(&sb, "%d changes: %d added, %d removed, %d retyped, %d re-cardinalized\n",
+		len(changes), counts[ChangeAdded], counts[ChangeRemoved], counts[ChangeRetyped], counts[ChangeCardinality])
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,190 @@
+package workato
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Columns of a field list
+var fieldListHeader = []string{"Path", "XML", "XSD type", "Workato type", "Required", "Cardinality", "Documentation", "Constraints"}
+
+// FieldEntry describes a generated field along with the XML node it maps to
+type FieldEntry struct {
+	Path          string // Field names from the root, joined with "/"
+	XMLPath       string // Element path from the root, with attributes as @name
+	XSDType       string // Declared XSD type, or the base type of an anonymous simpleType
+	Type          string // Workato type, such as string or array of object
+	Required      bool
+	Cardinality   string // Occurrences of the XML node, such as 1, 0..1 or 1..*
+	Documentation string
+	Constraints   string // Identity constraints the value takes part in, such as keys, separated by "; "
+
+	element xsd.Element // Declaration the field is generated from
+}
+
+// Function to list every field generated for the global elements of a schema, in the order
+// of the Workato schema, with the XSD declaration each one comes from
+func FieldList(schema xsd.Schema, opts Options) []FieldEntry {
+	opts = opts.Resolve(schema)
+	var entries []FieldEntry
+	for _, element := range schema.Elements {
+		if opts.Excluded(element.Name) {
+			continue
+		}
+		entries = listField(entries, element, element.Name, "", opts)
+	}
+	return entries
+}
+
+// Function to append the entries of the element at path and of its content, under the
+// field at parent
+func listField(entries []FieldEntry, element xsd.Element, path, parent string, opts Options) []FieldEntry {
+	field := generateField(element, opts.FieldName(path), path, opts)
+	entries = append(entries, FieldEntry{
+		Path:          parent + field.Name,
+		XMLPath:       path,
+		XSDType:       xsdType(element),
+		Type:          describeType(field),
+		Required:      !field.Optional,
+		Cardinality:   occurrences(element),
+		Documentation: element.Annotation.Text(),
+		Constraints:   strings.Join(element.Constraints, "; "),
+		element:       element,
+	})
+	if element.IsLeaf() {
+		return entries
+	}
+
+	parent += field.Name + "/"
+	for _, attribute := range element.Attributes {
+		attributePath := ChildPath(path, "@"+attribute.Name)
+		if opts.Excluded(attributePath) || attribute.AsElement().IsFixed() {
+			continue
+		}
+		entries = listField(entries, attribute.AsElement(), attributePath, parent, opts)
+	}
+	if textPath := TextPath(path); element.Text != nil && !opts.Excluded(textPath) {
+		entries = listField(entries, *element.Text, textPath, parent, opts)
+	}
+	for _, child := range element.Children {
+		childPath := ChildPath(path, child.Name)
+		if opts.Excluded(childPath) || child.IsFixed() {
+			continue
+		}
+		entries = listField(entries, child, childPath, parent, opts)
+	}
+	if len(element.Alternatives) == 0 {
+		return entries
+	}
+
+	alternatives := generateAlternativeFields(element, path, opts)
+	selector := alternatives[0]
+	entries = append(entries, FieldEntry{
+		Path:          parent + selector.Name,
+		XMLPath:       TypePath(path),
+		XSDType:       "xs:QName",
+		Type:          describeType(selector),
+		Required:      !selector.Optional,
+		Cardinality:   "1",
+		Documentation: selector.Hint,
+	})
+	for _, alternative := range element.Alternatives {
+		alternativePath := ChildPath(path, alternative.Name)
+		if opts.Excluded(alternativePath) {
+			continue
+		}
+		// Concrete types are optional objects, one of which is populated
+		alternative.MinOccurs = "0"
+		entries = listField(entries, alternative, alternativePath, parent, opts)
+	}
+	return entries
+}
+
+// Helper function to get the XSD type of an element as declared, or the base type of its
+// anonymous simpleType. Anonymous complexTypes have no type name.
+func xsdType(element xsd.Element) string {
+	switch {
+	case element.Type != "":
+		return element.Type
+	case element.IsList():
+		return "list of " + element.ListItem().BaseType()
+	case len(element.UnionMembers()) > 0:
+		return "union of " + strings.Join(element.UnionMembers(), " ")
+	case element.SimpleType != nil:
+		return element.BaseType()
+	}
+	return ""
+}
+
+// Helper function to describe the Workato type of a field, with the item type of arrays
+func describeType(field Field) string {
+	if field.Type == "array" && field.Of != "" {
+		return "array of " + field.Of
+	}
+	return field.Type
+}
+
+// Helper function to describe how many times an XML node occurs, from its minOccurs and
+// maxOccurs: 1 when both are 1, or else min..max with * for unbounded
+func occurrences(element xsd.Element) string {
+	minOccurs, maxOccurs := element.MinOccurs, element.MaxOccurs
+	if minOccurs == "" {
+		minOccurs = "1"
+	}
+	switch maxOccurs {
+	case "":
+		maxOccurs = "1"
+	case "unbounded":
+		maxOccurs = "*"
+	}
+	if minOccurs == maxOccurs {
+		return minOccurs
+	}
+	return minOccurs + ".." + maxOccurs
+}
+
+// Helper function to get the columns of an entry
+func (entry FieldEntry) columns() []string {
+	required := "optional"
+	if entry.Required {
+		required = "required"
+	}
+	return []string{entry.Path, entry.XMLPath, entry.XSDType, entry.Type, required, entry.Cardinality, entry.Documentation, entry.Constraints}
+}
+
+// Function to write a field list as CSV, with a header row
+func WriteFieldListCSV(w io.Writer, entries []FieldEntry) error {
+	writer := csv.NewWriter(w)
+	writer.Write(fieldListHeader)
+	for _, entry := range entries {
+		writer.Write(entry.columns())
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Function to write a field list as a Markdown table. Pipes are escaped and line breaks
+// replaced with spaces, so that every entry stays on its row.
+func WriteFieldListMarkdown(w io.Writer, entries []FieldEntry) error {
+	var sb strings.Builder
+	writeRow := func(columns []string) {
+		for _, column := range columns {
+			column = strings.Join(strings.Fields(column), " ")
+			sb.WriteString("| " + strings.ReplaceAll(column, "|", "\\|") + " ")
+		}
+		sb.WriteString("|\n")
+	}
+	writeRow(fieldListHeader)
+	for range fieldListHeader {
+		sb.WriteString("| --- ")
+	}
+	sb.WriteString("|\n")
+	for _, entry := range entries {
+		writeRow(entry.columns())
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
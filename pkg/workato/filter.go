@@ -0,0 +1,161 @@
+package workato
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// PathFilter selects the elements and attributes to generate by their path. The zero value
+// keeps every path.
+type PathFilter struct {
+	include [][]string // Patterns split into segments; when set, only matching paths are kept
+	exclude [][]string // Patterns split into segments of the paths left out
+
+	// Paths whose content matches an include pattern, once resolved against a schema
+	ancestors map[string]bool
+}
+
+// Function to build a path filter from include and exclude patterns. Patterns are element
+// paths such as Order/Line/Sku, whose segments may use the wildcards of path.Match, and **
+// for any number of segments, such as Order/*/Sku or **/Signature.
+func NewPathFilter(include, exclude []string) (PathFilter, error) {
+	var filter PathFilter
+	var err error
+	if filter.include, err = splitPatterns(include); err != nil {
+		return PathFilter{}, err
+	}
+	if filter.exclude, err = splitPatterns(exclude); err != nil {
+		return PathFilter{}, err
+	}
+	return filter, nil
+}
+
+// Helper function to split path patterns into segments, checking their syntax
+func splitPatterns(patterns []string) ([][]string, error) {
+	var split [][]string
+	for _, pattern := range patterns {
+		segments := strings.Split(strings.Trim(pattern, "/"), "/")
+		for _, segment := range segments {
+			if _, err := path.Match(segment, ""); err != nil || segment == "" {
+				return nil, fmt.Errorf("invalid path pattern %q", pattern)
+			}
+		}
+		split = append(split, segments)
+	}
+	return split, nil
+}
+
+// Function to resolve the include patterns against the elements of a schema, so that only
+// the ancestors of matching paths are kept. Unresolved, a ** pattern keeps every path that
+// could have matching content.
+func (filter PathFilter) Resolve(schema xsd.Schema) PathFilter {
+	if len(filter.include) == 0 {
+		return filter
+	}
+	filter.ancestors = make(map[string]bool)
+	for _, element := range schema.Elements {
+		filter.resolve(element, element.Name)
+	}
+	return filter
+}
+
+// Function to record the element at path as an ancestor of included paths if it or its
+// content matches an include pattern, and report whether it does
+func (filter PathFilter) resolve(element xsd.Element, elementPath string) bool {
+	matched := filter.matchesInclude(elementPath)
+	for _, attribute := range element.Attributes {
+		matched = filter.matchesInclude(ChildPath(elementPath, "@"+attribute.Name)) || matched
+	}
+	if element.Text != nil {
+		matched = filter.matchesInclude(TextPath(elementPath)) || matched
+	}
+	for _, child := range element.Children {
+		matched = filter.resolve(child, ChildPath(elementPath, child.Name)) || matched
+	}
+	for _, alternative := range element.Alternatives {
+		matched = filter.resolve(alternative, ChildPath(elementPath, alternative.Name)) || matched
+	}
+	if matched {
+		filter.ancestors[elementPath] = true
+	}
+	return matched
+}
+
+// Helper function to check whether a path matches an include pattern
+func (filter PathFilter) matchesInclude(elementPath string) bool {
+	segments := strings.Split(elementPath, "/")
+	for _, pattern := range filter.include {
+		if matchSegments(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// Function to check whether the element or attribute at path is left out. Paths matching
+// an exclude pattern are left out with their content. When include patterns are set, the
+// paths matching them are kept with their content and their ancestors, and others are left out.
+func (filter PathFilter) Excluded(elementPath string) bool {
+	segments := strings.Split(elementPath, "/")
+	for _, pattern := range filter.exclude {
+		if matchSegments(pattern, segments) {
+			return true
+		}
+	}
+	if len(filter.include) == 0 {
+		return false
+	}
+	// Included paths and their content
+	for i := len(segments); i > 0; i-- {
+		if filter.matchesInclude(strings.Join(segments[:i], "/")) {
+			return false
+		}
+	}
+	if filter.ancestors != nil {
+		return !filter.ancestors[elementPath]
+	}
+	for _, pattern := range filter.include {
+		if leadsToMatch(pattern, segments) {
+			return false
+		}
+	}
+	return true
+}
+
+// Helper function to check whether the segments of a path match a pattern
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	matched, _ := path.Match(pattern[0], segments[0])
+	return matched && matchSegments(pattern[1:], segments[1:])
+}
+
+// Helper function to check whether a path matches a pattern or may have content that does,
+// as the ancestors of included elements are kept
+func leadsToMatch(pattern, segments []string) bool {
+	switch {
+	case len(segments) == 0:
+		return true
+	case len(pattern) == 0:
+		return false
+	case pattern[0] == "**":
+		return true
+	}
+	matched, _ := path.Match(pattern[0], segments[0])
+	return matched && leadsToMatch(pattern[1:], segments[1:])
+}
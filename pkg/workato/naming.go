@@ -0,0 +1,115 @@
+package workato
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Case conversions of generated field names
+const (
+	CaseOriginal = "original" // Element names as written in the XSD, such as PostalCode
+	CaseCamel    = "camel"    // Such as postalCode
+	CaseSnake    = "snake"    // Such as postal_code
+	CasePascal   = "pascal"   // Such as PostalCode
+)
+
+// Function to convert a field name to one of the case conversions. Words are split at
+// underscores, hyphens, dots and case changes, so that XMLData_ID becomes xml_data_id
+// in snake case. Unknown conversions leave the name unchanged.
+func ConvertCase(name, conversion string) string {
+	words := SplitWords(name)
+	if len(words) == 0 {
+		return name
+	}
+	switch conversion {
+	case CaseSnake:
+		for i, word := range words {
+			words[i] = strings.ToLower(word)
+		}
+		return strings.Join(words, "_")
+	case CaseCamel:
+		for i, word := range words {
+			words[i] = capitalize(word)
+		}
+		words[0] = strings.ToLower(words[0])
+		return strings.Join(words, "")
+	case CasePascal:
+		for i, word := range words {
+			words[i] = capitalize(word)
+		}
+		return strings.Join(words, "")
+	}
+	return name
+}
+
+// Function to split a name into words at non-alphanumeric characters and case changes.
+// Acronyms are kept together, and digits stay with the word before them: CustomerXMLData2
+// becomes Customer, XML and Data2.
+func SplitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextLower) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// Function to build the human-friendly label of a field from its name: the words of the
+// name without the attribute prefix, each capitalized or spelled out by the abbreviation
+// dictionary, so that GrpHdr_MsgId becomes "Grp Hdr Msg Id", or "Group Header Message
+// ID" with abbreviations for each word.
+func (opts Options) Label(fieldName string) string {
+	name := strings.TrimPrefix(fieldName, opts.AttributePrefix)
+	words := SplitWords(name)
+	if len(words) == 0 {
+		return fieldName
+	}
+	for i, word := range words {
+		words[i] = opts.expandAbbreviation(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// Helper function to spell out a word found in the abbreviation dictionary, or to
+// upper-case its first letter otherwise
+func (opts Options) expandAbbreviation(word string) string {
+	if expansion, ok := opts.Abbreviations[word]; ok {
+		return expansion
+	}
+	for abbreviation, expansion := range opts.Abbreviations {
+		if strings.EqualFold(abbreviation, word) {
+			return expansion
+		}
+	}
+	runes := []rune(word)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// Helper function to upper-case the first letter of a word and lower-case the others
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,153 @@
+package workato
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Mapping of Workato types back to the XSD types they are generated from
+var reverseTypeMap = map[string]string{
+	"string":    "xs:string",
+	"date_time": "xs:dateTime",
+	"date":      "xs:date",
+	"timestamp": "xs:dateTimeStamp",
+	"boolean":   "xs:boolean",
+	"integer":   "xs:integer",
+	"number":    "xs:decimal",
+}
+
+// Pattern of the maxLength sentence appended to hints by Hint
+var maxLengthHint = regexp.MustCompile(`(?:^|\.? )Max (\d+) characters$`)
+
+// Pattern of the provenance note xsd2wkt appends to the hints of root fields, which is not
+// documentation of the element
+var provenanceHint = regexp.MustCompile(`(?:^|\.? )Generated by xsd2wkt \S+ from .+ \(sha256 [0-9a-f]+\) at \S+$`)
+
+// Function to read Workato schema fields from a JSON file
+func ReadFile(inputFile string) ([]Field, error) {
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	var fields []Field
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Workato schema: %w", err)
+	}
+	return fields, nil
+}
+
+// Function to build a schema from Workato schema fields, reversing Generate: every
+// top-level field becomes a global element, field names lose the prefix that the naming
+// strategy adds, and fields marked with the attribute prefix become attributes.
+func ToSchema(fields []Field, opts Options) xsd.Schema {
+	var schema xsd.Schema
+	for _, field := range fields {
+		schema.Elements = append(schema.Elements, fieldElement(field, field.Name, opts))
+	}
+	return schema
+}
+
+// Function to build the element of a field, named name
+func fieldElement(field Field, name string, opts Options) xsd.Element {
+	element := xsd.Element{Name: name}
+	if field.Optional {
+		element.MinOccurs = "0"
+	}
+
+	fieldType := field.Type
+	if fieldType == "array" {
+		element.MaxOccurs = "unbounded"
+		fieldType = field.Of
+	}
+
+	documentation := provenanceHint.ReplaceAllString(field.Hint, "")
+	var restriction xsd.Restriction
+	if match := maxLengthHint.FindStringSubmatchIndex(documentation); match != nil {
+		restriction.MaxLength = &xsd.Facet{Value: documentation[match[2]:match[3]]}
+		documentation = documentation[:match[0]]
+	}
+	if documentation != "" {
+		element.Annotation = &xsd.Annotation{Documentation: []string{documentation}}
+	}
+
+	if fieldType == "object" || len(field.Properties) > 0 {
+		prefixes := childFieldPrefixes(field, name, opts)
+		for _, property := range field.Properties {
+			if opts.AttributePrefix != "" && strings.HasPrefix(property.Name, opts.AttributePrefix) {
+				element.Attributes = append(element.Attributes, fieldAttribute(property, prefixes, opts))
+				continue
+			}
+			childName := trimPrefixes(property.Name, prefixes)
+			element.Children = append(element.Children, fieldElement(property, childName, opts))
+		}
+		// The text of an element with attributes is its only other field, named like a child called text
+		if text := element.Children; len(text) == 1 && len(element.Attributes) > 0 && text[0].Name == "text" && text[0].IsLeaf() && !text[0].IsRepeating() {
+			text[0].Name, text[0].MinOccurs = name, ""
+			element.Text, element.Children = &text[0], nil
+		}
+		return element
+	}
+
+	element.Type = "xs:string"
+	element.Default = field.Default
+	if xsdType, ok := reverseTypeMap[fieldType]; ok {
+		element.Type = xsdType
+	}
+	for _, option := range field.PickList {
+		if len(option) > 0 {
+			restriction.Enumerations = append(restriction.Enumerations, xsd.Facet{Value: option[len(option)-1]})
+		}
+	}
+	if restriction.MaxLength != nil || len(restriction.Enumerations) > 0 {
+		restriction.Base = element.Type
+		element.SimpleType = &xsd.SimpleType{Restriction: restriction}
+	}
+	return element
+}
+
+// Helper function to get the prefixes that the naming strategy adds to the names of the
+// properties of field, the field of the element called name. Flat names renamed to avoid
+// a collision are prefixed with more ancestors, such as the whole name of the field, so
+// that prefix is tried first.
+func childFieldPrefixes(field Field, name string, opts Options) []string {
+	switch opts.Naming {
+	case NamingNested:
+		return nil
+	case NamingPath:
+		return []string{field.Name + "_"}
+	}
+	return []string{field.Name + "_", name + "_"}
+}
+
+// Helper function to remove the first of the prefixes that a name starts with
+func trimPrefixes(name string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return strings.TrimPrefix(name, prefix)
+		}
+	}
+	return name
+}
+
+// Function to build the attribute of an attribute field, whose name is marked with the
+// attribute prefix and followed by one of prefixes
+func fieldAttribute(field Field, prefixes []string, opts Options) xsd.Attribute {
+	name := trimPrefixes(strings.TrimPrefix(field.Name, opts.AttributePrefix), prefixes)
+	leaf := fieldElement(field, name, opts)
+	attribute := xsd.Attribute{
+		Name:       leaf.Name,
+		Type:       leaf.Type,
+		Default:    leaf.Default,
+		SimpleType: leaf.SimpleType,
+		Annotation: leaf.Annotation,
+	}
+	if !field.Optional {
+		attribute.Use = "required"
+	}
+	return attribute
+}
@@ -0,0 +1,83 @@
+package workato
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// ValidationRule holds the constraints on the value of a leaf field, for a connector to check
+// its input before rendering the template, such as in the execute block of an action. The
+// rules of fields within arrays apply to every item.
+type ValidationRule struct {
+	Path             string   `json:"path"`     // Field names from the root, joined with "/"
+	XMLPath          string   `json:"xml_path"` // Element path from the root, with attributes as @name
+	Required         bool     `json:"required"`
+	MinLength        int      `json:"min_length,omitempty"`
+	MaxLength        int      `json:"max_length,omitempty"`
+	Pattern          string   `json:"pattern,omitempty"` // Ruby regular expression matching the whole value, one of the XSD patterns
+	Enum             []string `json:"enum,omitempty"`
+	Minimum          string   `json:"minimum,omitempty"`
+	Maximum          string   `json:"maximum,omitempty"`
+	ExclusiveMinimum string   `json:"exclusive_minimum,omitempty"`
+	ExclusiveMaximum string   `json:"exclusive_maximum,omitempty"`
+	TotalDigits      int      `json:"total_digits,omitempty"`
+	FractionDigits   int      `json:"fraction_digits,omitempty"`
+}
+
+// Function to list the validation rules of the leaf fields of a schema that are required or
+// restricted by facets, in the order of the Workato schema. Lists keep only their
+// requiredness, since their facets constrain the list rather than its items.
+func ValidationRules(schema xsd.Schema, opts Options) []ValidationRule {
+	rules := []ValidationRule{} // Written as [] rather than null when there are none
+	for _, entry := range FieldList(schema, opts) {
+		element := entry.element
+		if !element.IsLeaf() || element.IsWildcard() {
+			continue
+		}
+		rule := ValidationRule{Path: entry.Path, XMLPath: entry.XMLPath, Required: entry.Required}
+		if !element.IsList() {
+			rule.MinLength, rule.MaxLength = element.MinLength(), element.MaxLength()
+			if patterns := element.Patterns(); len(patterns) > 0 {
+				rule.Pattern = `\A(?:` + strings.Join(patterns, "|") + `)\z`
+			}
+			rule.Enum = element.Enumerations()
+			lower, upper := element.Bounds()
+			if lower.Exclusive {
+				rule.ExclusiveMinimum = lower.Value
+			} else {
+				rule.Minimum = lower.Value
+			}
+			if upper.Exclusive {
+				rule.ExclusiveMaximum = upper.Value
+			} else {
+				rule.Maximum = upper.Value
+			}
+			rule.TotalDigits, rule.FractionDigits = element.Digits()
+		}
+		if reflect.DeepEqual(rule, ValidationRule{Path: rule.Path, XMLPath: rule.XMLPath}) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// Function to write validation rules to an indented JSON file
+func WriteValidationRules(rules []ValidationRule, outputFile string) error {
+	rulesJSON, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling validation rules to JSON: %w", err)
+	}
+
+	err = os.WriteFile(outputFile, rulesJSON, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing validation rules to file: %w", err)
+	}
+
+	return nil
+}
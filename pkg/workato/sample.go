@@ -0,0 +1,306 @@
+package workato
+
+import (
+	"math/big"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Function to generate sample input data for a schema, keyed by the same field names as
+// the Workato schema. Repeating elements get a single item, and only the first branch of
+// each xs:choice is populated so that the data renders to a valid document.
+func Sample(schema xsd.Schema, opts Options) map[string]any {
+	opts = opts.Resolve(schema)
+	data := make(map[string]any, len(schema.Elements))
+	for _, element := range schema.Elements {
+		if opts.Excluded(element.Name) {
+			continue
+		}
+		data[opts.FieldName(element.Name)] = sampleField(element, element.Name, opts)
+	}
+	return data
+}
+
+// Function to generate the sample data of the element at path, mirroring generateField
+func sampleField(element xsd.Element, path string, opts Options) any {
+	var value any
+	switch {
+	case element.IsList() && !element.IsRepeating():
+		value = []any{sampleScalar(element.ListItem(), opts)}
+	case element.IsLeaf():
+		value = sampleScalar(element, opts)
+	default:
+		object := make(map[string]any)
+		for _, attribute := range element.Attributes {
+			attributePath := ChildPath(path, "@"+attribute.Name)
+			if opts.Excluded(attributePath) || attribute.AsElement().IsFixed() {
+				continue
+			}
+			object[opts.FieldName(attributePath)] = sampleScalar(attribute.AsElement(), opts)
+		}
+		if textPath := TextPath(path); element.Text != nil && !opts.Excluded(textPath) {
+			object[opts.FieldName(textPath)] = sampleScalar(*element.Text, opts)
+		}
+		inChoice := false
+		for _, child := range element.Children {
+			childPath := ChildPath(path, child.Name)
+			// Consecutive choice branches belong to the same xs:choice; keep the first one
+			if opts.Excluded(childPath) || child.IsFixed() || (child.ChoiceItem && inChoice) {
+				continue
+			}
+			inChoice = child.ChoiceItem
+			object[opts.FieldName(childPath)] = sampleField(child, childPath, opts)
+		}
+		// Polymorphic elements get the first of their concrete types
+		for _, alternative := range element.Alternatives {
+			alternativePath := ChildPath(path, alternative.Name)
+			if opts.Excluded(alternativePath) {
+				continue
+			}
+			object[opts.FieldName(TypePath(path))] = alternative.Name
+			object[opts.FieldName(alternativePath)] = sampleField(alternative, alternativePath, opts)
+			break
+		}
+		value = object
+	}
+
+	if element.IsRepeating() {
+		return []any{value}
+	}
+	return value
+}
+
+// Helper function to convert the sample value of a leaf element to its JSON type
+func sampleScalar(element xsd.Element, opts Options) any {
+	workatoType := opts.TypeMap.Resolve(element).Type
+	value := sampleValue(element, workatoType)
+	switch workatoType {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// Function to generate a placeholder value for an element. Elements with a default use it,
+// enumerated elements use their first allowed value, numbers are moved within their bounds
+// and digits facets, and other strings are clipped to their maxLength facet.
+func SampleValue(element xsd.Element) string {
+	return sampleValue(element, MapType(element.BaseType()))
+}
+
+// Placeholder values of XSD types that map to strings but only accept some of them
+var lexicalSamples = map[string]string{
+	"xs:time":               "00:00:00",
+	"xs:gYear":              "2024",
+	"xs:gYearMonth":         "2024-01",
+	"xs:gMonth":             "--01",
+	"xs:gMonthDay":          "--01-01",
+	"xs:gDay":               "---01",
+	"xs:duration":           "P1D",
+	"xs:dayTimeDuration":    "P1D",
+	"xs:yearMonthDuration":  "P1Y",
+	"xs:long":               "1",
+	"xs:int":                "1",
+	"xs:short":              "1",
+	"xs:byte":               "1",
+	"xs:nonNegativeInteger": "1",
+	"xs:positiveInteger":    "1",
+	"xs:nonPositiveInteger": "0",
+	"xs:negativeInteger":    "-1",
+	"xs:unsignedLong":       "1",
+	"xs:unsignedInt":        "1",
+	"xs:unsignedShort":      "1",
+	"xs:unsignedByte":       "1",
+}
+
+// Function to generate the placeholder value of an element mapped to workatoType
+func sampleValue(element xsd.Element, workatoType string) string {
+	if element.IsWildcard() {
+		// An element the wildcard accepts, which the templates write as is
+		return "<any>Sample any</any>"
+	}
+	if element.Default != "" {
+		return element.Default
+	}
+	if values := element.Enumerations(); len(values) > 0 {
+		return values[0]
+	}
+	if sample, ok := patternSample(element); ok {
+		return sample
+	}
+
+	var value string
+	switch workatoType {
+	case "date":
+		value = "2024-01-01"
+	case "date_time", "timestamp":
+		value = "2024-01-01T00:00:00Z"
+	case "boolean":
+		value = "true"
+	case "integer":
+		value = "1"
+	case "number":
+		value = "1.0"
+	default:
+		value = "Sample " + element.Name
+		if sample, ok := lexicalSamples[element.BaseType()]; ok {
+			value = sample
+		}
+	}
+	value = boundedSample(element, value)
+
+	if maxLength := element.MaxLength(); maxLength > 0 && utf8.RuneCountInString(value) > maxLength {
+		value = string([]rune(value)[:maxLength])
+	}
+	if length := utf8.RuneCountInString(value); length < element.MinLength() {
+		value += strings.Repeat("x", element.MinLength()-length)
+	}
+	return value
+}
+
+// Helper function to generate the shortest value matching the first xs:pattern of an
+// element, such as AAA for [A-Z]{3}. Patterns in XML Schema syntax that Go regular
+// expressions lack, which are not validated either, get no sample.
+func patternSample(element xsd.Element) (string, bool) {
+	patterns := element.Patterns()
+	if len(patterns) == 0 || strings.Contains(patterns[0], "-[") {
+		return "", false
+	}
+	parsed, err := syntax.Parse(patterns[0], syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	sample := shortestMatch(parsed)
+	if matched, err := regexp.MatchString(`^(?:`+patterns[0]+`)$`, sample); err != nil || !matched {
+		return "", false
+	}
+	return sample, true
+}
+
+// Helper function to build the shortest string a parsed regular expression matches, taking
+// the first branch of alternations and the first letter or digit of character classes
+func shortestMatch(re *syntax.Regexp) string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return string(re.Rune)
+	case syntax.OpCharClass:
+		return classSample(re.Rune)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return "a"
+	case syntax.OpCapture, syntax.OpAlternate, syntax.OpPlus:
+		return shortestMatch(re.Sub[0])
+	case syntax.OpRepeat:
+		return strings.Repeat(shortestMatch(re.Sub[0]), re.Min)
+	case syntax.OpConcat:
+		var sb strings.Builder
+		for _, sub := range re.Sub {
+			sb.WriteString(shortestMatch(sub))
+		}
+		return sb.String()
+	}
+	// Optional content, such as x* and x?, and anchors match the empty string
+	return ""
+}
+
+// Helper function to pick a character of a class given as pairs of rune ranges, preferring
+// letters and digits over punctuation
+func classSample(ranges []rune) string {
+	for _, preferred := range "Aa0" {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= preferred && preferred <= ranges[i+1] {
+				return string(preferred)
+			}
+		}
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if unicode.IsLetter(ranges[i]) || unicode.IsDigit(ranges[i]) {
+			return string(ranges[i])
+		}
+	}
+	if len(ranges) == 0 {
+		return ""
+	}
+	return string(ranges[0])
+}
+
+// Helper function to move a numeric sample value within the minInclusive, minExclusive,
+// maxInclusive, maxExclusive, totalDigits and fractionDigits facets of an element, so that
+// the document rendered from the sample is valid. Values that are not numbers are returned
+// unchanged, as are those of bounds that are not numbers, such as dates.
+func boundedSample(element xsd.Element, value string) string {
+	number, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return value
+	}
+	integral := !strings.Contains(value, ".")
+	decimals := 1
+	if integral {
+		decimals = 0
+	}
+
+	one := big.NewRat(1, 1)
+	lower, upper := element.Bounds()
+	low, hasLow := new(big.Rat).SetString(strings.TrimPrefix(lower.Value, "+"))
+	high, hasHigh := new(big.Rat).SetString(strings.TrimPrefix(upper.Value, "+"))
+	below := func(n *big.Rat) bool {
+		return hasLow && (n.Cmp(low) < 0 || (lower.Exclusive && n.Cmp(low) == 0))
+	}
+	above := func(n *big.Rat) bool {
+		return hasHigh && (n.Cmp(high) > 0 || (upper.Exclusive && n.Cmp(high) == 0))
+	}
+	if below(number) {
+		number.Set(low)
+		if lower.Exclusive {
+			number.Add(number, one)
+		}
+		decimals = max(decimals, decimalPlaces(lower.Value))
+	}
+	if above(number) {
+		number.Set(high)
+		if upper.Exclusive {
+			number.Sub(number, one)
+		}
+		decimals = max(decimals, decimalPlaces(upper.Value))
+		// Ranges narrower than 1 get their middle value
+		if below(number) && !integral {
+			number.Add(low, high)
+			number.Quo(number, big.NewRat(2, 1))
+			decimals = max(decimalPlaces(lower.Value), decimalPlaces(upper.Value)) + 1
+		}
+	}
+
+	total, fraction := element.Digits()
+	if fraction > 0 {
+		decimals = min(decimals, fraction)
+	}
+	if total > 0 && !integral {
+		integerDigits := len(strings.TrimLeft(number.FloatString(0), "-0"))
+		decimals = max(0, min(decimals, total-integerDigits))
+	}
+	return number.FloatString(decimals)
+}
+
+// Helper function to count the decimals of a number in its lexical form
+func decimalPlaces(value string) int {
+	_, fraction, _ := strings.Cut(value, ".")
+	if i := strings.IndexAny(fraction, "eE"); i >= 0 {
+		fraction = fraction[:i]
+	}
+	return len(fraction)
+}
@@ -0,0 +1,80 @@
+package workato
+
+import "slices"
+
+// Part of a Workato schema split into several schemas of a limited number of fields
+type Part struct {
+	Fields []Field  // Fields of the part, with the objects they belong to
+	Paths  []string // Paths of the fields whose whole content is in the part, such as Order/Order_Customer
+}
+
+// Helper function to count fields along with their nested properties
+func CountFields(fields []Field) int {
+	count := len(fields)
+	for _, field := range fields {
+		count += CountFields(field.Properties)
+	}
+	return count
+}
+
+// Function to split fields into parts of at most maxFields fields each, counting nested
+// properties. Objects too large for a part are split in turn, each part holding the object
+// with some of its properties, so that merging the data of the parts gives the data of the
+// whole schema. Objects nested too deep for any of their properties to fit are collapsed
+// into opaque objects, whose data is passed as a whole.
+func Split(fields []Field, maxFields int) []Part {
+	return split(fields, maxFields, "")
+}
+
+// Function to split the fields of the object at prefix into parts of at most maxFields fields,
+// filling each part with the fields in order before starting the next one
+func split(fields []Field, maxFields int, prefix string) []Part {
+	var parts []Part
+	var current Part
+	size := 0
+	for _, field := range fields {
+		for _, piece := range splitField(field, maxFields, prefix) {
+			count := CountFields(piece.Fields)
+			// The pieces of a split object go into parts of their own, since a part cannot
+			// hold two fields of the same name
+			full := size+count > maxFields || slices.ContainsFunc(current.Fields, func(f Field) bool { return f.Name == field.Name })
+			if len(current.Fields) > 0 && full {
+				parts = append(parts, current)
+				current, size = Part{}, 0
+			}
+			current.Fields = append(current.Fields, piece.Fields...)
+			current.Paths = append(current.Paths, piece.Paths...)
+			size += count
+		}
+	}
+	if len(current.Fields) > 0 {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// Function to split a field into pieces of at most maxFields fields, each a part holding the
+// field with some of its properties. Fields that fit are a single piece, and so are objects
+// collapsed because not even the object and one of its properties fit.
+func splitField(field Field, maxFields int, prefix string) []Part {
+	path := prefix + field.Name
+	if len(field.Properties) == 0 || CountFields([]Field{field}) <= maxFields {
+		return []Part{{Fields: []Field{field}, Paths: []string{path}}}
+	}
+	if maxFields < 2 {
+		const collapsed = "Content collapsed to fit the field limit, pass it as a whole object"
+		hint := collapsed
+		if field.Hint != "" {
+			hint = joinSentences([]string{field.Hint, collapsed})
+		}
+		field.Properties, field.Hint = nil, hint
+		return []Part{{Fields: []Field{field}, Paths: []string{path}}}
+	}
+	var pieces []Part
+	for _, part := range split(field.Properties, maxFields-1, path+"/") {
+		piece := field
+		piece.Properties = part.Fields
+		pieces = append(pieces, Part{Fields: []Field{piece}, Paths: part.Paths})
+	}
+	return pieces
+}
@@ -0,0 +1,172 @@
+package workato
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Built-in mapping of XSD types to Workato types
+var DefaultTypeMap = map[string]string{
+	"xs:string":   "string",
+	"xs:date":     "date",
+	"xs:dateTime": "date_time",
+	"xs:boolean":  "boolean",
+	"xs:integer":  "integer",
+	"xs:float":    "number",
+	"xs:double":   "number",
+	"xs:decimal":  "number",
+
+	"xs:dateTimeStamp": "timestamp", // XSD 1.1 dateTime with a mandatory time zone
+}
+
+// Helper function to map XSD types to Workato types
+func MapType(xsdType string) string {
+	if workatoType, ok := DefaultTypeMap[xsdType]; ok {
+		return workatoType
+	}
+	return "string" // Default to string if type is unknown
+}
+
+// TypeRule maps the XSD types matching a pattern to a Workato type and control_type
+type TypeRule struct {
+	Type        string `json:"type"`
+	ControlType string `json:"control_type,omitempty"`
+}
+
+// TypeMap holds user-defined type rules, which take precedence over DefaultTypeMap.
+// The zero value has no rules.
+type TypeMap struct {
+	rules []typeRule // In order of precedence
+}
+
+// Kinds of type rule patterns, in order of precedence
+const (
+	exactPattern = iota
+	wildcardPattern
+	regexpPattern
+)
+
+type typeRule struct {
+	pattern string
+	kind    int
+	regexp  *regexp.Regexp
+	TypeRule
+}
+
+// Function to build a type map from rules keyed by pattern. A pattern is either an exact
+// type name (tns:AmountType), a wildcard pattern (tns:*Amount) or a regular expression
+// enclosed in slashes (/^tns:.*Code$/). Exact names take precedence over wildcards, and
+// wildcards over regular expressions; among patterns of the same kind the longest wins.
+func NewTypeMap(rules map[string]TypeRule) (TypeMap, error) {
+	var typeMap TypeMap
+	for pattern, rule := range rules {
+		if rule.Type == "" {
+			return TypeMap{}, fmt.Errorf("type rule %q has no type", pattern)
+		}
+		compiled := typeRule{pattern: pattern, TypeRule: rule}
+		switch {
+		case len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/"):
+			re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+			if err != nil {
+				return TypeMap{}, fmt.Errorf("type rule %q: %w", pattern, err)
+			}
+			compiled.kind, compiled.regexp = regexpPattern, re
+		case strings.ContainsAny(pattern, "*?["):
+			if _, err := path.Match(pattern, ""); err != nil {
+				return TypeMap{}, fmt.Errorf("type rule %q: %w", pattern, err)
+			}
+			compiled.kind = wildcardPattern
+		}
+		typeMap.rules = append(typeMap.rules, compiled)
+	}
+
+	sort.Slice(typeMap.rules, func(i, j int) bool {
+		a, b := typeMap.rules[i], typeMap.rules[j]
+		if a.kind != b.kind {
+			return a.kind < b.kind
+		}
+		if len(a.pattern) != len(b.pattern) {
+			return len(a.pattern) > len(b.pattern)
+		}
+		return a.pattern < b.pattern
+	})
+	return typeMap, nil
+}
+
+// Function to find the rule matching an XSD type name, along with its pattern
+func (typeMap TypeMap) Lookup(xsdType string) (TypeRule, string, bool) {
+	for _, rule := range typeMap.rules {
+		var matched bool
+		switch rule.kind {
+		case exactPattern:
+			matched = rule.pattern == xsdType
+		case wildcardPattern:
+			matched, _ = path.Match(rule.pattern, xsdType)
+		case regexpPattern:
+			matched = rule.regexp.MatchString(xsdType)
+		}
+		if matched {
+			return rule.TypeRule, rule.pattern, true
+		}
+	}
+	return TypeRule{}, "", false
+}
+
+// Function to get the Workato type and control_type of an element. Rules are matched
+// against the declared type first, so that a named simpleType can be mapped on its own,
+// then against the built-in type it restricts, before falling back to DefaultTypeMap.
+func (typeMap TypeMap) Resolve(element xsd.Element) TypeRule {
+	for _, xsdType := range []string{element.Type, element.BaseType()} {
+		if xsdType == "" {
+			continue
+		}
+		if rule, _, ok := typeMap.Lookup(xsdType); ok {
+			return rule
+		}
+	}
+	return TypeRule{Type: MapType(element.BaseType())}
+}
+
+// Function to print the effective type map as a text table.
+// Types matched by a rule of overrides are marked as such, followed by the rules that
+// do not name a built-in type.
+func PrintTypeMap(w io.Writer, defaults map[string]string, overrides TypeMap) error {
+	xsdTypes := make([]string, 0, len(defaults))
+	for xsdType := range defaults {
+		xsdTypes = append(xsdTypes, xsdType)
+	}
+	sort.Strings(xsdTypes)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "XSD TYPE\tWORKATO TYPE\tCONTROL TYPE\tSOURCE")
+	for _, xsdType := range xsdTypes {
+		rule, pattern, ok := overrides.Lookup(xsdType)
+		switch {
+		case !ok:
+			fmt.Fprintf(tw, "%s\t%s\t\tdefault\n", xsdType, defaults[xsdType])
+		case pattern == xsdType:
+			fmt.Fprintf(tw, "%s\t%s\t%s\toverride\n", xsdType, rule.Type, rule.ControlType)
+		default:
+			fmt.Fprintf(tw, "%s\t%s\t%s\toverride (%s)\n", xsdType, rule.Type, rule.ControlType, pattern)
+		}
+	}
+
+	var patterns []typeRule
+	for _, rule := range overrides.rules {
+		if _, ok := defaults[rule.pattern]; !ok {
+			patterns = append(patterns, rule)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool { return patterns[i].pattern < patterns[j].pattern })
+	for _, rule := range patterns {
+		fmt.Fprintf(tw, "%s\t%s\t%s\toverride\n", rule.pattern, rule.Type, rule.ControlType)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,569 @@
+// Package workato generates Workato schema fields from a parsed XSD schema.
+package workato
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Options controlling how the Workato schema is generated
+type Options struct {
+	AttributePrefix string                   // Prefix marking Workato fields generated from XML attributes
+	Overrides       map[string]FieldOverride // Keyed by element path, such as Order/Line/Sku, or Order/@id for attributes
+	TypeMap         TypeMap                  // Custom type rules, taking precedence over DefaultTypeMap
+	Filter          PathFilter               // Include and exclude patterns selecting the paths to generate
+	InferControls   bool                     // Infer control_types such as checkbox or email from types and names
+	Naming          string                   // Strategy naming nested fields: NamingFlat (the default), NamingNested or NamingPath
+	Case            string                   // Case conversion of field names, such as CaseSnake; names are kept as is by default
+	Abbreviations   map[string]string        // Words spelled out in labels, such as Msg: Message, matched regardless of case
+	SortFields      bool                     // Sort the fields of each object by name instead of the XSD sequence order
+
+	renames []Rename // Fields renamed to avoid collisions, once resolved against a schema
+}
+
+// Naming strategies of nested fields
+const (
+	NamingFlat   = "flat"   // Prefixed with the name of the parent element, such as Address_Street
+	NamingNested = "nested" // The plain element name, such as Street
+	NamingPath   = "path"   // The whole element path, such as Customer_Address_Street
+)
+
+// FieldOverride holds the settings replacing the generated ones for a single element or attribute
+type FieldOverride struct {
+	Name        string `json:"name,omitempty"`         // Field name, also used by the template
+	Label       string `json:"label,omitempty"`        // Label shown in Workato
+	Type        string `json:"type,omitempty"`         // Workato type (of the items, for repeating elements)
+	ControlType string `json:"control_type,omitempty"` // Workato control_type
+	Optional    *bool  `json:"optional,omitempty"`     // Optionality, when set
+	Exclude     bool   `json:"exclude,omitempty"`      // Leaves the element out of the schema and the template
+	Sticky      bool   `json:"sticky,omitempty"`       // Keeps the optional field shown in the recipe once it was shown
+	Toggle      bool   `json:"toggle,omitempty"`       // Adds a free-text variant of a field with a control, such as a select
+}
+
+// Field is a single field of a Workato schema
+type Field struct {
+	Name        string     `json:"name"`
+	Label       string     `json:"label,omitempty"`
+	Type        string     `json:"type,omitempty"`
+	Of          string     `json:"of,omitempty"`
+	Optional    bool       `json:"optional"`
+	ControlType string     `json:"control_type,omitempty"`
+	Hint        string     `json:"hint,omitempty"`
+	Default     string     `json:"default,omitempty"`
+	PickList    [][]string `json:"pick_list,omitempty"`
+	Sticky      bool       `json:"sticky,omitempty"`
+	ToggleHint  string     `json:"toggle_hint,omitempty"`
+	ToggleField *Field     `json:"toggle_field,omitempty"`
+	Properties  []Field    `json:"properties,omitempty"`
+}
+
+// Function to generate the Workato schema fields of the global elements of a schema. Fields
+// follow the order of the XSD: attributes, then the text, then the children in sequence
+// order, unless SortFields is set. The templates always follow the sequence order, which
+// the XML must keep to be valid.
+func Generate(schema xsd.Schema, opts Options) ([]Field, error) {
+	// Include patterns keep the ancestors of the matching elements of this schema, and
+	// colliding names are renamed
+	opts = opts.Resolve(schema)
+	var fields []Field
+
+	for _, element := range schema.Elements {
+		if opts.Excluded(element.Name) {
+			continue
+		}
+		fields = append(fields, generateField(element, opts.FieldName(element.Name), element.Name, opts))
+	}
+	if opts.SortFields {
+		sortFields(fields)
+	}
+
+	return fields, nil
+}
+
+// Helper function to sort fields and their properties by name, regardless of case
+func sortFields(fields []Field) {
+	slices.SortStableFunc(fields, func(a, b Field) int {
+		return cmp.Or(strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)), strings.Compare(a.Name, b.Name))
+	})
+	for i := range fields {
+		sortFields(fields[i].Properties)
+	}
+}
+
+// Helper function to build the path of the type selector of the polymorphic element at
+// path, which is addressed like its xsi:type attribute
+func TypePath(path string) string {
+	return ChildPath(path, "@xsi:type")
+}
+
+// Helper function to build the path of the text of the element at path, for elements
+// with attributes and simpleContent, which is addressed like a child called #text
+func TextPath(path string) string {
+	return ChildPath(path, "#text")
+}
+
+// Helper function to build the path of a child element of the element at parent.
+// Attributes are addressed by their name prefixed with "@".
+func ChildPath(parent, name string) string {
+	return parent + "/" + name
+}
+
+// Function to describe the required attributes of the element at path that a template maps
+// to fields, which are written even when their field is empty unlike the optional ones.
+// Attributes with a fixed value or excluded from the outputs are left out, and "" is
+// returned when there are none.
+func (opts Options) RequiredAttributesNote(element xsd.Element, path string) string {
+	var names []string
+	for _, attribute := range element.Attributes {
+		if attribute.Use == "required" && attribute.Fixed == "" && !opts.Excluded(ChildPath(path, "@"+attribute.Name)) {
+			names = append(names, attribute.Name)
+		}
+	}
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return "The required attribute " + names[0] + " of " + element.Name + " is written even when empty"
+	}
+	return "The required attributes " + strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1] +
+		" of " + element.Name + " are written even when empty"
+}
+
+// Helper function to check whether a template leaves out an attribute when its field is
+// empty: optional attributes are, except the boolean ones, since the sections of the
+// templates cannot tell false from a missing value
+func (opts Options) OmitsEmpty(attribute xsd.Attribute) bool {
+	return attribute.Use != "required" && opts.TypeMap.Resolve(attribute.AsElement()).Type != "boolean"
+}
+
+// Helper function to check whether a template leaves out an optional element when its field
+// is absent, as for attributes by OmitsEmpty. Booleans are always written, since sections
+// cannot tell false from a missing value, and so are lists, whose section would repeat the
+// element for each item. Repeating, choice and nillable elements have sections of their own.
+func (opts Options) OmitsAbsent(element xsd.Element) bool {
+	if element.MinOccurs != "0" || element.IsRepeating() || element.ChoiceItem || element.NilWhenAbsent() || element.IsFixed() || element.IsWildcard() {
+		return false
+	}
+	return !element.IsLeaf() || (!element.IsList() && opts.TypeMap.Resolve(element).Type != "boolean")
+}
+
+// Helper function to get the field name of the element at path, applying its name override
+// or its rename when it collides with another field
+func (opts Options) FieldName(path string) string {
+	if override := opts.Overrides[path]; override.Name != "" {
+		return override.Name
+	}
+	for _, rename := range opts.renames {
+		if rename.Path == path {
+			return rename.Renamed
+		}
+	}
+	return opts.DefaultFieldName(path)
+}
+
+// Function to build the field name of the element at path following the naming strategy
+// and the case conversion. Attribute fields are marked with the attribute prefix, and the
+// type selector of a polymorphic element, the text of an element and its wildcard content
+// are named like children called "type", "text" and "any". Path names are joined with
+// underscores, since Mustache reads dots as lookups.
+func (opts Options) DefaultFieldName(path string) string {
+	return opts.fieldName(path, opts.namingDepth(strings.Count(path, "/")))
+}
+
+// Function to build the field name of the element at path, prefixed with the names of
+// depth ancestors
+func (opts Options) fieldName(path string, depth int) string {
+	segments := strings.Split(path, "/")
+	name, prefix := segments[len(segments)-1], ""
+	switch {
+	case name == "@xsi:type":
+		name = "type"
+	case strings.HasPrefix(name, "#"):
+		name = name[1:]
+	case strings.HasPrefix(name, "@"):
+		name, prefix = name[1:], opts.AttributePrefix
+	}
+
+	if parents := segments[:len(segments)-1]; depth > 0 {
+		name = strings.Join(parents[len(parents)-depth:], "_") + "_" + name
+	}
+	if opts.Case != "" {
+		name = ConvertCase(name, opts.Case)
+	}
+	return prefix + name
+}
+
+// Helper function to check whether the element at path is excluded from the outputs, by
+// its override or by the path filter
+func (opts Options) Excluded(path string) bool {
+	return opts.Overrides[path].Exclude || opts.Filter.Excluded(path)
+}
+
+// Function to generate the Workato field for the element at path. Repeating elements become
+// arrays of their item type, and elements with children carry them as properties.
+func generateField(element xsd.Element, fieldName, path string, opts Options) Field {
+	rule := opts.TypeMap.Resolve(element)
+	field := Field{
+		Name:        fieldName,
+		Label:       opts.Label(fieldName),
+		Type:        rule.Type,
+		Optional:    element.IsOptional(),
+		ControlType: rule.ControlType,
+		Hint:        hint(element, opts.TypeMap),
+		Default:     element.Default,
+	}
+	if field.ControlType == "" && opts.InferControls {
+		field.ControlType = InferControlType(element)
+	}
+
+	// Lists become arrays of their item type; repeating lists keep one string per occurrence
+	if element.IsList() && !element.IsRepeating() {
+		field.Type, field.Of = "array", opts.TypeMap.Resolve(element.ListItem()).Type
+	}
+
+	// Enumerated values become a select control with a static pick list of [label, value] pairs
+	if values := element.Enumerations(); len(values) > 0 {
+		field.ControlType = "select"
+		for _, value := range values {
+			field.PickList = append(field.PickList, []string{value, value})
+		}
+	}
+
+	if !element.IsLeaf() {
+		field.Type, field.ControlType = "object", ""
+		for _, attribute := range element.Attributes {
+			attributePath := ChildPath(path, "@"+attribute.Name)
+			// Fixed values are written by the template as constants
+			if opts.Excluded(attributePath) || attribute.AsElement().IsFixed() {
+				continue
+			}
+			field.Properties = append(field.Properties,
+				generateField(attribute.AsElement(), opts.FieldName(attributePath), attributePath, opts))
+		}
+		if textPath := TextPath(path); element.Text != nil && !opts.Excluded(textPath) {
+			field.Properties = append(field.Properties, generateField(*element.Text, opts.FieldName(textPath), textPath, opts))
+		}
+		field.Properties = append(field.Properties, generateChildFields(element.Children, path, opts)...)
+		if len(element.Alternatives) > 0 {
+			field.Properties = append(field.Properties, generateAlternativeFields(element, path, opts)...)
+		}
+	}
+
+	applyOverride(&field, opts.Overrides[path])
+	// Arrays have no single value to type in instead
+	if opts.Overrides[path].Toggle && !element.IsRepeating() {
+		addToggleField(&field)
+	}
+
+	if element.IsRepeating() {
+		field.Of = field.Type
+		field.Type = "array"
+	}
+
+	return field
+}
+
+// Function to generate Workato fields for the child elements of the element at path
+func generateChildFields(children []xsd.Element, path string, opts Options) []Field {
+	var properties []Field
+	for _, child := range children {
+		childPath := ChildPath(path, child.Name)
+		if opts.Excluded(childPath) || child.IsFixed() {
+			continue
+		}
+		properties = append(properties, generateField(child, opts.FieldName(childPath), childPath, opts))
+	}
+	return properties
+}
+
+// Function to infer the Workato control_type of a leaf element from its type, then from
+// its name. Returns "" when no heuristic applies.
+func InferControlType(element xsd.Element) string {
+	switch element.BaseType() {
+	case "xs:date":
+		return "date"
+	case "xs:dateTime", "xs:dateTimeStamp":
+		return "date_time"
+	case "xs:boolean":
+		return "checkbox"
+	}
+
+	name := strings.ToLower(element.Name)
+	for _, control := range []string{"email", "phone", "url"} {
+		if strings.Contains(name, control) {
+			return control
+		}
+	}
+	return ""
+}
+
+// Function to generate the fields of a polymorphic element: a selector of its concrete type,
+// written as xsi:type, followed by an optional object per concrete type holding its content
+func generateAlternativeFields(element xsd.Element, path string, opts Options) []Field {
+	selectorName := opts.FieldName(TypePath(path))
+	selector := Field{
+		Name:        selectorName,
+		Label:       opts.Label(selectorName),
+		Type:        "string",
+		ControlType: "select",
+		Hint:        "Concrete type of " + element.Name + ", written as xsi:type. Populate the object of the same name.",
+	}
+	properties := []Field{selector}
+	for _, alternative := range element.Alternatives {
+		alternativePath := ChildPath(path, alternative.Name)
+		if opts.Excluded(alternativePath) {
+			continue
+		}
+		properties[0].PickList = append(properties[0].PickList, []string{alternative.Name, alternative.Name})
+		field := generateField(alternative, opts.FieldName(alternativePath), alternativePath, opts)
+		field.Optional = true
+		properties = append(properties, field)
+	}
+	applyOverride(&properties[0], opts.Overrides[TypePath(path)])
+	if opts.Overrides[TypePath(path)].Toggle {
+		addToggleField(&properties[0])
+	}
+	return properties
+}
+
+// Helper function to apply the label, type, control_type and optionality of an override
+func applyOverride(field *Field, override FieldOverride) {
+	if override.Label != "" {
+		field.Label = override.Label
+	}
+	if override.Type != "" {
+		field.Type = override.Type
+	}
+	if override.ControlType != "" {
+		field.ControlType = override.ControlType
+	}
+	if override.Optional != nil {
+		field.Optional = *override.Optional
+	}
+	if override.Sticky {
+		field.Sticky = true
+	}
+}
+
+// Function to add a free-text variant of a field with a control, such as the select of an
+// enumeration or a date picker, as its toggle_field, so that recipes can switch between
+// picking a value and typing any value or formula. Fields without a control have nothing
+// to toggle.
+func addToggleField(field *Field) {
+	if field.ControlType == "" || field.Type == "object" {
+		return
+	}
+	field.ToggleHint = "Use " + strings.ReplaceAll(field.ControlType, "_", " ") + " control"
+	if field.ControlType == "select" || field.ControlType == "checkbox" {
+		field.ToggleHint = "Select from list"
+	}
+	field.ToggleField = &Field{
+		Name:        field.Name,
+		Label:       field.Label,
+		Type:        field.Type,
+		Optional:    field.Optional,
+		ControlType: "text",
+		Hint:        field.Hint,
+		Sticky:      field.Sticky,
+		ToggleHint:  "Use custom value",
+	}
+}
+
+// Function to build the Workato hint text for an element from its documentation and facets,
+// describing dates and times as the default type map maps them
+func Hint(element xsd.Element) string {
+	return hint(element, TypeMap{})
+}
+
+// Helper function to build the hint text of an element, describing the lexical form of
+// dates and times that typeMap keeps as strings
+func hint(element xsd.Element, typeMap TypeMap) string {
+	var parts []string
+	if documentation := element.Annotation.Text(); documentation != "" {
+		parts = append(parts, documentation)
+	}
+	switch patterns := element.Patterns(); len(patterns) {
+	case 0:
+	case 1:
+		parts = append(parts, "Must match the pattern "+patterns[0])
+	default:
+		parts = append(parts, "Must match one of the patterns "+strings.Join(patterns, ", "))
+	}
+	switch minLength := element.MinLength(); {
+	case minLength == 1:
+		parts = append(parts, "Must not be empty")
+	case minLength > 1:
+		parts = append(parts, fmt.Sprintf("Min %d characters", minLength))
+	}
+	// The maxLength sentence comes after the other facets of strings, where wkt2xsd reads it back
+	if maxLength := element.MaxLength(); maxLength > 0 {
+		parts = append(parts, fmt.Sprintf("Max %d characters", maxLength))
+	}
+	if format := formatHint(element, typeMap); format != "" {
+		parts = append(parts, format)
+	}
+	if digits := describeDigits(element); digits != "" {
+		parts = append(parts, digits)
+	}
+	if bounds := describeBounds(element); bounds != "" {
+		parts = append(parts, bounds)
+	}
+	if members := element.UnionMembers(); len(members) > 0 {
+		parts = append(parts, "Accepts values of any of the types "+strings.Join(members, ", "))
+	}
+	for _, assertion := range element.Assertions {
+		parts = append(parts, "Must satisfy "+assertion)
+	}
+	parts = append(parts, element.Constraints...)
+	if alternatives := describeTypeAlternatives(element.TypeAlternatives); alternatives != "" {
+		parts = append(parts, "Type assigned conditionally: "+alternatives)
+	}
+	if element.Truncated {
+		parts = append(parts, "Recursive content truncated at the maximum depth")
+	}
+	if element.IsPassThrough() {
+		parts = append(parts, "XML content, written to the document as is")
+	}
+	return joinSentences(parts)
+}
+
+// Helper function to describe the totalDigits and fractionDigits facets of a number, such as
+// "Up to 18 digits, of which 2 decimals"
+func describeDigits(element xsd.Element) string {
+	switch total, fraction := element.Digits(); {
+	case total > 0 && fraction > 0:
+		return fmt.Sprintf("Up to %d digits, of which %d decimals", total, fraction)
+	case total > 0:
+		return fmt.Sprintf("Up to %d digits", total)
+	case fraction > 0:
+		return fmt.Sprintf("Up to %d decimals", fraction)
+	}
+	return ""
+}
+
+// Helper function to describe the range facets of a value, such as "Between 1 and 100" or
+// "Greater than 0"
+func describeBounds(element xsd.Element) string {
+	lower, upper := element.Bounds()
+	if lower.Value != "" && upper.Value != "" && !lower.Exclusive && !upper.Exclusive {
+		return "Between " + lower.Value + " and " + upper.Value
+	}
+	var limits []string
+	switch {
+	case lower.Exclusive:
+		limits = append(limits, "greater than "+lower.Value)
+	case lower.Value != "":
+		limits = append(limits, "at least "+lower.Value)
+	}
+	switch {
+	case upper.Exclusive:
+		limits = append(limits, "less than "+upper.Value)
+	case upper.Value != "":
+		limits = append(limits, "at most "+upper.Value)
+	}
+	if len(limits) == 0 {
+		return ""
+	}
+	description := strings.Join(limits, " and ")
+	return strings.ToUpper(description[:1]) + description[1:]
+}
+
+// Helper function to describe XSD 1.1 type alternatives, such as "xs:int when @kind='n',
+// otherwise xs:string"
+func describeTypeAlternatives(alternatives []xsd.TypeAlternative) string {
+	var described []string
+	for _, alternative := range alternatives {
+		if alternative.Type == "" {
+			continue
+		}
+		if alternative.Test == "" {
+			described = append(described, "otherwise "+alternative.Type)
+		} else {
+			described = append(described, alternative.Type+" when "+alternative.Test)
+		}
+	}
+	return strings.Join(described, ", ")
+}
+
+// Helper function to join hint sentences, adding a full stop where one is missing
+func joinSentences(parts []string) string {
+	var sb strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			if !strings.HasSuffix(parts[i-1], ".") {
+				sb.WriteString(".")
+			}
+			sb.WriteString(" ")
+		}
+		sb.WriteString(part)
+	}
+	return sb.String()
+}
+
+// Function to marshal Workato schema fields to indented JSON
+func Marshal(fields []Field) ([]byte, error) {
+	schemaJSON, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling schema to JSON: %w", err)
+	}
+	return schemaJSON, nil
+}
+
+// Function to write Workato schema fields to a JSON file
+func WriteFile(fields []Field, outputFile string) error {
+	schemaJSON, err := Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(outputFile, schemaJSON, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing schema to file: %w", err)
+	}
+
+	return nil
+}
+
+// Function to print Workato schema fields as a tree, one field per line with its type,
+// followed by a summary. Arrays are marked with [] and optional fields with (optional).
+func PrintTree(w io.Writer, fields []Field) error {
+	var count, optional, arrays int
+	var sb strings.Builder
+	var walk func(fields []Field, indent string)
+	walk = func(fields []Field, indent string) {
+		for i, field := range fields {
+			branch, childIndent := "├── ", "│   "
+			if i == len(fields)-1 {
+				branch, childIndent = "└── ", "    "
+			}
+			if indent == "" && len(fields) == 1 {
+				branch, childIndent = "", ""
+			}
+
+			name, fieldType := field.Name, field.Type
+			if field.Type == "array" {
+				name, fieldType = name+"[]", field.Of
+				arrays++
+			}
+			sb.WriteString(indent + branch + name + ": " + fieldType)
+			if field.Optional {
+				sb.WriteString(" (optional)")
+				optional++
+			}
+			sb.WriteString("\n")
+			count++
+			walk(field.Properties, indent+childIndent)
+		}
+	}
+	walk(fields, "")
+	fmt.Fprintf(&sb, "%d fields: %d optional, %d arrays\n", count, optional, arrays)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
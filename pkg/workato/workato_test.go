@@ -0,0 +1,926 @@
+package workato
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Options matching the command line defaults
+var testOptions = Options{AttributePrefix: "@"}
+
+// Helper function to parse an XSD string
+func parseString(t *testing.T, content string) xsd.Schema {
+	t.Helper()
+	schema, err := xsd.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("xsd.Parse: %v", err)
+	}
+	return schema
+}
+
+func TestMaxLengthHintAndSample(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Max5Text">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="5"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Code" type="Max5Text"/>
+        <xs:element name="Comment">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:maxLength value="35"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Note" type="xs:string"/>
+        <xs:element name="Größe">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:maxLength value="10"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	fields, err := Generate(schema, testOptions)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	properties := fields[0].Properties
+
+	wantHints := map[string]string{
+		"Order_Code":    "Max 5 characters",
+		"Order_Comment": "Max 35 characters",
+		"Order_Note":    "",
+		"Order_Größe":   "Max 10 characters",
+	}
+	for _, field := range properties {
+		if field.Hint != wantHints[field.Name] {
+			t.Errorf("%s: hint = %q, want %q", field.Name, field.Hint, wantHints[field.Name])
+		}
+		if field.Type != "string" {
+			t.Errorf("%s: type = %q, want string", field.Name, field.Type)
+		}
+	}
+
+	children := schema.Elements[0].Children
+	if got := SampleValue(children[0]); got != "Sampl" {
+		t.Errorf("sample for Code = %q, want %q", got, "Sampl")
+	}
+	if got := SampleValue(children[1]); got != "Sample Comment" {
+		t.Errorf("sample for Comment = %q, want %q", got, "Sample Comment")
+	}
+	if got := SampleValue(children[2]); got != "Sample Note" {
+		t.Errorf("sample for Note = %q, want %q", got, "Sample Note")
+	}
+	// maxLength counts characters, not bytes
+	if got := SampleValue(children[3]); got != "Sample Grö" {
+		t.Errorf("sample for Größe = %q, want %q", got, "Sample Grö")
+	}
+}
+
+func TestXSD11Hints(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Range">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Min" type="xs:int"/>
+        <xs:element name="Max" type="xs:int"/>
+        <xs:element name="Step" type="xs:string">
+          <xs:alternative test="@kind = 'int'" type="xs:int"/>
+          <xs:alternative type="xs:string"/>
+        </xs:element>
+      </xs:sequence>
+      <xs:assert test="Min le Max"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	fields, err := Generate(schema, testOptions)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if want := "Must satisfy Min le Max"; fields[0].Hint != want {
+		t.Errorf("Range: hint = %q, want %q", fields[0].Hint, want)
+	}
+	if step, want := fields[0].Properties[2], "Type assigned conditionally: xs:int when @kind = 'int', otherwise xs:string"; step.Hint != want || step.Type != "string" {
+		t.Errorf("Step: hint = %q, type %q, want %q as string", step.Hint, step.Type, want)
+	}
+}
+
+func TestTypeMapRules(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="urn:orders" targetNamespace="urn:orders">
+  <xs:simpleType name="AmountType">
+    <xs:restriction base="xs:decimal"/>
+  </xs:simpleType>
+  <xs:simpleType name="CountryCode">
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Total" type="tns:AmountType"/>
+        <xs:element name="Country" type="tns:CountryCode"/>
+        <xs:element name="Quantity" type="xs:int"/>
+        <xs:element name="Note" type="xs:string"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	typeMap, err := NewTypeMap(map[string]TypeRule{
+		"tns:AmountType": {Type: "number", ControlType: "currency"},
+		"tns:*Type":      {Type: "string"},
+		"/Code$/":        {Type: "string", ControlType: "text-area"},
+		"xs:int":         {Type: "integer"},
+	})
+	if err != nil {
+		t.Fatalf("NewTypeMap: %v", err)
+	}
+	opts := testOptions
+	opts.TypeMap = typeMap
+	fields, err := Generate(schema, opts)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	want := []TypeRule{
+		{Type: "number", ControlType: "currency"}, // The exact name wins over the wildcard
+		{Type: "string", ControlType: "text-area"},
+		{Type: "integer"},
+		{Type: "string"},
+	}
+	for i, field := range fields[0].Properties {
+		if got := (TypeRule{Type: field.Type, ControlType: field.ControlType}); got != want[i] {
+			t.Errorf("%s = %+v, want %+v", field.Name, got, want[i])
+		}
+	}
+	if fields[0].ControlType != "" {
+		t.Errorf("Order control_type = %q, want none", fields[0].ControlType)
+	}
+	if sample := Sample(schema, opts)["Order"].(map[string]any); sample["Order_Quantity"] != int64(1) {
+		t.Errorf("Order_Quantity sample = %#v, want 1", sample["Order_Quantity"])
+	}
+
+	if _, err := NewTypeMap(map[string]TypeRule{"/(/": {Type: "string"}}); err == nil {
+		t.Error("NewTypeMap: expected an error for an invalid regular expression")
+	}
+}
+
+func TestPathFilter(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Id" type="xs:string"/>
+        <xs:element name="Customer">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Name" type="xs:string"/>
+              <xs:element name="Extension" type="xs:string"/>
+            </xs:sequence>
+            <xs:attribute name="id" type="xs:string"/>
+          </xs:complexType>
+        </xs:element>
+        <xs:element name="Line" maxOccurs="unbounded">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Sku" type="xs:string"/>
+              <xs:element name="Extension" type="xs:string"/>
+            </xs:sequence>
+          </xs:complexType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	for _, test := range []struct {
+		include, exclude []string
+		want             []string
+	}{
+		{nil, []string{"Order/*/Extension"}, []string{"Order", "Order/Id", "Order/Customer", "Order/Customer/@id", "Order/Customer/Name", "Order/Line", "Order/Line/Sku"}},
+		{[]string{"Order/Customer"}, []string{"**/@id"}, []string{"Order", "Order/Customer", "Order/Customer/Name", "Order/Customer/Extension"}},
+		// Only the ancestors of matching elements are kept, not every path a ** could lead to
+		{[]string{"**/Sku"}, nil, []string{"Order", "Order/Line", "Order/Line/Sku"}},
+	} {
+		filter, err := NewPathFilter(test.include, test.exclude)
+		if err != nil {
+			t.Fatalf("NewPathFilter: %v", err)
+		}
+		opts := testOptions
+		opts.Filter = filter
+		var paths []string
+		for _, entry := range FieldList(schema, opts) {
+			paths = append(paths, entry.XMLPath)
+		}
+		if strings.Join(paths, " ") != strings.Join(test.want, " ") {
+			t.Errorf("include %v, exclude %v: paths = %v, want %v", test.include, test.exclude, paths, test.want)
+		}
+	}
+
+	if _, err := NewPathFilter([]string{"Order/["}, nil); err == nil {
+		t.Error("NewPathFilter: expected an error for an invalid pattern")
+	}
+}
+
+func TestCollapseLimit(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Id" type="xs:string"/>
+        <xs:element name="Customer">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Name" type="xs:string"/>
+            </xs:sequence>
+            <xs:attribute name="id" type="xs:string"/>
+          </xs:complexType>
+        </xs:element>
+        <xs:element name="Line" maxOccurs="unbounded">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Sku" type="xs:string"/>
+            </xs:sequence>
+          </xs:complexType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	for _, test := range []struct {
+		limit string
+		want  []string
+	}{
+		{"2", []string{"Order:object", "Order/Id:string", "Order/Customer:string", "Order/Line:array of string"}},
+		{"**/Line", []string{"Order:object", "Order/Id:string", "Order/Customer:object", "Order/Customer/@id:string", "Order/Customer/Name:string", "Order/Line:array of string"}},
+		// Leaves have no content to collapse
+		{"Order/Id", []string{"Order:object", "Order/Id:string", "Order/Customer:object", "Order/Customer/@id:string", "Order/Customer/Name:string", "Order/Line:array of object", "Order/Line/Sku:string"}},
+	} {
+		limit, err := NewCollapseLimit(test.limit)
+		if err != nil {
+			t.Fatalf("NewCollapseLimit(%q): %v", test.limit, err)
+		}
+		var fields []string
+		for _, entry := range FieldList(limit.Apply(schema), testOptions) {
+			fields = append(fields, entry.XMLPath+":"+entry.Type)
+		}
+		if strings.Join(fields, " ") != strings.Join(test.want, " ") {
+			t.Errorf("collapse below %s: fields = %v, want %v", test.limit, fields, test.want)
+		}
+	}
+	if schema.Elements[0].Children[1].IsLeaf() {
+		t.Error("Apply changed the schema passed in")
+	}
+
+	// Collapsed elements keep their own required flag, and their required content is warned about
+	schema = parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Customer">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Name" type="xs:string"/>
+              <xs:element name="Email" type="xs:string" minOccurs="0"/>
+            </xs:sequence>
+            <xs:attribute name="id" type="xs:string" use="required"/>
+            <xs:attribute name="vip" type="xs:boolean"/>
+          </xs:complexType>
+        </xs:element>
+        <xs:element name="Note" minOccurs="0">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Text" type="xs:string"/>
+            </xs:sequence>
+          </xs:complexType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+	limit, err := NewCollapseLimit("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collapsed := limit.Apply(schema)
+	var required []string
+	for _, entry := range FieldList(collapsed, testOptions) {
+		required = append(required, entry.XMLPath+":"+strconv.FormatBool(entry.Required))
+	}
+	if want := "Order:true Order/Customer:true Order/Note:false"; strings.Join(required, " ") != want {
+		t.Errorf("required = %s, want %s", strings.Join(required, " "), want)
+	}
+	want := []string{
+		"Order/Customer was collapsed into raw XML, which must include its required content: @id, Name",
+		"Order/Note was collapsed into raw XML, which must include its required content: Text",
+	}
+	if !slices.Equal(collapsed.Warnings, want) {
+		t.Errorf("warnings = %q, want %q", collapsed.Warnings, want)
+	}
+
+	for _, limit := range []string{"0", "Order/["} {
+		if _, err := NewCollapseLimit(limit); err == nil {
+			t.Errorf("NewCollapseLimit(%q): expected an error", limit)
+		}
+	}
+}
+
+func TestStickyAndToggle(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Note" type="xs:string" minOccurs="0"/>
+        <xs:element name="Status">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:enumeration value="open"/>
+              <xs:enumeration value="closed"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Comment" type="xs:string"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	opts := testOptions
+	opts.Overrides = map[string]FieldOverride{
+		"Order/Note":    {Sticky: true},
+		"Order/Status":  {Toggle: true},
+		"Order/Comment": {Toggle: true},
+	}
+	fields, err := Generate(schema, opts)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	note, status, comment := fields[0].Properties[0], fields[0].Properties[1], fields[0].Properties[2]
+	if !note.Sticky || status.Sticky {
+		t.Errorf("sticky: Note %v, Status %v, want only Note", note.Sticky, status.Sticky)
+	}
+	want := &Field{Name: "Order_Status", Label: "Order Status", Type: "string", ControlType: "text", ToggleHint: "Use custom value"}
+	if status.ToggleHint != "Select from list" || !reflect.DeepEqual(status.ToggleField, want) {
+		t.Errorf("Status toggle = %q, %+v, want Select from list and %+v", status.ToggleHint, status.ToggleField, want)
+	}
+	// Plain text fields have no control to toggle
+	if comment.ToggleHint != "" || comment.ToggleField != nil {
+		t.Errorf("Comment toggle = %q, %+v, want none", comment.ToggleHint, comment.ToggleField)
+	}
+}
+
+func TestNameCollisions(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="AddressType">
+    <xs:sequence>
+      <xs:element name="Street" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Billing">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Address" type="AddressType"/>
+            </xs:sequence>
+          </xs:complexType>
+        </xs:element>
+        <xs:element name="Shipping">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Address" type="AddressType"/>
+            </xs:sequence>
+          </xs:complexType>
+        </xs:element>
+        <xs:element name="id" type="xs:string"/>
+      </xs:sequence>
+      <xs:attribute name="id" type="xs:string"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	for _, test := range []struct {
+		naming, prefix string
+		want           []string
+	}{
+		{NamingFlat, "@", []string{"Shipping_Address_Street"}},
+		// Without attribute prefix, the id element collides with the id attribute
+		{NamingFlat, "", []string{"Shipping_Address_Street", "Order_id_2"}},
+		{NamingNested, "", []string{"Order_id"}},
+		{NamingPath, "@", nil},
+	} {
+		opts := Options{Naming: test.naming, AttributePrefix: test.prefix}
+		var renamed []string
+		for _, rename := range Renames(schema, opts) {
+			renamed = append(renamed, rename.Renamed)
+		}
+		if strings.Join(renamed, " ") != strings.Join(test.want, " ") {
+			t.Errorf("naming %s, prefix %q: renamed = %v, want %v", test.naming, test.prefix, renamed, test.want)
+		}
+
+		// The schema, and so the templates, use the new names
+		names := make(map[string]bool)
+		var walk func(fields []Field)
+		walk = func(fields []Field) {
+			for _, field := range fields {
+				names[field.Name] = true
+				walk(field.Properties)
+			}
+		}
+		fields, _ := Generate(schema, opts)
+		walk(fields)
+		for _, name := range renamed {
+			if !names[name] {
+				t.Errorf("naming %s: field %s missing from the schema", test.naming, name)
+			}
+		}
+	}
+}
+
+func TestNumericFacetHints(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Payment">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Amount">
+          <xs:simpleType>
+            <xs:restriction base="xs:decimal">
+              <xs:totalDigits value="18"/>
+              <xs:fractionDigits value="2"/>
+              <xs:minInclusive value="0"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Installments">
+          <xs:simpleType>
+            <xs:restriction base="xs:integer">
+              <xs:minInclusive value="1"/>
+              <xs:maxInclusive value="12"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Rate">
+          <xs:simpleType>
+            <xs:restriction base="xs:decimal">
+              <xs:minExclusive value="0"/>
+              <xs:maxExclusive value="1"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	fields, err := Generate(schema, testOptions)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	want := []string{
+		"Up to 18 digits, of which 2 decimals. At least 0",
+		"Between 1 and 12",
+		"Greater than 0 and less than 1",
+	}
+	for i, field := range fields[0].Properties {
+		if field.Hint != want[i] {
+			t.Errorf("%s: hint = %q, want %q", field.Name, field.Hint, want[i])
+		}
+	}
+}
+
+func TestDateTypes(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Shift">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Day" type="xs:date"/>
+        <xs:element name="Start" type="xs:dateTime"/>
+        <xs:element name="Logged" type="xs:dateTimeStamp"/>
+        <xs:element name="Break" type="xs:time"/>
+        <xs:element name="Season" type="xs:gYear"/>
+        <xs:element name="Length" type="xs:duration"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	fields, err := Generate(schema, testOptions)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	want := []struct{ workatoType, hint string }{
+		{"date", ""},
+		{"date_time", ""},
+		{"timestamp", ""},
+		{"string", "Formatted as hh:mm:ss, such as 13:45:00"},
+		{"string", "Formatted as YYYY, such as 2024"},
+		{"string", "ISO 8601 duration formatted as PnYnMnDTnHnMnS, such as P1DT2H30M"},
+	}
+	for i, field := range fields[0].Properties {
+		if field.Type != want[i].workatoType || field.Hint != want[i].hint {
+			t.Errorf("%s: type %q, hint %q, want %q, %q", field.Name, field.Type, field.Hint, want[i].workatoType, want[i].hint)
+		}
+	}
+
+	// Dates are written in the lexical form of their type, unless a rule maps them to strings
+	day := schema.Elements[0].Children[0]
+	if format, ok := testOptions.DateFormat(day); !ok || format.Strftime != "%Y-%m-%d" {
+		t.Errorf("Day: format = %+v, %v, want %%Y-%%m-%%d", format, ok)
+	}
+	typeMap, err := NewTypeMap(map[string]TypeRule{"xs:date": {Type: "string"}})
+	if err != nil {
+		t.Fatalf("NewTypeMap: %v", err)
+	}
+	opts := testOptions
+	opts.TypeMap = typeMap
+	if format, ok := opts.DateFormat(day); ok {
+		t.Errorf("Day mapped to string: format = %+v, want none", format)
+	}
+
+	// The hints follow the type map too, describing dates kept as strings but not times mapped to dates
+	typeMap, err = NewTypeMap(map[string]TypeRule{"xs:date": {Type: "string"}, "xs:time": {Type: "date_time"}})
+	if err != nil {
+		t.Fatalf("NewTypeMap: %v", err)
+	}
+	opts.TypeMap = typeMap
+	fields, err = Generate(schema, opts)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if hint := fields[0].Properties[0].Hint; hint != "Formatted as YYYY-MM-DD, such as 2024-01-31" {
+		t.Errorf("Day mapped to string: hint %q, want its lexical form", hint)
+	}
+	if hint := fields[0].Properties[3].Hint; hint != "" {
+		t.Errorf("Break mapped to date_time: hint %q, want none", hint)
+	}
+}
+
+func TestInferControlType(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Contact">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="BirthDate" type="xs:date"/>
+        <xs:element name="Active" type="xs:boolean"/>
+        <xs:element name="WorkEmail" type="xs:string"/>
+        <xs:element name="PhoneNumber" type="xs:string"/>
+        <xs:element name="HomepageURL" type="xs:string"/>
+        <xs:element name="Name" type="xs:string"/>
+      </xs:sequence>
+      <xs:attribute name="updated" type="xs:dateTime"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	opts := testOptions
+	opts.InferControls = true
+	fields, err := Generate(schema, opts)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	want := []string{"date_time", "date", "checkbox", "email", "phone", "url", ""}
+	for i, field := range fields[0].Properties {
+		if field.ControlType != want[i] {
+			t.Errorf("%s control_type = %q, want %q", field.Name, field.ControlType, want[i])
+		}
+	}
+
+	// Without the option, no control_type is inferred
+	fields, _ = Generate(schema, testOptions)
+	if control := fields[0].Properties[2].ControlType; control != "" {
+		t.Errorf("Contact_Active control_type = %q without InferControls, want none", control)
+	}
+}
+
+func TestConvertCase(t *testing.T) {
+	cases := []struct {
+		name, conversion, want string
+	}{
+		{"PostalCode", CaseSnake, "postal_code"},
+		{"CstmrCdtTrfInitn_GrpHdr_MsgId", CaseSnake, "cstmr_cdt_trf_initn_grp_hdr_msg_id"},
+		{"XMLData_ID", CaseSnake, "xml_data_id"},
+		{"Address_Line2", CaseCamel, "addressLine2"},
+		{"order-line.sku", CasePascal, "OrderLineSku"},
+		{"shipTo", CasePascal, "ShipTo"},
+		{"Ship_To", CaseOriginal, "Ship_To"},
+	}
+	for _, c := range cases {
+		if got := ConvertCase(c.name, c.conversion); got != c.want {
+			t.Errorf("ConvertCase(%q, %s) = %q, want %q", c.name, c.conversion, got, c.want)
+		}
+	}
+
+	// Attribute fields keep their prefix
+	opts := testOptions
+	opts.Case = CaseSnake
+	if got := opts.FieldName("Order/@orderDate"); got != "@order_order_date" {
+		t.Errorf("attribute field = %q, want @order_order_date", got)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	opts := testOptions
+	opts.Abbreviations = map[string]string{"Cstmr": "Customer", "msg": "Message", "Id": "ID"}
+	cases := map[string]string{
+		"CstmrCdtTrfInitn_GrpHdr_MsgId": "Customer Cdt Trf Initn Grp Hdr Message ID",
+		"@Order_orderDate":              "Order Order Date",
+		"postal_code":                   "Postal Code",
+		"IBAN":                          "IBAN",
+	}
+	for name, want := range cases {
+		if got := opts.Label(name); got != want {
+			t.Errorf("Label(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestPrintTree(t *testing.T) {
+	fields := []Field{{Name: "Order", Type: "object", Properties: []Field{
+		{Name: "Order_Id", Type: "integer"},
+		{Name: "Order_Line", Type: "array", Of: "object", Properties: []Field{
+			{Name: "Line_Sku", Type: "string"},
+			{Name: "Line_Note", Type: "string", Optional: true},
+		}},
+		{Name: "Order_Total", Type: "number"},
+	}}}
+
+	var buf bytes.Buffer
+	if err := PrintTree(&buf, fields); err != nil {
+		t.Fatalf("PrintTree: %v", err)
+	}
+	want := `Order: object
+├── Order_Id: integer
+├── Order_Line[]: object
+│   ├── Line_Sku: string
+│   └── Line_Note: string (optional)
+└── Order_Total: number
+6 fields: 1 optional, 1 arrays
+`
+	if buf.String() != want {
+		t.Errorf("tree:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	oldFields := []Field{{Name: "Order", Type: "object", Properties: []Field{
+		{Name: "Order_Id", Type: "string"},
+		{Name: "Order_Fax", Type: "string", Optional: true},
+		{Name: "Order_Line", Type: "object", Properties: []Field{
+			{Name: "Line_Sku", Type: "string"},
+		}},
+		{Name: "Order_Note", Type: "string"},
+	}}}
+	newFields := []Field{{Name: "Order", Type: "object", Properties: []Field{
+		{Name: "Order_Id", Type: "integer"},
+		{Name: "Order_Line", Type: "array", Of: "object", Optional: true, Properties: []Field{
+			{Name: "Line_Sku", Type: "string"},
+			{Name: "Line_Quantity", Type: "integer"},
+		}},
+		{Name: "Order_Note", Type: "string", Optional: true},
+		{Name: "Order_Email", Type: "string", Optional: true},
+	}}}
+
+	var buf bytes.Buffer
+	if err := PrintDiff(&buf, Diff(oldFields, newFields)); err != nil {
+		t.Fatalf("PrintDiff: %v", err)
+	}
+	want := `~ Order/Order_Id: type string -> integer
+- Order/Order_Fax: string, optional
+~ Order/Order_Line: required -> optional array
++ Order/Order_Line/Line_Quantity: integer, required
+~ Order/Order_Note: required -> optional
++ Order/Order_Email: string, optional
+6 changes: 2 added, 1 removed, 1 retyped, 2 re-cardinalized
+`
+	if buf.String() != want {
+		t.Errorf("diff:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	if changes := Diff(newFields, newFields); len(changes) != 0 {
+		t.Errorf("Diff of identical fields = %v", changes)
+	}
+}
+
+func TestFieldList(t *testing.T) {
+	schema := parseString(t, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Id" type="xs:integer">
+          <xs:annotation><xs:documentation>Order | number</xs:documentation></xs:annotation>
+        </xs:element>
+        <xs:element name="Line" maxOccurs="unbounded">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Sku">
+                <xs:simpleType>
+                  <xs:restriction base="xs:string"><xs:maxLength value="12"/></xs:restriction>
+                </xs:simpleType>
+              </xs:element>
+            </xs:sequence>
+          </xs:complexType>
+        </xs:element>
+      </xs:sequence>
+      <xs:attribute name="channel" type="xs:string"/>
+    </xs:complexType>
+    <xs:unique name="UniqueSku">
+      <xs:selector xpath="Line"/>
+      <xs:field xpath="Sku"/>
+    </xs:unique>
+  </xs:element>
+</xs:schema>`)
+
+	var buf bytes.Buffer
+	if err := WriteFieldListCSV(&buf, FieldList(schema, testOptions)); err != nil {
+		t.Fatalf("WriteFieldListCSV: %v", err)
+	}
+	want := `Path,XML,XSD type,Workato type,Required,Cardinality,Documentation,Constraints
+Order,Order,,object,required,1,,
+Order/@Order_channel,Order/@channel,xs:string,string,optional,0..1,,
+Order/Order_Id,Order/Id,xs:integer,integer,required,1,Order | number,
+Order/Order_Line,Order/Line,,array of object,required,1..*,,
+Order/Order_Line/Line_Sku,Order/Line/Sku,xs:string,string,required,1,,Must be unique among the Line of Order
+`
+	if buf.String() != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	// Identity constraints are noted in the hints as well
+	if hint, want := Hint(FieldList(schema, testOptions)[4].element), "Max 12 characters. Must be unique among the Line of Order"; hint != want {
+		t.Errorf("hint of Sku: %q, want %q", hint, want)
+	}
+
+	buf.Reset()
+	if err := WriteFieldListMarkdown(&buf, FieldList(schema, testOptions)[2:3]); err != nil {
+		t.Fatalf("WriteFieldListMarkdown: %v", err)
+	}
+	want = `| Path | XML | XSD type | Workato type | Required | Cardinality | Documentation | Constraints |
+| --- | --- | --- | --- | --- | --- | --- | --- |
+| Order/Order_Id | Order/Id | xs:integer | integer | required | 1 | Order \| number |  |
+`
+	if buf.String() != want {
+		t.Errorf("Markdown:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestSortFields(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Number" type="xs:string"/>
+        <xs:element name="Customer">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="name" type="xs:string"/>
+              <xs:element name="Email" type="xs:string"/>
+            </xs:sequence>
+          </xs:complexType>
+        </xs:element>
+        <xs:element name="Amount" type="xs:decimal"/>
+      </xs:sequence>
+      <xs:attribute name="version" type="xs:string"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	// Without sorting, the fields follow the attributes and then the sequence order
+	names := func(fields []Field) string {
+		var list []string
+		for _, field := range fields {
+			list = append(list, field.Name)
+		}
+		return strings.Join(list, " ")
+	}
+	fields, err := Generate(schema, Options{AttributePrefix: "@", Naming: NamingNested})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if got, want := names(fields[0].Properties), "@version Number Customer Amount"; got != want {
+		t.Errorf("sequence order = %s, want %s", got, want)
+	}
+
+	sortOptions := Options{AttributePrefix: "@", Naming: NamingNested, SortFields: true}
+	fields, err = Generate(schema, sortOptions)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if got, want := names(fields[0].Properties), "@version Amount Customer Number"; got != want {
+		t.Errorf("sorted order = %s, want %s", got, want)
+	}
+	if got, want := names(fields[0].Properties[2].Properties), "Email name"; got != want {
+		t.Errorf("sorted Customer order = %s, want %s", got, want)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	leaf := func(name string) Field { return Field{Name: name, Type: "string"} }
+	fields := []Field{{Name: "Order", Type: "object", Properties: []Field{
+		leaf("Id"),
+		{Name: "Customer", Type: "object", Properties: []Field{leaf("Name"), leaf("Email"), leaf("Phone")}},
+		leaf("Note"),
+	}}}
+
+	// Order and Customer are split, each part holding the objects leading to its fields
+	parts := Split(fields, 4)
+	var paths [][]string
+	for _, part := range parts {
+		if count := CountFields(part.Fields); count > 4 {
+			t.Errorf("part %v has %d fields, want at most 4", part.Paths, count)
+		}
+		paths = append(paths, part.Paths)
+	}
+	want := [][]string{
+		{"Order/Id"},
+		{"Order/Customer/Name", "Order/Customer/Email"},
+		{"Order/Customer/Phone", "Order/Note"},
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+	if got := parts[2].Fields[0].Properties; len(got) != 2 || got[0].Name != "Customer" || len(got[0].Properties) != 1 {
+		t.Errorf("last part = %+v, want Order with Customer holding Phone, and Note", parts[2].Fields)
+	}
+
+	// Objects too deep for the limit are passed as a whole
+	if parts := Split(fields, 1); len(parts) != 1 || len(parts[0].Fields[0].Properties) != 0 || parts[0].Fields[0].Hint == "" {
+		t.Errorf("Split at 1 = %+v, want Order collapsed", parts)
+	}
+	if parts := Split(fields, 10); len(parts) != 1 || !reflect.DeepEqual(parts[0].Fields, fields) {
+		t.Errorf("Split under the limit = %+v, want the fields in a single part", parts)
+	}
+}
+
+func TestValidationRules(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Code">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:pattern value="[A-Z]{3}"/>
+              <xs:pattern value="X[0-9]+"/>
+              <xs:minLength value="2"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Note" type="xs:string" minOccurs="0"/>
+        <xs:element name="Tags" minOccurs="0">
+          <xs:simpleType>
+            <xs:list itemType="xs:string"/>
+          </xs:simpleType>
+        </xs:element>
+      </xs:sequence>
+      <xs:attribute name="id" type="xs:string" use="required"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	got := ValidationRules(schema, testOptions)
+	want := []ValidationRule{
+		{Path: "Order/@Order_id", XMLPath: "Order/@id", Required: true},
+		{Path: "Order/Order_Code", XMLPath: "Order/Code", Required: true, MinLength: 2, Pattern: `\A(?:[A-Z]{3}|X[0-9]+)\z`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rules = %+v, want %+v", got, want)
+	}
+
+	fields, err := Generate(schema, testOptions)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if hint, want := fields[0].Properties[1].Hint, "Must match one of the patterns [A-Z]{3}, X[0-9]+. Min 2 characters"; hint != want {
+		t.Errorf("Order_Code hint = %q, want %q", hint, want)
+	}
+}
@@ -0,0 +1,156 @@
+package xsd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Catalog remaps the schemaLocations of includes and imports, typically to local copies of
+// published schemas, following the uri, system, rewrite and suffix entries of OASIS XML
+// Catalogs. Imports without a schemaLocation are looked up by their namespace.
+type Catalog struct {
+	exact    map[string]string // uri and system entries, keyed by the URI they match
+	rewrites []catalogRewrite  // rewriteURI and rewriteSystem entries
+	suffixes []catalogRewrite  // uriSuffix and systemSuffix entries
+}
+
+// Catalog entry replacing the start, or matching the end, of a URI
+type catalogRewrite struct {
+	match  string
+	target string
+}
+
+// Function to load an XML catalog file along with the catalogs it chains with nextCatalog
+func LoadCatalog(location string) (*Catalog, error) {
+	catalog := &Catalog{exact: make(map[string]string)}
+	if err := catalog.load(location, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// Function to add the entries of a catalog file. Entries of chained catalogs come after
+// those of the catalog chaining them, so they only apply to the URIs it leaves unmatched.
+func (catalog *Catalog) load(location string, visited map[string]bool) error {
+	if visited[location] {
+		return nil
+	}
+	visited[location] = true
+
+	data, err := Fetcher{}.Fetch(location)
+	if err != nil {
+		return fmt.Errorf("failed to read catalog: %w", err)
+	}
+
+	// Entries may be nested in group elements, so walk every element of the document
+	var next []string
+	decoder := NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse catalog %s: %w", location, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		attr := func(name string) string {
+			for _, a := range start.Attr {
+				if a.Name.Local == name {
+					return a.Value
+				}
+			}
+			return ""
+		}
+		switch start.Name.Local {
+		case "uri":
+			catalog.addExact(attr("name"), resolveCatalogTarget(location, attr("uri")))
+		case "system":
+			catalog.addExact(attr("systemId"), resolveCatalogTarget(location, attr("uri")))
+		case "rewriteURI":
+			catalog.rewrites = append(catalog.rewrites, catalogRewrite{attr("uriStartString"), resolveCatalogTarget(location, attr("rewritePrefix"))})
+		case "rewriteSystem":
+			catalog.rewrites = append(catalog.rewrites, catalogRewrite{attr("systemIdStartString"), resolveCatalogTarget(location, attr("rewritePrefix"))})
+		case "uriSuffix":
+			catalog.suffixes = append(catalog.suffixes, catalogRewrite{attr("uriSuffix"), resolveCatalogTarget(location, attr("uri"))})
+		case "systemSuffix":
+			catalog.suffixes = append(catalog.suffixes, catalogRewrite{attr("systemIdSuffix"), resolveCatalogTarget(location, attr("uri"))})
+		case "nextCatalog":
+			next = append(next, resolveCatalogTarget(location, attr("catalog")))
+		}
+	}
+
+	for _, nextLocation := range next {
+		if err := catalog.load(nextLocation, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Helper function to add an exact entry, unless an earlier entry matches the same URI
+func (catalog *Catalog) addExact(match, target string) {
+	if _, ok := catalog.exact[match]; !ok && match != "" {
+		catalog.exact[match] = target
+	}
+}
+
+// Helper function to resolve the target of a catalog entry relative to the catalog file.
+// file: URIs become local paths.
+func resolveCatalogTarget(catalogLocation, target string) string {
+	if strings.HasPrefix(target, "file:") {
+		if targetURL, err := url.Parse(target); err == nil {
+			return filepath.FromSlash(targetURL.Path)
+		}
+	}
+	location, err := resolveSchemaLocation(catalogLocation, target)
+	if err != nil {
+		return target
+	}
+	// Rewrite prefixes are directories, whose trailing slash filepath.Join drops
+	if strings.HasSuffix(target, "/") && !strings.HasSuffix(location, "/") {
+		location += "/"
+	}
+	return location
+}
+
+// Function to look up a URI in the catalog. Exact entries win over the longest matching
+// rewrite prefix, which wins over the longest matching suffix. Returns false when no entry
+// matches.
+func (catalog *Catalog) Resolve(uri string) (string, bool) {
+	if catalog == nil || uri == "" {
+		return "", false
+	}
+	if target, ok := catalog.exact[uri]; ok {
+		return target, true
+	}
+
+	var best catalogRewrite
+	for _, rewrite := range catalog.rewrites {
+		if strings.HasPrefix(uri, rewrite.match) && len(rewrite.match) > len(best.match) {
+			best = rewrite
+		}
+	}
+	if best.match != "" {
+		return best.target + uri[len(best.match):], true
+	}
+
+	for _, suffix := range catalog.suffixes {
+		if strings.HasSuffix(uri, suffix.match) && len(suffix.match) > len(best.match) {
+			best = suffix
+		}
+	}
+	if best.match != "" {
+		return best.target, true
+	}
+	return "", false
+}
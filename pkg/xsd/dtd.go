@@ -0,0 +1,669 @@
+package xsd
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Maximum nesting of parameter entity references, beyond which an entity is taken to
+// reference itself
+const maxEntityDepth = 32
+
+// Types of DTD attributes, besides enumerations, mapped to their XSD built-in types
+var dtdAttributeTypes = map[string]string{
+	"CDATA":    "xs:string",
+	"ID":       "xs:ID",
+	"IDREF":    "xs:IDREF",
+	"IDREFS":   "xs:IDREFS",
+	"ENTITY":   "xs:ENTITY",
+	"ENTITIES": "xs:ENTITIES",
+	"NMTOKEN":  "xs:NMTOKEN",
+	"NMTOKENS": "xs:NMTOKENS",
+}
+
+// Reference to a parameter entity, such as %content;
+var entityReference = regexp.MustCompile(`%([A-Za-z_:][-A-Za-z0-9._:]*);`)
+
+// State of the parsing of a DTD and of the external parameter entities it references
+type dtdParser struct {
+	parser     Parser
+	entities   map[string]dtdEntity   // Parameter entities, by name
+	elements   []dtdElement           // Element declarations in document order
+	attributes map[string][]Attribute // Attributes declared for each element, by element name
+	depth      int                    // Nesting of the parameter entity references being expanded
+}
+
+// Parameter entity, either internal with its replacement text, or external and read from
+// its system identifier resolved against the document declaring it
+type dtdEntity struct {
+	value    string
+	systemID string
+	base     string
+}
+
+// Element declaration with the tokens of its content specification
+type dtdElement struct {
+	name    string
+	content []string
+}
+
+// Content particle of an element declaration: an element name or a group of particles
+type dtdParticle struct {
+	name      string // Element name, or "" for a group
+	choice    bool   // Whether the particles of the group are separated by |, or else by ,
+	particles []dtdParticle
+	occurs    string // ?, * or +, or "" for exactly once
+}
+
+// Function to parse a DTD read from r with the default settings
+func ParseDTD(r io.Reader) (Schema, error) {
+	return Parser{}.ParseDTD(r)
+}
+
+// Function to parse the DTD file, or HTTP(S) URL, at location with the default settings
+func ParseDTDFile(location string) (Schema, error) {
+	return Parser{}.ParseDTDFile(location)
+}
+
+// Function to parse a DTD read from r into a schema, as if its declarations had been written
+// in XSD. Relative system identifiers of external parameter entities are resolved against
+// the working directory.
+func (parser Parser) ParseDTD(r io.Reader) (Schema, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Schema{}, fmt.Errorf("failed to read DTD: %w", err)
+	}
+	return parser.parseDTD(data, "")
+}
+
+// Function to parse the DTD file, or HTTP(S) URL, at location into a schema
+func (parser Parser) ParseDTDFile(location string) (Schema, error) {
+	data, err := parser.Fetcher.Fetch(location)
+	if err != nil {
+		return Schema{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	return parser.parseDTD(data, location)
+}
+
+// Function to parse the content of a DTD loaded from location. Every element declaration
+// becomes a complexType named after the element, and the elements no other element contains
+// become the global elements, unless the root is set. Elements holding text only are
+// declared as xs:string, those with attributes as a simpleContent extension of xs:string,
+// and those with #PCDATA among other elements as mixed content.
+func (parser Parser) parseDTD(data []byte, location string) (Schema, error) {
+	data, err := decodeText(data)
+	if err != nil {
+		return Schema{}, fmt.Errorf("failed to decode DTD: %w", err)
+	}
+	dtd := &dtdParser{parser: parser, entities: make(map[string]dtdEntity), attributes: make(map[string][]Attribute)}
+	if err := dtd.parse(string(data), location); err != nil {
+		return Schema{}, err
+	}
+	schema, err := dtd.schema()
+	if err != nil {
+		return Schema{}, err
+	}
+
+	return parser.Resolve(*schema, location)
+}
+
+// Function to parse the markup declarations of a DTD, or of an external parameter entity,
+// loaded from base. Comments, processing instructions and notations are skipped.
+func (dtd *dtdParser) parse(text, base string) error {
+	for {
+		text = strings.TrimLeft(text, " \t\r\n")
+		switch {
+		case text == "":
+			return nil
+		case strings.HasPrefix(text, "%"):
+			// Parameter entities between declarations are replaced by their declarations
+			match := entityReference.FindStringSubmatchIndex(text)
+			if match == nil || match[0] != 0 {
+				return fmt.Errorf("invalid parameter entity reference %q", firstLine(text))
+			}
+			replacement, err := dtd.replacement(text[match[2]:match[3]])
+			if err != nil {
+				return err
+			}
+			dtd.depth++
+			err = dtd.parse(replacement, dtd.location(dtd.entities[text[match[2]:match[3]]], base))
+			dtd.depth--
+			if err != nil {
+				return err
+			}
+			text = text[match[1]:]
+		case strings.HasPrefix(text, "<!--"):
+			end := strings.Index(text, "-->")
+			if end < 0 {
+				return fmt.Errorf("unterminated comment %q", firstLine(text))
+			}
+			text = text[end+len("-->"):]
+		case strings.HasPrefix(text, "<?"):
+			end := strings.Index(text, "?>")
+			if end < 0 {
+				return fmt.Errorf("unterminated processing instruction %q", firstLine(text))
+			}
+			text = text[end+len("?>"):]
+		case strings.HasPrefix(text, "<!["):
+			rest, err := dtd.parseConditional(text, base)
+			if err != nil {
+				return err
+			}
+			text = rest
+		case strings.HasPrefix(text, "<!"):
+			end := declarationEnd(text)
+			if end < 0 {
+				return fmt.Errorf("unterminated declaration %q", firstLine(text))
+			}
+			if err := dtd.declare(text[len("<!"):end], base); err != nil {
+				return err
+			}
+			text = text[end+len(">"):]
+		default:
+			return fmt.Errorf("unexpected content %q", firstLine(text))
+		}
+	}
+}
+
+// Function to parse a conditional section, whose content is parsed when its keyword is
+// INCLUDE and skipped when it is IGNORE, and return the text following it
+func (dtd *dtdParser) parseConditional(text, base string) (string, error) {
+	open := strings.Index(text[len("<!["):], "[")
+	if open < 0 {
+		return "", fmt.Errorf("invalid conditional section %q", firstLine(text))
+	}
+	open += len("<![")
+	keyword, err := dtd.expand(text[len("<!["):open])
+	if err != nil {
+		return "", err
+	}
+
+	// Conditional sections nest, in ignored sections too
+	depth := 1
+	end := open + 1
+	for depth > 0 {
+		next := strings.Index(text[end:], "]]>")
+		if next < 0 {
+			return "", fmt.Errorf("unterminated conditional section %q", firstLine(text))
+		}
+		depth += strings.Count(text[end:end+next], "<![") - 1
+		end += next + len("]]>")
+	}
+	body := text[open+1 : end-len("]]>")]
+
+	switch strings.TrimSpace(keyword) {
+	case "INCLUDE":
+		if err := dtd.parse(body, base); err != nil {
+			return "", err
+		}
+	case "IGNORE":
+	default:
+		return "", fmt.Errorf("invalid conditional section keyword %q", strings.TrimSpace(keyword))
+	}
+	return text[end:], nil
+}
+
+// Function to record a markup declaration, given without its delimiters
+func (dtd *dtdParser) declare(declaration, base string) error {
+	keyword, rest := declaration, ""
+	if i := strings.IndexAny(declaration, " \t\r\n"); i >= 0 {
+		keyword, rest = declaration[:i], strings.TrimSpace(declaration[i:])
+	}
+
+	switch keyword {
+	case "ENTITY":
+		return dtd.declareEntity(rest, base)
+	case "NOTATION":
+		return nil
+	case "ELEMENT", "ATTLIST":
+	default:
+		return fmt.Errorf("invalid declaration %q", firstLine("<!"+declaration))
+	}
+
+	expanded, err := dtd.expand(rest)
+	if err != nil {
+		return err
+	}
+	tokens, err := dtdTokens(expanded)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("invalid declaration %q", firstLine("<!"+declaration))
+	}
+	if keyword == "ATTLIST" {
+		return dtd.declareAttributes(tokens[0], tokens[1:])
+	}
+	if len(tokens) < 2 {
+		return fmt.Errorf("element %s has no content specification", tokens[0])
+	}
+	// Only the first declaration of an element counts
+	if !slices.ContainsFunc(dtd.elements, func(element dtdElement) bool { return element.name == tokens[0] }) {
+		dtd.elements = append(dtd.elements, dtdElement{name: tokens[0], content: tokens[1:]})
+	}
+	return nil
+}
+
+// Function to record a parameter entity declaration. General entities only stand for text
+// of documents, so they are skipped. The first declaration of an entity counts.
+func (dtd *dtdParser) declareEntity(declaration, base string) error {
+	if !strings.HasPrefix(declaration, "%") {
+		return nil
+	}
+	tokens, err := dtdTokens(strings.TrimPrefix(declaration, "%"))
+	if err != nil {
+		return err
+	}
+	if len(tokens) < 2 {
+		return fmt.Errorf("invalid parameter entity declaration %q", firstLine(declaration))
+	}
+	name := tokens[0]
+	if _, declared := dtd.entities[name]; declared {
+		return nil
+	}
+
+	var entity dtdEntity
+	switch {
+	case isLiteral(tokens[1]):
+		// References in the replacement text are expanded when the entity is declared
+		if entity.value, err = dtd.expand(unquote(tokens[1])); err != nil {
+			return err
+		}
+	case tokens[1] == "SYSTEM" && len(tokens) > 2 && isLiteral(tokens[2]):
+		entity = dtdEntity{systemID: unquote(tokens[2]), base: base}
+	case tokens[1] == "PUBLIC" && len(tokens) > 3 && isLiteral(tokens[3]):
+		entity = dtdEntity{systemID: unquote(tokens[3]), base: base}
+	default:
+		return fmt.Errorf("invalid parameter entity declaration %q", firstLine(declaration))
+	}
+	dtd.entities[name] = entity
+	return nil
+}
+
+// Function to record the attributes of an attribute-list declaration for element. Namespace
+// declarations are left out, and the first declaration of an attribute counts.
+func (dtd *dtdParser) declareAttributes(element string, tokens []string) error {
+	for len(tokens) > 0 {
+		if len(tokens) < 3 {
+			return fmt.Errorf("invalid attribute declaration of element %s", element)
+		}
+		attribute := Attribute{Name: tokens[0]}
+		tokens = tokens[1:]
+
+		switch {
+		case tokens[0] == "(" || tokens[0] == "NOTATION":
+			base := "xs:NMTOKEN"
+			if tokens[0] == "NOTATION" {
+				base, tokens = "xs:NOTATION", tokens[1:]
+			}
+			values, rest, err := enumerationValues(tokens)
+			if err != nil {
+				return fmt.Errorf("attribute %s of element %s: %w", attribute.Name, element, err)
+			}
+			attribute.SimpleType = &SimpleType{Restriction: Restriction{Base: base, Enumerations: values}}
+			tokens = rest
+		case dtdAttributeTypes[tokens[0]] != "":
+			attribute.Type = dtdAttributeTypes[tokens[0]]
+			tokens = tokens[1:]
+		default:
+			return fmt.Errorf("attribute %s of element %s has invalid type %q", attribute.Name, element, tokens[0])
+		}
+
+		if len(tokens) == 0 {
+			return fmt.Errorf("attribute %s of element %s has no default declaration", attribute.Name, element)
+		}
+		switch {
+		case tokens[0] == "#REQUIRED":
+			attribute.Use = "required"
+		case tokens[0] == "#IMPLIED":
+		case tokens[0] == "#FIXED" && len(tokens) > 1 && isLiteral(tokens[1]):
+			attribute.Fixed, tokens = unquote(tokens[1]), tokens[1:]
+		case isLiteral(tokens[0]):
+			attribute.Default = unquote(tokens[0])
+		default:
+			return fmt.Errorf("attribute %s of element %s has invalid default %q", attribute.Name, element, tokens[0])
+		}
+		tokens = tokens[1:]
+
+		declared := slices.ContainsFunc(dtd.attributes[element], func(existing Attribute) bool { return existing.Name == attribute.Name })
+		if attribute.Name != "xmlns" && !strings.HasPrefix(attribute.Name, "xmlns:") && !declared {
+			dtd.attributes[element] = append(dtd.attributes[element], attribute)
+		}
+	}
+	return nil
+}
+
+// Helper function to read the values of an enumerated attribute type, such as (a|b|c), and
+// return the tokens following it
+func enumerationValues(tokens []string) ([]Facet, []string, error) {
+	if len(tokens) == 0 || tokens[0] != "(" {
+		return nil, nil, fmt.Errorf("invalid enumeration")
+	}
+	var values []Facet
+	for i := 1; i < len(tokens); i += 2 {
+		if tokens[i] == ")" || !isName(tokens[i]) {
+			break
+		}
+		values = append(values, Facet{Value: tokens[i]})
+		switch {
+		case i+1 < len(tokens) && tokens[i+1] == ")":
+			return values, tokens[i+2:], nil
+		case i+1 >= len(tokens) || tokens[i+1] != "|":
+			return nil, nil, fmt.Errorf("invalid enumeration")
+		}
+	}
+	return nil, nil, fmt.Errorf("invalid enumeration")
+}
+
+// Function to expand the parameter entity references of a declaration
+func (dtd *dtdParser) expand(text string) (string, error) {
+	var err error
+	expanded := entityReference.ReplaceAllStringFunc(text, func(reference string) string {
+		if err != nil {
+			return ""
+		}
+		var replacement string
+		if replacement, err = dtd.replacement(reference[1 : len(reference)-1]); err != nil {
+			return ""
+		}
+		dtd.depth++
+		defer func() { dtd.depth-- }()
+		// Parameter entities are padded with spaces when expanded in declarations
+		replacement, err = dtd.expand(replacement)
+		return " " + replacement + " "
+	})
+	return expanded, err
+}
+
+// Function to get the replacement text of a parameter entity, reading external ones from
+// their system identifier, remapped by the catalog if it lists it
+func (dtd *dtdParser) replacement(name string) (string, error) {
+	entity, ok := dtd.entities[name]
+	switch {
+	case !ok:
+		return "", fmt.Errorf("parameter entity %%%s; is not declared", name)
+	case dtd.depth >= maxEntityDepth:
+		return "", fmt.Errorf("parameter entity %%%s; references itself", name)
+	case entity.systemID == "":
+		return entity.value, nil
+	}
+
+	location := dtd.location(entity, "")
+	data, err := dtd.parser.Fetcher.Fetch(location)
+	if err != nil {
+		return "", &ImportError{SchemaLocation: entity.systemID, Err: err}
+	}
+	if data, err = decodeText(data); err != nil {
+		return "", &ImportError{SchemaLocation: entity.systemID, Err: err}
+	}
+	text := string(data)
+	// The text declaration of external entities is not part of their replacement text
+	if strings.HasPrefix(text, "<?xml") {
+		if end := strings.Index(text, "?>"); end >= 0 {
+			text = text[end+len("?>"):]
+		}
+	}
+	return text, nil
+}
+
+// Helper function to get the location of an external parameter entity, or base for internal
+// ones, as the documents their references are parsed in. The catalog remaps system
+// identifiers like schemaLocations.
+func (dtd *dtdParser) location(entity dtdEntity, base string) string {
+	if entity.systemID == "" {
+		return base
+	}
+	if location, found := dtd.parser.Catalog.Resolve(entity.systemID); found {
+		return location
+	}
+	location, err := resolveSchemaLocation(entity.base, entity.systemID)
+	if err != nil {
+		return entity.systemID
+	}
+	return location
+}
+
+// Function to build the schema declaring the elements of the DTD
+func (dtd *dtdParser) schema() (*Schema, error) {
+	declared := make(map[string]dtdElement, len(dtd.elements))
+	for _, element := range dtd.elements {
+		declared[element.name] = element
+	}
+
+	schema := &Schema{Namespaces: map[string]string{"xs": Namespace}}
+	referenced := make(map[string]bool)
+	for _, element := range dtd.elements {
+		complexType, err := dtd.complexType(element, declared, referenced)
+		if err != nil {
+			return nil, err
+		}
+		if complexType != nil {
+			schema.ComplexTypes = append(schema.ComplexTypes, *complexType)
+		}
+	}
+	for _, element := range dtd.elements {
+		// Any element may be chosen as the root, and the others are left unresolved
+		if dtd.parser.Root == "" && referenced[element.name] {
+			continue
+		}
+		schema.Elements = append(schema.Elements, Element{Name: element.name, Type: dtd.typeName(element.name, declared)})
+	}
+	// Elements all contained in others, such as in recursive DTDs, are all candidate roots
+	if len(schema.Elements) == 0 {
+		for _, element := range dtd.elements {
+			schema.Elements = append(schema.Elements, Element{Name: element.name, Type: dtd.typeName(element.name, declared)})
+		}
+	}
+	return schema, nil
+}
+
+// Function to build the complexType of an element declaration, recording the elements its
+// content references. Elements holding text only without attributes have no complexType,
+// and ANY content is mixed content accepting any element through a wildcard.
+func (dtd *dtdParser) complexType(element dtdElement, declared map[string]dtdElement, referenced map[string]bool) (*ComplexType, error) {
+	complexType := &ComplexType{Name: dtdTypeName(element.name), Attributes: dtd.attributes[element.name]}
+	switch element.content[0] {
+	case "EMPTY":
+		return complexType, nil
+	case "ANY":
+		// Any declared element, mixed with text
+		wildcard := Particle{Any: &Any{ProcessContents: "lax", MinOccurs: "0"}}
+		complexType.Mixed, complexType.Sequence = true, &Compositor{Kind: "sequence", Particles: []Particle{wildcard}}
+		return complexType, nil
+	}
+
+	particle, rest, err := parseDTDParticle(element.content)
+	if err != nil || len(rest) > 0 || particle.name != "" {
+		return nil, fmt.Errorf("element %s has an invalid content model", element.name)
+	}
+	if slices.ContainsFunc(particle.particles, func(particle dtdParticle) bool { return particle.name == "#PCDATA" }) {
+		if particle.particles[0].name != "#PCDATA" || (len(particle.particles) > 1 && (!particle.choice || particle.occurs != "*")) {
+			return nil, fmt.Errorf("element %s has an invalid mixed content model", element.name)
+		}
+		if len(particle.particles) == 1 {
+			if len(complexType.Attributes) == 0 {
+				return nil, nil
+			}
+			// Text with attributes is declared as a simpleContent extension in XSD
+			complexType.SimpleContent = &SimpleContent{Extension: &Derivation{Base: "xs:string", Attributes: complexType.Attributes}}
+			complexType.Attributes = nil
+			return complexType, nil
+		}
+		complexType.Mixed = true
+		particle.particles = particle.particles[1:]
+	}
+	compositor := dtd.compositor(particle, declared, referenced)
+	if compositor.Kind == "choice" {
+		complexType.Choice = compositor
+	} else {
+		complexType.Sequence = compositor
+	}
+	return complexType, nil
+}
+
+// Function to build the compositor of a group of content particles
+func (dtd *dtdParser) compositor(group dtdParticle, declared map[string]dtdElement, referenced map[string]bool) *Compositor {
+	compositor := &Compositor{Kind: "sequence"}
+	if group.choice {
+		compositor.Kind = "choice"
+	}
+	compositor.MinOccurs, compositor.MaxOccurs = group.occurrences()
+	for _, particle := range group.particles {
+		if particle.name == "" {
+			compositor.Particles = append(compositor.Particles, Particle{Group: dtd.compositor(particle, declared, referenced)})
+			continue
+		}
+		referenced[particle.name] = true
+		element := &Element{Name: particle.name, Type: dtd.typeName(particle.name, declared)}
+		element.MinOccurs, element.MaxOccurs = particle.occurrences()
+		compositor.Particles = append(compositor.Particles, Particle{Element: element})
+	}
+	return compositor
+}
+
+// Helper function to get the type of an element: xs:string for text without attributes, or
+// else its complexType. Undeclared elements reference a complexType that is reported as
+// unresolved.
+func (dtd *dtdParser) typeName(name string, declared map[string]dtdElement) string {
+	element, ok := declared[name]
+	text := slices.Equal(element.content, []string{"(", "#PCDATA", ")"}) || slices.Equal(element.content, []string{"(", "#PCDATA", ")", "*"})
+	if ok && text && len(dtd.attributes[name]) == 0 {
+		return "xs:string"
+	}
+	return dtdTypeName(name)
+}
+
+// Helper function to name the complexType of an element. Colons of prefixed names would be
+// taken for a namespace prefix, so they are replaced with dots.
+func dtdTypeName(name string) string {
+	return strings.ReplaceAll(name, ":", ".")
+}
+
+// Helper function to get the minOccurs and maxOccurs of a content particle
+func (particle dtdParticle) occurrences() (string, string) {
+	switch particle.occurs {
+	case "?":
+		return "0", ""
+	case "*":
+		return "0", "unbounded"
+	case "+":
+		return "", "unbounded"
+	}
+	return "", ""
+}
+
+// Function to parse a content particle from the tokens of a content model, returning the
+// tokens following it
+func parseDTDParticle(tokens []string) (dtdParticle, []string, error) {
+	if len(tokens) == 0 {
+		return dtdParticle{}, nil, fmt.Errorf("unexpected end of content model")
+	}
+
+	var particle dtdParticle
+	switch {
+	case tokens[0] == "(":
+		tokens = tokens[1:]
+		separator := ""
+		for {
+			item, rest, err := parseDTDParticle(tokens)
+			if err != nil {
+				return dtdParticle{}, nil, err
+			}
+			particle.particles = append(particle.particles, item)
+			if len(rest) == 0 {
+				return dtdParticle{}, nil, fmt.Errorf("unterminated group in content model")
+			}
+			if rest[0] == ")" {
+				tokens = rest[1:]
+				break
+			}
+			if (rest[0] != "|" && rest[0] != ",") || (separator != "" && rest[0] != separator) {
+				return dtdParticle{}, nil, fmt.Errorf("unexpected %q in content model", rest[0])
+			}
+			separator, tokens = rest[0], rest[1:]
+		}
+		particle.choice = separator == "|"
+	case isName(tokens[0]) || tokens[0] == "#PCDATA":
+		particle.name, tokens = tokens[0], tokens[1:]
+	default:
+		return dtdParticle{}, nil, fmt.Errorf("unexpected %q in content model", tokens[0])
+	}
+
+	if len(tokens) > 0 && strings.Contains("?*+", tokens[0]) {
+		particle.occurs, tokens = tokens[0], tokens[1:]
+	}
+	return particle, tokens, nil
+}
+
+// Function to split the body of a declaration into names, quoted literals and the
+// punctuation of content models and enumerations
+func dtdTokens(text string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(text); {
+		switch c := text[i]; {
+		case strings.ContainsRune(" \t\r\n", rune(c)):
+			i++
+		case strings.ContainsRune("()|,?*+", rune(c)):
+			tokens = append(tokens, text[i:i+1])
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(text[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated literal %q", firstLine(text[i:]))
+			}
+			tokens = append(tokens, text[i:i+end+2])
+			i += end + 2
+		default:
+			end := i
+			for end < len(text) && !strings.ContainsRune(" \t\r\n()|,?*+\"'", rune(text[end])) {
+				end++
+			}
+			tokens = append(tokens, text[i:end])
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// Helper function to find the > closing the declaration text starts with, skipping quoted
+// literals, or -1 if it is not closed
+func declarationEnd(text string) int {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch {
+		case quote != 0:
+			if text[i] == quote {
+				quote = 0
+			}
+		case text[i] == '"' || text[i] == '\'':
+			quote = text[i]
+		case text[i] == '>':
+			return i
+		}
+	}
+	return -1
+}
+
+// Helper function to check whether a token is a quoted literal
+func isLiteral(token string) bool {
+	return len(token) >= 2 && (token[0] == '"' || token[0] == '\'') && token[len(token)-1] == token[0]
+}
+
+// Helper function to remove the quotes of a literal
+func unquote(literal string) string {
+	return literal[1 : len(literal)-1]
+}
+
+// Helper function to check whether a token is a name or name token rather than punctuation
+func isName(token string) bool {
+	return token != "" && !strings.ContainsAny(token, "()|,?*+\"'#%")
+}
+
+// Helper function to get the first line of a text for error messages
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
@@ -0,0 +1,176 @@
+package xsd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Characters of windows-1252 in the range 0x80-0x9F, where it differs from ISO-8859-1. The
+// bytes it leaves undefined are mapped to the C1 controls of the same value.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// Pattern of the encoding declared by the XML or text declaration starting a document
+var encodingDeclaration = regexp.MustCompile(`^<\?xml[^>]*?\sencoding\s*=\s*["']([A-Za-z0-9._-]+)["']`)
+
+// Function to create an XML decoder of a document in any of the encodings schemas are
+// exported in: UTF-8, with or without a byte order mark, UTF-16 with a byte order mark or
+// an XML declaration, and the US-ASCII, ISO-8859-1 or windows-1252 encodings declared by
+// the encoding attribute of the XML declaration
+func NewDecoder(r io.Reader) *xml.Decoder {
+	decoder := xml.NewDecoder(UTF8Reader(r))
+	decoder.CharsetReader = charsetReader
+	return decoder
+}
+
+// Function to read a document as UTF-8 whatever its Unicode encoding, detected from its
+// byte order mark or, in its absence, from the first characters of the XML declaration. The
+// byte order mark is dropped and UTF-16 is transcoded; other content is read as is.
+func UTF8Reader(r io.Reader) io.Reader {
+	buffered := bufio.NewReader(r)
+	start, _ := buffered.Peek(4)
+	switch {
+	case bytes.HasPrefix(start, []byte{0xEF, 0xBB, 0xBF}):
+		buffered.Discard(3)
+	case bytes.HasPrefix(start, []byte{0xFF, 0xFE}):
+		buffered.Discard(2)
+		return &utf16Reader{r: buffered}
+	case bytes.HasPrefix(start, []byte{0xFE, 0xFF}):
+		buffered.Discard(2)
+		return &utf16Reader{r: buffered, bigEndian: true}
+	case bytes.Equal(start, []byte{'<', 0, '?', 0}):
+		return &utf16Reader{r: buffered}
+	case bytes.Equal(start, []byte{0, '<', 0, '?'}):
+		return &utf16Reader{r: buffered, bigEndian: true}
+	}
+	return buffered
+}
+
+// Function to decode a whole document that is not parsed as XML, such as a DTD or a RELAX NG
+// schema in the compact syntax, to UTF-8. Besides the Unicode encodings, the encoding
+// declared by a leading text declaration is honored.
+func decodeText(data []byte) ([]byte, error) {
+	data, err := io.ReadAll(UTF8Reader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, err
+	}
+	match := encodingDeclaration.FindSubmatch(data)
+	if match == nil {
+		return data, nil
+	}
+	reader, err := charsetReader(string(match[1]), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+// Function to read content in the encoding declared by its XML declaration as UTF-8, called
+// by the XML decoder for encodings other than UTF-8. UTF-16 content was transcoded already
+// by UTF8Reader.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(label) {
+	case "utf-8", "utf8", "utf-16", "utf-16le", "utf-16be", "unicode", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "l1":
+		return &singleByteReader{r: bufio.NewReader(input)}, nil
+	case "windows-1252", "cp1252":
+		return &singleByteReader{r: bufio.NewReader(input), table: &windows1252}, nil
+	}
+	return nil, fmt.Errorf("unsupported encoding %q, expected UTF-8, UTF-16, US-ASCII, ISO-8859-1 or windows-1252", label)
+}
+
+// Reader transcoding UTF-16 to UTF-8. Unpaired surrogates are replaced with U+FFFD.
+type utf16Reader struct {
+	r         *bufio.Reader
+	bigEndian bool
+	pending   []byte // Transcoded content not read yet
+}
+
+// Function to read transcoded content, a code unit at a time
+func (reader *utf16Reader) Read(p []byte) (int, error) {
+	for len(reader.pending) < len(p) {
+		unit, err := reader.unit()
+		if err != nil {
+			if len(reader.pending) > 0 {
+				break
+			}
+			return 0, err
+		}
+		r := rune(unit)
+		if unit >= 0xD800 && unit < 0xDC00 {
+			// High surrogate, which must be followed by a low one
+			if next, err := reader.r.Peek(2); err == nil {
+				low := reader.decode(next)
+				if low >= 0xDC00 && low < 0xE000 {
+					reader.r.Discard(2)
+					r = 0x10000 + (rune(unit)-0xD800)<<10 + rune(low) - 0xDC00
+				}
+			}
+		}
+		if r >= 0xD800 && r < 0xE000 {
+			r = utf8.RuneError
+		}
+		reader.pending = utf8.AppendRune(reader.pending, r)
+	}
+	n := copy(p, reader.pending)
+	reader.pending = reader.pending[n:]
+	return n, nil
+}
+
+// Function to read the next code unit
+func (reader *utf16Reader) unit() (uint16, error) {
+	var b [2]byte
+	if _, err := io.ReadFull(reader.r, b[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return 0, fmt.Errorf("truncated UTF-16 content")
+		}
+		return 0, err
+	}
+	return reader.decode(b[:]), nil
+}
+
+// Helper function to decode a code unit of the byte order of the content
+func (reader *utf16Reader) decode(b []byte) uint16 {
+	if reader.bigEndian {
+		return uint16(b[0])<<8 | uint16(b[1])
+	}
+	return uint16(b[1])<<8 | uint16(b[0])
+}
+
+// Reader transcoding a single byte encoding to UTF-8: ISO-8859-1, whose bytes are the code
+// points of their characters, or windows-1252 when its table is set
+type singleByteReader struct {
+	r       *bufio.Reader
+	table   *[32]rune // Characters of the bytes 0x80-0x9F, if they differ from ISO-8859-1
+	pending []byte    // Transcoded content not read yet
+}
+
+// Function to read transcoded content, a byte at a time
+func (reader *singleByteReader) Read(p []byte) (int, error) {
+	for len(reader.pending) < len(p) {
+		b, err := reader.r.ReadByte()
+		if err != nil {
+			if len(reader.pending) > 0 {
+				break
+			}
+			return 0, err
+		}
+		r := rune(b)
+		if reader.table != nil && b >= 0x80 && b < 0xA0 {
+			r = reader.table[b-0x80]
+		}
+		reader.pending = utf8.AppendRune(reader.pending, r)
+	}
+	n := copy(p, reader.pending)
+	reader.pending = reader.pending[n:]
+	return n, nil
+}
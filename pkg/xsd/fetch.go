@@ -0,0 +1,237 @@
+package xsd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Timeout of HTTP requests when the fetcher does not set one
+const DefaultFetchTimeout = 30 * time.Second
+
+// Time cached documents are used without revalidating them when the fetcher does not set one
+const DefaultCacheTTL = 24 * time.Hour
+
+// Fetcher reads schema documents from local paths and HTTP(S) URLs, for the document parsed
+// and for the schemaLocations it includes or imports alike. The zero value fetches URLs
+// without authentication and without caching them.
+type Fetcher struct {
+	Timeout     time.Duration // Timeout of each HTTP request, DefaultFetchTimeout if 0
+	Username    string        // User of HTTP basic authentication, if set
+	Password    string        // Password of HTTP basic authentication
+	BearerToken string        // Token sent as "Authorization: Bearer", if set
+	AuthHost    string        // Host the credentials are sent to, such as partner.example.com; every host if empty
+	CacheDir    string        // Directory keeping the documents fetched from URLs for later runs, if set
+	CacheTTL    time.Duration // Time cached documents are used before being revalidated, DefaultCacheTTL if 0
+	Refresh     bool          // Whether to fetch cached URLs again, updating the cache
+	RootDir     string        // Directory the documents must be in, if set: URLs and other paths are rejected
+}
+
+// Function to read a schema document from a local path or an HTTP(S) URL
+func (fetcher Fetcher) Fetch(location string) ([]byte, error) {
+	document, err := fetcher.Open(location)
+	if err != nil {
+		return nil, err
+	}
+	defer document.Close()
+	return io.ReadAll(document)
+}
+
+// Function to open a schema document from a local path or an HTTP(S) URL, to be read as a
+// stream and closed by the caller
+func (fetcher Fetcher) Open(location string) (io.ReadCloser, error) {
+	return fetcher.OpenContext(context.Background(), location)
+}
+
+// Function to open a schema document like Open, cancelling the request fetching a URL once
+// ctx is done
+func (fetcher Fetcher) OpenContext(ctx context.Context, location string) (io.ReadCloser, error) {
+	if fetcher.RootDir != "" {
+		if err := fetcher.confine(location); err != nil {
+			return nil, err
+		}
+	}
+	if !isURL(location) {
+		return os.Open(location)
+	}
+	if fetcher.CacheDir == "" {
+		return fetcher.get(ctx, location)
+	}
+
+	return fetcher.openCached(ctx, location)
+}
+
+// Validators of a cached document, sent back to the server to revalidate it
+type cacheValidators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// Function to open the document at a URL from the cache. Copies younger than the TTL are
+// used as is, and older ones are revalidated with their ETag or Last-Modified date, so that
+// only changed documents are downloaded again. Copies that cannot be revalidated, such as
+// when offline, are used as they are. Only complete 200 responses are cached.
+func (fetcher Fetcher) openCached(ctx context.Context, location string) (io.ReadCloser, error) {
+	cached := fetcher.cacheFile(location)
+	ttl := fetcher.CacheTTL
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	info, err := os.Stat(cached)
+	hit := err == nil && !fetcher.Refresh
+	if hit && time.Since(info.ModTime()) < ttl {
+		return os.Open(cached)
+	}
+
+	header := http.Header{}
+	if hit {
+		var validators cacheValidators
+		if data, err := os.ReadFile(cached + ".json"); err == nil && json.Unmarshal(data, &validators) == nil {
+			if validators.ETag != "" {
+				header.Set("If-None-Match", validators.ETag)
+			}
+			if validators.LastModified != "" {
+				header.Set("If-Modified-Since", validators.LastModified)
+			}
+		}
+	}
+	resp, err := fetcher.send(ctx, location, header)
+	if err != nil {
+		if hit {
+			return os.Open(cached)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hit:
+		// The copy is up to date for another TTL
+		now := time.Now()
+		os.Chtimes(cached, now, now)
+		return os.Open(cached)
+	case resp.StatusCode != http.StatusOK && hit:
+		return os.Open(cached)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ContentLength >= 0 && int64(len(data)) != resp.ContentLength {
+		return nil, fmt.Errorf("incomplete response: got %d of %d bytes", len(data), resp.ContentLength)
+	}
+	validators, err := json.Marshal(cacheValidators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeCacheFile(cached+".json", validators); err != nil {
+		return nil, fmt.Errorf("failed to cache %s: %w", location, err)
+	}
+	if err := writeCacheFile(cached, data); err != nil {
+		return nil, fmt.Errorf("failed to cache %s: %w", location, err)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Helper function to check that a location is a path within the root directory, so that
+// schemas from untrusted sources can neither read other files nor send requests
+func (fetcher Fetcher) confine(location string) error {
+	if isURL(location) {
+		return fmt.Errorf("%s: only files within the schema directory can be read", location)
+	}
+	rel, err := filepath.Rel(fetcher.RootDir, location)
+	if err != nil || !filepath.IsLocal(rel) {
+		if err == nil {
+			location = rel
+		}
+		return fmt.Errorf("%s: only files within the schema directory can be read", location)
+	}
+	return nil
+}
+
+// Function to get the path of the cached copy of the document at a URL, named after the
+// SHA-256 of the URL, and of the credentials sent with it, so that any URL makes a valid
+// file name and documents fetched with other credentials are cached apart
+func (fetcher Fetcher) cacheFile(location string) string {
+	key := location
+	if parsed, err := url.Parse(location); err == nil && fetcher.authenticates(parsed) {
+		key += "\n" + fetcher.Username + "\n" + fetcher.BearerToken
+	}
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(fetcher.CacheDir, hex.EncodeToString(hash[:])+".xml")
+}
+
+// Helper function to write a document to the cache through a temporary file, so that runs
+// in parallel never read a partial copy
+func writeCacheFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), ".fetch-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// Function to send the HTTP request fetching the document at a URL
+func (fetcher Fetcher) get(ctx context.Context, location string) (io.ReadCloser, error) {
+	resp, err := fetcher.send(ctx, location, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// Helper function to send a GET request for the document at a URL with the credentials of
+// the fetcher and the header given, whatever the status of the response
+func (fetcher Fetcher) send(ctx context.Context, location string, header http.Header) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header = header
+	if fetcher.authenticates(request.URL) {
+		if fetcher.BearerToken != "" {
+			request.Header.Set("Authorization", "Bearer "+fetcher.BearerToken)
+		} else if fetcher.Username != "" {
+			request.SetBasicAuth(fetcher.Username, fetcher.Password)
+		}
+	}
+
+	timeout := fetcher.Timeout
+	if timeout <= 0 {
+		timeout = DefaultFetchTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	return client.Do(request)
+}
+
+// Helper function to check whether the credentials are sent to a URL. They are kept from
+// other hosts, such as those of the standard schemas a partner schema imports.
+func (fetcher Fetcher) authenticates(location *url.URL) bool {
+	return fetcher.AuthHost == "" || location.Hostname() == fetcher.AuthHost
+}
@@ -0,0 +1,220 @@
+package xsd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// IdentityConstraint holds an xs:key, xs:keyref or xs:unique of an element: the values of
+// its fields identify the elements its selector selects within the element, or refer to
+// those of the key it refers to for keyrefs. Constraints are not checked, but noted in the
+// hints of the fields they involve.
+type IdentityConstraint struct {
+	Name     string  `xml:"name,attr"`
+	Refer    string  `xml:"refer,attr"` // Key or unique constraint referenced by a keyref
+	Selector XPath   `xml:"selector"`
+	Fields   []XPath `xml:"field"`
+}
+
+// XPath holds the restricted XPath expression of an identity constraint selector or field
+type XPath struct {
+	XPath string `xml:"xpath,attr"`
+}
+
+// Function to note the identity constraints of a resolved element tree on the elements and
+// attributes whose values they involve, such as "Must reference an existing OrderLine/@id"
+// on the field of a keyref. Keyrefs are described through the key they refer to, which may
+// be declared anywhere in the tree.
+func noteIdentityConstraints(elements []Element) {
+	keys := map[string]IdentityConstraint{}
+	walkElements(elements, func(element *Element) {
+		for _, key := range slices.Concat(element.Keys, element.Uniques) {
+			keys[key.Name] = key
+		}
+	})
+	walkElements(elements, func(element *Element) {
+		for _, key := range element.Keys {
+			noteConstraint(element, key, func(field string, others []string) string {
+				return fmt.Sprintf("Key of %s%s: must be present and unique", selection(key, element.Name), combinedWith(others))
+			})
+		}
+		for _, unique := range element.Uniques {
+			noteConstraint(element, unique, func(field string, others []string) string {
+				return fmt.Sprintf("Must be unique among %s%s", selection(unique, element.Name), combinedWith(others))
+			})
+		}
+		for _, keyref := range element.KeyRefs {
+			_, refer, _ := strings.Cut(keyref.Refer, ":")
+			if refer == "" {
+				refer = keyref.Refer
+			}
+			noteConstraint(element, keyref, func(field string, others []string) string {
+				key, ok := keys[refer]
+				i := slices.IndexFunc(keyref.Fields, func(xpath XPath) bool { return xpath.XPath == field })
+				if !ok || i >= len(key.Fields) {
+					return fmt.Sprintf("Must reference an existing %s%s", refer, combinedWith(others))
+				}
+				return fmt.Sprintf("Must reference an existing %s/%s%s", displayXPath(key.Selector.XPath), displayXPath(key.Fields[i].XPath), combinedWith(others))
+			})
+		}
+	})
+}
+
+// Helper function to call visit on every element of a tree, parents first
+func walkElements(elements []Element, visit func(*Element)) {
+	for i := range elements {
+		element := &elements[i]
+		visit(element)
+		walkElements(element.Children, visit)
+		walkElements(element.Alternatives, visit)
+	}
+}
+
+// Function to add the description of an identity constraint to the nodes each of its fields
+// selects, given the xpath of the field and the display of the other fields
+func noteConstraint(scope *Element, constraint IdentityConstraint, describe func(field string, others []string) string) {
+	selected := selectElements([]*Element{scope}, constraint.Selector.XPath)
+	for _, field := range constraint.Fields {
+		var others []string
+		for _, other := range constraint.Fields {
+			if other.XPath != field.XPath {
+				others = append(others, displayXPath(other.XPath))
+			}
+		}
+		description := describe(field.XPath, others)
+		for _, constraints := range selectFields(selected, field.XPath) {
+			if !slices.Contains(*constraints, description) {
+				*constraints = append(*constraints, description)
+			}
+		}
+	}
+}
+
+// Helper function to describe the elements a constraint selects within its scope element,
+// such as "the OrderLine of Order"
+func selection(constraint IdentityConstraint, scope string) string {
+	if selector := displayXPath(constraint.Selector.XPath); selector != "" {
+		return "the " + selector + " of " + scope
+	}
+	return scope
+}
+
+// Helper function to describe the other fields of a constraint spanning several fields
+func combinedWith(others []string) string {
+	if len(others) == 0 {
+		return ""
+	}
+	return " in combination with " + strings.Join(others, ", ")
+}
+
+// Function to split the restricted XPath expression of a selector or field into its
+// alternative paths, each a list of local name steps. A leading "//" step stands for the
+// descendant axis of .//, and attribute steps start with @.
+func xpathPaths(xpath string) [][]string {
+	var paths [][]string
+	for _, path := range strings.Split(xpath, "|") {
+		path = strings.TrimSpace(path)
+		var steps []string
+		if rest, ok := strings.CutPrefix(path, ".//"); ok {
+			steps, path = append(steps, "//"), rest
+		}
+		for _, step := range strings.Split(path, "/") {
+			step = strings.TrimSpace(step)
+			step = strings.TrimPrefix(step, "child::")
+			if rest, ok := strings.CutPrefix(step, "attribute::"); ok {
+				step = "@" + rest
+			}
+			attribute := strings.HasPrefix(step, "@")
+			name := strings.TrimPrefix(step, "@")
+			if _, local, ok := strings.Cut(name, ":"); ok {
+				name = local
+			}
+			if attribute {
+				name = "@" + name
+			}
+			steps = append(steps, name)
+		}
+		paths = append(paths, steps)
+	}
+	return paths
+}
+
+// Helper function to display an XPath expression the way fields are named, without
+// namespace prefixes nor self steps
+func displayXPath(xpath string) string {
+	var paths []string
+	for _, steps := range xpathPaths(xpath) {
+		steps = slices.DeleteFunc(steps, func(step string) bool { return step == "." || step == "//" })
+		if len(steps) > 0 {
+			paths = append(paths, strings.Join(steps, "/"))
+		}
+	}
+	return strings.Join(paths, " or ")
+}
+
+// Function to select the elements a selector xpath reaches from its scope elements
+func selectElements(scopes []*Element, xpath string) []*Element {
+	var selected []*Element
+	for _, steps := range xpathPaths(xpath) {
+		current := scopes
+		for _, step := range steps {
+			current = stepElements(current, step)
+		}
+		selected = append(selected, current...)
+	}
+	return selected
+}
+
+// Function to follow a step from elements to the elements it reaches
+func stepElements(elements []*Element, step string) []*Element {
+	var reached []*Element
+	for _, element := range elements {
+		switch step {
+		case ".":
+			reached = append(reached, element)
+		case "//":
+			// The descendant axis of .// includes the element itself
+			reached = append(reached, element)
+			walkElements(element.Children, func(descendant *Element) { reached = append(reached, descendant) })
+		default:
+			for i := range element.Children {
+				if step == "*" || element.Children[i].Name == step {
+					reached = append(reached, &element.Children[i])
+				}
+			}
+		}
+	}
+	return reached
+}
+
+// Function to get the constraints of the nodes a field xpath reaches from the selected
+// elements: attributes, or elements, whose text holds the value when they have attributes
+func selectFields(selected []*Element, xpath string) []*[]string {
+	var fields []*[]string
+	for _, steps := range xpathPaths(xpath) {
+		last := steps[len(steps)-1]
+		current := selected
+		if strings.HasPrefix(last, "@") {
+			steps = steps[:len(steps)-1]
+		}
+		for _, step := range steps {
+			current = stepElements(current, step)
+		}
+		for _, element := range current {
+			switch {
+			case strings.HasPrefix(last, "@"):
+				for i := range element.Attributes {
+					if last == "@*" || element.Attributes[i].Name == last[1:] {
+						fields = append(fields, &element.Attributes[i].Constraints)
+					}
+				}
+			case element.Text != nil:
+				fields = append(fields, &element.Text.Constraints)
+			default:
+				fields = append(fields, &element.Constraints)
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,255 @@
+package xsd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Patterns of the sample values read as numbers. Integers with a leading zero or more than
+// 15 digits are identifiers, such as account numbers, which are kept as strings.
+var (
+	sampleInteger = regexp.MustCompile(`^[+-]?(0|[1-9][0-9]{0,14})$`)
+	sampleDecimal = regexp.MustCompile(`^[+-]?(0|[1-9][0-9]{0,14})?\.[0-9]+$`)
+)
+
+// Layouts of the sample values read as dates and times, with and without a time zone
+var (
+	sampleDateLayouts     = []string{"2006-01-02", "2006-01-02Z07:00"}
+	sampleDateTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"}
+)
+
+// Function to infer a schema from a sample XML document read from r with the default settings
+func Infer(r io.Reader) (Schema, error) {
+	return Parser{}.Infer(r)
+}
+
+// Function to infer a schema from the sample XML file, or HTTP(S) URL, at location with the
+// default settings
+func InferFile(location string) (Schema, error) {
+	return Parser{}.InferFile(location)
+}
+
+// Function to infer a schema from a sample XML document read from r, for messages of which
+// only examples exist. The root of the sample becomes the global element, elements repeated
+// under a parent repeat, elements or attributes missing from some instances of their parent
+// are optional, and the types of leaves are guessed from their values: xs:boolean,
+// xs:integer, xs:decimal, xs:date or xs:dateTime when every value matches, xs:string
+// otherwise. The text of elements that also have attributes is declared as a simpleContent
+// extension of its guessed type, and text next to children as mixed content.
+func (parser Parser) Infer(r io.Reader) (Schema, error) {
+	decoder := NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return Schema{}, fmt.Errorf("sample has no root element")
+		}
+		if err != nil {
+			return Schema{}, fmt.Errorf("failed to decode sample: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			root := &sampleElement{name: start.Name}
+			if err := root.read(decoder, start); err != nil {
+				return Schema{}, fmt.Errorf("failed to decode sample: %w", err)
+			}
+			return parser.Resolve(root.schema(), "")
+		}
+	}
+}
+
+// Function to infer a schema from the sample XML file, or HTTP(S) URL, at location
+func (parser Parser) InferFile(location string) (Schema, error) {
+	document, err := parser.Fetcher.Open(location)
+	if err != nil {
+		return Schema{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer document.Close()
+	return parser.Infer(document)
+}
+
+// Element of a sample document, merged across all its instances under the instances of its parent
+type sampleElement struct {
+	name       xml.Name
+	instances  int                // Occurrences of the element in the sample
+	parents    int                // Instances of the parent containing the element
+	maxCount   int                // Largest number of occurrences in one instance of the parent
+	values     []string           // Text of the instances without children
+	mixed      bool               // Whether text was found next to children
+	nillable   bool               // Whether an instance was written as xsi:nil
+	children   []*sampleElement   // In the order they were first found
+	attributes []*sampleAttribute // In the order they were first found
+}
+
+// Attribute of a sample element, merged across the instances of the element
+type sampleAttribute struct {
+	name   string
+	values []string
+}
+
+// Function to read an instance of an element, from its start to its end, merging it into
+// what was read of the previous instances
+func (element *sampleElement) read(decoder *xml.Decoder, start xml.StartElement) error {
+	element.instances++
+	for _, attr := range start.Attr {
+		switch {
+		case attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns":
+		case attr.Name.Space == InstanceNamespace:
+			element.nillable = element.nillable || (attr.Name.Local == "nil" && attr.Value == "true")
+		default:
+			element.attribute(attr.Name.Local).values = append(element.attribute(attr.Name.Local).values, attr.Value)
+		}
+	}
+
+	counts := make(map[*sampleElement]int)
+	var text strings.Builder
+	var last *sampleElement
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			child := element.child(token.Name, last)
+			counts[child]++
+			if err := child.read(decoder, token); err != nil {
+				return err
+			}
+			last = child
+		case xml.CharData:
+			text.Write(token)
+		case xml.EndElement:
+			for child, count := range counts {
+				child.parents++
+				child.maxCount = max(child.maxCount, count)
+			}
+			value := strings.TrimSpace(text.String())
+			if len(counts) == 0 {
+				element.values = append(element.values, value)
+			} else if value != "" {
+				element.mixed = true
+			}
+			return nil
+		}
+	}
+}
+
+// Function to get the child of an element named name, adding it after the child previous
+// when it was not found before, so that children keep the order of the instances
+func (element *sampleElement) child(name xml.Name, previous *sampleElement) *sampleElement {
+	for _, child := range element.children {
+		if child.name == name {
+			return child
+		}
+	}
+	child := &sampleElement{name: name}
+	element.children = slices.Insert(element.children, slices.Index(element.children, previous)+1, child)
+	return child
+}
+
+// Function to get the attribute of an element named name, adding it when it was not found before
+func (element *sampleElement) attribute(name string) *sampleAttribute {
+	for _, attribute := range element.attributes {
+		if attribute.name == name {
+			return attribute
+		}
+	}
+	attribute := &sampleAttribute{name: name}
+	element.attributes = append(element.attributes, attribute)
+	return attribute
+}
+
+// Function to build the schema declaring the root of a sample. Its namespace is the target
+// namespace, and its descendants are qualified unless they have no namespace.
+func (root *sampleElement) schema() Schema {
+	schema := Schema{Namespaces: map[string]string{"xs": Namespace}}
+	if root.name.Space != "" {
+		schema.TargetNamespace = root.name.Space
+		schema.Namespaces[""] = root.name.Space
+		if root.qualified() {
+			schema.ElementFormDefault = "qualified"
+		}
+	}
+	schema.Elements = []Element{root.declaration()}
+	return schema
+}
+
+// Helper function to check whether any descendant of an element has a namespace
+func (element *sampleElement) qualified() bool {
+	return slices.ContainsFunc(element.children, func(child *sampleElement) bool {
+		return child.name.Space != "" || child.qualified()
+	})
+}
+
+// Function to declare an element from its instances. Elements without children nor
+// attributes are leaves of the type guessed from their values.
+func (element *sampleElement) declaration() Element {
+	declared := Element{Name: element.name.Local, Nillable: element.nillable}
+	if len(element.children) == 0 && len(element.attributes) == 0 {
+		declared.Type = inferType(element.values)
+		return declared
+	}
+
+	complexType := &ComplexType{Sequence: &Compositor{Kind: "sequence"}}
+	for _, child := range element.children {
+		particle := child.declaration()
+		if child.parents < element.instances {
+			particle.MinOccurs = "0"
+		}
+		if child.maxCount > 1 {
+			particle.MaxOccurs = "unbounded"
+		}
+		complexType.Sequence.Particles = append(complexType.Sequence.Particles, Particle{Element: &particle})
+	}
+	for _, attribute := range element.attributes {
+		declaredAttribute := Attribute{Name: attribute.name, Type: inferType(attribute.values)}
+		if len(attribute.values) == element.instances {
+			declaredAttribute.Use = "required"
+		}
+		complexType.Attributes = append(complexType.Attributes, declaredAttribute)
+	}
+	complexType.Mixed = element.mixed
+	if len(element.children) == 0 && slices.ContainsFunc(element.values, func(value string) bool { return value != "" }) {
+		extension := &Derivation{Base: inferType(element.values), Attributes: complexType.Attributes}
+		complexType = &ComplexType{SimpleContent: &SimpleContent{Extension: extension}}
+	}
+	declared.ComplexType = complexType
+	return declared
+}
+
+// Helper function to guess the built-in type of sample values, ignoring empty ones. The
+// first type every value matches is chosen, xs:string when none does.
+func inferType(values []string) string {
+	values = slices.DeleteFunc(slices.Clone(values), func(value string) bool { return value == "" })
+	if len(values) == 0 {
+		return "xs:string"
+	}
+	candidates := []struct {
+		typeName string
+		matches  func(string) bool
+	}{
+		{"xs:boolean", func(value string) bool { return value == "true" || value == "false" }},
+		{"xs:integer", sampleInteger.MatchString},
+		{"xs:decimal", func(value string) bool { return sampleInteger.MatchString(value) || sampleDecimal.MatchString(value) }},
+		{"xs:date", func(value string) bool { return parsesAs(value, sampleDateLayouts) }},
+		{"xs:dateTime", func(value string) bool { return parsesAs(value, sampleDateTimeLayouts) }},
+	}
+	for _, candidate := range candidates {
+		if !slices.ContainsFunc(values, func(value string) bool { return !candidate.matches(value) }) {
+			return candidate.typeName
+		}
+	}
+	return "xs:string"
+}
+
+// Helper function to check whether a value parses with one of the time layouts
+func parsesAs(value string, layouts []string) bool {
+	return slices.ContainsFunc(layouts, func(layout string) bool {
+		_, err := time.Parse(layout, value)
+		return err == nil
+	})
+}
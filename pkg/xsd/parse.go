@@ -0,0 +1,1192 @@
+package xsd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Default number of element levels a recursive type is expanded to
+const DefaultMaxDepth = 10
+
+// Log level of the messages about every declaration resolved, below slog.LevelDebug
+const LevelTrace = slog.LevelDebug - 4
+
+// Parser holds the settings used to parse schemas. The zero value uses the defaults.
+type Parser struct {
+	MaxDepth int          // Element nesting depth at which recursive types stop being expanded, DefaultMaxDepth if 0
+	Root     string       // Global element to resolve when declared, skipping the others of large libraries; every one if empty
+	Logger   *slog.Logger // Receives the documents loaded at debug level and the elements resolved at LevelTrace, if set
+	Fetcher  Fetcher      // Reads the documents parsed by location, and those they include or import
+	Catalog  *Catalog     // Remaps the schemaLocations of includes and imports, if set
+}
+
+// Function to parse an XSD document read from r with the default settings
+func Parse(r io.Reader) (Schema, error) {
+	return Parser{}.Parse(r)
+}
+
+// Function to parse the XSD file, or HTTP(S) URL, at location with the default settings
+func ParseFile(location string) (Schema, error) {
+	return Parser{}.ParseFile(location)
+}
+
+// Function to parse an XSD document read from r. Relative include and import
+// schemaLocations are resolved against the working directory; use ParseFile to
+// resolve them against the document's own location.
+func (parser Parser) Parse(r io.Reader) (Schema, error) {
+	return parser.ParseContext(context.Background(), r)
+}
+
+// Function to parse an XSD document read from r, like Parse, stopping the loading of the
+// documents it includes or imports, and the requests fetching them, once ctx is done
+func (parser Parser) ParseContext(ctx context.Context, r io.Reader) (Schema, error) {
+	schema, err := decodeSchema(r)
+	if err != nil {
+		return Schema{}, err
+	}
+
+	loader := parser.newSchemaLoader()
+	loader.ctx = ctx
+	if err := loader.add(schema, "", "", true); err != nil {
+		return Schema{}, err
+	}
+	return loader.resolve(schema), nil
+}
+
+// Function to parse the XSD file, or HTTP(S) URL, at location
+func (parser Parser) ParseFile(location string) (Schema, error) {
+	return parser.ParseFileContext(context.Background(), location)
+}
+
+// Function to parse the XSD file, or HTTP(S) URL, at location, stopping the loading of the
+// documents, and the requests fetching them, once ctx is done
+func (parser Parser) ParseFileContext(ctx context.Context, location string) (Schema, error) {
+	loader := parser.newSchemaLoader()
+	loader.ctx = ctx
+	schema, err := loader.load(location, "", true)
+	if err != nil {
+		return Schema{}, err
+	}
+	return loader.resolve(schema), nil
+}
+
+// Function to resolve declarations built rather than parsed, such as the translation of
+// another schema language, as the document at location. Includes and imports are loaded
+// relative to location, which is not read itself.
+func (parser Parser) Resolve(schema Schema, location string) (Schema, error) {
+	loader := parser.newSchemaLoader()
+	loader.loaded[location] = true
+	if err := loader.add(&schema, location, "", true); err != nil {
+		return Schema{}, err
+	}
+	return loader.resolve(&schema), nil
+}
+
+// Registry of the global type definitions declared across all loaded schema documents,
+// along with the state of the resolution in progress
+type typeRegistry struct {
+	elements        map[string]elementDef        // Keyed by {namespace}localName
+	complexTypes    map[string]complexTypeDef    // Keyed by {namespace}localName
+	simpleTypes     map[string]simpleTypeDef     // Keyed by {namespace}localName
+	groups          map[string]groupDef          // Keyed by {namespace}localName
+	attributeGroups map[string]attributeGroupDef // Keyed by {namespace}localName
+	localNames      map[string]string            // Key of the first definition of each kind and local name, such as "complexType Address"
+	substitutes     map[string][]elementDef      // Members of substitution groups, keyed by the {namespace}localName of the head
+	derived         map[string][]complexTypeDef  // complexTypes derived from a base type, keyed by the {namespace}localName of the base
+	maxDepth        int                          // Element nesting depth at which recursive types are truncated
+	depth           int                          // Nesting depth of the complexType being resolved
+	expanding       map[*ComplexType]int         // complexTypes being resolved, with their nesting count
+	deriving        []*ComplexType               // complexTypes whose base type is being resolved, outermost first
+	inlining        map[*Group]int               // Model groups being inlined, with the depth they are inlined at
+	warnings        []string                     // Warnings of the resolution in progress
+	unsupported     []string                     // Warnings of the resolution in progress about skipped constructs
+	skipped         []string                     // Local names of the constructs skipped in the content of the element being resolved
+	unresolved      []string                     // Names of the types and groups referenced by the resolution in progress but not declared
+	logger          *slog.Logger                 // Destination of trace messages, or nil
+}
+
+// Global element along with the schema document declaring it
+type elementDef struct {
+	element *Element
+	schema  *Schema
+}
+
+// Global complexType along with the schema document declaring it
+type complexTypeDef struct {
+	complexType *ComplexType
+	schema      *Schema
+}
+
+// Global simpleType along with the schema document declaring it
+type simpleTypeDef struct {
+	simpleType *SimpleType
+	schema     *Schema
+}
+
+// Named model group along with the schema document declaring it
+type groupDef struct {
+	group  *Group
+	schema *Schema
+}
+
+// Named attribute group along with the schema document declaring it
+type attributeGroupDef struct {
+	attributeGroup *AttributeGroup
+	schema         *Schema
+}
+
+// Helper function to build a registry key from a namespace and local name
+func typeKey(namespace, local string) string {
+	return "{" + namespace + "}" + local
+}
+
+// Function to create an empty type registry
+func newTypeRegistry(maxDepth int) *typeRegistry {
+	return &typeRegistry{
+		elements:        make(map[string]elementDef),
+		complexTypes:    make(map[string]complexTypeDef),
+		simpleTypes:     make(map[string]simpleTypeDef),
+		groups:          make(map[string]groupDef),
+		attributeGroups: make(map[string]attributeGroupDef),
+		localNames:      make(map[string]string),
+		substitutes:     make(map[string][]elementDef),
+		derived:         make(map[string][]complexTypeDef),
+		maxDepth:        maxDepth,
+		expanding:       make(map[*ComplexType]int),
+		inlining:        make(map[*Group]int),
+	}
+}
+
+// Function to add the global type definitions of a schema document to the registry
+func (registry *typeRegistry) add(schema *Schema) {
+	for i := range schema.Elements {
+		key := typeKey(schema.TargetNamespace, schema.Elements[i].Name)
+		registry.elements[key] = elementDef{&schema.Elements[i], schema}
+		if head := schema.Elements[i].SubstitutionGroup; head != "" {
+			headKey := typeKey(schema.ResolveQName(head))
+			registry.substitutes[headKey] = append(registry.substitutes[headKey], elementDef{&schema.Elements[i], schema})
+		}
+	}
+	for i := range schema.ComplexTypes {
+		key := typeKey(schema.TargetNamespace, schema.ComplexTypes[i].Name)
+		registry.complexTypes[key] = complexTypeDef{&schema.ComplexTypes[i], schema}
+		registry.indexLocalName("complexType", schema.ComplexTypes[i].Name, key)
+		if derivation := schema.ComplexTypes[i].Derivation(); derivation != nil {
+			baseKey := typeKey(schema.ResolveQName(derivation.Base))
+			registry.derived[baseKey] = append(registry.derived[baseKey], complexTypeDef{&schema.ComplexTypes[i], schema})
+		}
+	}
+	for i := range schema.SimpleTypes {
+		schema.SimpleTypes[i].Restriction.Base = normalizeType(*schema, schema.SimpleTypes[i].Restriction.Base)
+		key := typeKey(schema.TargetNamespace, schema.SimpleTypes[i].Name)
+		registry.simpleTypes[key] = simpleTypeDef{&schema.SimpleTypes[i], schema}
+		registry.indexLocalName("simpleType", schema.SimpleTypes[i].Name, key)
+	}
+	for i := range schema.Groups {
+		key := typeKey(schema.TargetNamespace, schema.Groups[i].Name)
+		registry.groups[key] = groupDef{&schema.Groups[i], schema}
+		registry.indexLocalName("group", schema.Groups[i].Name, key)
+	}
+	for i := range schema.AttributeGroups {
+		key := typeKey(schema.TargetNamespace, schema.AttributeGroups[i].Name)
+		registry.attributeGroups[key] = attributeGroupDef{&schema.AttributeGroups[i], schema}
+		registry.indexLocalName("attributeGroup", schema.AttributeGroups[i].Name, key)
+	}
+	// Like xs:openContent, the wildcard content it adds to every complexType is not generated
+	if schema.DefaultOpenContent != nil {
+		registry.warnSkipped("xs:defaultOpenContent is not supported and was skipped")
+	}
+}
+
+// Function to index the key of a definition by its kind and local name, for the lookups
+// falling back to a match on local name only. The first definition loaded is kept, so that
+// the fallback does not depend on the order of map iteration.
+func (registry *typeRegistry) indexLocalName(kind, local, key string) {
+	if _, ok := registry.localNames[kind+" "+local]; !ok {
+		registry.localNames[kind+" "+local] = key
+	}
+}
+
+// Function to rewrite built-in type references to the canonical xs: prefix,
+// so that xsd:string, string (with XSD as default namespace) and xs:string all map alike
+func normalizeType(schema Schema, qname string) string {
+	if qname == "" {
+		return ""
+	}
+	namespace, local := schema.ResolveQName(qname)
+	if namespace == Namespace {
+		return "xs:" + local
+	}
+	return qname
+}
+
+// Function to look up a global element by its qualified name
+func (registry *typeRegistry) lookupElement(schema Schema, qname string) (elementDef, bool) {
+	namespace, local := schema.ResolveQName(qname)
+	def, ok := registry.elements[typeKey(namespace, local)]
+	return def, ok
+}
+
+// Function to look up a named complexType, falling back to a match on local name only
+func (registry *typeRegistry) lookupComplexType(schema Schema, qname string) (complexTypeDef, bool) {
+	namespace, local := schema.ResolveQName(qname)
+	if def, ok := registry.complexTypes[typeKey(namespace, local)]; ok {
+		return def, true
+	}
+	if def, ok := registry.complexTypes[registry.localNames["complexType "+local]]; ok {
+		return def, true
+	}
+	return complexTypeDef{}, false
+}
+
+// Function to look up a named simpleType, falling back to a match on local name only
+func (registry *typeRegistry) lookupSimpleType(schema Schema, qname string) (simpleTypeDef, bool) {
+	namespace, local := schema.ResolveQName(qname)
+	if def, ok := registry.simpleTypes[typeKey(namespace, local)]; ok {
+		return def, true
+	}
+	if def, ok := registry.simpleTypes[registry.localNames["simpleType "+local]]; ok {
+		return def, true
+	}
+	return simpleTypeDef{}, false
+}
+
+// Function to look up a named model group, falling back to a match on local name only
+func (registry *typeRegistry) lookupGroup(schema Schema, qname string) (groupDef, bool) {
+	namespace, local := schema.ResolveQName(qname)
+	if def, ok := registry.groups[typeKey(namespace, local)]; ok {
+		return def, true
+	}
+	if def, ok := registry.groups[registry.localNames["group "+local]]; ok {
+		return def, true
+	}
+	return groupDef{}, false
+}
+
+// Function to look up a named attribute group, falling back to a match on local name only
+func (registry *typeRegistry) lookupAttributeGroup(schema Schema, qname string) (attributeGroupDef, bool) {
+	namespace, local := schema.ResolveQName(qname)
+	if def, ok := registry.attributeGroups[typeKey(namespace, local)]; ok {
+		return def, true
+	}
+	if def, ok := registry.attributeGroups[registry.localNames["attributeGroup "+local]]; ok {
+		return def, true
+	}
+	return attributeGroupDef{}, false
+}
+
+// Loader following xs:include and xs:import references across schema documents
+type schemaLoader struct {
+	schemas  []*Schema       // Every loaded document, in load order
+	included []*Schema       // Documents included (directly or transitively) into the main schema
+	loaded   map[string]bool // Locations already loaded, to break include cycles
+	maxDepth int             // Element nesting depth at which recursive types are truncated
+	root     string          // Global element to resolve alone when declared, or ""
+	logger   *slog.Logger    // Destination of debug messages, or nil
+	fetcher  Fetcher         // Reads the documents by location
+	catalog  *Catalog        // Remaps schemaLocations, or nil
+	ctx      context.Context // Cancels the loading of documents when done
+}
+
+// Function to create a loader with nothing loaded yet
+func (parser Parser) newSchemaLoader() *schemaLoader {
+	maxDepth := parser.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	return &schemaLoader{loaded: make(map[string]bool), maxDepth: maxDepth, root: parser.Root, logger: parser.Logger, fetcher: parser.Fetcher, catalog: parser.Catalog, ctx: context.Background()}
+}
+
+// Helper function to log a message at level, if the loader has a logger
+func (loader *schemaLoader) log(level slog.Level, msg string, args ...any) {
+	if loader.logger != nil {
+		loader.logger.Log(loader.ctx, level, msg, args...)
+	}
+}
+
+// Function to build the type registry from every document loaded so far
+func (loader *schemaLoader) registry() *typeRegistry {
+	registry := newTypeRegistry(loader.maxDepth)
+	registry.logger = loader.logger
+	for _, schema := range loader.schemas {
+		registry.add(schema)
+	}
+	for _, schema := range loader.schemas {
+		registry.redefine(schema)
+	}
+	return registry
+}
+
+// Function to resolve the global elements of the main schema and of the documents it includes.
+// Types are only resolved as the elements reach them, so when the root is declared, the
+// rest of the documents is left unresolved. An undeclared root resolves every element, for
+// the error of SelectRoot to list them.
+func (loader *schemaLoader) resolve(schema *Schema) Schema {
+	registry := loader.registry()
+	documents := append([]*Schema{schema}, loader.included...)
+	declared := slices.ContainsFunc(documents, func(document *Schema) bool {
+		return slices.ContainsFunc(concreteElements(document.Elements), func(element Element) bool { return element.Name == loader.root })
+	})
+
+	var elements []Element
+	for _, document := range documents {
+		roots := concreteElements(document.Elements)
+		if declared {
+			roots = slices.DeleteFunc(roots, func(element Element) bool { return element.Name != loader.root })
+		}
+		for i := range roots {
+			roots[i] = globalElement(roots[i])
+		}
+		elements = append(elements, resolveElements(roots, *document, registry)...)
+	}
+	noteIdentityConstraints(elements)
+	schema.Elements = elements
+	schema.Warnings = registry.takeWarnings()
+	schema.Unsupported = registry.takeUnsupported()
+	schema.Unresolved = registry.takeUnresolved()
+	return *schema
+}
+
+// Helper function to mark a global element as qualified, as global elements are in the
+// target namespace of their document whatever its elementFormDefault
+func globalElement(element Element) Element {
+	element.Form = "qualified"
+	return element
+}
+
+// Helper function to leave out abstract global elements, which cannot be document roots
+func concreteElements(elements []Element) []Element {
+	var concrete []Element
+	for _, element := range elements {
+		if !element.Abstract {
+			concrete = append(concrete, element)
+		}
+	}
+	return concrete
+}
+
+// Function to load a schema document and, recursively, the documents it includes or imports.
+// Included documents without a targetNamespace adopt the including document's namespace.
+func (loader *schemaLoader) load(location, includingNamespace string, included bool) (*Schema, error) {
+	if err := loader.ctx.Err(); err != nil {
+		return nil, err
+	}
+	document, err := loader.fetcher.OpenContext(loader.ctx, location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer document.Close()
+	loader.loaded[location] = true
+
+	schema, err := decodeSchema(document)
+	if err != nil {
+		return nil, err
+	}
+	loader.log(slog.LevelDebug, "loaded schema document", "location", location, "targetNamespace", schema.TargetNamespace)
+	if err := loader.add(schema, location, includingNamespace, included); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// Function to add an already decoded schema document, following its includes and imports
+// relative to location
+func (loader *schemaLoader) add(schema *Schema, location, includingNamespace string, included bool) error {
+	if schema.TargetNamespace == "" {
+		schema.TargetNamespace = includingNamespace
+	}
+	schema.referOriginals()
+	loader.schemas = append(loader.schemas, schema)
+	if included && len(loader.schemas) > 1 {
+		loader.included = append(loader.included, schema)
+	}
+
+	for _, include := range schema.Includes {
+		if err := loader.follow(location, include, schema.TargetNamespace, included); err != nil {
+			return err
+		}
+	}
+	// Redefined and overridden documents are included, their definitions being replaced
+	// once every document is loaded
+	for _, redefine := range slices.Concat(schema.Redefines, schema.Overrides) {
+		if err := loader.follow(location, SchemaRef{SchemaLocation: redefine.SchemaLocation}, schema.TargetNamespace, included); err != nil {
+			return err
+		}
+	}
+	for _, imp := range schema.Imports {
+		if err := loader.follow(location, imp, "", false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Function to load a referenced schema document unless it was loaded already. The catalog
+// is looked up with the schemaLocation as written, then as resolved against base, and with
+// the namespace of imports without a schemaLocation.
+func (loader *schemaLoader) follow(base string, ref SchemaRef, includingNamespace string, included bool) error {
+	location, found := loader.catalog.Resolve(ref.SchemaLocation)
+	if !found && ref.SchemaLocation == "" {
+		if location, found = loader.catalog.Resolve(ref.Namespace); !found {
+			// Imports without a location refer to namespaces we cannot resolve
+			loader.log(slog.LevelDebug, "skipped import without schemaLocation", "namespace", ref.Namespace)
+			return nil
+		}
+	}
+	if !found {
+		resolved, err := resolveSchemaLocation(base, ref.SchemaLocation)
+		if err != nil {
+			return err
+		}
+		if location, found = loader.catalog.Resolve(resolved); !found {
+			location = resolved
+		}
+	}
+	if found {
+		loader.log(slog.LevelDebug, "remapped schemaLocation with the catalog", "schemaLocation", ref.SchemaLocation, "namespace", ref.Namespace, "location", location)
+	}
+	if loader.loaded[location] {
+		return nil
+	}
+	if _, err := loader.load(location, includingNamespace, included); err != nil {
+		schemaLocation := ref.SchemaLocation
+		if schemaLocation == "" {
+			schemaLocation = location
+		}
+		return &ImportError{SchemaLocation: schemaLocation, Err: err}
+	}
+	return nil
+}
+
+// ImportError reports an included or imported schema document that could not be loaded
+type ImportError struct {
+	SchemaLocation string // As written in the referencing document
+	Err            error
+}
+
+// Function to format an import error with the schemaLocation that failed
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("failed to load %s: %v", e.SchemaLocation, e.Err)
+}
+
+// Function to return the cause of an import error
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// Function to resolve a schemaLocation relative to the document referencing it
+func resolveSchemaLocation(base, location string) (string, error) {
+	if isURL(location) || filepath.IsAbs(location) {
+		return location, nil
+	}
+	if isURL(base) {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", fmt.Errorf("invalid schema URL %s: %w", base, err)
+		}
+		ref, err := url.Parse(location)
+		if err != nil {
+			return "", fmt.Errorf("invalid schemaLocation %s: %w", location, err)
+		}
+		return baseURL.ResolveReference(ref).String(), nil
+	}
+	return filepath.Join(filepath.Dir(base), location), nil
+}
+
+// Helper function to check whether a location is an HTTP(S) URL
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// Function to decode a single schema document from its token stream and collect its
+// namespace declarations. Global declarations are decoded one at a time as they are read,
+// and other content such as annotations is skipped, so that large documents are neither
+// read into memory whole nor decoded beyond what is resolved.
+func decodeSchema(r io.Reader) (*Schema, error) {
+	decoder := NewDecoder(r)
+	var schema Schema
+	root := true
+	for {
+		token, err := decoder.Token()
+		switch {
+		case err == io.EOF && !root:
+			schema.Namespaces = collectNamespaces(schema.Attrs, nil)
+			return &schema, nil
+		case err != nil:
+			return nil, fmt.Errorf("failed to unmarshal XML: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if root {
+			root = false
+			for _, attr := range start.Attr {
+				switch {
+				case attr.Name.Space == "" && attr.Name.Local == "targetNamespace":
+					schema.TargetNamespace = attr.Value
+				case attr.Name.Space == "" && attr.Name.Local == "elementFormDefault":
+					schema.ElementFormDefault = attr.Value
+				default:
+					schema.Attrs = append(schema.Attrs, attr)
+				}
+			}
+			continue
+		}
+		if err := schema.decodeDeclaration(decoder, start); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal XML: %w", err)
+		}
+	}
+}
+
+// Function to decode a global declaration of a schema document into its list, skipping
+// the content that is not resolved
+func (schema *Schema) decodeDeclaration(decoder *xml.Decoder, start xml.StartElement) error {
+	switch start.Name.Local {
+	case "element":
+		return decodeInto(decoder, start, &schema.Elements)
+	case "complexType":
+		return decodeInto(decoder, start, &schema.ComplexTypes)
+	case "simpleType":
+		return decodeInto(decoder, start, &schema.SimpleTypes)
+	case "group":
+		return decodeInto(decoder, start, &schema.Groups)
+	case "attributeGroup":
+		return decodeInto(decoder, start, &schema.AttributeGroups)
+	case "include":
+		return decodeInto(decoder, start, &schema.Includes)
+	case "import":
+		return decodeInto(decoder, start, &schema.Imports)
+	case "redefine":
+		return decodeInto(decoder, start, &schema.Redefines)
+	case "override":
+		return decodeInto(decoder, start, &schema.Overrides)
+	case "defaultOpenContent":
+		schema.DefaultOpenContent = &Construct{XMLName: start.Name}
+	}
+	return decoder.Skip()
+}
+
+// Helper function to decode the declaration starting with start and append it to list
+func decodeInto[T any](decoder *xml.Decoder, start xml.StartElement, list *[]T) error {
+	var declaration T
+	if err := decoder.DecodeElement(&declaration, &start); err != nil {
+		return err
+	}
+	*list = append(*list, declaration)
+	return nil
+}
+
+// Function to collect the namespace declarations among attrs on top of the inherited ones
+func collectNamespaces(attrs []xml.Attr, inherited map[string]string) map[string]string {
+	namespaces := make(map[string]string, len(inherited))
+	for prefix, uri := range inherited {
+		namespaces[prefix] = uri
+	}
+	for _, attr := range attrs {
+		switch {
+		case attr.Name.Space == "xmlns":
+			namespaces[attr.Name.Local] = attr.Value
+		case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+			namespaces[""] = attr.Value
+		}
+	}
+	return namespaces
+}
+
+// Function to resolve type references recursively, returning a copy of the elements
+// with their children and simpleTypes populated from the registry. Qualified names
+// are resolved against the schema document the elements were declared in.
+func resolveElements(elements []Element, schema Schema, registry *typeRegistry) []Element {
+	if len(elements) == 0 {
+		return nil
+	}
+
+	resolved := make([]Element, len(elements))
+	for i, element := range elements {
+		element.SimpleType = resolveSimpleType(element.SimpleType, element.Type, schema, registry)
+		if element.Form == "qualified" || (element.Form == "" && schema.ElementFormDefault == "qualified") {
+			element.Namespace = schema.TargetNamespace
+		}
+
+		complexType := element.ComplexType
+		element.Skipped = registry.skippedIn(func() {
+			switch {
+			case complexType != nil:
+				registry.warnUnsupported(complexType, element.Name)
+				// Anonymous complexTypes only recur through the model groups they reference
+				if registry.truncates(complexType, "of element "+element.Name) {
+					element.Truncated = true
+				} else {
+					element.Children, element.Attributes = registry.expand(complexType, schema)
+					element.Text = registry.resolveText(complexType, schema, element.Name)
+				}
+			case element.Type != "":
+				if def, ok := registry.lookupComplexType(schema, element.Type); ok {
+					complexType = def.complexType
+					registry.warnUnsupported(complexType, element.Name)
+					if registry.truncates(complexType, element.Type) {
+						element.Truncated = true
+					} else {
+						if complexType.Abstract {
+							element.Alternatives = registry.resolveAlternatives(def)
+						}
+						// Abstract types without concrete derived types are expanded as usual
+						if len(element.Alternatives) == 0 {
+							element.Children, element.Attributes = registry.expand(complexType, *def.schema)
+							element.Text = registry.resolveText(complexType, *def.schema, element.Name)
+						}
+					}
+				}
+			}
+		})
+
+		for _, construct := range unsupportedConstructs(element.Unsupported, nil) {
+			registry.warnSkipped(fmt.Sprintf("xs:%s in element %s is not supported and was skipped", construct, element.Name))
+		}
+		if len(element.TypeAlternatives) > 0 {
+			registry.warnSkipped(fmt.Sprintf("conditional type assignment (xs:alternative) of element %s is not supported, its declared type was used", element.Name))
+		}
+
+		// XSD 1.1 assertions are not checked, but noted in the hints of their elements
+		if complexType != nil {
+			element.Assertions = complexType.AssertionTests()
+		}
+		if element.SimpleType != nil {
+			for _, assertion := range element.SimpleType.Restriction.Assertions {
+				element.Assertions = append(element.Assertions, assertion.Test)
+			}
+		}
+
+		// Elements without their own documentation inherit the documentation of their type
+		if element.Annotation.Text() == "" {
+			switch {
+			case complexType != nil:
+				element.Annotation = complexType.Annotation
+			case element.SimpleType != nil:
+				element.Annotation = element.SimpleType.Annotation
+			}
+		}
+		element.Type = normalizeType(schema, element.Type)
+		// Text without attributes nor children is a plain value of the type it extends
+		if element.Text != nil && len(element.Attributes) == 0 && len(element.Children) == 0 {
+			element.Type, element.SimpleType, element.Text = element.Text.Type, element.Text.SimpleType, nil
+		}
+		if complexType == nil && element.SimpleType == nil {
+			registry.warnUnresolved(element.Type, "element "+element.Name)
+		}
+		registry.trace("resolved element", "name", element.Name, "type", element.Type,
+			"children", len(element.Children), "attributes", len(element.Attributes))
+		resolved[i] = element
+	}
+	return resolved
+}
+
+// Function to resolve a complexType one level deeper, keeping track of the complexTypes
+// being expanded to detect recursion
+func (registry *typeRegistry) expand(complexType *ComplexType, schema Schema) ([]Element, []Attribute) {
+	registry.depth++
+	registry.expanding[complexType]++
+	defer func() {
+		registry.depth--
+		registry.expanding[complexType]--
+	}()
+	return resolveComplexType(complexType, schema, registry)
+}
+
+// Function to resolve the concrete complexTypes derived, directly or not, from an abstract
+// complexType into the alternatives of an element, each named after its type. The
+// abstract type counts as being expanded, so that recursion through it is detected.
+func (registry *typeRegistry) resolveAlternatives(base complexTypeDef) []Element {
+	registry.expanding[base.complexType]++
+	defer func() { registry.expanding[base.complexType]-- }()
+
+	var alternatives []Element
+	for _, def := range registry.derivedTypes(base, make(map[*ComplexType]bool)) {
+		if def.complexType.Abstract {
+			continue
+		}
+		alternative := Element{Name: def.complexType.Name, Annotation: def.complexType.Annotation}
+		alternative.Skipped = registry.skippedIn(func() {
+			registry.warnUnsupported(def.complexType, alternative.Name)
+			alternative.Children, alternative.Attributes = registry.expand(def.complexType, *def.schema)
+		})
+		alternatives = append(alternatives, alternative)
+	}
+	return alternatives
+}
+
+// Function to list the complexTypes derived from base, including the types derived from them
+func (registry *typeRegistry) derivedTypes(base complexTypeDef, visited map[*ComplexType]bool) []complexTypeDef {
+	var derived []complexTypeDef
+	for _, def := range registry.derived[typeKey(base.schema.TargetNamespace, base.complexType.Name)] {
+		if visited[def.complexType] {
+			continue
+		}
+		visited[def.complexType] = true
+		derived = append(derived, def)
+		derived = append(derived, registry.derivedTypes(def, visited)...)
+	}
+	return derived
+}
+
+// Function to check whether a reference to complexType must be left unexpanded, which is
+// the case for recursive references once the maximum depth is reached
+func (registry *typeRegistry) truncates(complexType *ComplexType, typeName string) bool {
+	if registry.expanding[complexType] == 0 || registry.depth < registry.maxDepth {
+		return false
+	}
+	registry.warn("recursive type %s truncated at depth %d", typeName, registry.maxDepth)
+	return true
+}
+
+// Helper function to add a warning to the resolution in progress, unless it was given already
+func (registry *typeRegistry) warn(format string, args ...any) {
+	warning := fmt.Sprintf(format, args...)
+	if !slices.Contains(registry.warnings, warning) {
+		registry.warnings = append(registry.warnings, warning)
+	}
+}
+
+// Helper function to warn about the unsupported constructs of a complexType, which is
+// described by its name, or by the name of its element when it is anonymous
+func (registry *typeRegistry) warnUnsupported(complexType *ComplexType, elementName string) {
+	owner := "complexType " + complexType.Name
+	if complexType.Name == "" {
+		owner = "the complexType of element " + elementName
+	}
+	registry.warnConstructs(complexType.UnsupportedConstructs(), owner)
+}
+
+// Helper function to warn about the unsupported constructs of a declaration described by owner,
+// which are recorded as skipped in the content of the element being resolved
+func (registry *typeRegistry) warnConstructs(constructs []string, owner string) {
+	for _, construct := range constructs {
+		registry.warnSkipped(fmt.Sprintf("xs:%s in %s is not supported and was skipped", construct, owner))
+		if !slices.Contains(registry.skipped, construct) {
+			registry.skipped = append(registry.skipped, construct)
+		}
+	}
+}
+
+// Helper function to add a warning about a skipped construct, which makes the outputs incomplete
+func (registry *typeRegistry) warnSkipped(warning string) {
+	if !slices.Contains(registry.unsupported, warning) {
+		registry.unsupported = append(registry.unsupported, warning)
+	}
+	registry.warn("%s", warning)
+}
+
+// Function to run the resolution of the content of an element, returning the local names
+// of the constructs skipped in it. Those of nested elements are recorded on them.
+func (registry *typeRegistry) skippedIn(resolve func()) []string {
+	outer := registry.skipped
+	registry.skipped = nil
+	resolve()
+	skipped := registry.skipped
+	registry.skipped = outer
+	return skipped
+}
+
+// Helper function to warn about a reference to a type that is neither built in nor
+// declared in the loaded documents, which is then treated as a string
+func (registry *typeRegistry) warnUnresolved(typeName, owner string) {
+	if typeName != "" && !strings.HasPrefix(typeName, "xs:") {
+		registry.warn("type %s of %s could not be resolved, treating it as xs:string", typeName, owner)
+		registry.addUnresolved(typeName)
+	}
+}
+
+// Helper function to log a trace message, if the registry has a logger
+func (registry *typeRegistry) trace(msg string, args ...any) {
+	if registry.logger != nil {
+		registry.logger.Log(context.Background(), LevelTrace, msg, args...)
+	}
+}
+
+// Function to return the warnings of the resolution in progress and start afresh
+func (registry *typeRegistry) takeWarnings() []string {
+	warnings := registry.warnings
+	registry.warnings = nil
+	return warnings
+}
+
+// Helper function to record a reference to a type or group that is not declared, once
+func (registry *typeRegistry) addUnresolved(name string) {
+	if !slices.Contains(registry.unresolved, name) {
+		registry.unresolved = append(registry.unresolved, name)
+	}
+}
+
+// Function to return the unresolved references of the resolution in progress and start afresh
+func (registry *typeRegistry) takeUnresolved() []string {
+	unresolved := registry.unresolved
+	registry.unresolved = nil
+	return unresolved
+}
+
+// Function to return the warnings about skipped constructs of the resolution in progress
+// and start afresh
+func (registry *typeRegistry) takeUnsupported() []string {
+	unsupported := registry.unsupported
+	registry.unsupported = nil
+	return unsupported
+}
+
+// Function to resolve the content model of a complexType into child elements and attributes.
+// Types derived by complexContent extension get the content of their base type followed by
+// their own; restrictions restate the elements they keep and inherit the attributes they
+// do not prohibit. Attributes declared with use="prohibited" are left out in any case.
+func resolveComplexType(complexType *ComplexType, schema Schema, registry *typeRegistry) ([]Element, []Attribute) {
+	derivation := complexType.Derivation()
+	if derivation == nil {
+		compositors := []*Compositor{complexType.Sequence, complexType.Choice, complexType.All, complexType.Group.compositor()}
+		children, attributes := resolveContent(compositors, complexType.Attributes, complexType.AttributeGroups, schema, registry)
+		return children, mergeAttributes(nil, attributes)
+	}
+
+	// The base type is looked up in the document declaring the derived type; types deriving
+	// from themselves, directly or not, are invalid and only get their own content
+	var baseChildren []Element
+	var baseAttributes []Attribute
+	def, ok := registry.lookupComplexType(schema, derivation.Base)
+	// simpleContent extends simpleTypes too, whose text resolveText describes
+	if !ok && complexType.SimpleContent == nil {
+		registry.warnUnresolved(normalizeType(schema, derivation.Base), "the derivation of complexType "+complexType.Name)
+	}
+	if ok && !registry.derivesCyclically(complexType, def.complexType) {
+		registry.warnUnsupported(def.complexType, "")
+		registry.deriving = append(registry.deriving, complexType)
+		baseChildren, baseAttributes = resolveComplexType(def.complexType, *def.schema, registry)
+		registry.deriving = registry.deriving[:len(registry.deriving)-1]
+	}
+
+	compositors := []*Compositor{derivation.Sequence, derivation.Choice, derivation.All, derivation.Group.compositor()}
+	children, attributes := resolveContent(compositors, derivation.Attributes, derivation.AttributeGroups, schema, registry)
+	attributes = mergeAttributes(baseAttributes, attributes)
+	if complexType.ComplexContent != nil && complexType.ComplexContent.Extension != nil {
+		children = append(baseChildren, children...)
+	}
+	return children, attributes
+}
+
+// Helper function to check whether deriving complexType from base closes a cycle, base being
+// resolved already, and warn about it naming the types in the cycle
+func (registry *typeRegistry) derivesCyclically(complexType, base *ComplexType) bool {
+	start := slices.Index(registry.deriving, base)
+	if start < 0 {
+		return false
+	}
+	if base == complexType {
+		registry.warn("complexType %s derives from itself, its derivation was skipped", complexType.Name)
+		return true
+	}
+	var names []string
+	for _, deriving := range registry.deriving[start:] {
+		names = append(names, deriving.Name)
+	}
+	names = append(names, complexType.Name, base.Name)
+	registry.warn("complexTypes %s derive from each other in a cycle, the derivation of %s from %s was skipped",
+		strings.Join(names, " -> "), complexType.Name, base.Name)
+	return true
+}
+
+// Function to resolve the text of the elements of a complexType with simpleContent into a
+// leaf element called name, of the simpleType it extends, or nil for other complexTypes.
+// Extensions of another complexType with simpleContent have the text of their base type,
+// and the text between the children of mixed content is an optional xs:string.
+func (registry *typeRegistry) resolveText(complexType *ComplexType, schema Schema, name string) *Element {
+	if complexType.IsMixed() {
+		return &Element{Name: name, Type: "xs:string", MinOccurs: "0"}
+	}
+	if complexType.SimpleContent == nil || complexType.SimpleContent.Extension == nil {
+		return nil
+	}
+	base := complexType.SimpleContent.Extension.Base
+	if def, ok := registry.lookupComplexType(schema, base); ok {
+		if registry.derivesCyclically(complexType, def.complexType) {
+			return nil
+		}
+		registry.deriving = append(registry.deriving, complexType)
+		defer func() { registry.deriving = registry.deriving[:len(registry.deriving)-1] }()
+		return registry.resolveText(def.complexType, *def.schema, name)
+	}
+
+	text := &Element{Name: name, SimpleType: resolveSimpleType(nil, base, schema, registry)}
+	text.Type = normalizeType(schema, base)
+	if text.SimpleType == nil {
+		registry.warnUnresolved(text.Type, "the simpleContent of complexType "+complexType.Name)
+	}
+	return text
+}
+
+// Function to resolve the compositors and attributes declared by a complexType or derivation,
+// followed by the attributes of the attribute groups it references
+func resolveContent(compositors []*Compositor, declaredAttributes []Attribute, attributeGroups []GroupRef, schema Schema, registry *typeRegistry) ([]Element, []Attribute) {
+	var children []Element
+	for _, compositor := range compositors {
+		if compositor != nil {
+			children = append(children, resolveCompositor(compositor, false, schema, registry)...)
+		}
+	}
+	attributes := resolveAttributes(declaredAttributes, schema, registry)
+	attributes = append(attributes, registry.resolveAttributeGroups(attributeGroups, schema, make(map[*AttributeGroup]bool))...)
+	return children, attributes
+}
+
+// Function to resolve the simpleTypes of attributes declared in schema
+func resolveAttributes(declaredAttributes []Attribute, schema Schema, registry *typeRegistry) []Attribute {
+	var attributes []Attribute
+	for _, attribute := range declaredAttributes {
+		attribute.SimpleType = resolveSimpleType(attribute.SimpleType, attribute.Type, schema, registry)
+		if attribute.Annotation.Text() == "" && attribute.SimpleType != nil {
+			attribute.Annotation = attribute.SimpleType.Annotation
+		}
+		attribute.Type = normalizeType(schema, attribute.Type)
+		if attribute.SimpleType == nil {
+			registry.warnUnresolved(attribute.Type, "attribute "+attribute.Name)
+		}
+		attributes = append(attributes, attribute)
+	}
+	return attributes
+}
+
+// Function to resolve the attributes of referenced attribute groups, including the groups
+// they reference, in the documents declaring them. Groups already visited are skipped, so
+// that each attribute is added once and circular references end.
+func (registry *typeRegistry) resolveAttributeGroups(refs []GroupRef, schema Schema, visited map[*AttributeGroup]bool) []Attribute {
+	var attributes []Attribute
+	for _, ref := range refs {
+		def, ok := registry.lookupAttributeGroup(schema, ref.Ref)
+		if !ok {
+			registry.warn("attributeGroup %s could not be resolved and was skipped", ref.Ref)
+			registry.addUnresolved(ref.Ref)
+			continue
+		}
+		if visited[def.attributeGroup] {
+			continue
+		}
+		visited[def.attributeGroup] = true
+		registry.trace("inlined attributeGroup", "name", def.attributeGroup.Name)
+		attributes = append(attributes, resolveAttributes(def.attributeGroup.Attributes, *def.schema, registry)...)
+		attributes = append(attributes, registry.resolveAttributeGroups(def.attributeGroup.AttributeGroups, *def.schema, visited)...)
+	}
+	return attributes
+}
+
+// Helper function to merge the attributes declared by a derived type into the inherited
+// ones: redeclared attributes replace the inherited ones, and prohibited ones are removed
+func mergeAttributes(inherited, declared []Attribute) []Attribute {
+	merged := slices.Clone(inherited)
+	for _, attribute := range declared {
+		i := slices.IndexFunc(merged, func(a Attribute) bool { return a.Name == attribute.Name })
+		if i >= 0 {
+			merged[i] = attribute
+		} else {
+			merged = append(merged, attribute)
+		}
+	}
+	return slices.DeleteFunc(merged, func(a Attribute) bool { return a.Use == "prohibited" })
+}
+
+// Function to flatten a compositor into the list of child elements it may contain.
+// Elements inside an xs:choice (at any depth) are marked as choice branches, and the
+// occurrence constraints of the group are carried over to its elements.
+func resolveCompositor(compositor *Compositor, inChoice bool, schema Schema, registry *typeRegistry) []Element {
+	inChoice = inChoice || compositor.Kind == "choice"
+
+	var children []Element
+	for _, particle := range compositor.Particles {
+		if particle.Group != nil {
+			children = append(children, resolveCompositor(particle.Group, inChoice, schema, registry)...)
+			continue
+		}
+		if particle.GroupRef != nil {
+			children = append(children, registry.resolveGroup(*particle.GroupRef, inChoice, schema)...)
+			continue
+		}
+		if particle.Any != nil {
+			// The elements matching a wildcard are passed through together, as one XML fragment
+			children = append(children, Element{Name: WildcardName, Type: "xs:anyType", MinOccurs: particle.Any.MinOccurs, ChoiceItem: inChoice})
+			continue
+		}
+		element := *particle.Element
+		if substitutes := registry.resolveSubstitutes(element, schema); len(substitutes) > 0 {
+			children = append(children, substitutes...)
+			continue
+		}
+		if element.Ref != "" {
+			children = append(children, registry.resolveRef(element, inChoice, schema)...)
+			continue
+		}
+		element.ChoiceItem = inChoice
+		children = append(children, resolveElements([]Element{element}, schema, registry)...)
+	}
+
+	for i := range children {
+		if compositor.MinOccurs == "0" {
+			children[i].MinOccurs = "0"
+		}
+		if (Element{MaxOccurs: compositor.MaxOccurs}).IsRepeating() && !children[i].IsRepeating() && !children[i].IsWildcard() {
+			children[i].MaxOccurs = compositor.MaxOccurs
+		}
+	}
+	return children
+}
+
+// Function to inline the content of the model group referenced by ref, resolved in the
+// document declaring the group. The occurrence constraints of the reference apply to the
+// compositor of the group. Groups that contain themselves without an element in between
+// are invalid and skipped; recursion through elements is truncated like recursive types.
+func (registry *typeRegistry) resolveGroup(ref GroupRef, inChoice bool, schema Schema) []Element {
+	def, ok := registry.lookupGroup(schema, ref.Ref)
+	if !ok {
+		registry.warn("group %s could not be resolved and was skipped", ref.Ref)
+		registry.addUnresolved(ref.Ref)
+		return nil
+	}
+	compositor := def.group.Compositor()
+	if compositor == nil {
+		return nil
+	}
+	depth, inlining := registry.inlining[def.group]
+	if inlining && depth == registry.depth {
+		registry.warn("group %s contains itself and was skipped", def.group.Name)
+		return nil
+	}
+	registry.inlining[def.group] = registry.depth
+	defer func() {
+		if inlining {
+			registry.inlining[def.group] = depth
+		} else {
+			delete(registry.inlining, def.group)
+		}
+	}()
+
+	registry.warnConstructs(def.group.UnsupportedConstructs(), "group "+def.group.Name)
+	registry.trace("inlined group", "name", def.group.Name)
+	group := *compositor
+	group.MinOccurs, group.MaxOccurs = ref.MinOccurs, ref.MaxOccurs
+	return resolveCompositor(&group, inChoice, *def.schema, registry)
+}
+
+// Helper function to present a reference to a model group as a compositor holding it, or
+// nil without a reference
+func (ref *GroupRef) compositor() *Compositor {
+	if ref == nil {
+		return nil
+	}
+	return &Compositor{Kind: "sequence", Particles: []Particle{{GroupRef: ref}}}
+}
+
+// Function to resolve a reference to a global element, looked up in the namespace of its
+// qualified name, including imported ones, and resolved in the document declaring it with
+// its full content model. The occurrence constraints and documentation of the reference
+// take precedence. References that cannot be resolved are skipped.
+func (registry *typeRegistry) resolveRef(ref Element, inChoice bool, schema Schema) []Element {
+	def, ok := registry.lookupElement(schema, ref.Ref)
+	if !ok {
+		registry.warn("element %s could not be resolved and was skipped", ref.Ref)
+		registry.addUnresolved(ref.Ref)
+		return nil
+	}
+	element := globalElement(*def.element)
+	element.MinOccurs, element.MaxOccurs = ref.MinOccurs, ref.MaxOccurs
+	element.ChoiceItem = inChoice
+	if ref.Annotation.Text() != "" {
+		element.Annotation = ref.Annotation
+	}
+	return resolveElements([]Element{element}, *def.schema, registry)
+}
+
+// Function to expand a reference to the head of a substitution group into the elements that
+// may appear in its place: the head itself unless it is abstract, and the members of the
+// group. They become choice branches carrying the occurrence constraints of the reference.
+// Returns nil when ref does not refer to the head of a substitution group.
+func (registry *typeRegistry) resolveSubstitutes(ref Element, schema Schema) []Element {
+	if ref.Ref == "" {
+		return nil
+	}
+	head, ok := registry.lookupElement(schema, ref.Ref)
+	if !ok {
+		return nil
+	}
+	candidates := registry.substitutionGroup(head, make(map[*Element]bool))
+	if len(candidates) == 1 {
+		return nil
+	}
+
+	var elements []Element
+	for _, def := range candidates {
+		if def.element.Abstract {
+			continue
+		}
+		element := globalElement(*def.element)
+		element.MinOccurs, element.MaxOccurs = ref.MinOccurs, ref.MaxOccurs
+		element.ChoiceItem = true
+		elements = append(elements, resolveElements([]Element{element}, *def.schema, registry)...)
+	}
+	return elements
+}
+
+// Function to list a global element followed by the members of its substitution group,
+// including the members of nested groups
+func (registry *typeRegistry) substitutionGroup(head elementDef, visited map[*Element]bool) []elementDef {
+	if visited[head.element] {
+		return nil
+	}
+	visited[head.element] = true
+
+	group := []elementDef{head}
+	for _, member := range registry.substitutes[typeKey(head.schema.TargetNamespace, head.element.Name)] {
+		group = append(group, registry.substitutionGroup(member, visited)...)
+	}
+	return group
+}
+
+// Function to resolve the simpleType of an element or attribute, inline or looked up by
+// name in the registry. Type references are normalized against the declaring document,
+// and the named item types of lists are resolved.
+func resolveSimpleType(inline *SimpleType, typeName string, schema Schema, registry *typeRegistry) *SimpleType {
+	var simpleType SimpleType
+	switch {
+	case inline != nil:
+		simpleType = *inline
+	case typeName != "":
+		def, ok := registry.lookupSimpleType(schema, typeName)
+		if !ok {
+			return nil
+		}
+		simpleType, schema = *def.simpleType, *def.schema
+	default:
+		return nil
+	}
+
+	simpleType.Restriction.Base = normalizeType(schema, simpleType.Restriction.Base)
+	if simpleType.List != nil {
+		list := *simpleType.List
+		list.SimpleType = resolveSimpleType(list.SimpleType, list.ItemType, schema, registry)
+		list.ItemType = normalizeType(schema, list.ItemType)
+		simpleType.List = &list
+	}
+	if simpleType.Union != nil {
+		union := Union{SimpleTypes: slices.Clone(simpleType.Union.SimpleTypes)}
+		var members []string
+		for _, member := range strings.Fields(simpleType.Union.MemberTypes) {
+			members = append(members, normalizeType(schema, member))
+		}
+		union.MemberTypes = strings.Join(members, " ")
+		for i := range union.SimpleTypes {
+			union.SimpleTypes[i].Restriction.Base = normalizeType(schema, union.SimpleTypes[i].Restriction.Base)
+		}
+		simpleType.Union = &union
+	}
+	return &simpleType
+}
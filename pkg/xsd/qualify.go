@@ -0,0 +1,72 @@
+package xsd
+
+import (
+	"slices"
+	"strconv"
+)
+
+// Qualifier names the elements of the documents of a schema after their namespace, as set
+// by the NamespacePrefix and Unqualified settings of the schema
+type Qualifier struct {
+	root     string            // Tag of the document root
+	xmlns    string            // Namespace declarations of the document root
+	prefixes map[string]string // Prefix of each namespace, nil when only the root is qualified
+}
+
+// Function to get how the elements of documents of the schema are named. By default, the
+// target namespace is declared on the root as by RootTag. With a NamespacePrefix, every
+// qualified element is written with the prefix of its namespace: NamespacePrefix for the
+// namespace of the root, and the prefix declared by the schema, or ns1, ns2, ..., for
+// imported ones, all declared on the root. Unqualified schemas are written without any
+// namespace.
+func (schema Schema) Qualifier() Qualifier {
+	if len(schema.Elements) == 0 {
+		return Qualifier{}
+	}
+	root := schema.Elements[0]
+	switch {
+	case schema.Unqualified:
+		return Qualifier{root: root.Name}
+	case schema.NamespacePrefix == "":
+		tag, xmlns := schema.RootTag()
+		return Qualifier{root: tag, xmlns: xmlns}
+	}
+
+	// Namespaces are declared in document order, starting with the target namespace of the root
+	var namespaces []string
+	walkElements(schema.Elements[:1], func(element *Element) {
+		if element.Namespace != "" && !slices.Contains(namespaces, element.Namespace) {
+			namespaces = append(namespaces, element.Namespace)
+		}
+	})
+	qualifier := Qualifier{prefixes: make(map[string]string)}
+	used := map[string]bool{}
+	for _, namespace := range namespaces {
+		prefix := schema.PrefixFor(namespace)
+		if namespace == root.Namespace {
+			prefix = schema.NamespacePrefix
+		}
+		for n := 1; prefix == "" || used[prefix] || (prefix == schema.NamespacePrefix && namespace != root.Namespace); n++ {
+			prefix = "ns" + strconv.Itoa(n)
+		}
+		used[prefix] = true
+		qualifier.prefixes[namespace] = prefix
+		qualifier.xmlns += " xmlns:" + prefix + "=\"" + namespace + "\""
+	}
+	qualifier.root = qualifier.Tag(root)
+	return qualifier
+}
+
+// Function to get the tag name of the document root and its namespace declarations
+func (qualifier Qualifier) Root() (string, string) {
+	return qualifier.root, qualifier.xmlns
+}
+
+// Function to get the tag name of an element below the root, prefixed when the element is
+// qualified and the qualifier prefixes every element
+func (qualifier Qualifier) Tag(element Element) string {
+	if prefix, ok := qualifier.prefixes[element.Namespace]; ok && element.Namespace != "" {
+		return prefix + ":" + element.Name
+	}
+	return element.Name
+}
@@ -0,0 +1,156 @@
+package xsd
+
+import "slices"
+
+// Redefine holds an xs:redefine or XSD 1.1 xs:override declaration: a document included
+// along with the definitions replacing those of the same name it declares
+type Redefine struct {
+	SchemaLocation  string           `xml:"schemaLocation,attr"`
+	Elements        []Element        `xml:"element"` // Only overrides replace elements
+	ComplexTypes    []ComplexType    `xml:"complexType"`
+	SimpleTypes     []SimpleType     `xml:"simpleType"`
+	Groups          []Group          `xml:"group"`
+	AttributeGroups []AttributeGroup `xml:"attributeGroup"`
+}
+
+// Suffix of the local name the original of a redefined definition is kept under, for the
+// redefinition to derive from it or to refer to it
+const redefinedSuffix = "~redefined"
+
+// Function to point the references of the redefinitions of a schema document to their own
+// name to the original definition instead, as a redefined complexType extends or restricts
+// its original, and a redefined group or attributeGroup may refer to its original. Called
+// once the document has its target namespace.
+func (schema *Schema) referOriginals() {
+	for _, redefine := range schema.Redefines {
+		for i := range redefine.ComplexTypes {
+			key := typeKey(schema.TargetNamespace, redefine.ComplexTypes[i].Name)
+			if derivation := redefine.ComplexTypes[i].Derivation(); derivation != nil && refersTo(*schema, derivation.Base, key) {
+				derivation.Base += redefinedSuffix
+			}
+		}
+		for i := range redefine.Groups {
+			renameGroupRefs(*schema, redefine.Groups[i].Compositor(), typeKey(schema.TargetNamespace, redefine.Groups[i].Name))
+		}
+		for i := range redefine.AttributeGroups {
+			renameRefs(*schema, redefine.AttributeGroups[i].AttributeGroups, typeKey(schema.TargetNamespace, redefine.AttributeGroups[i].Name))
+		}
+	}
+}
+
+// Function to replace the definitions of the documents a schema document redefines or
+// overrides with its own, once every document is in the registry
+func (registry *typeRegistry) redefine(schema *Schema) {
+	for i := range schema.Redefines {
+		registry.replace(schema, &schema.Redefines[i], true)
+	}
+	for i := range schema.Overrides {
+		registry.replace(schema, &schema.Overrides[i], false)
+	}
+}
+
+// Function to replace the definitions of the registry with those of a redefine or override.
+// Only redefinitions refer to their original, which is kept under another name; the
+// definitions of overrides replace theirs.
+func (registry *typeRegistry) replace(schema *Schema, redefine *Redefine, redefinition bool) {
+	namespace := schema.TargetNamespace
+	for i := range redefine.Elements {
+		registry.elements[typeKey(namespace, redefine.Elements[i].Name)] = elementDef{&redefine.Elements[i], schema}
+	}
+	for i := range redefine.ComplexTypes {
+		complexType := &redefine.ComplexTypes[i]
+		key := typeKey(namespace, complexType.Name)
+		if original, ok := registry.complexTypes[key]; ok && redefinition {
+			registry.complexTypes[key+redefinedSuffix] = original
+		}
+		registry.complexTypes[key] = complexTypeDef{complexType, schema}
+	}
+	for i := range redefine.SimpleTypes {
+		simpleType := &redefine.SimpleTypes[i]
+		key, owner := typeKey(namespace, simpleType.Name), schema
+		// Restrictions of a simpleType are not resolved through their base, so the facets
+		// of the original are merged into its redefinition, declared by the original document
+		if original, ok := registry.simpleTypes[key]; ok && redefinition && refersTo(*schema, simpleType.Restriction.Base, key) {
+			merged := *original.simpleType
+			merged.Restriction = restrict(original.simpleType.Restriction, simpleType.Restriction)
+			*simpleType, owner = merged, original.schema
+		}
+		simpleType.Restriction.Base = normalizeType(*owner, simpleType.Restriction.Base)
+		registry.simpleTypes[key] = simpleTypeDef{simpleType, owner}
+	}
+	for i := range redefine.Groups {
+		group := &redefine.Groups[i]
+		key := typeKey(namespace, group.Name)
+		if original, ok := registry.groups[key]; ok && redefinition {
+			registry.groups[key+redefinedSuffix] = original
+		}
+		registry.groups[key] = groupDef{group, schema}
+	}
+	for i := range redefine.AttributeGroups {
+		attributeGroup := &redefine.AttributeGroups[i]
+		key := typeKey(namespace, attributeGroup.Name)
+		if original, ok := registry.attributeGroups[key]; ok && redefinition {
+			registry.attributeGroups[key+redefinedSuffix] = original
+		}
+		registry.attributeGroups[key] = attributeGroupDef{attributeGroup, schema}
+	}
+}
+
+// Helper function to check whether a qualified name refers to the definition of a key
+func refersTo(schema Schema, qname, key string) bool {
+	return qname != "" && typeKey(schema.ResolveQName(qname)) == key
+}
+
+// Function to point the references to a model group within a compositor, including nested
+// ones, to its original
+func renameGroupRefs(schema Schema, compositor *Compositor, key string) {
+	if compositor == nil {
+		return
+	}
+	for _, particle := range compositor.Particles {
+		switch {
+		case particle.GroupRef != nil && refersTo(schema, particle.GroupRef.Ref, key):
+			particle.GroupRef.Ref += redefinedSuffix
+		case particle.Group != nil:
+			renameGroupRefs(schema, particle.Group, key)
+		}
+	}
+}
+
+// Function to point the references to an attribute group to its original
+func renameRefs(schema Schema, refs []GroupRef, key string) {
+	for i := range refs {
+		if refersTo(schema, refs[i].Ref, key) {
+			refs[i].Ref += redefinedSuffix
+		}
+	}
+}
+
+// Function to restrict the facets of a simpleType further: the facets the restriction sets
+// replace those of the base, and its assertions come on top of those of the base
+func restrict(base, restriction Restriction) Restriction {
+	merged := base
+	for _, facet := range []struct{ merged, restriction **Facet }{
+		{&merged.Length, &restriction.Length},
+		{&merged.MinLength, &restriction.MinLength},
+		{&merged.MaxLength, &restriction.MaxLength},
+		{&merged.TotalDigits, &restriction.TotalDigits},
+		{&merged.FractionDigits, &restriction.FractionDigits},
+		{&merged.MinInclusive, &restriction.MinInclusive},
+		{&merged.MaxInclusive, &restriction.MaxInclusive},
+		{&merged.MinExclusive, &restriction.MinExclusive},
+		{&merged.MaxExclusive, &restriction.MaxExclusive},
+	} {
+		if *facet.restriction != nil {
+			*facet.merged = *facet.restriction
+		}
+	}
+	if len(restriction.Patterns) > 0 {
+		merged.Patterns = restriction.Patterns
+	}
+	if len(restriction.Enumerations) > 0 {
+		merged.Enumerations = restriction.Enumerations
+	}
+	merged.Assertions = append(slices.Clip(base.Assertions), restriction.Assertions...)
+	return merged
+}
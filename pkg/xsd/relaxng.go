@@ -0,0 +1,705 @@
+package xsd
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Namespace of the RELAX NG XML syntax
+const RelaxNGNamespace = "http://relaxng.org/ns/structure/1.0"
+
+// Namespace of the RELAX NG DTD compatibility annotations, such as a:documentation
+const relaxNGAnnotations = "http://relaxng.org/ns/compatibility/annotations/1.0"
+
+// Datatype library of the XSD built-in types, as referenced by RELAX NG patterns
+const xsdDatatypes = "http://www.w3.org/2001/XMLSchema-datatypes"
+
+// Pattern of a RELAX NG schema, as read from either syntax
+type rngPattern struct {
+	kind          string // element, attribute, group, interleave, choice, optional, zeroOrMore, oneOrMore, mixed, list, ref, text, data, value, empty, notAllowed, or an unsupported pattern
+	name          string // Name of elements and attributes, or "" for other name classes; define of refs; datatype of data and values; href of externalRef
+	namespace     string // Namespace of elements and attributes
+	value         string // Value of value patterns
+	params        map[string]string
+	documentation string
+	children      []rngPattern
+}
+
+// RELAX NG grammar: the start pattern and the named patterns it references
+type rngGrammar struct {
+	start   []rngPattern
+	defines map[string][]rngPattern // Patterns combined into each define, by name
+	order   []string                // Names of the defines in document order
+	combine map[string]string       // choice or interleave, for defines declared more than once
+}
+
+// Function to parse a RELAX NG schema read from r with the default settings. The XML syntax
+// is expected; use ParseRelaxNGFile for the compact syntax.
+func ParseRelaxNG(r io.Reader) (Schema, error) {
+	return Parser{}.ParseRelaxNG(r)
+}
+
+// Function to parse the RELAX NG file, or HTTP(S) URL, at location with the default settings
+func ParseRelaxNGFile(location string) (Schema, error) {
+	return Parser{}.ParseRelaxNGFile(location)
+}
+
+// Function to parse a RELAX NG schema in the XML syntax read from r into a schema, as if its
+// patterns had been written in XSD. Relative hrefs of includes are resolved against the
+// working directory.
+func (parser Parser) ParseRelaxNG(r io.Reader) (Schema, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Schema{}, fmt.Errorf("failed to read RELAX NG schema: %w", err)
+	}
+	return parser.parseRelaxNG(data, "")
+}
+
+// Function to parse the RELAX NG file, or HTTP(S) URL, at location into a schema. Files
+// with the .rnc extension are read in the compact syntax, others in the XML syntax.
+func (parser Parser) ParseRelaxNGFile(location string) (Schema, error) {
+	data, err := parser.Fetcher.Fetch(location)
+	if err != nil {
+		return Schema{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	return parser.parseRelaxNG(data, location)
+}
+
+// Function to parse the content of a RELAX NG schema loaded from location. Every define
+// becomes a model group holding its elements and an attribute group holding its attributes,
+// both referenced where the define is, and every element an element with an anonymous
+// complexType. The elements of the start pattern are the global elements.
+func (parser Parser) parseRelaxNG(data []byte, location string) (Schema, error) {
+	builder := &rngBuilder{parser: parser, loaded: map[string]bool{location: true}}
+	grammar, err := builder.load(data, location)
+	if err != nil {
+		return Schema{}, err
+	}
+	if len(grammar.start) == 0 {
+		return Schema{}, errors.New("RELAX NG grammar has no start pattern")
+	}
+	schema := builder.schema(grammar)
+
+	resolved, err := parser.Resolve(*schema, location)
+	if err != nil {
+		return Schema{}, err
+	}
+	resolved.Warnings = append(resolved.Warnings, builder.warnings...)
+	resolved.Unsupported = append(resolved.Unsupported, builder.warnings...)
+	return resolved, nil
+}
+
+// State of the translation of a RELAX NG grammar into schema declarations
+type rngBuilder struct {
+	parser   Parser
+	grammar  rngGrammar
+	loaded   map[string]bool // Locations of the documents included so far
+	warnings []string        // Patterns skipped, which the schema does not cover
+}
+
+// Function to parse a RELAX NG document in the syntax matching its location, along with
+// the documents it includes
+func (builder *rngBuilder) load(data []byte, location string) (rngGrammar, error) {
+	if strings.HasSuffix(strings.ToLower(location), ".rnc") {
+		text, err := decodeText(data)
+		if err != nil {
+			return rngGrammar{}, fmt.Errorf("failed to decode %s: %w", location, err)
+		}
+		return builder.parseCompact(string(text), location)
+	}
+	node, err := decodeRNGNode(data)
+	if err != nil {
+		return rngGrammar{}, err
+	}
+	if node.XMLName.Space != RelaxNGNamespace {
+		return rngGrammar{}, fmt.Errorf("root element %s is not a RELAX NG pattern", node.XMLName.Local)
+	}
+	reader := rngReader{builder: builder, location: location}
+	return reader.grammar(node, rngContext{})
+}
+
+// Function to add a warning about a pattern that was skipped, unless it was given already
+func (builder *rngBuilder) warnSkipped(format string, args ...any) {
+	warning := fmt.Sprintf(format, args...)
+	if !slices.Contains(builder.warnings, warning) {
+		builder.warnings = append(builder.warnings, warning)
+	}
+}
+
+// Function to load the grammar of an included document, relative to the including one
+func (builder *rngBuilder) include(href, base string) (rngGrammar, error) {
+	location, found := builder.parser.Catalog.Resolve(href)
+	if !found {
+		resolved, err := resolveSchemaLocation(base, href)
+		if err != nil {
+			return rngGrammar{}, err
+		}
+		location = resolved
+	}
+	if builder.loaded[location] {
+		return rngGrammar{defines: make(map[string][]rngPattern)}, nil
+	}
+	builder.loaded[location] = true
+	data, err := builder.parser.Fetcher.Fetch(location)
+	if err != nil {
+		return rngGrammar{}, &ImportError{SchemaLocation: href, Err: err}
+	}
+	grammar, err := builder.load(data, location)
+	if err != nil {
+		return rngGrammar{}, &ImportError{SchemaLocation: href, Err: err}
+	}
+	return grammar, nil
+}
+
+// Function to merge an included grammar into the including one. The defines and start the
+// include overrides are left out of the included grammar.
+func (grammar *rngGrammar) merge(included rngGrammar, overrides rngGrammar) {
+	if len(overrides.start) == 0 {
+		grammar.start = append(grammar.start, included.start...)
+	}
+	for _, name := range included.order {
+		if _, overridden := overrides.defines[name]; !overridden {
+			grammar.define(name, included.combine[name], included.defines[name]...)
+		}
+	}
+	grammar.start = append(grammar.start, overrides.start...)
+	for _, name := range overrides.order {
+		grammar.define(name, overrides.combine[name], overrides.defines[name]...)
+	}
+}
+
+// Function to add patterns to a define, combining them with the patterns defined already
+func (grammar *rngGrammar) define(name, combine string, patterns ...rngPattern) {
+	if grammar.defines == nil {
+		grammar.defines, grammar.combine = make(map[string][]rngPattern), make(map[string]string)
+	}
+	if _, defined := grammar.defines[name]; !defined {
+		grammar.order = append(grammar.order, name)
+	}
+	grammar.defines[name] = append(grammar.defines[name], patterns...)
+	if combine != "" {
+		grammar.combine[name] = combine
+	}
+}
+
+// Element of a RELAX NG document in the XML syntax, decoded generically
+type rngNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []rngNode  `xml:",any"`
+	Text     string     `xml:",chardata"`
+}
+
+// Helper function to decode a RELAX NG document in the XML syntax
+func decodeRNGNode(data []byte) (rngNode, error) {
+	var node rngNode
+	if err := NewDecoder(bytes.NewReader(data)).Decode(&node); err != nil {
+		return rngNode{}, fmt.Errorf("failed to decode RELAX NG schema: %w", err)
+	}
+	return node, nil
+}
+
+// Helper function to get the value of an unqualified attribute of a node, trimmed
+func (node rngNode) attr(name string) string {
+	for _, attr := range node.Attrs {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return strings.TrimSpace(attr.Value)
+		}
+	}
+	return ""
+}
+
+// Inherited attributes of the XML syntax
+type rngContext struct {
+	datatypeLibrary string
+	namespace       string
+}
+
+// Helper function to apply the ns and datatypeLibrary attributes of a node to the context
+func (context rngContext) of(node rngNode) rngContext {
+	for _, attr := range node.Attrs {
+		switch {
+		case attr.Name.Space != "":
+		case attr.Name.Local == "ns":
+			context.namespace = attr.Value
+		case attr.Name.Local == "datatypeLibrary":
+			context.datatypeLibrary = attr.Value
+		}
+	}
+	return context
+}
+
+// Reader of the RELAX NG XML syntax
+type rngReader struct {
+	builder  *rngBuilder
+	location string
+}
+
+// Function to read the grammar of a document whose root is node. A root pattern other than
+// a grammar is its start pattern.
+func (reader rngReader) grammar(node rngNode, context rngContext) (rngGrammar, error) {
+	context = context.of(node)
+	grammar := rngGrammar{defines: make(map[string][]rngPattern), combine: make(map[string]string)}
+	if node.XMLName.Local != "grammar" {
+		pattern, err := reader.pattern(node, context)
+		if err != nil {
+			return rngGrammar{}, err
+		}
+		grammar.start = []rngPattern{pattern}
+		return grammar, nil
+	}
+	if err := reader.grammarContent(&grammar, node.Children, context); err != nil {
+		return rngGrammar{}, err
+	}
+	return grammar, nil
+}
+
+// Function to read the start, defines, divs and includes of a grammar into grammar
+func (reader rngReader) grammarContent(grammar *rngGrammar, nodes []rngNode, context rngContext) error {
+	for _, node := range nodes {
+		if node.XMLName.Space != RelaxNGNamespace {
+			continue
+		}
+		nodeContext := context.of(node)
+		switch node.XMLName.Local {
+		case "start":
+			patterns, err := reader.patterns(node.Children, nodeContext)
+			if err != nil {
+				return err
+			}
+			grammar.start = append(grammar.start, patterns...)
+		case "define":
+			patterns, err := reader.patterns(node.Children, nodeContext)
+			if err != nil {
+				return err
+			}
+			grammar.define(node.attr("name"), node.attr("combine"), group(patterns))
+		case "div":
+			if err := reader.grammarContent(grammar, node.Children, nodeContext); err != nil {
+				return err
+			}
+		case "include":
+			included, err := reader.builder.include(node.attr("href"), reader.location)
+			if err != nil {
+				return err
+			}
+			var overrides rngGrammar
+			if err := reader.grammarContent(&overrides, node.Children, nodeContext); err != nil {
+				return err
+			}
+			grammar.merge(included, overrides)
+		default:
+			return fmt.Errorf("unexpected %s in RELAX NG grammar", node.XMLName.Local)
+		}
+	}
+	return nil
+}
+
+// Function to read the patterns among nodes, skipping annotations
+func (reader rngReader) patterns(nodes []rngNode, context rngContext) ([]rngPattern, error) {
+	var patterns []rngPattern
+	for _, node := range nodes {
+		if node.XMLName.Space != RelaxNGNamespace {
+			continue
+		}
+		pattern, err := reader.pattern(node, context)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// Function to read a pattern of the XML syntax
+func (reader rngReader) pattern(node rngNode, context rngContext) (rngPattern, error) {
+	context = context.of(node)
+	pattern := rngPattern{kind: node.XMLName.Local}
+	children := node.Children
+	switch pattern.kind {
+	case "element", "attribute":
+		pattern.name = node.attr("name")
+		// Attributes are unqualified unless ns is set on them
+		if pattern.kind == "element" || slices.ContainsFunc(node.Attrs, func(attr xml.Attr) bool { return attr.Name.Local == "ns" && attr.Name.Space == "" }) {
+			pattern.namespace = context.namespace
+		}
+		if pattern.name == "" {
+			// The first child is the name class
+			i := slices.IndexFunc(children, func(child rngNode) bool { return child.XMLName.Space == RelaxNGNamespace })
+			if i < 0 || !slices.Contains([]string{"name", "anyName", "nsName", "choice"}, children[i].XMLName.Local) {
+				return rngPattern{}, fmt.Errorf("%s without a name", pattern.kind)
+			}
+			if children[i].XMLName.Local == "name" {
+				pattern.name = strings.TrimSpace(children[i].Text)
+			}
+			children = children[i+1:]
+		}
+		_, pattern.name, _ = cutPrefix(pattern.name)
+		for _, child := range node.Children {
+			if child.XMLName.Space == relaxNGAnnotations && child.XMLName.Local == "documentation" {
+				pattern.documentation = strings.TrimSpace(child.Text)
+			}
+		}
+		if pattern.kind == "attribute" && len(children) == 0 {
+			pattern.children = []rngPattern{{kind: "text"}}
+			return pattern, nil
+		}
+	case "ref", "parentRef":
+		pattern.name = node.attr("name")
+		return pattern, nil
+	case "externalRef":
+		pattern.name = node.attr("href")
+		return pattern, nil
+	case "data":
+		pattern.name = datatypeName(context.datatypeLibrary, node.attr("type"))
+		pattern.params = make(map[string]string)
+		for _, child := range children {
+			if child.XMLName.Space == RelaxNGNamespace && child.XMLName.Local == "param" {
+				pattern.params[child.attr("name")] = strings.TrimSpace(child.Text)
+			}
+		}
+		return pattern, nil
+	case "value":
+		// Values without a type are tokens of the built-in library
+		typeName := node.attr("type")
+		if typeName == "" {
+			typeName, context.datatypeLibrary = "token", ""
+		}
+		pattern.name, pattern.value = datatypeName(context.datatypeLibrary, typeName), node.Text
+		return pattern, nil
+	case "text", "empty", "notAllowed":
+		return pattern, nil
+	case "grammar":
+		return pattern, nil
+	case "group", "interleave", "choice", "optional", "zeroOrMore", "oneOrMore", "mixed", "list":
+	default:
+		return rngPattern{}, fmt.Errorf("unexpected %s in RELAX NG pattern", pattern.kind)
+	}
+
+	var err error
+	pattern.children, err = reader.patterns(children, context)
+	return pattern, err
+}
+
+// Helper function to get the XSD type of a RELAX NG datatype. The built-in library only
+// has string and token, and types of other libraries are taken as strings.
+func datatypeName(library, name string) string {
+	switch {
+	case library == xsdDatatypes:
+		return "xs:" + name
+	case library == "" && name == "token":
+		return "xs:token"
+	}
+	return "xs:string"
+}
+
+// Helper function to split a name into its prefix and local name
+func cutPrefix(name string) (string, string, bool) {
+	if prefix, local, found := strings.Cut(name, ":"); found {
+		return prefix, local, true
+	}
+	return "", name, false
+}
+
+// Helper function to group patterns, unless there is a single one
+func group(patterns []rngPattern) rngPattern {
+	if len(patterns) == 1 {
+		return patterns[0]
+	}
+	return rngPattern{kind: "group", children: patterns}
+}
+
+// Content translated from RELAX NG patterns
+type rngContent struct {
+	particles       []Particle
+	attributes      []Attribute
+	attributeGroups []GroupRef
+	unsupported     []Construct // Wildcards, such as attributes with any name
+	text            bool        // Whether the content has text, data or values
+	typeName        string      // XSD type of the data, if given
+	enumerations    []Facet     // Values the text is restricted to
+	maxLength       string
+	list            bool
+}
+
+// Function to build the schema declaring the elements of a grammar
+func (builder *rngBuilder) schema(grammar rngGrammar) *Schema {
+	builder.grammar = grammar
+	schema := &Schema{Namespaces: map[string]string{"xs": Namespace}}
+	for _, name := range grammar.order {
+		var content rngContent
+		builder.translate(&content, builder.defined(name), false, map[string]bool{})
+		compositor := builder.compositor("sequence", content)
+		schema.Groups = append(schema.Groups, Group{Name: name, Sequence: compositor})
+		schema.AttributeGroups = append(schema.AttributeGroups, AttributeGroup{Name: name, Attributes: content.attributes, AttributeGroups: content.attributeGroups})
+	}
+
+	var roots []rngPattern
+	for _, start := range grammar.start {
+		roots = append(roots, builder.startElements(start, map[string]bool{})...)
+	}
+	// Any element of a define may be chosen as the root
+	if root := builder.parser.Root; root != "" && !slices.ContainsFunc(roots, func(element rngPattern) bool { return element.name == root }) {
+		for _, name := range grammar.order {
+			if element, ok := findElement(builder.defined(name), root); ok {
+				roots = append(roots, element)
+				break
+			}
+		}
+	}
+	for _, root := range roots {
+		element := builder.element(root)
+		// Elements of the start pattern are global, so they are qualified with the target namespace
+		if schema.TargetNamespace == "" && root.namespace != "" {
+			schema.TargetNamespace, schema.ElementFormDefault = root.namespace, "qualified"
+			schema.Namespaces[""] = root.namespace
+		}
+		schema.Elements = append(schema.Elements, element)
+	}
+	return schema
+}
+
+// Function to get the pattern of a define, combining its patterns as declared
+func (builder *rngBuilder) defined(name string) rngPattern {
+	patterns := builder.grammar.defines[name]
+	if len(patterns) == 1 {
+		return patterns[0]
+	}
+	kind := builder.grammar.combine[name]
+	if kind == "" {
+		kind = "choice"
+	}
+	return rngPattern{kind: kind, children: patterns}
+}
+
+// Function to list the element patterns a start pattern allows as the document root
+func (builder *rngBuilder) startElements(pattern rngPattern, visited map[string]bool) []rngPattern {
+	switch pattern.kind {
+	case "element":
+		if pattern.name == "" {
+			builder.warnSkipped("RELAX NG root element with a name class other than a name is not supported and was skipped")
+			return nil
+		}
+		return []rngPattern{pattern}
+	case "ref":
+		if visited[pattern.name] {
+			return nil
+		}
+		visited[pattern.name] = true
+		return builder.startElements(builder.defined(pattern.name), visited)
+	}
+	var elements []rngPattern
+	for _, child := range pattern.children {
+		elements = append(elements, builder.startElements(child, visited)...)
+	}
+	return elements
+}
+
+// Helper function to find an element pattern by name, at any depth
+func findElement(pattern rngPattern, name string) (rngPattern, bool) {
+	if pattern.kind == "element" && pattern.name == name {
+		return pattern, true
+	}
+	for _, child := range pattern.children {
+		if element, ok := findElement(child, name); ok {
+			return element, true
+		}
+	}
+	return rngPattern{}, false
+}
+
+// Function to translate an element pattern into an element. Elements with text only are
+// leaves of the type of their data; their attributes make them a simpleContent extension
+// of the built-in type of the data, and their elements mixed content.
+func (builder *rngBuilder) element(pattern rngPattern) Element {
+	element := Element{Name: pattern.name}
+	if pattern.documentation != "" {
+		element.Annotation = &Annotation{Documentation: []string{pattern.documentation}}
+	}
+	var content rngContent
+	for _, child := range pattern.children {
+		builder.translate(&content, child, false, map[string]bool{})
+	}
+
+	if len(content.particles) == 0 && len(content.attributes) == 0 && len(content.attributeGroups) == 0 && len(content.unsupported) == 0 {
+		if !content.text {
+			element.ComplexType = &ComplexType{}
+			return element
+		}
+		element.Type, element.SimpleType = content.simpleType()
+		return element
+	}
+	if content.text && len(content.particles) == 0 {
+		// A simpleContent extension has a named base, so the facets of the data are dropped
+		typeName, simpleType := content.simpleType()
+		extension := &Derivation{Base: Element{Type: typeName, SimpleType: simpleType}.BaseType(), Attributes: content.attributes, AttributeGroups: content.attributeGroups, Unsupported: content.unsupported}
+		element.ComplexType = &ComplexType{SimpleContent: &SimpleContent{Extension: extension}}
+		return element
+	}
+	// Text next to elements is mixed content
+	element.ComplexType = &ComplexType{Mixed: content.text, Sequence: builder.compositor("sequence", content), Attributes: content.attributes, AttributeGroups: content.attributeGroups}
+	return element
+}
+
+// Function to translate an attribute pattern into an attribute. Attributes are required
+// unless they are optional or a choice branch.
+func (builder *rngBuilder) attribute(pattern rngPattern, optional bool) Attribute {
+	attribute := Attribute{Name: pattern.name}
+	if !optional {
+		attribute.Use = "required"
+	}
+	if pattern.documentation != "" {
+		attribute.Annotation = &Annotation{Documentation: []string{pattern.documentation}}
+	}
+	var content rngContent
+	for _, child := range pattern.children {
+		builder.translate(&content, child, false, map[string]bool{})
+	}
+	attribute.Type, attribute.SimpleType = content.simpleType()
+	return attribute
+}
+
+// Helper function to get the type, or anonymous simpleType, of text content
+func (content rngContent) simpleType() (string, *SimpleType) {
+	typeName := content.typeName
+	if typeName == "" {
+		typeName = "xs:string"
+	}
+	switch {
+	case content.list:
+		return "", &SimpleType{List: &List{ItemType: typeName}}
+	case len(content.enumerations) > 0 || content.maxLength != "":
+		restriction := Restriction{Base: typeName, Enumerations: content.enumerations}
+		if content.maxLength != "" {
+			restriction.MaxLength = &Facet{Value: content.maxLength}
+		}
+		return "", &SimpleType{Restriction: restriction}
+	}
+	return typeName, nil
+}
+
+// Function to translate a pattern into content. Particles nested in optional, repeated and
+// choice patterns become nested compositors with the matching occurrence constraints.
+// References to defines holding text only are inlined, and other ones become references to
+// the model group and attribute group of the define.
+func (builder *rngBuilder) translate(content *rngContent, pattern rngPattern, optional bool, inlining map[string]bool) {
+	switch pattern.kind {
+	case "element":
+		if pattern.name == "" {
+			content.particles = append(content.particles, Particle{Any: &Any{ProcessContents: "lax"}})
+			return
+		}
+		element := builder.element(pattern)
+		content.particles = append(content.particles, Particle{Element: &element})
+	case "attribute":
+		if pattern.name == "" {
+			content.unsupported = append(content.unsupported, Construct{XMLName: xml.Name{Space: Namespace, Local: "anyAttribute"}})
+			return
+		}
+		content.attributes = append(content.attributes, builder.attribute(pattern, optional))
+	case "ref":
+		if _, defined := builder.grammar.defines[pattern.name]; defined && !inlining[pattern.name] && builder.isText(builder.defined(pattern.name), map[string]bool{}) {
+			inlining[pattern.name] = true
+			builder.translate(content, builder.defined(pattern.name), optional, inlining)
+			delete(inlining, pattern.name)
+			return
+		}
+		content.particles = append(content.particles, Particle{GroupRef: &GroupRef{Ref: pattern.name}})
+		content.attributeGroups = append(content.attributeGroups, GroupRef{Ref: pattern.name})
+	case "text":
+		content.text = true
+	case "data":
+		content.text, content.typeName = true, pattern.name
+		if maxLength, ok := pattern.params["maxLength"]; ok {
+			content.maxLength = maxLength
+		}
+	case "value":
+		content.text = true
+		if content.typeName == "" {
+			content.typeName = pattern.name
+		}
+		content.enumerations = append(content.enumerations, Facet{Value: pattern.value})
+	case "list":
+		content.text, content.list = true, true
+		var item rngContent
+		for _, child := range pattern.children {
+			builder.translate(&item, child, false, inlining)
+		}
+		content.typeName = item.typeName
+	case "mixed":
+		content.text = true
+		for _, child := range pattern.children {
+			builder.translate(content, child, optional, inlining)
+		}
+	case "empty", "notAllowed":
+	case "group", "interleave", "choice", "optional", "zeroOrMore", "oneOrMore":
+		kind := map[string]string{"interleave": "all", "choice": "choice"}[pattern.kind]
+		if kind == "" {
+			kind = "sequence"
+		}
+		var nested rngContent
+		for _, child := range pattern.children {
+			builder.translate(&nested, child, optional || pattern.kind != "group" && pattern.kind != "interleave" && pattern.kind != "oneOrMore", inlining)
+		}
+		compositor := builder.compositor(kind, nested)
+		switch pattern.kind {
+		case "optional":
+			compositor.MinOccurs = "0"
+		case "zeroOrMore":
+			compositor.MinOccurs, compositor.MaxOccurs = "0", "unbounded"
+		case "oneOrMore":
+			compositor.MaxOccurs = "unbounded"
+		}
+		if len(compositor.Particles) > 0 || len(compositor.Unsupported) > 0 {
+			content.particles = append(content.particles, Particle{Group: compositor})
+		}
+		content.attributes = append(content.attributes, nested.attributes...)
+		content.attributeGroups = append(content.attributeGroups, nested.attributeGroups...)
+		content.text = content.text || nested.text
+		if nested.typeName != "" && content.typeName == "" {
+			content.typeName = nested.typeName
+		}
+		content.enumerations = append(content.enumerations, nested.enumerations...)
+		content.maxLength = cmp.Or(content.maxLength, nested.maxLength)
+		content.list = content.list || nested.list
+	default:
+		// Such as externalRef, parentRef and nested grammars
+		builder.warnSkipped("RELAX NG %s is not supported and was skipped", strings.TrimSpace(pattern.kind+" "+pattern.name))
+	}
+}
+
+// Function to check whether a pattern only holds text, following references to defines
+func (builder *rngBuilder) isText(pattern rngPattern, visited map[string]bool) bool {
+	switch pattern.kind {
+	case "text", "data", "value", "list":
+		return true
+	case "element", "attribute", "empty", "notAllowed", "externalRef", "parentRef", "grammar":
+		return false
+	case "ref":
+		if visited[pattern.name] {
+			return false
+		}
+		visited[pattern.name] = true
+		if _, defined := builder.grammar.defines[pattern.name]; !defined {
+			return false
+		}
+		return builder.isText(builder.defined(pattern.name), visited)
+	}
+	for _, child := range pattern.children {
+		if !builder.isText(child, visited) {
+			return false
+		}
+	}
+	return len(pattern.children) > 0
+}
+
+// Helper function to build a compositor of the given kind from translated content
+func (builder *rngBuilder) compositor(kind string, content rngContent) *Compositor {
+	return &Compositor{Kind: kind, Particles: content.particles, Unsupported: content.unsupported}
+}
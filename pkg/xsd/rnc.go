@@ -0,0 +1,619 @@
+package xsd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keywords of the RELAX NG compact syntax, which are identifiers only when escaped with \
+var rncKeywords = map[string]bool{
+	"attribute": true, "default": true, "datatypes": true, "div": true, "element": true,
+	"empty": true, "external": true, "grammar": true, "include": true, "inherit": true,
+	"list": true, "mixed": true, "namespace": true, "notAllowed": true, "parent": true,
+	"start": true, "string": true, "text": true, "token": true,
+}
+
+// Token of the RELAX NG compact syntax
+type rncToken struct {
+	kind          string // name, literal or punctuation
+	text          string // Name, value of the literal, or punctuation
+	keyword       bool   // Whether the name is an unescaped keyword
+	documentation string // Text of the ## comments preceding the token
+}
+
+// Parser of a document in the RELAX NG compact syntax
+type rncParser struct {
+	builder          *rngBuilder
+	location         string
+	tokens           []rncToken
+	pos              int
+	namespaces       map[string]string // Namespace prefixes
+	defaultNamespace string
+	datatypes        map[string]string // Datatype library prefixes
+}
+
+// Function to parse a grammar in the RELAX NG compact syntax, along with the documents it includes
+func (builder *rngBuilder) parseCompact(text, location string) (rngGrammar, error) {
+	tokens, err := rncTokens(text)
+	if err != nil {
+		return rngGrammar{}, err
+	}
+	parser := &rncParser{
+		builder:    builder,
+		location:   location,
+		tokens:     tokens,
+		namespaces: map[string]string{"xml": "http://www.w3.org/XML/1998/namespace"},
+		datatypes:  map[string]string{"xsd": xsdDatatypes},
+	}
+	if err := parser.declarations(); err != nil {
+		return rngGrammar{}, err
+	}
+
+	grammar := rngGrammar{defines: make(map[string][]rngPattern), combine: make(map[string]string)}
+	if !parser.atGrammarContent() {
+		pattern, err := parser.pattern()
+		if err != nil {
+			return rngGrammar{}, err
+		}
+		grammar.start = []rngPattern{pattern}
+	} else if err := parser.grammarContent(&grammar); err != nil {
+		return rngGrammar{}, err
+	}
+	if parser.pos < len(parser.tokens) {
+		return rngGrammar{}, parser.unexpected()
+	}
+	return grammar, nil
+}
+
+// Function to read the namespace and datatypes declarations opening a document
+func (parser *rncParser) declarations() error {
+	for {
+		switch {
+		case parser.isKeyword("namespace"):
+			parser.pos++
+			prefix, err := parser.expectName()
+			if err != nil {
+				return err
+			}
+			uri, err := parser.declaredLiteral()
+			if err != nil {
+				return err
+			}
+			parser.namespaces[prefix] = uri
+		case parser.isKeyword("default"):
+			parser.pos++
+			if !parser.isKeyword("namespace") {
+				return parser.unexpected()
+			}
+			parser.pos++
+			prefix := ""
+			if token := parser.peek(); token.kind == "name" {
+				prefix, parser.pos = token.text, parser.pos+1
+			}
+			uri, err := parser.declaredLiteral()
+			if err != nil {
+				return err
+			}
+			parser.defaultNamespace = uri
+			if prefix != "" {
+				parser.namespaces[prefix] = uri
+			}
+		case parser.isKeyword("datatypes"):
+			parser.pos++
+			prefix, err := parser.expectName()
+			if err != nil {
+				return err
+			}
+			uri, err := parser.declaredLiteral()
+			if err != nil {
+				return err
+			}
+			parser.datatypes[prefix] = uri
+		default:
+			return nil
+		}
+	}
+}
+
+// Helper function to read the = and literal of a declaration
+func (parser *rncParser) declaredLiteral() (string, error) {
+	if err := parser.expect("="); err != nil {
+		return "", err
+	}
+	return parser.literal()
+}
+
+// Function to check whether the tokens following the declarations are the content of a
+// grammar rather than a single pattern
+func (parser *rncParser) atGrammarContent() bool {
+	parser.skipAnnotations()
+	token := parser.peek()
+	switch {
+	case token.kind == "":
+		return true
+	case parser.isKeyword("start") || parser.isKeyword("div") || parser.isKeyword("include"):
+		return true
+	case token.kind == "name" && !token.keyword:
+		next := parser.peekAt(1)
+		return next.kind == "punctuation" && (next.text == "=" || next.text == "|=" || next.text == "&=" || next.text == "[")
+	}
+	return false
+}
+
+// Function to read the start, defines, divs and includes of a grammar, up to } or the end
+func (parser *rncParser) grammarContent(grammar *rngGrammar) error {
+	for {
+		parser.skipAnnotations()
+		token := parser.peek()
+		switch {
+		case token.kind == "" || token.text == "}" && token.kind == "punctuation":
+			return nil
+		case parser.isKeyword("start"):
+			parser.pos++
+			if _, err := parser.assignment(); err != nil {
+				return err
+			}
+			pattern, err := parser.pattern()
+			if err != nil {
+				return err
+			}
+			grammar.start = append(grammar.start, pattern)
+		case parser.isKeyword("div"):
+			parser.pos++
+			if err := parser.expect("{"); err != nil {
+				return err
+			}
+			if err := parser.grammarContent(grammar); err != nil {
+				return err
+			}
+			if err := parser.expect("}"); err != nil {
+				return err
+			}
+		case parser.isKeyword("include"):
+			parser.pos++
+			href, err := parser.literal()
+			if err != nil {
+				return err
+			}
+			if parser.isKeyword("inherit") {
+				parser.pos += 3 // inherit = prefix
+			}
+			var overrides rngGrammar
+			if parser.isPunctuation("{") {
+				parser.pos++
+				if err := parser.grammarContent(&overrides); err != nil {
+					return err
+				}
+				if err := parser.expect("}"); err != nil {
+					return err
+				}
+			}
+			included, err := parser.builder.include(href, parser.location)
+			if err != nil {
+				return err
+			}
+			grammar.merge(included, overrides)
+		case token.kind == "name" && strings.Contains(token.text, ":") && parser.peekAt(1).text == "[":
+			// Annotation elements between definitions
+			parser.pos++
+			parser.skipAnnotations()
+		case token.kind == "name" && !token.keyword:
+			parser.pos++
+			combine, err := parser.assignment()
+			if err != nil {
+				return err
+			}
+			pattern, err := parser.pattern()
+			if err != nil {
+				return err
+			}
+			grammar.define(token.text, combine, pattern)
+		default:
+			return parser.unexpected()
+		}
+	}
+}
+
+// Helper function to read the assignment operator of a definition, returning how it combines
+// with other definitions of the same name
+func (parser *rncParser) assignment() (string, error) {
+	parser.skipAnnotations()
+	token := parser.next()
+	switch {
+	case token.kind != "punctuation":
+	case token.text == "=":
+		return "", nil
+	case token.text == "|=":
+		return "choice", nil
+	case token.text == "&=":
+		return "interleave", nil
+	}
+	return "", fmt.Errorf("expected an assignment, found %q", token.text)
+}
+
+// Function to read a pattern: particles separated by the same operator, , for a group,
+// | for a choice or & for an interleave
+func (parser *rncParser) pattern() (rngPattern, error) {
+	first, err := parser.particle()
+	if err != nil {
+		return rngPattern{}, err
+	}
+	kinds := map[string]string{",": "group", "|": "choice", "&": "interleave"}
+	operator := parser.peek()
+	kind, ok := kinds[operator.text]
+	if operator.kind != "punctuation" || !ok {
+		return first, nil
+	}
+
+	pattern := rngPattern{kind: kind, children: []rngPattern{first}}
+	for parser.isPunctuation(operator.text) {
+		parser.pos++
+		particle, err := parser.particle()
+		if err != nil {
+			return rngPattern{}, err
+		}
+		pattern.children = append(pattern.children, particle)
+	}
+	if next := parser.peek(); next.kind == "punctuation" && kinds[next.text] != "" {
+		return rngPattern{}, fmt.Errorf("operators %s and %s mixed without parentheses", operator.text, next.text)
+	}
+	return pattern, nil
+}
+
+// Function to read a primary pattern with its occurrence operator, if any
+func (parser *rncParser) particle() (rngPattern, error) {
+	documentation := parser.peek().documentation
+	parser.skipAnnotations()
+	pattern, err := parser.primary()
+	if err != nil {
+		return rngPattern{}, err
+	}
+	if (pattern.kind == "element" || pattern.kind == "attribute") && documentation != "" {
+		pattern.documentation = documentation
+	}
+	// Following annotations are written as >> name [ ... ]
+	for parser.isPunctuation(">>") {
+		parser.pos += 2
+		parser.skipAnnotations()
+	}
+	if token := parser.peek(); token.kind == "punctuation" {
+		switch token.text {
+		case "?":
+			pattern = rngPattern{kind: "optional", children: []rngPattern{pattern}}
+		case "*":
+			pattern = rngPattern{kind: "zeroOrMore", children: []rngPattern{pattern}}
+		case "+":
+			pattern = rngPattern{kind: "oneOrMore", children: []rngPattern{pattern}}
+		default:
+			return pattern, nil
+		}
+		parser.pos++
+	}
+	return pattern, nil
+}
+
+// Function to read a primary pattern
+func (parser *rncParser) primary() (rngPattern, error) {
+	token := parser.next()
+	switch {
+	case token.kind == "literal":
+		return parser.value("xs:token", token.text)
+	case token.kind == "punctuation" && token.text == "(":
+		pattern, err := parser.pattern()
+		if err != nil {
+			return rngPattern{}, err
+		}
+		return pattern, parser.expect(")")
+	case token.kind != "name":
+		return rngPattern{}, fmt.Errorf("expected a pattern, found %q", token.text)
+	case !token.keyword && strings.Contains(token.text, ":"):
+		prefix, local, _ := cutPrefix(token.text)
+		library, ok := parser.datatypes[prefix]
+		if !ok {
+			return rngPattern{}, fmt.Errorf("undeclared datatypes prefix %s", prefix)
+		}
+		return parser.data(datatypeName(library, local))
+	case !token.keyword:
+		return rngPattern{kind: "ref", name: token.text}, nil
+	}
+
+	switch token.text {
+	case "element", "attribute":
+		pattern := rngPattern{kind: token.text}
+		var err error
+		if pattern.name, pattern.namespace, err = parser.nameClass(token.text == "attribute"); err != nil {
+			return rngPattern{}, err
+		}
+		content, err := parser.braced()
+		if err != nil {
+			return rngPattern{}, err
+		}
+		pattern.children = []rngPattern{content}
+		return pattern, nil
+	case "list", "mixed":
+		content, err := parser.braced()
+		return rngPattern{kind: token.text, children: []rngPattern{content}}, err
+	case "empty", "text", "notAllowed":
+		return rngPattern{kind: token.text}, nil
+	case "string", "token":
+		if parser.peek().kind == "literal" {
+			return parser.value("xs:"+token.text, parser.next().text)
+		}
+		return parser.data("xs:" + token.text)
+	case "parent":
+		name, err := parser.expectName()
+		return rngPattern{kind: "parentRef", name: name}, err
+	case "external":
+		href, err := parser.literal()
+		return rngPattern{kind: "externalRef", name: href}, err
+	case "grammar":
+		if err := parser.expect("{"); err != nil {
+			return rngPattern{}, err
+		}
+		if err := parser.grammarContent(&rngGrammar{}); err != nil {
+			return rngPattern{}, err
+		}
+		return rngPattern{kind: "grammar"}, parser.expect("}")
+	}
+	return rngPattern{}, fmt.Errorf("unexpected keyword %s in pattern", token.text)
+}
+
+// Function to read a value pattern of a datatype, with the literals concatenated with ~
+func (parser *rncParser) value(datatype, value string) (rngPattern, error) {
+	for parser.isPunctuation("~") {
+		parser.pos++
+		literal, err := parser.literal()
+		if err != nil {
+			return rngPattern{}, err
+		}
+		value += literal
+	}
+	return rngPattern{kind: "value", name: datatype, value: value}, nil
+}
+
+// Function to read a data pattern of a datatype, with its parameters or a following value
+func (parser *rncParser) data(datatype string) (rngPattern, error) {
+	if parser.peek().kind == "literal" {
+		return parser.value(datatype, parser.next().text)
+	}
+	pattern := rngPattern{kind: "data", name: datatype, params: make(map[string]string)}
+	if !parser.isPunctuation("{") {
+		return pattern, nil
+	}
+	parser.pos++
+	for !parser.isPunctuation("}") {
+		name, err := parser.expectName()
+		if err != nil {
+			return rngPattern{}, err
+		}
+		if pattern.params[name], err = parser.declaredLiteral(); err != nil {
+			return rngPattern{}, err
+		}
+	}
+	parser.pos++
+	return pattern, nil
+}
+
+// Function to read the name class of an element or attribute, returning its local name and
+// namespace. Name classes other than a single name, such as * or ns:*, have no name.
+// Unprefixed attribute names are unqualified.
+func (parser *rncParser) nameClass(attribute bool) (string, string, error) {
+	name, namespace, err := parser.simpleNameClass(attribute)
+	if err != nil {
+		return "", "", err
+	}
+	for parser.isPunctuation("|") || parser.isPunctuation("-") {
+		parser.pos++
+		if _, _, err := parser.simpleNameClass(attribute); err != nil {
+			return "", "", err
+		}
+		name, namespace = "", ""
+	}
+	return name, namespace, nil
+}
+
+// Helper function to read a name, wildcard or parenthesized name class
+func (parser *rncParser) simpleNameClass(attribute bool) (string, string, error) {
+	token := parser.next()
+	switch {
+	case token.kind == "punctuation" && token.text == "*":
+		return "", "", nil
+	case token.kind == "punctuation" && token.text == "(":
+		if _, _, err := parser.nameClass(attribute); err != nil {
+			return "", "", err
+		}
+		return "", "", parser.expect(")")
+	case token.kind != "name":
+		return "", "", fmt.Errorf("expected a name, found %q", token.text)
+	case strings.HasSuffix(token.text, ":*"):
+		return "", "", nil
+	}
+	prefix, local, prefixed := cutPrefix(token.text)
+	switch {
+	case prefixed:
+		namespace, ok := parser.namespaces[prefix]
+		if !ok {
+			return "", "", fmt.Errorf("undeclared namespace prefix %s", prefix)
+		}
+		return local, namespace, nil
+	case attribute:
+		return local, "", nil
+	}
+	return local, parser.defaultNamespace, nil
+}
+
+// Helper function to read a pattern enclosed in braces
+func (parser *rncParser) braced() (rngPattern, error) {
+	if err := parser.expect("{"); err != nil {
+		return rngPattern{}, err
+	}
+	pattern, err := parser.pattern()
+	if err != nil {
+		return rngPattern{}, err
+	}
+	return pattern, parser.expect("}")
+}
+
+// Helper function to skip annotations, written in brackets
+func (parser *rncParser) skipAnnotations() {
+	for parser.isPunctuation("[") {
+		depth := 0
+		for parser.pos < len(parser.tokens) {
+			token := parser.next()
+			if token.kind == "punctuation" && token.text == "[" {
+				depth++
+			} else if token.kind == "punctuation" && token.text == "]" {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+	}
+}
+
+// Helper function to get the next token without consuming it, or a token without kind at the end
+func (parser *rncParser) peek() rncToken {
+	return parser.peekAt(0)
+}
+
+// Helper function to get the token offset tokens ahead without consuming it
+func (parser *rncParser) peekAt(offset int) rncToken {
+	if parser.pos+offset >= len(parser.tokens) {
+		return rncToken{}
+	}
+	return parser.tokens[parser.pos+offset]
+}
+
+// Helper function to consume the next token
+func (parser *rncParser) next() rncToken {
+	token := parser.peek()
+	if parser.pos < len(parser.tokens) {
+		parser.pos++
+	}
+	return token
+}
+
+// Helper function to check whether the next token is the given keyword
+func (parser *rncParser) isKeyword(keyword string) bool {
+	token := parser.peek()
+	return token.kind == "name" && token.keyword && token.text == keyword
+}
+
+// Helper function to check whether the next token is the given punctuation
+func (parser *rncParser) isPunctuation(punctuation string) bool {
+	token := parser.peek()
+	return token.kind == "punctuation" && token.text == punctuation
+}
+
+// Helper function to consume the given punctuation
+func (parser *rncParser) expect(punctuation string) error {
+	if !parser.isPunctuation(punctuation) {
+		return fmt.Errorf("expected %q, found %q", punctuation, parser.peek().text)
+	}
+	parser.pos++
+	return nil
+}
+
+// Helper function to consume a name
+func (parser *rncParser) expectName() (string, error) {
+	token := parser.next()
+	if token.kind != "name" {
+		return "", fmt.Errorf("expected a name, found %q", token.text)
+	}
+	return token.text, nil
+}
+
+// Helper function to consume a literal, with the literals concatenated to it with ~
+func (parser *rncParser) literal() (string, error) {
+	token := parser.next()
+	if token.kind != "literal" {
+		return "", fmt.Errorf("expected a literal, found %q", token.text)
+	}
+	value, err := parser.value("", token.text)
+	return value.value, err
+}
+
+// Helper function to report the next token as unexpected
+func (parser *rncParser) unexpected() error {
+	return fmt.Errorf("unexpected %q", parser.peek().text)
+}
+
+// Function to split a document in the RELAX NG compact syntax into tokens. Comments are
+// skipped, and documentation comments starting with ## are attached to the next token.
+func rncTokens(text string) ([]rncToken, error) {
+	var tokens []rncToken
+	var documentation []string
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case strings.IndexByte(" \t\r\n", c) >= 0:
+			i++
+		case c == '#':
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				end = len(text) - i
+			}
+			if line := text[i : i+end]; strings.HasPrefix(line, "##") {
+				documentation = append(documentation, strings.TrimSpace(strings.TrimLeft(line, "#")))
+			}
+			i += end
+		case c == '"' || c == '\'':
+			quote := text[i : i+1]
+			if strings.HasPrefix(text[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+			}
+			end := strings.Index(text[i+len(quote):], quote)
+			if end < 0 || (len(quote) == 1 && strings.Contains(text[i+1:i+1+end], "\n")) {
+				return nil, fmt.Errorf("unterminated literal %q", firstLine(text[i:]))
+			}
+			tokens = append(tokens, rncToken{kind: "literal", text: text[i+len(quote) : i+len(quote)+end], documentation: strings.Join(documentation, " ")})
+			documentation = nil
+			i += len(quote)*2 + end
+		case isRNCNameChar(c, true) || c == '\\':
+			start := i
+			if c == '\\' {
+				i++
+			}
+			end := i
+			for end < len(text) && (isRNCNameChar(text[end], false) || text[end] == ':' && end+1 < len(text) && (isRNCNameChar(text[end+1], true) || text[end+1] == '*')) {
+				end++
+				if text[end-1] == ':' && text[end] == '*' {
+					end++
+					break
+				}
+			}
+			name := text[i:end]
+			if name == "" {
+				return nil, fmt.Errorf("invalid escape in %q", firstLine(text[start:]))
+			}
+			tokens = append(tokens, rncToken{kind: "name", text: name, keyword: c != '\\' && rncKeywords[name], documentation: strings.Join(documentation, " ")})
+			documentation = nil
+			i = end
+		default:
+			punctuation := text[i : i+1]
+			for _, operator := range []string{"|=", "&=", ">>"} {
+				if strings.HasPrefix(text[i:], operator) {
+					punctuation = operator
+				}
+			}
+			if !strings.Contains("={}()[],|&?*+-~", punctuation[:1]) && punctuation != ">>" {
+				return nil, fmt.Errorf("unexpected character %q", punctuation)
+			}
+			tokens = append(tokens, rncToken{kind: "punctuation", text: punctuation, documentation: strings.Join(documentation, " ")})
+			documentation = nil
+			i += len(punctuation)
+		}
+	}
+	return tokens, nil
+}
+
+// Helper function to check whether a byte may be part of a name, or start one
+func isRNCNameChar(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c >= 0x80:
+		return true
+	case first:
+		return false
+	}
+	return c >= '0' && c <= '9' || c == '-' || c == '.'
+}
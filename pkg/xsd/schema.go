@@ -0,0 +1,683 @@
+// Package xsd parses XML Schema (XSD) and WSDL documents into a resolved element tree,
+// with type references, includes and imports expanded.
+package xsd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Namespace of the XML Schema built-in types
+const Namespace = "http://www.w3.org/2001/XMLSchema"
+
+// Namespace of the xsi:type attribute selecting the type of a polymorphic element
+const InstanceNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// Name of the child elements standing for the xs:any wildcards of a content model, which
+// no declared element can have
+const WildcardName = "#any"
+
+// Schema structure to hold parsed data. After parsing, Elements holds the global elements
+// with their children and attributes resolved.
+type Schema struct {
+	TargetNamespace    string            `xml:"targetNamespace,attr"`
+	ElementFormDefault string            `xml:"elementFormDefault,attr"`
+	Attrs              []xml.Attr        `xml:",any,attr"`
+	Namespaces         map[string]string `xml:"-"` // Prefix to namespace URI, "" for the default namespace
+	Elements           []Element         `xml:"element"`
+	ComplexTypes       []ComplexType     `xml:"complexType"`
+	SimpleTypes        []SimpleType      `xml:"simpleType"`
+	Groups             []Group           `xml:"group"`
+	AttributeGroups    []AttributeGroup  `xml:"attributeGroup"`
+	Includes           []SchemaRef       `xml:"include"`
+	Imports            []SchemaRef       `xml:"import"`
+	Redefines          []Redefine        `xml:"redefine"`           // Documents included with some of their definitions replaced
+	Overrides          []Redefine        `xml:"override"`           // XSD 1.1 documents included with some of their definitions replaced
+	DefaultOpenContent *Construct        `xml:"defaultOpenContent"` // XSD 1.1 wildcard content of every complexType, which is skipped
+	Warnings           []string          `xml:"-"`                  // Problems found while resolving, such as truncated recursive types
+	Unsupported        []string          `xml:"-"`                  // The warnings about constructs that were skipped, such as xs:anyAttribute
+	Unresolved         []string          `xml:"-"`                  // Names of the types and groups referenced but declared in none of the loaded documents
+	NamespacePrefix    string            `xml:"-"`                  // Prefix qualifying the elements of the target namespace in documents, "" to declare it as the default namespace
+	Unqualified        bool              `xml:"-"`                  // Set to write documents without namespaces, for legacy endpoints
+}
+
+// SchemaRef holds an xs:include or xs:import declaration
+type SchemaRef struct {
+	Namespace      string `xml:"namespace,attr"`
+	SchemaLocation string `xml:"schemaLocation,attr"`
+}
+
+// Function to resolve a qualified name against the schema's namespace declarations
+func (schema Schema) ResolveQName(qname string) (namespace, local string) {
+	prefix := ""
+	local = qname
+	if i := strings.Index(qname, ":"); i >= 0 {
+		prefix, local = qname[:i], qname[i+1:]
+	}
+	return schema.Namespaces[prefix], local
+}
+
+// Function to find the prefix declared for a namespace, or "" if there is none
+func (schema Schema) PrefixFor(namespace string) string {
+	prefixes := make([]string, 0, len(schema.Namespaces))
+	for prefix, uri := range schema.Namespaces {
+		if uri == namespace && prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if len(prefixes) == 0 {
+		return ""
+	}
+	sort.Strings(prefixes)
+	return prefixes[0]
+}
+
+// Function to get the tag name of the document root and the xmlns declaration for the
+// target namespace. Qualified schemas declare it as the default namespace; otherwise only
+// the root is prefixed so that local elements stay unqualified.
+func (schema Schema) RootTag() (string, string) {
+	name := schema.Elements[0].Name
+	if schema.TargetNamespace == "" {
+		return name, ""
+	}
+	if schema.ElementFormDefault == "qualified" {
+		return name, " xmlns=\"" + schema.TargetNamespace + "\""
+	}
+	prefix := schema.PrefixFor(schema.TargetNamespace)
+	if prefix == "" {
+		prefix = "tns"
+	}
+	return prefix + ":" + name, " xmlns:" + prefix + "=\"" + schema.TargetNamespace + "\""
+}
+
+// Function to narrow the global elements of a schema down to the document root.
+// Without a name, the schema must declare exactly one global element.
+func (schema Schema) SelectRoot(name string) (Schema, error) {
+	var candidates []string
+	for _, element := range schema.Elements {
+		if element.Name == name {
+			schema.Elements = []Element{element}
+			return schema, nil
+		}
+		candidates = append(candidates, element.Name)
+	}
+
+	switch {
+	case name != "":
+		return Schema{}, fmt.Errorf("root element %q not found, candidates are: %s", name, strings.Join(candidates, ", "))
+	case len(candidates) > 1:
+		return Schema{}, fmt.Errorf("schema declares multiple global elements, choose one of: %s", strings.Join(candidates, ", "))
+	}
+	return schema, nil
+}
+
+// Element holds an xs:element declaration
+type Element struct {
+	Name              string               `xml:"name,attr"`
+	Type              string               `xml:"type,attr"`
+	Ref               string               `xml:"ref,attr"`
+	Form              string               `xml:"form,attr"` // qualified or unqualified, elementFormDefault of the document by default
+	Default           string               `xml:"default,attr"`
+	Fixed             string               `xml:"fixed,attr"`
+	MinOccurs         string               `xml:"minOccurs,attr"`
+	MaxOccurs         string               `xml:"maxOccurs,attr"`
+	SubstitutionGroup string               `xml:"substitutionGroup,attr"` // Head of the substitution group of a global element
+	Abstract          bool                 `xml:"abstract,attr"`          // Set on global elements that must be substituted
+	Nillable          bool                 `xml:"nillable,attr"`          // Set on elements that may be written as xsi:nil
+	SimpleType        *SimpleType          `xml:"simpleType"`
+	ComplexType       *ComplexType         `xml:"complexType"`
+	Annotation        *Annotation          `xml:"annotation"`
+	TypeAlternatives  []TypeAlternative    `xml:"alternative"` // XSD 1.1 conditional type assignment, noted in the hint
+	Keys              []IdentityConstraint `xml:"key"`         // Identity constraints, noted in the hints of the fields they involve
+	KeyRefs           []IdentityConstraint `xml:"keyref"`      // Identity constraints, noted in the hints of the fields they involve
+	Uniques           []IdentityConstraint `xml:"unique"`      // Identity constraints, noted in the hints of the fields they involve
+	Unsupported       []Construct          `xml:",any"`        // Unknown content, which is skipped
+	Children          []Element            `xml:"-"`           // Populated from the inline or referenced complexType
+	Attributes        []Attribute          `xml:"-"`           // Populated from the inline or referenced complexType
+	ChoiceItem        bool                 `xml:"-"`           // Set when the element is one of the branches of an xs:choice
+	Truncated         bool                 `xml:"-"`           // Set when the expansion of a recursive type stopped at this element
+	Alternatives      []Element            `xml:"-"`           // Concrete types usable through xsi:type when the type is abstract, each named after its type
+	Skipped           []string             `xml:"-"`           // Local names of the unsupported constructs skipped in the content, such as any
+	Assertions        []string             `xml:"-"`           // Tests of the XSD 1.1 assertions of its type, which are not checked
+	Constraints       []string             `xml:"-"`           // Descriptions of the identity constraints its value takes part in, which are not checked
+	Namespace         string               `xml:"-"`           // Namespace of the element name once resolved, "" for unqualified local elements
+	Text              *Element             `xml:"-"`           // Text of an element with attributes and simpleContent, as a leaf of the type it extends, or the xs:string text of mixed content
+}
+
+// TypeAlternative holds an XSD 1.1 xs:alternative, which assigns a type to an element when
+// its test holds. The alternative without test applies otherwise.
+type TypeAlternative struct {
+	Test string `xml:"test,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// Assertion holds an XSD 1.1 xs:assert of a complexType or xs:assertion of a simpleType
+type Assertion struct {
+	Test string `xml:"test,attr"`
+}
+
+// Helper function to check whether an element may be omitted (minOccurs="0" or a choice branch)
+// or left without a value (nillable)
+func (element Element) IsOptional() bool {
+	return element.MinOccurs == "0" || element.ChoiceItem || element.Nillable
+}
+
+// Helper function to check whether an element is written as xsi:nil when its field has no
+// value. Repeating elements and choice branches are left out instead.
+func (element Element) NilWhenAbsent() bool {
+	return element.Nillable && !element.IsRepeating() && !element.ChoiceItem && !element.IsFixed()
+}
+
+// Helper function to check whether an element may occur more than once
+func (element Element) IsRepeating() bool {
+	if element.MaxOccurs == "unbounded" {
+		return true
+	}
+	maxOccurs, err := strconv.Atoi(element.MaxOccurs)
+	return err == nil && maxOccurs > 1
+}
+
+// Helper function to check whether an element has no children nor attributes. Truncated
+// and polymorphic elements are not leaves, even though they have no content of their own.
+func (element Element) IsLeaf() bool {
+	return len(element.Children) == 0 && len(element.Attributes) == 0 && !element.Truncated && len(element.Alternatives) == 0
+}
+
+// Helper function to get the XSD type of an element, following simpleType restrictions.
+// Lists and unions are written as strings.
+func (element Element) BaseType() string {
+	if element.IsList() || len(element.UnionMembers()) > 0 {
+		return "xs:string"
+	}
+	if element.SimpleType != nil && element.SimpleType.Restriction.Base != "" {
+		return element.SimpleType.Restriction.Base
+	}
+	return element.Type
+}
+
+// Helper function to check whether an element stands for an xs:any wildcard
+func (element Element) IsWildcard() bool {
+	return element.Name == WildcardName
+}
+
+// Helper function to check whether a leaf element holds XML content that is passed through
+// as is: an xs:any wildcard or an element of type xs:anyType
+func (element Element) IsPassThrough() bool {
+	return element.Type == "xs:anyType" && element.IsLeaf()
+}
+
+// Helper function to check whether an element has mixed content, text between its children
+func (element Element) IsMixed() bool {
+	return element.Text != nil && len(element.Children) > 0
+}
+
+// Helper function to check whether a leaf element has a fixed value, leaving nothing to populate
+func (element Element) IsFixed() bool {
+	return element.Fixed != "" && element.IsLeaf()
+}
+
+// Helper function to check whether an element holds an xs:list of values
+func (element Element) IsList() bool {
+	return element.SimpleType != nil && element.SimpleType.List != nil
+}
+
+// Helper function to describe the items of an xs:list element as an element of their own
+func (element Element) ListItem() Element {
+	list := element.SimpleType.List
+	return Element{Name: element.Name, Type: list.ItemType, SimpleType: list.SimpleType}
+}
+
+// Helper function to get the member types of an xs:union element. Anonymous members are
+// given by their base type.
+func (element Element) UnionMembers() []string {
+	if element.SimpleType == nil || element.SimpleType.Union == nil {
+		return nil
+	}
+	members := strings.Fields(element.SimpleType.Union.MemberTypes)
+	for _, simpleType := range element.SimpleType.Union.SimpleTypes {
+		members = append(members, simpleType.Restriction.Base)
+	}
+	return members
+}
+
+// Helper function to get the maxLength facet of an element, or its length facet, or 0 if
+// there is none
+func (element Element) MaxLength() int {
+	if element.SimpleType == nil {
+		return 0
+	}
+	if restriction := element.SimpleType.Restriction; restriction.Length != nil {
+		return restriction.Length.count()
+	}
+	return element.SimpleType.Restriction.MaxLength.count()
+}
+
+// Helper function to get the minLength facet of an element, or its length facet, or 0 if
+// there is none
+func (element Element) MinLength() int {
+	if element.SimpleType == nil {
+		return 0
+	}
+	if restriction := element.SimpleType.Restriction; restriction.Length != nil {
+		return restriction.Length.count()
+	}
+	return element.SimpleType.Restriction.MinLength.count()
+}
+
+// Helper function to get the totalDigits and fractionDigits facets of an element, each 0
+// if there is none
+func (element Element) Digits() (total, fraction int) {
+	if element.SimpleType == nil {
+		return 0, 0
+	}
+	return element.SimpleType.Restriction.TotalDigits.count(), element.SimpleType.Restriction.FractionDigits.count()
+}
+
+// Bound is a lower or upper limit of the values of an element, from its minInclusive,
+// minExclusive, maxInclusive or maxExclusive facet
+type Bound struct {
+	Value     string // Limit in the lexical form of the type, "" if there is none
+	Exclusive bool   // Whether the limit itself is not allowed
+}
+
+// Helper function to get the lower and upper bounds of the values of an element, whose
+// Value is "" if there is none
+func (element Element) Bounds() (lower, upper Bound) {
+	if element.SimpleType == nil {
+		return Bound{}, Bound{}
+	}
+	restriction := element.SimpleType.Restriction
+	if restriction.MinExclusive != nil {
+		lower = Bound{Value: restriction.MinExclusive.Value, Exclusive: true}
+	} else if restriction.MinInclusive != nil {
+		lower = Bound{Value: restriction.MinInclusive.Value}
+	}
+	if restriction.MaxExclusive != nil {
+		upper = Bound{Value: restriction.MaxExclusive.Value, Exclusive: true}
+	} else if restriction.MaxInclusive != nil {
+		upper = Bound{Value: restriction.MaxInclusive.Value}
+	}
+	return lower, upper
+}
+
+// Helper function to get the regular expressions an element restricted by xs:pattern must match
+func (element Element) Patterns() []string {
+	if element.SimpleType == nil {
+		return nil
+	}
+	var patterns []string
+	for _, pattern := range element.SimpleType.Restriction.Patterns {
+		patterns = append(patterns, pattern.Value)
+	}
+	return patterns
+}
+
+// Helper function to get the allowed values of an element restricted by xs:enumeration
+func (element Element) Enumerations() []string {
+	if element.SimpleType == nil {
+		return nil
+	}
+	var values []string
+	for _, enumeration := range element.SimpleType.Restriction.Enumerations {
+		values = append(values, enumeration.Value)
+	}
+	return values
+}
+
+// ComplexType holds an inline or named xs:complexType definition
+type ComplexType struct {
+	Name            string          `xml:"name,attr"`
+	Abstract        bool            `xml:"abstract,attr"`
+	Mixed           bool            `xml:"mixed,attr"` // Whether text may appear between the child elements
+	Annotation      *Annotation     `xml:"annotation"`
+	Sequence        *Compositor     `xml:"sequence"`
+	Choice          *Compositor     `xml:"choice"`
+	All             *Compositor     `xml:"all"`
+	Group           *GroupRef       `xml:"group"`
+	Attributes      []Attribute     `xml:"attribute"`
+	AttributeGroups []GroupRef      `xml:"attributeGroup"`
+	ComplexContent  *ComplexContent `xml:"complexContent"`
+	SimpleContent   *SimpleContent  `xml:"simpleContent"`
+	Asserts         []Assertion     `xml:"assert"` // XSD 1.1 assertions, noted in the hints of the elements of the type
+	Unsupported     []Construct     `xml:",any"`   // Content such as xs:anyAttribute, which is skipped
+}
+
+// Construct holds the name of an XSD construct that is not supported
+type Construct struct {
+	XMLName xml.Name
+}
+
+// ComplexContent holds the xs:complexContent derivation of a complexType from a base type
+type ComplexContent struct {
+	Mixed       bool        `xml:"mixed,attr"` // Overrides the mixed attribute of the complexType
+	Extension   *Derivation `xml:"extension"`
+	Restriction *Derivation `xml:"restriction"`
+}
+
+// Derivation holds an xs:extension or xs:restriction of a base type, with the content it declares
+type Derivation struct {
+	Base            string      `xml:"base,attr"`
+	Sequence        *Compositor `xml:"sequence"`
+	Choice          *Compositor `xml:"choice"`
+	All             *Compositor `xml:"all"`
+	Group           *GroupRef   `xml:"group"`
+	Attributes      []Attribute `xml:"attribute"`
+	AttributeGroups []GroupRef  `xml:"attributeGroup"`
+	Asserts         []Assertion `xml:"assert"` // XSD 1.1 assertions added by the derivation
+	Unsupported     []Construct `xml:",any"`   // Content such as xs:anyAttribute, which is skipped
+}
+
+// SimpleContent holds the xs:simpleContent of a complexType, whose elements have text of a
+// simple type along with their attributes. Only extensions are supported: restrictions,
+// which add facets to the text of their base type, are skipped.
+type SimpleContent struct {
+	Extension   *Derivation `xml:"extension"`
+	Restriction *Derivation `xml:"restriction"`
+}
+
+// Helper function to check whether a complexType, or its complexContent, has mixed content
+func (complexType ComplexType) IsMixed() bool {
+	return complexType.Mixed || (complexType.ComplexContent != nil && complexType.ComplexContent.Mixed)
+}
+
+// Helper function to get the complexContent derivation of a complexType, or its
+// simpleContent extension, or nil
+func (complexType ComplexType) Derivation() *Derivation {
+	switch {
+	case complexType.SimpleContent != nil:
+		return complexType.SimpleContent.Extension
+	case complexType.ComplexContent == nil:
+		return nil
+	case complexType.ComplexContent.Extension != nil:
+		return complexType.ComplexContent.Extension
+	}
+	return complexType.ComplexContent.Restriction
+}
+
+// Helper function to list the local names of the unsupported constructs a complexType
+// contains, including those of its derivation and compositors, without duplicates.
+// Referenced model groups report their own.
+func (complexType ComplexType) UnsupportedConstructs() []string {
+	constructs := complexType.Unsupported
+	compositors := []*Compositor{complexType.Sequence, complexType.Choice, complexType.All}
+	if complexType.SimpleContent != nil && complexType.SimpleContent.Extension == nil {
+		constructs = append(slices.Clip(constructs), Construct{xml.Name{Space: Namespace, Local: "simpleContent"}})
+	}
+	if derivation := complexType.Derivation(); derivation != nil {
+		constructs = slices.Concat(constructs, derivation.Unsupported)
+		compositors = append(compositors, derivation.Sequence, derivation.Choice, derivation.All)
+	}
+	return unsupportedConstructs(constructs, compositors)
+}
+
+// Function to list the tests of the XSD 1.1 assertions of a complexType and of its derivation
+func (complexType ComplexType) AssertionTests() []string {
+	asserts := complexType.Asserts
+	if derivation := complexType.Derivation(); derivation != nil {
+		asserts = slices.Concat(asserts, derivation.Asserts)
+	}
+	var tests []string
+	for _, assert := range asserts {
+		tests = append(tests, assert.Test)
+	}
+	return tests
+}
+
+// Helper function to list the local names of the unsupported constructs a model group contains
+func (group Group) UnsupportedConstructs() []string {
+	return unsupportedConstructs(nil, []*Compositor{group.Compositor()})
+}
+
+// Function to list the local names of unsupported constructs along with those of the
+// compositors, including nested ones, without duplicates
+func unsupportedConstructs(constructs []Construct, compositors []*Compositor) []string {
+	constructs = slices.Clone(constructs)
+	for len(compositors) > 0 {
+		compositor := compositors[0]
+		compositors = compositors[1:]
+		if compositor == nil {
+			continue
+		}
+		constructs = append(constructs, compositor.Unsupported...)
+		for _, particle := range compositor.Particles {
+			compositors = append(compositors, particle.Group)
+		}
+	}
+
+	var names []string
+	for _, construct := range constructs {
+		if construct.XMLName.Local != "annotation" && !slices.Contains(names, construct.XMLName.Local) {
+			names = append(names, construct.XMLName.Local)
+		}
+	}
+	return names
+}
+
+// Compositor holds an xs:sequence, xs:choice or xs:all group with its particles in document order
+type Compositor struct {
+	Kind        string // Local name of the compositor: sequence, choice or all
+	MinOccurs   string
+	MaxOccurs   string
+	Particles   []Particle
+	Unsupported []Construct // Unknown particles, which are skipped
+}
+
+// Particle is a single item of a compositor: an element, a nested compositor, a
+// reference to a named model group or a wildcard
+type Particle struct {
+	Element  *Element
+	Group    *Compositor
+	GroupRef *GroupRef
+	Any      *Any
+}
+
+// Any holds an xs:any wildcard, accepting elements that are not declared
+type Any struct {
+	Namespace       string `xml:"namespace,attr"`
+	ProcessContents string `xml:"processContents,attr"`
+	MinOccurs       string `xml:"minOccurs,attr"`
+	MaxOccurs       string `xml:"maxOccurs,attr"`
+}
+
+// Group holds a named xs:group model group, whose content is inlined where it is referenced
+type Group struct {
+	Name       string      `xml:"name,attr"`
+	Annotation *Annotation `xml:"annotation"`
+	Sequence   *Compositor `xml:"sequence"`
+	Choice     *Compositor `xml:"choice"`
+	All        *Compositor `xml:"all"`
+}
+
+// Helper function to get the compositor of a model group, or nil if it is empty
+func (group Group) Compositor() *Compositor {
+	for _, compositor := range []*Compositor{group.Sequence, group.Choice, group.All} {
+		if compositor != nil {
+			return compositor
+		}
+	}
+	return nil
+}
+
+// AttributeGroup holds a named xs:attributeGroup, whose attributes are added to the types
+// referencing it
+type AttributeGroup struct {
+	Name            string      `xml:"name,attr"`
+	Attributes      []Attribute `xml:"attribute"`
+	AttributeGroups []GroupRef  `xml:"attributeGroup"`
+}
+
+// GroupRef holds a reference to a named model group or attribute group
+type GroupRef struct {
+	Ref       string `xml:"ref,attr"`
+	MinOccurs string `xml:"minOccurs,attr"`
+	MaxOccurs string `xml:"maxOccurs,attr"`
+}
+
+// Function to decode a compositor, keeping elements and nested groups in document order
+func (compositor *Compositor) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	compositor.Kind = start.Name.Local
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "minOccurs":
+			compositor.MinOccurs = attr.Value
+		case "maxOccurs":
+			compositor.MaxOccurs = attr.Value
+		}
+	}
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "element":
+				var element Element
+				if err := d.DecodeElement(&element, &t); err != nil {
+					return err
+				}
+				compositor.Particles = append(compositor.Particles, Particle{Element: &element})
+			case "sequence", "choice", "all":
+				var group Compositor
+				if err := d.DecodeElement(&group, &t); err != nil {
+					return err
+				}
+				compositor.Particles = append(compositor.Particles, Particle{Group: &group})
+			case "group":
+				var ref GroupRef
+				if err := d.DecodeElement(&ref, &t); err != nil {
+					return err
+				}
+				compositor.Particles = append(compositor.Particles, Particle{GroupRef: &ref})
+			case "any":
+				var wildcard Any
+				if err := d.DecodeElement(&wildcard, &t); err != nil {
+					return err
+				}
+				compositor.Particles = append(compositor.Particles, Particle{Any: &wildcard})
+			default:
+				if t.Name.Local != "annotation" {
+					compositor.Unsupported = append(compositor.Unsupported, Construct{t.Name})
+				}
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// Attribute holds an xs:attribute declaration
+type Attribute struct {
+	Name        string      `xml:"name,attr"`
+	Type        string      `xml:"type,attr"`
+	Use         string      `xml:"use,attr"`
+	Default     string      `xml:"default,attr"`
+	Fixed       string      `xml:"fixed,attr"`
+	SimpleType  *SimpleType `xml:"simpleType"`
+	Annotation  *Annotation `xml:"annotation"`
+	Constraints []string    `xml:"-"` // Descriptions of the identity constraints its value takes part in
+}
+
+// Helper function to present an attribute as a leaf element, so that it shares the
+// type mapping, hints and sample values of elements
+func (attribute Attribute) AsElement() Element {
+	minOccurs := "0"
+	if attribute.Use == "required" {
+		minOccurs = "1"
+	}
+	return Element{
+		Name:        attribute.Name,
+		Type:        attribute.Type,
+		MinOccurs:   minOccurs,
+		Default:     attribute.Default,
+		Fixed:       attribute.Fixed,
+		SimpleType:  attribute.SimpleType,
+		Annotation:  attribute.Annotation,
+		Constraints: attribute.Constraints,
+	}
+}
+
+// Annotation holds the xs:documentation of an xs:annotation
+type Annotation struct {
+	Documentation []string `xml:"documentation"`
+}
+
+// Function to get the documentation text of an annotation with whitespace collapsed.
+// A nil annotation has no text.
+func (annotation *Annotation) Text() string {
+	if annotation == nil {
+		return ""
+	}
+	var parts []string
+	for _, documentation := range annotation.Documentation {
+		if text := strings.Join(strings.Fields(documentation), " "); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// SimpleType holds an inline or named xs:simpleType definition
+type SimpleType struct {
+	Name        string      `xml:"name,attr"`
+	Annotation  *Annotation `xml:"annotation"`
+	Restriction Restriction `xml:"restriction"`
+	List        *List       `xml:"list"`
+	Union       *Union      `xml:"union"`
+}
+
+// List holds an xs:list, whose values are whitespace-separated items of the item type
+type List struct {
+	ItemType   string      `xml:"itemType,attr"`
+	SimpleType *SimpleType `xml:"simpleType"` // Inline item type, or the resolved named one
+}
+
+// Union holds an xs:union, whose values are those of any of its member types
+type Union struct {
+	MemberTypes string       `xml:"memberTypes,attr"` // Whitespace-separated qualified names
+	SimpleTypes []SimpleType `xml:"simpleType"`       // Anonymous member types
+}
+
+// Restriction holds the base type and facets of an xs:restriction
+type Restriction struct {
+	Base           string      `xml:"base,attr"`
+	Length         *Facet      `xml:"length"` // Exact length, of both minLength and maxLength
+	MinLength      *Facet      `xml:"minLength"`
+	MaxLength      *Facet      `xml:"maxLength"`
+	TotalDigits    *Facet      `xml:"totalDigits"`
+	FractionDigits *Facet      `xml:"fractionDigits"`
+	MinInclusive   *Facet      `xml:"minInclusive"`
+	MaxInclusive   *Facet      `xml:"maxInclusive"`
+	MinExclusive   *Facet      `xml:"minExclusive"`
+	MaxExclusive   *Facet      `xml:"maxExclusive"`
+	Patterns       []Facet     `xml:"pattern"` // Alternatives, a value matching any of them is valid
+	Enumerations   []Facet     `xml:"enumeration"`
+	Assertions     []Assertion `xml:"assertion"` // XSD 1.1 assertions, noted in the hints and not checked
+}
+
+// Facet holds the value of a single restriction facet
+type Facet struct {
+	Value string `xml:"value,attr"`
+}
+
+// Helper function to get the value of a length or digits facet, or 0 if there is none or
+// it is not a valid count
+func (facet *Facet) count() int {
+	if facet == nil {
+		return 0
+	}
+	count, err := strconv.Atoi(facet.Value)
+	if err != nil || count < 0 {
+		return 0
+	}
+	return count
+}
@@ -0,0 +1,65 @@
+package xsd
+
+// Statistics summarize the element tree of the global elements of a schema, to estimate the
+// complexity of the integrations built on it
+type Statistics struct {
+	Elements          int // Elements of the tree, including nested ones and the concrete types of polymorphic elements
+	Attributes        int // Attributes of the elements of the tree
+	MaxDepth          int // Nesting depth of the deepest element, 1 for a root without children
+	Types             int // Distinct named types of the elements and attributes, built-in types included
+	Enumerations      int // Elements, attributes and texts restricted to a list of values
+	EnumerationValues int // Values of those lists
+	Repeating         int // Elements that may occur more than once
+	RepeatingGroups   int // Repeating elements with children or attributes, which become arrays of objects
+}
+
+// Function to compute the statistics of the element tree of a schema
+func (schema Schema) Statistics() Statistics {
+	var stats Statistics
+	types := make(map[string]bool)
+	for _, element := range schema.Elements {
+		stats.add(element, 1, types)
+	}
+	stats.Types = len(types)
+	return stats
+}
+
+// Function to add an element at the given depth, along with its content, to the statistics
+func (stats *Statistics) add(element Element, depth int, types map[string]bool) {
+	stats.Elements++
+	stats.MaxDepth = max(stats.MaxDepth, depth)
+	stats.addType(element, types)
+	if element.IsRepeating() {
+		stats.Repeating++
+		if !element.IsLeaf() {
+			stats.RepeatingGroups++
+		}
+	}
+	for _, attribute := range element.Attributes {
+		stats.Attributes++
+		stats.addType(attribute.AsElement(), types)
+	}
+	if element.Text != nil {
+		stats.addType(*element.Text, types)
+	}
+	for _, child := range element.Children {
+		stats.add(child, depth+1, types)
+	}
+	// Concrete types of polymorphic elements stand for the element itself, whose occurrences
+	// were counted already
+	for _, alternative := range element.Alternatives {
+		alternative.MaxOccurs = ""
+		stats.add(alternative, depth, types)
+	}
+}
+
+// Function to add the type and the enumerated values of an element, attribute or text
+func (stats *Statistics) addType(element Element, types map[string]bool) {
+	if element.Type != "" {
+		types[element.Type] = true
+	}
+	if values := element.Enumerations(); len(values) > 0 {
+		stats.Enumerations++
+		stats.EnumerationValues += len(values)
+	}
+}
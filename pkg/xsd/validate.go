@@ -0,0 +1,536 @@
+package xsd
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError is a single violation of a schema by an XML document
+type ValidationError struct {
+	Path    string // Path of the offending element or attribute, such as Order/Line[2]/@id
+	Message string
+}
+
+// Function to format a validation error as "path: message"
+func (e ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Node of a parsed XML document
+type xmlNode struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*xmlNode
+	text     strings.Builder
+}
+
+// Function to validate an XML document against the global elements of a schema: the
+// presence and cardinality of elements and attributes, and the values of simple types
+// with their facets. Elements are matched by local name, and the order of sequences is
+// not checked. Returns the violations in document order, or an error if the document is
+// not well-formed.
+func Validate(schema Schema, r io.Reader) ([]ValidationError, error) {
+	root, err := parseXMLDocument(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, element := range schema.Elements {
+		if element.Name == root.name.Local {
+			v := validator{}
+			v.validateElement(element, root, root.name.Local)
+			return v.errors, nil
+		}
+		names = append(names, element.Name)
+	}
+	return []ValidationError{{root.name.Local, "unexpected root element, expected one of: " + strings.Join(names, ", ")}}, nil
+}
+
+// Function to parse an XML document into a tree of nodes
+func parseXMLDocument(r io.Reader) (*xmlNode, error) {
+	decoder := NewDecoder(r)
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("invalid XML: no root element")
+	}
+	return root, nil
+}
+
+// Validator collecting the violations of a document
+type validator struct {
+	errors []ValidationError
+}
+
+// Helper function to record a violation at path
+func (v *validator) report(path, format string, args ...any) {
+	v.errors = append(v.errors, ValidationError{path, fmt.Sprintf(format, args...)})
+}
+
+// Function to validate a node against the element declaration it matched
+func (v *validator) validateElement(element Element, node *xmlNode, path string) {
+	if nilled, ok := instanceAttribute(node, "nil"); ok && (nilled == "true" || nilled == "1") {
+		if !element.Nillable {
+			v.report(path, "xsi:nil is not allowed, the element is not nillable")
+		}
+		if len(node.children) > 0 || strings.TrimSpace(node.text.String()) != "" {
+			v.report(path, "element marked with xsi:nil must be empty")
+		}
+		return
+	}
+	if element.Truncated {
+		return
+	}
+
+	// The content of a polymorphic element is that of the concrete type named by xsi:type
+	content := element
+	if len(element.Alternatives) > 0 {
+		var names []string
+		for _, alternative := range element.Alternatives {
+			names = append(names, alternative.Name)
+		}
+		typeName, ok := instanceAttribute(node, "type")
+		if !ok {
+			v.report(path, "xsi:type is required, expected one of: %s", strings.Join(names, ", "))
+			return
+		}
+		found := false
+		for _, alternative := range element.Alternatives {
+			if alternative.Name == localName(typeName) {
+				content, found = alternative, true
+				break
+			}
+		}
+		if !found {
+			v.report(path, "unknown xsi:type %s, expected one of: %s", typeName, strings.Join(names, ", "))
+			return
+		}
+	}
+
+	// Any content is accepted where xs:anyType is declared
+	if content.IsPassThrough() {
+		return
+	}
+	if content.IsLeaf() {
+		if len(node.children) > 0 {
+			v.report(path, "unexpected element %s, the element has simple content", node.children[0].name.Local)
+		}
+		v.validateValue(element, node.text.String(), path)
+		return
+	}
+
+	v.validateAttributes(content, node, path)
+	if content.IsMixed() {
+		v.validateChildren(content, node, path)
+		return
+	}
+	if content.Text != nil {
+		if len(node.children) > 0 {
+			v.report(path, "unexpected element %s, the element has simple content", node.children[0].name.Local)
+		}
+		v.validateValue(*content.Text, node.text.String(), path)
+		return
+	}
+	if strings.TrimSpace(node.text.String()) != "" {
+		v.report(path, "unexpected text content")
+	}
+	v.validateChildren(content, node, path)
+}
+
+// Function to validate the attributes of a node
+func (v *validator) validateAttributes(element Element, node *xmlNode, path string) {
+	declared := make(map[string]bool, len(element.Attributes))
+	for _, attribute := range element.Attributes {
+		declared[attribute.Name] = true
+		attributePath := path + "/@" + attribute.Name
+		value, ok := findAttribute(node, attribute.Name)
+		if !ok {
+			if attribute.Use == "required" {
+				v.report(attributePath, "missing required attribute")
+			}
+			continue
+		}
+		v.validateValue(attribute.AsElement(), value, attributePath)
+	}
+	for _, attr := range node.attrs {
+		if attr.Name.Space == "" && attr.Name.Local != "xmlns" && !declared[attr.Name.Local] {
+			v.report(path+"/@"+attr.Name.Local, "unexpected attribute")
+		}
+	}
+}
+
+// Function to validate the child elements of a node: undeclared elements, the occurrences
+// of each declared one, and the branches taken in each xs:choice. Undeclared elements are
+// accepted, without being validated, by an xs:any wildcard.
+func (v *validator) validateChildren(element Element, node *xmlNode, path string) {
+	counts := make(map[string]int)
+	_, wildcard := findChild(element, WildcardName)
+	for _, child := range node.children {
+		declaration, ok := findChild(element, child.name.Local)
+		if !ok && wildcard {
+			counts[WildcardName]++
+			continue
+		}
+		if !ok {
+			v.report(path+"/"+child.name.Local, "unexpected element")
+			continue
+		}
+		counts[child.name.Local]++
+		childPath := path + "/" + child.name.Local
+		if declaration.IsRepeating() {
+			childPath += "[" + strconv.Itoa(counts[child.name.Local]) + "]"
+		}
+		v.validateElement(declaration, child, childPath)
+	}
+
+	var branches []Element
+	for i, child := range element.Children {
+		count := counts[child.Name]
+		if !child.ChoiceItem {
+			if minOccurs := occurs(child.MinOccurs, 1); count < minOccurs {
+				v.report(path+"/"+child.Name, "missing element, expected at least %d", minOccurs)
+			}
+		} else {
+			branches = append(branches, child)
+		}
+		if maxOccurs := occurs(child.MaxOccurs, 1); maxOccurs >= 0 && count > maxOccurs && !child.IsWildcard() {
+			v.report(path+"/"+child.Name, "element occurs %d times, expected at most %d", count, maxOccurs)
+		}
+
+		// Consecutive choice branches belong to the same xs:choice
+		if len(branches) > 0 && (i == len(element.Children)-1 || !element.Children[i+1].ChoiceItem) {
+			v.validateChoice(branches, counts, path)
+			branches = nil
+		}
+	}
+}
+
+// Function to check that a single branch of an xs:choice is taken, unless it is optional.
+// A repeating choice may take a different branch on each occurrence.
+func (v *validator) validateChoice(branches []Element, counts map[string]int, path string) {
+	var names, taken []string
+	optional, repeating := false, false
+	for _, branch := range branches {
+		names = append(names, branch.Name)
+		if counts[branch.Name] > 0 {
+			taken = append(taken, branch.Name)
+		}
+		optional = optional || branch.MinOccurs == "0"
+		repeating = repeating || branch.IsRepeating()
+	}
+	switch {
+	case len(taken) > 1 && !repeating:
+		v.report(path, "only one of %s may occur, found %s", strings.Join(names, ", "), strings.Join(taken, ", "))
+	case len(taken) == 0 && !optional:
+		v.report(path, "missing element, expected one of: %s", strings.Join(names, ", "))
+	}
+}
+
+// Function to validate the value of a leaf element or attribute against its fixed value,
+// list or union type, facets and built-in base type
+func (v *validator) validateValue(element Element, value, path string) {
+	if message := valueError(element, value); message != "" {
+		v.report(path, "%s", message)
+	}
+}
+
+// Helper function to describe why a value is not valid for a leaf element, or "" if it is
+func valueError(element Element, value string) string {
+	if element.BaseType() != "xs:string" || element.IsList() {
+		value = strings.TrimSpace(value)
+	}
+	if element.Fixed != "" && value != element.Fixed {
+		return fmt.Sprintf("value %q must be the fixed value %q", value, element.Fixed)
+	}
+
+	if values := element.Enumerations(); len(values) > 0 {
+		allowed := false
+		for _, enumeration := range values {
+			allowed = allowed || value == enumeration
+		}
+		if !allowed {
+			return fmt.Sprintf("value %q is not one of: %s", value, strings.Join(values, ", "))
+		}
+	}
+	if message := lengthError(element, value); message != "" {
+		return message
+	}
+	if pattern := patternRegexp(element); pattern != nil && !pattern.MatchString(value) {
+		return fmt.Sprintf("value %q does not match the pattern %s", value, strings.Join(element.Patterns(), " or "))
+	}
+
+	if element.IsList() {
+		for _, item := range strings.Fields(value) {
+			if message := valueError(element.ListItem(), item); message != "" {
+				return message
+			}
+		}
+		return ""
+	}
+	if members := element.UnionMembers(); len(members) > 0 {
+		for _, member := range members {
+			if builtinValueError(member, value) == "" {
+				return ""
+			}
+		}
+		return fmt.Sprintf("value %q is not valid for any of the types %s", value, strings.Join(members, ", "))
+	}
+	if message := builtinValueError(element.BaseType(), value); message != "" {
+		return message
+	}
+	return numericValueError(element, value)
+}
+
+// Helper function to describe why a value is not within the length, minLength and maxLength
+// facets of an element, or "" if it is. The length of a list is its number of items.
+func lengthError(element Element, value string) string {
+	minLength, maxLength := element.MinLength(), element.MaxLength()
+	if element.IsList() {
+		count := len(strings.Fields(value))
+		switch {
+		case minLength > 0 && minLength == maxLength && count != minLength:
+			return fmt.Sprintf("value %q must have exactly %d items", value, minLength)
+		case count < minLength:
+			return fmt.Sprintf("value %q has fewer than %d items", value, minLength)
+		case maxLength > 0 && count > maxLength:
+			return fmt.Sprintf("value %q has more than %d items", value, maxLength)
+		}
+		return ""
+	}
+	length := utf8.RuneCountInString(value)
+	switch {
+	case minLength > 0 && minLength == maxLength && length != minLength:
+		return fmt.Sprintf("value %q must be exactly %d characters long", value, minLength)
+	case length < minLength:
+		return fmt.Sprintf("value %q is shorter than %d characters", value, minLength)
+	case maxLength > 0 && length > maxLength:
+		return fmt.Sprintf("value %q is longer than %d characters", value, maxLength)
+	}
+	return ""
+}
+
+// Helper function to compile the xs:pattern facets of an element into a regular expression
+// matching whole values, or nil if there are none. Patterns using XML Schema syntax that Go
+// regular expressions lack, such as the \i and \c escapes, Unicode block names and character
+// class subtraction, are not checked.
+func patternRegexp(element Element) *regexp.Regexp {
+	patterns := element.Patterns()
+	if len(patterns) == 0 {
+		return nil
+	}
+	// Character class subtraction, as in [a-z-[aeiou]], would compile to another class
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, "-[") {
+			return nil
+		}
+	}
+	pattern, err := regexp.Compile(`^(?:` + strings.Join(patterns, "|") + `)$`)
+	if err != nil {
+		return nil
+	}
+	return pattern
+}
+
+// Helper function to describe why a number is not within the digits and bounds facets of an
+// element, or "" if it is. Values and bounds that are not numbers, such as dates, are not
+// checked.
+func numericValueError(element Element, value string) string {
+	number, ok := new(big.Rat).SetString(strings.TrimPrefix(value, "+"))
+	if !ok {
+		return ""
+	}
+	total, fraction := element.Digits()
+	integerDigits, fractionDigits := countDigits(value)
+	if total > 0 && integerDigits+fractionDigits > total {
+		return fmt.Sprintf("value %q has more than %d digits", value, total)
+	}
+	if fraction > 0 && fractionDigits > fraction {
+		return fmt.Sprintf("value %q has more than %d decimals", value, fraction)
+	}
+
+	lower, upper := element.Bounds()
+	if limit, ok := new(big.Rat).SetString(strings.TrimPrefix(lower.Value, "+")); ok {
+		if comparison := number.Cmp(limit); comparison < 0 || (lower.Exclusive && comparison == 0) {
+			return fmt.Sprintf("value %q is less than the minimum of %s", value, describeBound(lower))
+		}
+	}
+	if limit, ok := new(big.Rat).SetString(strings.TrimPrefix(upper.Value, "+")); ok {
+		if comparison := number.Cmp(limit); comparison > 0 || (upper.Exclusive && comparison == 0) {
+			return fmt.Sprintf("value %q is greater than the maximum of %s", value, describeBound(upper))
+		}
+	}
+	return ""
+}
+
+// Helper function to describe a bound in messages, such as "100 (exclusive)"
+func describeBound(bound Bound) string {
+	if bound.Exclusive {
+		return bound.Value + " (exclusive)"
+	}
+	return bound.Value
+}
+
+// Helper function to count the significant digits of a decimal, before and after the
+// decimal point, leaving out leading and trailing zeros
+func countDigits(value string) (integer, fraction int) {
+	value = strings.TrimLeft(value, "+-")
+	integerPart, fractionPart, _ := strings.Cut(value, ".")
+	return len(strings.TrimLeft(integerPart, "0")), len(strings.TrimRight(fractionPart, "0"))
+}
+
+// Patterns of the lexical forms of built-in types
+var (
+	decimalPattern  = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+	floatPattern    = regexp.MustCompile(`^([+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?|-?INF|NaN)$`)
+	timezonePattern = `(Z|[+-]\d{2}:\d{2})?$`
+	datePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}` + timezonePattern)
+	dateTimePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?` + timezonePattern)
+	timePattern     = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(\.\d+)?` + timezonePattern)
+)
+
+// Value ranges of the built-in integer types, as [min, max] with "" for no bound
+var integerRanges = map[string][2]string{
+	"integer":            {"", ""},
+	"long":               {"-9223372036854775808", "9223372036854775807"},
+	"int":                {"-2147483648", "2147483647"},
+	"short":              {"-32768", "32767"},
+	"byte":               {"-128", "127"},
+	"nonNegativeInteger": {"0", ""},
+	"positiveInteger":    {"1", ""},
+	"nonPositiveInteger": {"", "0"},
+	"negativeInteger":    {"", "-1"},
+	"unsignedLong":       {"0", "18446744073709551615"},
+	"unsignedInt":        {"0", "4294967295"},
+	"unsignedShort":      {"0", "65535"},
+	"unsignedByte":       {"0", "255"},
+}
+
+// Helper function to describe why a value is not in the lexical space of a built-in type,
+// or "" if it is. Types that are not built in, and string types, accept any value.
+func builtinValueError(xsdType, value string) string {
+	name := localName(xsdType)
+	if bounds, ok := integerRanges[name]; ok {
+		n, ok := new(big.Int).SetString(strings.TrimPrefix(value, "+"), 10)
+		if !ok {
+			return fmt.Sprintf("value %q is not a valid %s", value, name)
+		}
+		if min, _ := new(big.Int).SetString(bounds[0], 10); min != nil && n.Cmp(min) < 0 {
+			return fmt.Sprintf("value %q is less than the minimum %s of %s", value, name, bounds[0])
+		}
+		if max, _ := new(big.Int).SetString(bounds[1], 10); max != nil && n.Cmp(max) > 0 {
+			return fmt.Sprintf("value %q is greater than the maximum %s of %s", value, name, bounds[1])
+		}
+		return ""
+	}
+
+	valid := true
+	switch name {
+	case "boolean":
+		valid = value == "true" || value == "false" || value == "1" || value == "0"
+	case "decimal":
+		valid = decimalPattern.MatchString(value)
+	case "float", "double":
+		valid = floatPattern.MatchString(value)
+	case "date":
+		valid = datePattern.MatchString(value) && validTime("2006-01-02", value[:10])
+	case "dateTime":
+		valid = dateTimePattern.MatchString(value) && validTime("2006-01-02T15:04:05", value[:19])
+	case "time":
+		valid = timePattern.MatchString(value) && validTime("15:04:05", value[:8])
+	}
+	if !valid {
+		return fmt.Sprintf("value %q is not a valid %s", value, name)
+	}
+	return ""
+}
+
+// Helper function to check that a date or time has valid fields, such as months up to 12
+func validTime(layout, value string) bool {
+	_, err := time.Parse(layout, value)
+	return err == nil
+}
+
+// Helper function to get the value of an unqualified attribute of a node
+func findAttribute(node *xmlNode, name string) (string, bool) {
+	for _, attr := range node.attrs {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// Helper function to get the value of an XML Schema instance attribute, such as xsi:nil
+func instanceAttribute(node *xmlNode, name string) (string, bool) {
+	for _, attr := range node.attrs {
+		if attr.Name.Space == InstanceNamespace && attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// Helper function to find the declaration of a child element by name
+func findChild(element Element, name string) (Element, bool) {
+	for _, child := range element.Children {
+		if child.Name == name {
+			return child, true
+		}
+	}
+	return Element{}, false
+}
+
+// Helper function to parse a minOccurs or maxOccurs value, returning -1 for unbounded
+func occurs(value string, defaultValue int) int {
+	if value == "unbounded" {
+		return -1
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return n
+}
+
+// Helper function to strip the namespace prefix of a qualified name
+func localName(name string) string {
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
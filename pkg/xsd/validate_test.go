@@ -0,0 +1,229 @@
+package xsd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="StatusType">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="open"/>
+      <xs:enumeration value="closed"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Id" type="xs:unsignedInt"/>
+        <xs:element name="Date" type="xs:date"/>
+        <xs:element name="Status" type="StatusType"/>
+        <xs:element name="Line" maxOccurs="2">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Sku">
+                <xs:simpleType>
+                  <xs:restriction base="xs:string">
+                    <xs:maxLength value="4"/>
+                  </xs:restriction>
+                </xs:simpleType>
+              </xs:element>
+              <xs:element name="Price" type="xs:decimal"/>
+            </xs:sequence>
+            <xs:attribute name="number" type="xs:int" use="required"/>
+          </xs:complexType>
+        </xs:element>
+        <xs:choice>
+          <xs:element name="Email" type="xs:string"/>
+          <xs:element name="Phone" type="xs:string"/>
+        </xs:choice>
+        <xs:element name="Note" type="xs:string" nillable="true"/>
+      </xs:sequence>
+      <xs:attribute name="version" type="xs:string" fixed="2"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	valid := `<Order version="2" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <Id>42</Id>
+  <Date>2024-02-29</Date>
+  <Status>open</Status>
+  <Line number="1"><Sku>AB12</Sku><Price>9.95</Price></Line>
+  <Phone>555-0100</Phone>
+  <Note xsi:nil="true"/>
+</Order>`
+	errs, err := Validate(schema, strings.NewReader(valid))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(errs) > 0 {
+		t.Errorf("valid document: unexpected errors %v", errs)
+	}
+
+	invalid := `<Order version="3" extra="x">
+  <Id>-1</Id>
+  <Date>2024-02-30</Date>
+  <Status>pending</Status>
+  <Line><Sku>ABCDE</Sku><Price>cheap</Price></Line>
+  <Line number="2"><Sku>A</Sku><Price>1</Price></Line>
+  <Line number="3"><Sku>B</Sku><Price>1</Price></Line>
+  <Email>a@example.com</Email>
+  <Phone>555-0100</Phone>
+  <Gift/>
+</Order>`
+	errs, err = Validate(schema, strings.NewReader(invalid))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	want := []string{
+		`Order/@version: value "3" must be the fixed value "2"`,
+		`Order/@extra: unexpected attribute`,
+		`Order/Id: value "-1" is less than the minimum unsignedInt of 0`,
+		`Order/Date: value "2024-02-30" is not a valid date`,
+		`Order/Status: value "pending" is not one of: open, closed`,
+		`Order/Line[1]/@number: missing required attribute`,
+		`Order/Line[1]/Sku: value "ABCDE" is longer than 4 characters`,
+		`Order/Line[1]/Price: value "cheap" is not a valid decimal`,
+		`Order/Gift: unexpected element`,
+		`Order/Line: element occurs 3 times, expected at most 2`,
+		`Order: only one of Email, Phone may occur, found Email, Phone`,
+		`Order/Note: missing element, expected at least 1`,
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("errors:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if errs, _ := Validate(schema, strings.NewReader(`<Invoice/>`)); len(errs) != 1 {
+		t.Errorf("unknown root: errors %v, want one", errs)
+	}
+	if _, err := Validate(schema, strings.NewReader(`<Order>`)); err == nil {
+		t.Error("Validate: expected an error for a malformed document")
+	}
+}
+
+func TestValidateNumericFacets(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Payment">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Amount" maxOccurs="unbounded">
+          <xs:simpleType>
+            <xs:restriction base="xs:decimal">
+              <xs:totalDigits value="4"/>
+              <xs:fractionDigits value="2"/>
+              <xs:minExclusive value="0"/>
+              <xs:maxInclusive value="500"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	document := `<Payment>
+  <Amount>12.50</Amount>
+  <Amount>500.00</Amount>
+  <Amount>001.234</Amount>
+  <Amount>123.45</Amount>
+  <Amount>0</Amount>
+  <Amount>501</Amount>
+</Payment>`
+	errs, err := Validate(schema, strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	want := []string{
+		`Payment/Amount[3]: value "001.234" has more than 2 decimals`,
+		`Payment/Amount[4]: value "123.45" has more than 4 digits`,
+		`Payment/Amount[5]: value "0" is less than the minimum of 0 (exclusive)`,
+		`Payment/Amount[6]: value "501" is greater than the maximum of 500`,
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("errors:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateStringFacets(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Account">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Name" maxOccurs="unbounded">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:minLength value="2"/>
+              <xs:maxLength value="5"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Country" maxOccurs="unbounded">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:length value="2"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Currency" maxOccurs="unbounded">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:pattern value="[A-Z]{3}"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Initials">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:pattern value="\i\c*"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	document := `<Account>
+  <Name>Jo</Name>
+  <Name>J</Name>
+  <Name>Größe</Name>
+  <Name>Grössen</Name>
+  <Country>DE</Country>
+  <Country>DEU</Country>
+  <Currency>EUR</Currency>
+  <Currency>eur</Currency>
+  <Currency>EURO</Currency>
+  <Initials>-</Initials>
+</Account>`
+	errs, err := Validate(schema, strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	// Patterns in XML Schema syntax that Go lacks, such as \i and \c, are not checked
+	want := []string{
+		`Account/Name[2]: value "J" is shorter than 2 characters`,
+		`Account/Name[4]: value "Grössen" is longer than 5 characters`,
+		`Account/Country[2]: value "DEU" must be exactly 2 characters long`,
+		`Account/Currency[2]: value "eur" does not match the pattern [A-Z]{3}`,
+		`Account/Currency[3]: value "EURO" does not match the pattern [A-Z]{3}`,
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("errors:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
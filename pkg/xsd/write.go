@@ -0,0 +1,202 @@
+package xsd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Function to write a resolved schema as an XSD document. Children and attributes are
+// written as anonymous complexTypes, text next to attributes as a simpleContent extension
+// of its built-in type and text between children as mixed content, facets as inline
+// simpleTypes, and consecutive choice branches are grouped into an xs:choice.
+func Write(w io.Writer, schema Schema) error {
+	var sb strings.Builder
+	sb.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	sb.WriteString("<xs:schema xmlns:xs=\"" + Namespace + "\"")
+	if schema.TargetNamespace != "" {
+		sb.WriteString(xmlAttr("targetNamespace", schema.TargetNamespace))
+		sb.WriteString(xmlAttr("xmlns", schema.TargetNamespace))
+		if schema.ElementFormDefault != "" {
+			sb.WriteString(xmlAttr("elementFormDefault", schema.ElementFormDefault))
+		}
+	}
+	sb.WriteString(">\n")
+	for _, element := range schema.Elements {
+		writeElement(&sb, element, "  ")
+	}
+	sb.WriteString("</xs:schema>\n")
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	return nil
+}
+
+// Function to write an xs:element declaration with its content model, or the xs:any
+// wildcard a child stands for
+func writeElement(sb *strings.Builder, element Element, indent string) {
+	if element.IsWildcard() {
+		sb.WriteString(indent + "<xs:any" + xmlAttr("processContents", "lax"))
+		if element.MinOccurs != "" && element.MinOccurs != "1" {
+			sb.WriteString(xmlAttr("minOccurs", element.MinOccurs))
+		}
+		sb.WriteString("/>\n")
+		return
+	}
+	sb.WriteString(indent + "<xs:element" + xmlAttr("name", element.Name))
+	simpleType := element.SimpleType != nil && (element.MaxLength() > 0 || len(element.Enumerations()) > 0)
+	if element.IsLeaf() && !simpleType {
+		sb.WriteString(xmlAttr("type", builtinType(element)))
+	}
+	if element.MinOccurs != "" && element.MinOccurs != "1" {
+		sb.WriteString(xmlAttr("minOccurs", element.MinOccurs))
+	}
+	if element.MaxOccurs != "" && element.MaxOccurs != "1" {
+		sb.WriteString(xmlAttr("maxOccurs", element.MaxOccurs))
+	}
+	sb.WriteString(valueConstraints(element))
+
+	documentation := element.Annotation.Text()
+	if documentation == "" && (element.IsLeaf() && !simpleType) {
+		sb.WriteString("/>\n")
+		return
+	}
+	sb.WriteString(">\n")
+	writeAnnotation(sb, documentation, indent+"  ")
+
+	switch {
+	case element.IsMixed():
+		sb.WriteString(indent + "  <xs:complexType" + xmlAttr("mixed", "true") + ">\n")
+		writeParticles(sb, element.Children, indent+"    ")
+		for _, attribute := range element.Attributes {
+			writeAttribute(sb, attribute, indent+"    ")
+		}
+		sb.WriteString(indent + "  </xs:complexType>\n")
+	case element.Text != nil:
+		sb.WriteString(indent + "  <xs:complexType>\n")
+		sb.WriteString(indent + "    <xs:simpleContent>\n")
+		sb.WriteString(indent + "      <xs:extension" + xmlAttr("base", builtinType(*element.Text)) + ">\n")
+		for _, attribute := range element.Attributes {
+			writeAttribute(sb, attribute, indent+"        ")
+		}
+		sb.WriteString(indent + "      </xs:extension>\n")
+		sb.WriteString(indent + "    </xs:simpleContent>\n")
+		sb.WriteString(indent + "  </xs:complexType>\n")
+	case !element.IsLeaf():
+		sb.WriteString(indent + "  <xs:complexType>\n")
+		if len(element.Children) > 0 {
+			writeParticles(sb, element.Children, indent+"    ")
+		}
+		for _, attribute := range element.Attributes {
+			writeAttribute(sb, attribute, indent+"    ")
+		}
+		sb.WriteString(indent + "  </xs:complexType>\n")
+	case simpleType:
+		writeSimpleType(sb, builtinType(element), element.SimpleType.Restriction, indent+"  ")
+	}
+	sb.WriteString(indent + "</xs:element>\n")
+}
+
+// Function to write child elements as an xs:sequence, grouping consecutive choice branches
+func writeParticles(sb *strings.Builder, children []Element, indent string) {
+	sb.WriteString(indent + "<xs:sequence>\n")
+	for i := 0; i < len(children); i++ {
+		if !children[i].ChoiceItem {
+			writeElement(sb, children[i], indent+"  ")
+			continue
+		}
+		sb.WriteString(indent + "  <xs:choice>\n")
+		for ; i < len(children) && children[i].ChoiceItem; i++ {
+			writeElement(sb, children[i], indent+"    ")
+		}
+		i--
+		sb.WriteString(indent + "  </xs:choice>\n")
+	}
+	sb.WriteString(indent + "</xs:sequence>\n")
+}
+
+// Function to write an xs:attribute declaration
+func writeAttribute(sb *strings.Builder, attribute Attribute, indent string) {
+	element := attribute.AsElement()
+	simpleType := attribute.SimpleType != nil && (element.MaxLength() > 0 || len(element.Enumerations()) > 0)
+
+	sb.WriteString(indent + "<xs:attribute" + xmlAttr("name", attribute.Name))
+	if !simpleType {
+		sb.WriteString(xmlAttr("type", builtinType(element)))
+	}
+	if attribute.Use != "" {
+		sb.WriteString(xmlAttr("use", attribute.Use))
+	}
+	sb.WriteString(valueConstraints(element))
+
+	documentation := attribute.Annotation.Text()
+	if documentation == "" && !simpleType {
+		sb.WriteString("/>\n")
+		return
+	}
+	sb.WriteString(">\n")
+	writeAnnotation(sb, documentation, indent+"  ")
+	if simpleType {
+		writeSimpleType(sb, builtinType(element), attribute.SimpleType.Restriction, indent+"  ")
+	}
+	sb.WriteString(indent + "</xs:attribute>\n")
+}
+
+// Helper function to write the default and fixed attributes of an element or attribute
+func valueConstraints(element Element) string {
+	var constraints string
+	if element.Default != "" {
+		constraints += xmlAttr("default", element.Default)
+	}
+	if element.Fixed != "" {
+		constraints += xmlAttr("fixed", element.Fixed)
+	}
+	return constraints
+}
+
+// Function to write an anonymous xs:simpleType restricting base with the given facets
+func writeSimpleType(sb *strings.Builder, base string, restriction Restriction, indent string) {
+	sb.WriteString(indent + "<xs:simpleType>\n")
+	sb.WriteString(indent + "  <xs:restriction" + xmlAttr("base", base) + ">\n")
+	if restriction.MaxLength != nil {
+		sb.WriteString(indent + "    <xs:maxLength" + xmlAttr("value", restriction.MaxLength.Value) + "/>\n")
+	}
+	for _, enumeration := range restriction.Enumerations {
+		sb.WriteString(indent + "    <xs:enumeration" + xmlAttr("value", enumeration.Value) + "/>\n")
+	}
+	sb.WriteString(indent + "  </xs:restriction>\n")
+	sb.WriteString(indent + "</xs:simpleType>\n")
+}
+
+// Function to write an xs:annotation holding documentation, if there is any
+func writeAnnotation(sb *strings.Builder, documentation, indent string) {
+	if documentation == "" {
+		return
+	}
+	sb.WriteString(indent + "<xs:annotation>\n")
+	sb.WriteString(indent + "  <xs:documentation>" + xmlEscape(documentation) + "</xs:documentation>\n")
+	sb.WriteString(indent + "</xs:annotation>\n")
+}
+
+// Helper function to get the built-in XSD type of a leaf element, since the named types
+// it may refer to are not written
+func builtinType(element Element) string {
+	if base := element.BaseType(); strings.HasPrefix(base, "xs:") {
+		return base
+	}
+	return "xs:string"
+}
+
+// Helper function to format an XML attribute with its value escaped
+func xmlAttr(name, value string) string {
+	return " " + name + "=\"" + xmlEscape(value) + "\""
+}
+
+// Helper function to escape XML character data
+func xmlEscape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
@@ -0,0 +1,193 @@
+package xsd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// wsdlDefinitions holds the parts of a WSDL 1.1 document needed for generation
+type wsdlDefinitions struct {
+	TargetNamespace string         `xml:"targetNamespace,attr"`
+	Attrs           []xml.Attr     `xml:",any,attr"`
+	Schemas         []Schema       `xml:"types>schema"`
+	Messages        []wsdlMessage  `xml:"message"`
+	PortTypes       []wsdlPortType `xml:"portType"`
+}
+
+// wsdlMessage holds a wsdl:message and its parts
+type wsdlMessage struct {
+	Name  string     `xml:"name,attr"`
+	Parts []wsdlPart `xml:"part"`
+}
+
+// wsdlPart holds a wsdl:part, referencing either a global element or a type
+type wsdlPart struct {
+	Name    string `xml:"name,attr"`
+	Element string `xml:"element,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// wsdlPortType holds the operations of a wsdl:portType
+type wsdlPortType struct {
+	Name       string          `xml:"name,attr"`
+	Operations []wsdlOperation `xml:"operation"`
+}
+
+// wsdlOperation holds the input and output message references of an operation
+type wsdlOperation struct {
+	Name   string         `xml:"name,attr"`
+	Input  wsdlMessageRef `xml:"input"`
+	Output wsdlMessageRef `xml:"output"`
+}
+
+// wsdlMessageRef holds the qualified name of the message used by an operation
+type wsdlMessageRef struct {
+	Message string `xml:"message,attr"`
+}
+
+// Operation holds the request and response schemas extracted for a WSDL operation.
+// Response is nil for one-way operations.
+type Operation struct {
+	Name     string
+	Request  *Schema
+	Response *Schema
+}
+
+// Function to parse a WSDL document read from r with the default settings
+func ParseWSDL(r io.Reader) ([]Operation, error) {
+	return Parser{}.ParseWSDL(r)
+}
+
+// Function to parse a WSDL file with the default settings
+func ParseWSDLFile(filePath string) ([]Operation, error) {
+	return Parser{}.ParseWSDLFile(filePath)
+}
+
+// Function to parse a WSDL document read from r into one request/response schema pair
+// per operation. Relative schemaLocations are resolved against the working directory.
+func (parser Parser) ParseWSDL(r io.Reader) ([]Operation, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WSDL: %w", err)
+	}
+	return parser.parseWSDL(data, "")
+}
+
+// Function to parse a WSDL file, or HTTP(S) URL, into one request/response schema pair per operation
+func (parser Parser) ParseWSDLFile(filePath string) ([]Operation, error) {
+	data, err := parser.Fetcher.Fetch(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return parser.parseWSDL(data, filePath)
+}
+
+// Function to parse the content of a WSDL document loaded from location
+func (parser Parser) parseWSDL(data []byte, location string) ([]Operation, error) {
+	var wsdl wsdlDefinitions
+	if err := NewDecoder(bytes.NewReader(data)).Decode(&wsdl); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal WSDL: %w", err)
+	}
+
+	// Inline schemas inherit the namespace declarations of wsdl:definitions
+	definitions := Schema{
+		TargetNamespace: wsdl.TargetNamespace,
+		Namespaces:      collectNamespaces(wsdl.Attrs, nil),
+	}
+	loader := parser.newSchemaLoader()
+	loader.loaded[location] = true
+	for i := range wsdl.Schemas {
+		schema := &wsdl.Schemas[i]
+		schema.Namespaces = collectNamespaces(schema.Attrs, definitions.Namespaces)
+		if err := loader.add(schema, location, "", false); err != nil {
+			return nil, err
+		}
+	}
+	registry := loader.registry()
+
+	messages := make(map[string]wsdlMessage, len(wsdl.Messages))
+	for _, message := range wsdl.Messages {
+		messages[message.Name] = message
+	}
+
+	var operations []Operation
+	var err error
+	for _, portType := range wsdl.PortTypes {
+		for _, op := range portType.Operations {
+			operation := Operation{Name: op.Name}
+
+			_, requestName := definitions.ResolveQName(op.Input.Message)
+			request, ok := messages[requestName]
+			if !ok {
+				return nil, fmt.Errorf("operation %s: message %q not found", op.Name, op.Input.Message)
+			}
+			operation.Request, err = messageSchema(request, op.Name, definitions, registry)
+			if err != nil {
+				return nil, fmt.Errorf("operation %s: %w", op.Name, err)
+			}
+
+			if op.Output.Message != "" {
+				_, responseName := definitions.ResolveQName(op.Output.Message)
+				response, ok := messages[responseName]
+				if !ok {
+					return nil, fmt.Errorf("operation %s: message %q not found", op.Name, op.Output.Message)
+				}
+				operation.Response, err = messageSchema(response, op.Name+"Response", definitions, registry)
+				if err != nil {
+					return nil, fmt.Errorf("operation %s: %w", op.Name, err)
+				}
+			}
+			operations = append(operations, operation)
+		}
+	}
+	return operations, nil
+}
+
+// Function to build the schema of a WSDL message. A single element part (document/literal)
+// becomes the document root; otherwise the parts are wrapped in an element named wrapperName,
+// following the rpc style convention.
+func messageSchema(message wsdlMessage, wrapperName string, definitions Schema, registry *typeRegistry) (*Schema, error) {
+	if len(message.Parts) == 1 && message.Parts[0].Element != "" {
+		def, ok := registry.lookupElement(definitions, message.Parts[0].Element)
+		if !ok {
+			return nil, fmt.Errorf("element %q of message %s not found", message.Parts[0].Element, message.Name)
+		}
+		elements := resolveElements([]Element{globalElement(*def.element)}, *def.schema, registry)
+		noteIdentityConstraints(elements)
+		return &Schema{
+			TargetNamespace:    def.schema.TargetNamespace,
+			ElementFormDefault: def.schema.ElementFormDefault,
+			Namespaces:         def.schema.Namespaces,
+			Elements:           elements,
+			Warnings:           registry.takeWarnings(),
+			Unsupported:        registry.takeUnsupported(),
+			Unresolved:         registry.takeUnresolved(),
+		}, nil
+	}
+
+	wrapper := Element{Name: wrapperName}
+	for _, part := range message.Parts {
+		if part.Element != "" {
+			def, ok := registry.lookupElement(definitions, part.Element)
+			if !ok {
+				return nil, fmt.Errorf("element %q of message %s not found", part.Element, message.Name)
+			}
+			wrapper.Children = append(wrapper.Children, resolveElements([]Element{globalElement(*def.element)}, *def.schema, registry)...)
+			continue
+		}
+		partElement := Element{Name: part.Name, Type: part.Type}
+		wrapper.Children = append(wrapper.Children, resolveElements([]Element{partElement}, definitions, registry)...)
+	}
+	elements := []Element{wrapper}
+	noteIdentityConstraints(elements)
+	return &Schema{
+		TargetNamespace: definitions.TargetNamespace,
+		Namespaces:      definitions.Namespaces,
+		Elements:        elements,
+		Warnings:        registry.takeWarnings(),
+		Unsupported:     registry.takeUnsupported(),
+		Unresolved:      registry.takeUnresolved(),
+	}, nil
+}
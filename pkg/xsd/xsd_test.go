@@ -0,0 +1,1555 @@
+package xsd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Helper function to parse an XSD string
+func parseString(t *testing.T, content string) Schema {
+	t.Helper()
+	schema, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return schema
+}
+
+func TestParse(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Max5Text">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="5"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="LineType">
+    <xs:sequence>
+      <xs:element name="Code" type="Max5Text"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:integer" use="required"/>
+  </xs:complexType>
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Line" type="LineType" maxOccurs="unbounded"/>
+        <xs:element name="Note" type="xs:string" minOccurs="0"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	if len(schema.Elements) != 1 {
+		t.Fatalf("got %d global elements, want 1", len(schema.Elements))
+	}
+	order := schema.Elements[0]
+	if len(order.Children) != 2 {
+		t.Fatalf("Order has %d children, want 2", len(order.Children))
+	}
+
+	line, note := order.Children[0], order.Children[1]
+	if !line.IsRepeating() || line.IsOptional() || line.IsLeaf() {
+		t.Errorf("Line: repeating=%v optional=%v leaf=%v, want a required repeating complex element",
+			line.IsRepeating(), line.IsOptional(), line.IsLeaf())
+	}
+	if len(line.Attributes) != 1 || line.Attributes[0].AsElement().IsOptional() {
+		t.Errorf("Line attributes = %+v, want the required id attribute", line.Attributes)
+	}
+	if code := line.Children[0]; code.BaseType() != "xs:string" || code.MaxLength() != 5 {
+		t.Errorf("Code: base type %q, maxLength %d, want xs:string, 5", code.BaseType(), code.MaxLength())
+	}
+	if !note.IsOptional() || !note.IsLeaf() {
+		t.Errorf("Note: optional=%v leaf=%v, want an optional leaf", note.IsOptional(), note.IsLeaf())
+	}
+}
+
+func TestSelectRoot(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Request" type="xs:string"/>
+  <xs:element name="Response" type="xs:string"/>
+</xs:schema>`)
+
+	if _, err := schema.SelectRoot(""); err == nil || !strings.Contains(err.Error(), "Request, Response") {
+		t.Errorf("SelectRoot without name: error = %v, want candidates listed", err)
+	}
+	if _, err := schema.SelectRoot("Missing"); err == nil {
+		t.Error("SelectRoot with unknown name: expected an error")
+	}
+
+	selected, err := schema.SelectRoot("Response")
+	if err != nil {
+		t.Fatalf("SelectRoot: %v", err)
+	}
+	if len(selected.Elements) != 1 || selected.Elements[0].Name != "Response" {
+		t.Errorf("selected elements = %+v, want only Response", selected.Elements)
+	}
+}
+
+func TestParserRoot(t *testing.T) {
+	content := largeSchema(30)
+	for _, test := range []struct {
+		root string
+		want int
+	}{
+		{"Message12", 1},
+		// Undeclared roots resolve every element, for SelectRoot to list them
+		{"Missing", 30},
+	} {
+		schema, err := Parser{Root: test.root}.Parse(strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if len(schema.Elements) != test.want {
+			t.Errorf("root %s: %d elements resolved, want %d", test.root, len(schema.Elements), test.want)
+		}
+	}
+
+	// Comments, annotations and global attributes between declarations are skipped
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<!-- Library -->
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:annotation><xs:documentation>Orders</xs:documentation></xs:annotation>
+  <xs:attribute name="version" type="xs:string"/>
+  <xs:element name="Order" type="OrderType"/>
+  <xs:complexType name="OrderType"><xs:sequence><xs:element name="Id" type="xs:string"/></xs:sequence></xs:complexType>
+</xs:schema>`)
+	if len(schema.Elements) != 1 || len(schema.Elements[0].Children) != 1 {
+		t.Errorf("elements = %+v, want Order with Id", schema.Elements)
+	}
+}
+
+func TestRecursiveTypes(t *testing.T) {
+	schema, err := Parser{MaxDepth: 3}.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="PartyType">
+    <xs:sequence>
+      <xs:element name="Name" type="xs:string"/>
+      <xs:element name="Party" type="PartyType" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="Party" type="PartyType"/>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// Party (depth 1) > Party (2) > Party (3) > Party, truncated
+	element := schema.Elements[0]
+	for depth := 1; depth <= 3; depth++ {
+		if element.Truncated || len(element.Children) != 2 {
+			t.Fatalf("Party at depth %d: truncated=%v children=%d, want expanded", depth, element.Truncated, len(element.Children))
+		}
+		element = element.Children[1]
+	}
+	if !element.Truncated || len(element.Children) != 0 || element.IsLeaf() {
+		t.Errorf("Party at depth 4: truncated=%v children=%d leaf=%v, want truncated", element.Truncated, len(element.Children), element.IsLeaf())
+	}
+	if len(schema.Warnings) != 1 || !strings.Contains(schema.Warnings[0], "PartyType") {
+		t.Errorf("warnings = %q, want one for PartyType", schema.Warnings)
+	}
+}
+
+func TestDeepAnonymousNesting(t *testing.T) {
+	// Level1 > Level2 > ... > Level15 > Value, each with an inline complexType. The nesting
+	// is deeper than MaxDepth, which only limits recursive types.
+	const levels = 15
+	var sb strings.Builder
+	sb.WriteString(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">`)
+	for level := 1; level <= levels; level++ {
+		sb.WriteString(fmt.Sprintf(`<xs:element name="Level%d"><xs:complexType><xs:sequence>`, level))
+	}
+	sb.WriteString(`<xs:element name="Value" type="xs:string"/>`)
+	for level := 1; level <= levels; level++ {
+		sb.WriteString(`</xs:sequence><xs:attribute name="id" type="xs:string"/></xs:complexType></xs:element>`)
+	}
+	sb.WriteString(`</xs:schema>`)
+
+	schema, err := Parser{MaxDepth: 3}.Parse(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	element := schema.Elements[0]
+	for level := 1; level <= levels; level++ {
+		name := fmt.Sprintf("Level%d", level)
+		if element.Name != name || element.Truncated || len(element.Children) != 1 || len(element.Attributes) != 1 {
+			t.Fatalf("%s: got %s with truncated=%v children=%d attributes=%d, want expanded",
+				name, element.Name, element.Truncated, len(element.Children), len(element.Attributes))
+		}
+		element = element.Children[0]
+	}
+	if element.Name != "Value" || !element.IsLeaf() {
+		t.Errorf("innermost element = %s (leaf=%v), want the Value leaf", element.Name, element.IsLeaf())
+	}
+	if len(schema.Warnings) != 0 {
+		t.Errorf("warnings = %q, want none", schema.Warnings)
+	}
+}
+
+func TestComplexContent(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Name" type="xs:string"/>
+      <xs:element name="Email" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:string"/>
+    <xs:attribute name="internal" type="xs:boolean"/>
+  </xs:complexType>
+  <xs:complexType name="Customer">
+    <xs:complexContent>
+      <xs:extension base="Party">
+        <xs:sequence>
+          <xs:element name="Segment" type="xs:string"/>
+        </xs:sequence>
+        <xs:attribute name="vip" type="xs:boolean"/>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="PublicCustomer">
+    <xs:complexContent>
+      <xs:restriction base="Customer">
+        <xs:sequence>
+          <xs:element name="Name" type="xs:string"/>
+          <xs:element name="Segment" type="xs:string"/>
+        </xs:sequence>
+        <xs:attribute name="id" type="xs:integer"/>
+        <xs:attribute name="internal" use="prohibited"/>
+      </xs:restriction>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:element name="Accounts">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Customer" type="Customer"/>
+        <xs:element name="PublicCustomer" type="PublicCustomer"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	names := func(element Element) (children, attributes []string) {
+		for _, child := range element.Children {
+			children = append(children, child.Name)
+		}
+		for _, attribute := range element.Attributes {
+			attributes = append(attributes, attribute.Name+":"+attribute.Type)
+		}
+		return children, attributes
+	}
+
+	// Extensions append their content to the inherited one
+	children, attributes := names(schema.Elements[0].Children[0])
+	if strings.Join(children, ",") != "Name,Email,Segment" || strings.Join(attributes, ",") != "id:xs:string,internal:xs:boolean,vip:xs:boolean" {
+		t.Errorf("Customer = %v %v, want the Party content followed by its own", children, attributes)
+	}
+
+	// Restrictions keep the elements they restate, and the attributes they do not prohibit
+	children, attributes = names(schema.Elements[0].Children[1])
+	if strings.Join(children, ",") != "Name,Segment" || strings.Join(attributes, ",") != "id:xs:integer,vip:xs:boolean" {
+		t.Errorf("PublicCustomer = %v %v, want Email and internal removed", children, attributes)
+	}
+}
+
+func TestComplexContentCycle(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Buyer">
+    <xs:complexContent>
+      <xs:extension base="Seller">
+        <xs:sequence>
+          <xs:element name="Budget" type="xs:decimal"/>
+        </xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="Seller">
+    <xs:complexContent>
+      <xs:extension base="Buyer">
+        <xs:sequence>
+          <xs:element name="Stock" type="xs:integer"/>
+        </xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="Loop">
+    <xs:complexContent>
+      <xs:restriction base="Loop">
+        <xs:sequence>
+          <xs:element name="Name" type="xs:string"/>
+        </xs:sequence>
+      </xs:restriction>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:element name="Buyer" type="Buyer"/>
+  <xs:element name="Loop" type="Loop"/>
+</xs:schema>`)
+
+	// The derivation closing the cycle is skipped, leaving the other types their inherited content
+	var children []string
+	for _, child := range schema.Elements[0].Children {
+		children = append(children, child.Name)
+	}
+	if strings.Join(children, ",") != "Stock,Budget" {
+		t.Errorf("Buyer children = %v, want Stock,Budget", children)
+	}
+	for _, warning := range []string{
+		"complexTypes Buyer -> Seller -> Buyer derive from each other in a cycle, the derivation of Seller from Buyer was skipped",
+		"complexType Loop derives from itself, its derivation was skipped",
+	} {
+		if !slices.Contains(schema.Warnings, warning) {
+			t.Errorf("warnings = %q, want %q", schema.Warnings, warning)
+		}
+	}
+}
+
+func TestSimpleContent(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="CurrencyCode">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[A-Z]{3}"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="AmountValue">
+    <xs:restriction base="xs:decimal">
+      <xs:fractionDigits value="2"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Amount">
+    <xs:annotation><xs:documentation>Amount of money</xs:documentation></xs:annotation>
+    <xs:simpleContent>
+      <xs:extension base="AmountValue">
+        <xs:attribute name="Ccy" type="CurrencyCode" use="required"/>
+      </xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+  <xs:complexType name="TaxedAmount">
+    <xs:simpleContent>
+      <xs:extension base="Amount">
+        <xs:attribute name="rate" type="xs:decimal"/>
+      </xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+  <xs:element name="Invoice">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Total" type="Amount"/>
+        <xs:element name="Tax" type="TaxedAmount"/>
+        <xs:element name="Weight">
+          <xs:complexType>
+            <xs:simpleContent>
+              <xs:extension base="xs:decimal"/>
+            </xs:simpleContent>
+          </xs:complexType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+	if len(schema.Warnings) > 0 {
+		t.Errorf("warnings = %v, want none", schema.Warnings)
+	}
+
+	// The text is a leaf of the extended type, next to the attributes
+	total := schema.Elements[0].Children[0]
+	if total.IsLeaf() || total.Text == nil || total.Text.Name != "Total" || total.Text.BaseType() != "xs:decimal" || total.Annotation.Text() != "Amount of money" {
+		t.Fatalf("Total = %+v, want an element with attributes and decimal text", total)
+	}
+	if len(total.Attributes) != 1 || total.Attributes[0].Name != "Ccy" || total.Attributes[0].SimpleType == nil {
+		t.Errorf("Total attributes = %+v, want Ccy of CurrencyCode", total.Attributes)
+	} else if patterns := total.Attributes[0].AsElement().Patterns(); !slices.Equal(patterns, []string{"[A-Z]{3}"}) {
+		t.Errorf("Ccy patterns = %v, want [[A-Z]{3}]", patterns)
+	}
+	if totalDigits, fractionDigits := total.Text.Digits(); totalDigits != 0 || fractionDigits != 2 {
+		t.Errorf("Total digits = %d, %d, want 0, 2", totalDigits, fractionDigits)
+	}
+
+	// Extensions of a complexType with simpleContent keep its text and add attributes
+	tax := schema.Elements[0].Children[1]
+	if tax.Text == nil || tax.Text.BaseType() != "xs:decimal" || len(tax.Attributes) != 2 || tax.Attributes[1].Name != "rate" {
+		t.Errorf("Tax = %+v, want the text and Ccy of Amount followed by rate", tax)
+	}
+
+	// Without attributes, the element is a plain leaf of the extended type
+	weight := schema.Elements[0].Children[2]
+	if !weight.IsLeaf() || weight.Text != nil || weight.Type != "xs:decimal" {
+		t.Errorf("Weight = %+v, want a leaf of xs:decimal", weight)
+	}
+}
+
+func TestMixedContent(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Paragraph" mixed="true">
+    <xs:sequence>
+      <xs:element name="b" type="xs:string" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Note">
+    <xs:complexContent mixed="true">
+      <xs:extension base="Paragraph"/>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:element name="Text">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="p" type="Paragraph"/>
+        <xs:element name="note" type="Note"/>
+        <xs:element name="caption">
+          <xs:complexType mixed="true"/>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	// The text between the children is an optional string next to them
+	for _, element := range schema.Elements[0].Children[:2] {
+		if !element.IsMixed() || element.Text.Type != "xs:string" || !element.Text.IsOptional() || len(element.Children) != 1 {
+			t.Errorf("%s = %+v, want mixed content with an optional string text", element.Name, element)
+		}
+	}
+	// Without children nor attributes, mixed content is a plain string
+	if caption := schema.Elements[0].Children[2]; !caption.IsLeaf() || caption.Text != nil || caption.Type != "xs:string" {
+		t.Errorf("caption = %+v, want a leaf of xs:string", caption)
+	}
+
+	var sb strings.Builder
+	if err := Write(&sb, schema); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	written := parseString(t, sb.String())
+	if p := written.Elements[0].Children[0]; !p.IsMixed() || p.Children[0].Name != "b" {
+		t.Errorf("written p = %+v, want mixed content around b", p)
+	}
+}
+
+func TestResolutionWarnings(t *testing.T) {
+	var logs bytes.Buffer
+	parser := Parser{Logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: LevelTrace}))}
+	schema, err := parser.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:ext="http://example.com/ext">
+  <xs:import namespace="http://example.com/ext"/>
+  <xs:complexType name="AmountType">
+    <xs:simpleContent>
+      <xs:restriction base="ext:AmountType">
+        <xs:maxLength value="18"/>
+      </xs:restriction>
+    </xs:simpleContent>
+  </xs:complexType>
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Total" type="AmountType"/>
+        <xs:element name="Code" type="ext:CodeType"/>
+        <xs:any minOccurs="0"/>
+      </xs:sequence>
+      <xs:attribute name="origin" type="ext:OriginType"/>
+      <xs:anyAttribute/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	want := []string{
+		"xs:anyAttribute in the complexType of element Order is not supported and was skipped",
+		"xs:simpleContent in complexType AmountType is not supported and was skipped",
+		"type ext:CodeType of element Code could not be resolved, treating it as xs:string",
+		"type ext:OriginType of attribute origin could not be resolved, treating it as xs:string",
+	}
+	if strings.Join(schema.Warnings, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings:\n%s\nwant:\n%s", strings.Join(schema.Warnings, "\n"), strings.Join(want, "\n"))
+	}
+	if strings.Join(schema.Unsupported, "\n") != strings.Join(want[:2], "\n") {
+		t.Errorf("unsupported:\n%s\nwant:\n%s", strings.Join(schema.Unsupported, "\n"), strings.Join(want[:2], "\n"))
+	}
+	if !slices.Equal(schema.Unresolved, []string{"ext:CodeType", "ext:OriginType"}) {
+		t.Errorf("unresolved = %v, want the ext types", schema.Unresolved)
+	}
+	// Skipped content is recorded on the element it belongs to
+	if order := schema.Elements[0]; !slices.Equal(order.Skipped, []string{"anyAttribute"}) || !slices.Equal(order.Children[0].Skipped, []string{"simpleContent"}) {
+		t.Errorf("skipped: Order %v, Total %v, want [anyAttribute] and [simpleContent]", order.Skipped, order.Children[0].Skipped)
+	}
+	for _, message := range []string{"skipped import without schemaLocation", "resolved element"} {
+		if !strings.Contains(logs.String(), message) {
+			t.Errorf("log does not contain %q:\n%s", message, logs.String())
+		}
+	}
+}
+
+func TestIdentityConstraints(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="urn:orders">
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Id" type="xs:string"/>
+        <xs:element name="OrderLine" maxOccurs="unbounded">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Sku" type="xs:string"/>
+            </xs:sequence>
+            <xs:attribute name="id" type="xs:int"/>
+          </xs:complexType>
+        </xs:element>
+        <xs:element name="Shipment" maxOccurs="unbounded">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="LineRef" type="xs:int"/>
+            </xs:sequence>
+          </xs:complexType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+    <xs:key name="OrderLineKey">
+      <xs:selector xpath="tns:OrderLine"/>
+      <xs:field xpath="@id"/>
+    </xs:key>
+    <xs:keyref name="ShipmentLine" refer="tns:OrderLineKey">
+      <xs:selector xpath=".//Shipment"/>
+      <xs:field xpath="LineRef"/>
+    </xs:keyref>
+    <xs:unique name="UniqueId">
+      <xs:selector xpath="."/>
+      <xs:field xpath="Id"/>
+    </xs:unique>
+  </xs:element>
+</xs:schema>`)
+
+	// Identity constraints leave the content complete and are no longer skipped
+	if len(schema.Unsupported) != 0 {
+		t.Errorf("unsupported: %v, want none", schema.Unsupported)
+	}
+	order := schema.Elements[0]
+	if len(order.Skipped) != 0 || len(order.Children) != 3 {
+		t.Fatalf("Order: skipped %v, %d children, want no skipped content and 3 children", order.Skipped, len(order.Children))
+	}
+	for _, tc := range []struct {
+		name        string
+		constraints []string
+		want        string
+	}{
+		{"Id", order.Children[0].Constraints, "Must be unique among Order"},
+		{"OrderLine/@id", order.Children[1].Attributes[0].Constraints, "Key of the OrderLine of Order: must be present and unique"},
+		{"OrderLine/Sku", order.Children[1].Children[0].Constraints, ""},
+		{"Shipment/LineRef", order.Children[2].Children[0].Constraints, "Must reference an existing OrderLine/@id"},
+	} {
+		if got := strings.Join(tc.constraints, "; "); got != tc.want {
+			t.Errorf("%s: constraints %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestXSD11Constructs(t *testing.T) {
+	schema := parseString(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:defaultOpenContent mode="interleave">
+    <xs:any processContents="lax"/>
+  </xs:defaultOpenContent>
+  <xs:complexType name="RangeType">
+    <xs:openContent mode="suffix">
+      <xs:any namespace="##other"/>
+    </xs:openContent>
+    <xs:sequence>
+      <xs:element name="Min" type="xs:int"/>
+      <xs:element name="Max" type="xs:int"/>
+    </xs:sequence>
+    <xs:assert test="Min le Max"/>
+  </xs:complexType>
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Id">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:assertion test="starts-with($value, 'ORD')"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Range" type="RangeType"/>
+        <xs:element name="Amount" type="xs:string">
+          <xs:alternative test="@kind = 'int'" type="xs:int"/>
+          <xs:alternative type="xs:string"/>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	want := []string{
+		"xs:defaultOpenContent is not supported and was skipped",
+		"xs:openContent in complexType RangeType is not supported and was skipped",
+		"conditional type assignment (xs:alternative) of element Amount is not supported, its declared type was used",
+	}
+	if strings.Join(schema.Unsupported, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unsupported:\n%s\nwant:\n%s", strings.Join(schema.Unsupported, "\n"), strings.Join(want, "\n"))
+	}
+	// The content is generated as declared, with the assertions noted on their elements
+	children := schema.Elements[0].Children
+	if len(children) != 3 || len(children[1].Children) != 2 || children[2].Type != "xs:string" {
+		t.Fatalf("children = %+v, want Id, Range with Min and Max, and Amount as xs:string", children)
+	}
+	if !slices.Equal(children[0].Assertions, []string{"starts-with($value, 'ORD')"}) || !slices.Equal(children[1].Assertions, []string{"Min le Max"}) {
+		t.Errorf("assertions: Id %v, Range %v", children[0].Assertions, children[1].Assertions)
+	}
+	if len(children[2].TypeAlternatives) != 2 {
+		t.Errorf("type alternatives of Amount = %v, want 2", children[2].TypeAlternatives)
+	}
+}
+
+func TestImportError(t *testing.T) {
+	_, err := Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:import namespace="http://example.com/missing" schemaLocation="testdata/missing.xsd"/>
+</xs:schema>`))
+	var importError *ImportError
+	if !errors.As(err, &importError) || importError.SchemaLocation != "testdata/missing.xsd" {
+		t.Errorf("Parse: error %v, want an ImportError for testdata/missing.xsd", err)
+	}
+}
+
+func TestFetchAuthentication(t *testing.T) {
+	// The common schema is served from another host, which must not receive the credentials
+	common := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("credentials sent to %s", r.Host)
+		}
+		io.WriteString(w, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/common">
+  <xs:simpleType name="CodeType"><xs:restriction base="xs:string"/></xs:simpleType>
+</xs:schema>`)
+	}))
+	defer common.Close()
+	commonURL := strings.Replace(common.URL, "127.0.0.1", "localhost", 1)
+
+	partner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/order.xsd":
+			io.WriteString(w, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:c="http://example.com/common">
+  <xs:include schemaLocation="types.xsd"/>
+  <xs:import namespace="http://example.com/common" schemaLocation="`+commonURL+`/common.xsd"/>
+  <xs:element name="Order" type="OrderType"/>
+</xs:schema>`)
+		case "/types.xsd":
+			io.WriteString(w, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:c="http://example.com/common">
+  <xs:complexType name="OrderType">
+    <xs:sequence><xs:element name="Code" type="c:CodeType"/></xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer partner.Close()
+
+	if _, err := ParseFile(partner.URL + "/order.xsd"); err == nil {
+		t.Error("ParseFile without credentials: expected an error")
+	}
+
+	parser := Parser{Fetcher: Fetcher{BearerToken: "secret", AuthHost: "127.0.0.1", Timeout: 5 * time.Second}}
+	schema, err := parser.ParseFile(partner.URL + "/order.xsd")
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(schema.Warnings) > 0 || schema.Elements[0].Children[0].Name != "Code" {
+		t.Errorf("Order = %+v, warnings %v", schema.Elements[0], schema.Warnings)
+	}
+}
+
+func TestElementRefs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"contact.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:contact">
+  <xs:element name="Contact">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Email" type="xs:string"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`,
+		"order.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="urn:order" xmlns:c="urn:contact" targetNamespace="urn:order">
+  <xs:import namespace="urn:contact" schemaLocation="contact.xsd"/>
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element ref="tns:Address" minOccurs="0"/>
+        <xs:element ref="c:Contact" maxOccurs="unbounded"/>
+        <xs:element ref="tns:Missing"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+  <xs:element name="Address">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Street" type="xs:string"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schema, err := Parser{Root: "Order"}.ParseFile(filepath.Join(dir, "order.xsd"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	order := schema.Elements[0]
+	if len(order.Children) != 2 {
+		t.Fatalf("Order children = %+v, want Address and Contact", order.Children)
+	}
+	// The references keep their occurrences and get the content of the global elements
+	address, contact := order.Children[0], order.Children[1]
+	if address.Name != "Address" || address.MinOccurs != "0" || len(address.Children) != 1 || address.Children[0].Name != "Street" {
+		t.Errorf("Address = %+v", address)
+	}
+	if contact.Name != "Contact" || !contact.IsRepeating() || len(contact.Children) != 1 || contact.Children[0].Name != "Email" {
+		t.Errorf("Contact = %+v", contact)
+	}
+	if !slices.Contains(schema.Unresolved, "tns:Missing") {
+		t.Errorf("unresolved = %v, want tns:Missing", schema.Unresolved)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"catalog.xml": `<catalog xmlns="urn:oasis:names:tc:entity:xmlns:xml:catalog">
+  <uri name="http://example.com/schemas/common.xsd" uri="local/common.xsd"/>
+  <group>
+    <rewriteURI uriStartString="http://example.com/iso/" rewritePrefix="iso/"/>
+  </group>
+  <nextCatalog catalog="more/catalog.xml"/>
+</catalog>`,
+		"more/catalog.xml": `<catalog xmlns="urn:oasis:names:tc:entity:xmlns:xml:catalog">
+  <uri name="http://example.com/codes" uri="../local/codes.xsd"/>
+</catalog>`,
+		"local/common.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/common">
+  <xs:simpleType name="NameType"><xs:restriction base="xs:string"/></xs:simpleType>
+</xs:schema>`,
+		"iso/v2/amount.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/iso">
+  <xs:simpleType name="AmountType"><xs:restriction base="xs:decimal"/></xs:simpleType>
+</xs:schema>`,
+		"local/codes.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/codes">
+  <xs:simpleType name="CodeType"><xs:restriction base="xs:token"/></xs:simpleType>
+</xs:schema>`,
+		"order.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"
+    xmlns:c="http://example.com/common" xmlns:i="http://example.com/iso" xmlns:k="http://example.com/codes">
+  <xs:import namespace="http://example.com/common" schemaLocation="http://example.com/schemas/common.xsd"/>
+  <xs:import namespace="http://example.com/iso" schemaLocation="http://example.com/iso/v2/amount.xsd"/>
+  <xs:import namespace="http://example.com/codes"/>
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Name" type="c:NameType"/>
+        <xs:element name="Amount" type="i:AmountType"/>
+        <xs:element name="Code" type="k:CodeType"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	catalog, err := LoadCatalog(filepath.Join(dir, "catalog.xml"))
+	if err != nil {
+		t.Fatalf("LoadCatalog: %v", err)
+	}
+	schema, err := Parser{Catalog: catalog}.ParseFile(filepath.Join(dir, "order.xsd"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(schema.Warnings) > 0 {
+		t.Errorf("unresolved types: %v", schema.Warnings)
+	}
+	for i, want := range []string{"xs:string", "xs:decimal", "xs:token"} {
+		if got := schema.Elements[0].Children[i].BaseType(); got != want {
+			t.Errorf("%s base type = %s, want %s", schema.Elements[0].Children[i].Name, got, want)
+		}
+	}
+}
+
+func TestGroupReferences(t *testing.T) {
+	schema, err := Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Node">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:group ref="NodeContent"/>
+        <xs:group ref="Loop"/>
+        <xs:group ref="Missing"/>
+      </xs:sequence>
+      <xs:attributeGroup ref="Ids"/>
+    </xs:complexType>
+  </xs:element>
+  <xs:group name="NodeContent">
+    <xs:sequence>
+      <xs:element name="Label" type="xs:string"/>
+      <xs:element name="Child" minOccurs="0">
+        <xs:complexType>
+          <xs:group ref="NodeContent"/>
+        </xs:complexType>
+      </xs:element>
+      <xs:any/>
+    </xs:sequence>
+  </xs:group>
+  <xs:group name="Loop">
+    <xs:sequence>
+      <xs:group ref="Loop"/>
+    </xs:sequence>
+  </xs:group>
+  <xs:attributeGroup name="Ids">
+    <xs:attribute name="id" type="xs:ID"/>
+    <xs:attributeGroup ref="Ids"/>
+  </xs:attributeGroup>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// Recursion through an element is expanded again; only the depth limit stops it
+	node := schema.Elements[0]
+	if len(node.Children) != 3 || node.Children[1].Children[0].Name != "Label" || !node.Children[2].IsWildcard() {
+		t.Fatalf("children = %+v", node.Children)
+	}
+	if len(node.Attributes) != 1 || node.Attributes[0].Name != "id" {
+		t.Errorf("attributes = %+v", node.Attributes)
+	}
+	for _, warning := range []string{
+		"group Loop contains itself and was skipped",
+		"group Missing could not be resolved and was skipped",
+		"recursive type of element Child truncated at depth 10",
+	} {
+		if !slices.Contains(schema.Warnings, warning) {
+			t.Errorf("warnings %q do not contain %q", schema.Warnings, warning)
+		}
+	}
+}
+
+// Helper function to generate a schema library the size of the ISO 20022 or SAP ones: many
+// global elements, each with a complexType of a few leaves and, in chains of ten, a
+// reference to the next one
+func largeSchema(types int) string {
+	var sb strings.Builder
+	sb.WriteString(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns="urn:large" targetNamespace="urn:large">` + "\n")
+	for i := 0; i < types; i++ {
+		next := ""
+		if i%10 != 9 && i+1 < types {
+			next = fmt.Sprintf(`<xs:element name="Next" type="Type%d" minOccurs="0"/>`, i+1)
+		}
+		fmt.Fprintf(&sb, `  <xs:element name="Message%d" type="Type%d"/>`+"\n", i, i)
+		fmt.Fprintf(&sb, `  <xs:complexType name="Type%d">
+    <xs:annotation><xs:documentation>Type number %d of the library</xs:documentation></xs:annotation>
+    <xs:sequence>
+      <xs:element name="Id" type="xs:string"/>
+      <xs:element name="Amount" type="xs:decimal" minOccurs="0"/>
+      <xs:element name="Code" type="CodeType"/>
+      %s
+    </xs:sequence>
+    <xs:attribute name="version" type="xs:string"/>
+  </xs:complexType>`+"\n", i, i, next)
+	}
+	sb.WriteString(`  <xs:simpleType name="CodeType">
+    <xs:restriction base="xs:string"><xs:enumeration value="A"/><xs:enumeration value="B"/></xs:restriction>
+  </xs:simpleType>
+</xs:schema>`)
+	return sb.String()
+}
+
+func BenchmarkParse(b *testing.B) {
+	content := largeSchema(2000)
+	b.SetBytes(int64(len(content)))
+	for _, bench := range []struct {
+		name   string
+		parser Parser
+	}{
+		{"all", Parser{}},
+		{"root", Parser{Root: "Message0"}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := bench.parser.Parse(strings.NewReader(content)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDTD(t *testing.T) {
+	dir := t.TempDir()
+	// External parameter entities are read relative to the DTD declaring them
+	if err := os.WriteFile(filepath.Join(dir, "common.ent"), []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!ENTITY % codes "(new|used)">
+<!ELEMENT Price (#PCDATA)>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	content := `<!ENTITY % common SYSTEM "common.ent">
+%common;
+<!ENTITY % legacy "INCLUDE">
+<!ELEMENT Catalog (Item*, Extra)>
+<!ELEMENT Item ((Name | Title), Price?, Item*)>
+<!ATTLIST Item
+  condition %codes; #REQUIRED
+  xmlns CDATA #FIXED "http://example.com/catalog">
+<!ELEMENT Name (#PCDATA)>
+<!ELEMENT Title (#PCDATA)>
+<![%legacy;[
+<!ELEMENT Extra ANY>
+]]>
+<![IGNORE[
+<!ELEMENT Extra EMPTY>
+<![INCLUDE[ <!ELEMENT Ignored EMPTY> ]]>
+]]>
+<!ELEMENT Orphan EMPTY>`
+	if err := os.WriteFile(filepath.Join(dir, "catalog.dtd"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := ParseDTDFile(filepath.Join(dir, "catalog.dtd"))
+	if err != nil {
+		t.Fatalf("ParseDTDFile: %v", err)
+	}
+	// Elements no other element contains are the candidate roots
+	var roots []string
+	for _, element := range schema.Elements {
+		roots = append(roots, element.Name)
+	}
+	if !slices.Equal(roots, []string{"Catalog", "Orphan"}) {
+		t.Fatalf("roots = %v, want Catalog and Orphan", roots)
+	}
+	if len(schema.Unsupported) != 0 {
+		t.Errorf("unsupported = %v, want none", schema.Unsupported)
+	}
+
+	item := schema.Elements[0].Children[0]
+	if item.Name != "Item" || item.MinOccurs != "0" || item.MaxOccurs != "unbounded" {
+		t.Fatalf("first child = %+v, want repeating optional Item", item)
+	}
+	if names := []string{item.Children[0].Name, item.Children[1].Name, item.Children[2].Name}; !slices.Equal(names, []string{"Name", "Title", "Price"}) || !item.Children[1].ChoiceItem {
+		t.Errorf("Item children = %v, want the Name and Title branches and Price", names)
+	}
+	if len(item.Attributes) != 1 || item.Attributes[0].Use != "required" || !slices.Equal(item.Attributes[0].AsElement().Enumerations(), []string{"new", "used"}) {
+		t.Errorf("Item attributes = %+v, want the required condition enumeration", item.Attributes)
+	}
+
+	// Any declared element may be chosen as the root
+	schema, err = Parser{Root: "Item"}.ParseDTDFile(filepath.Join(dir, "catalog.dtd"))
+	if err != nil {
+		t.Fatalf("ParseDTDFile with root: %v", err)
+	}
+	if schema, err = schema.SelectRoot("Item"); err != nil || len(schema.Elements[0].Children) != 4 {
+		t.Errorf("SelectRoot(Item): %v, want Item with its content", err)
+	}
+
+	for _, invalid := range []string{
+		`<!ELEMENT A (%missing;)>`,
+		`<!ELEMENT A (B, C | D)>`,
+		`<!ATTLIST A b BOGUS #IMPLIED>`,
+		`<!ELEMENT A (B>`,
+	} {
+		if _, err := ParseDTD(strings.NewReader(invalid)); err == nil {
+			t.Errorf("ParseDTD(%q): expected an error", invalid)
+		}
+	}
+}
+
+func TestParseRelaxNG(t *testing.T) {
+	dir := t.TempDir()
+	// Included grammars are read relative to the including one, and overridden by its defines
+	if err := os.WriteFile(filepath.Join(dir, "common.rng"), []byte(`<grammar xmlns="http://relaxng.org/ns/structure/1.0"
+    datatypeLibrary="http://www.w3.org/2001/XMLSchema-datatypes">
+  <define name="code"><data type="token"/></define>
+  <define name="price"><element name="Price"><data type="decimal"/></element></define>
+</grammar>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	content := `<grammar xmlns="http://relaxng.org/ns/structure/1.0"
+    xmlns:a="http://relaxng.org/ns/compatibility/annotations/1.0"
+    ns="http://example.com/catalog"
+    datatypeLibrary="http://www.w3.org/2001/XMLSchema-datatypes">
+  <include href="common.rng">
+    <define name="code"><choice><value>new</value><value>used</value></choice></define>
+  </include>
+  <start>
+    <element name="Catalog">
+      <zeroOrMore><ref name="item"/></zeroOrMore>
+      <externalRef href="extra.rng"/>
+    </element>
+  </start>
+  <define name="item">
+    <element name="Item">
+      <a:documentation>An item of the catalog</a:documentation>
+      <attribute name="condition"><ref name="code"/></attribute>
+      <optional><attribute name="lot"><data type="int"/></attribute></optional>
+      <choice>
+        <element name="Name"><text/></element>
+        <element name="Title"><text/></element>
+      </choice>
+      <optional><ref name="price"/></optional>
+    </element>
+  </define>
+</grammar>`
+	if err := os.WriteFile(filepath.Join(dir, "catalog.rng"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := ParseRelaxNGFile(filepath.Join(dir, "catalog.rng"))
+	if err != nil {
+		t.Fatalf("ParseRelaxNGFile: %v", err)
+	}
+	if len(schema.Elements) != 1 || schema.Elements[0].Name != "Catalog" || schema.TargetNamespace != "http://example.com/catalog" {
+		t.Fatalf("elements = %+v in %q, want Catalog in the catalog namespace", schema.Elements, schema.TargetNamespace)
+	}
+	if want := []string{"RELAX NG externalRef extra.rng is not supported and was skipped"}; !slices.Equal(schema.Unsupported, want) {
+		t.Errorf("unsupported = %v, want %v", schema.Unsupported, want)
+	}
+
+	item := schema.Elements[0].Children[0]
+	if item.Name != "Item" || item.MinOccurs != "0" || item.MaxOccurs != "unbounded" || item.Annotation.Text() != "An item of the catalog" {
+		t.Fatalf("first child = %+v, want repeating optional documented Item", item)
+	}
+	if names := []string{item.Children[0].Name, item.Children[1].Name, item.Children[2].Name}; !slices.Equal(names, []string{"Name", "Title", "Price"}) || !item.Children[1].ChoiceItem {
+		t.Errorf("Item children = %v, want the Name and Title branches and Price", names)
+	}
+	if price := item.Children[2]; price.Type != "xs:decimal" || price.MinOccurs != "0" {
+		t.Errorf("Price = %+v, want an optional decimal from the included grammar", price)
+	}
+	if len(item.Attributes) != 2 || item.Attributes[0].Use != "required" || item.Attributes[1].Use == "required" {
+		t.Fatalf("Item attributes = %+v, want the required condition and the optional lot", item.Attributes)
+	}
+	if values := item.Attributes[0].AsElement().Enumerations(); !slices.Equal(values, []string{"new", "used"}) {
+		t.Errorf("condition values = %v, want the overriding define", values)
+	}
+
+	// The compact syntax describes the same model
+	compact := `default namespace = "http://example.com/catalog"
+start = element Catalog { item* }
+## An item of the catalog
+item = element Item {
+  attribute condition { "new" | "used" },
+  attribute lot { xsd:int }?,
+  (element Name { text } | element Title { text }),
+  element Price { xsd:decimal }?
+}`
+	if err := os.WriteFile(filepath.Join(dir, "catalog.rnc"), []byte(compact), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fromCompact, err := ParseRelaxNGFile(filepath.Join(dir, "catalog.rnc"))
+	if err != nil {
+		t.Fatalf("ParseRelaxNGFile(compact): %v", err)
+	}
+	compactItem := fromCompact.Elements[0].Children[0]
+	if len(compactItem.Children) != 3 || len(compactItem.Attributes) != 2 || compactItem.Children[2].Type != "xs:decimal" {
+		t.Errorf("compact Item = %+v, want the content of the XML syntax", compactItem)
+	}
+
+	// The element of a define may be chosen as the root
+	schema, err = Parser{Root: "Item"}.ParseRelaxNGFile(filepath.Join(dir, "catalog.rnc"))
+	if err != nil {
+		t.Fatalf("ParseRelaxNGFile with root: %v", err)
+	}
+	if schema, err = schema.SelectRoot("Item"); err != nil || len(schema.Elements[0].Children) != 3 {
+		t.Errorf("SelectRoot(Item): %v, want Item with its content", err)
+	}
+
+	for _, invalid := range []string{
+		`<element xmlns="http://relaxng.org/ns/structure/1.0"><text/></element>`,
+		`<grammar xmlns="http://relaxng.org/ns/structure/1.0"><define name="a"><text/></define></grammar>`,
+		`<schema xmlns="http://www.w3.org/2001/XMLSchema"/>`,
+	} {
+		if _, err := ParseRelaxNG(strings.NewReader(invalid)); err == nil {
+			t.Errorf("ParseRelaxNG(%q): expected an error", invalid)
+		}
+	}
+}
+
+func TestInfer(t *testing.T) {
+	sample := `<Catalog xmlns="http://example.com/catalog" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" version="1">
+  <Item sku="A1" code="007">
+    <Name>Chair</Name>
+    <Price>12</Price>
+    <Added>2024-01-31</Added>
+  </Item>
+  <Item sku="B2">
+    <Name>Table</Name>
+    <Tag>new</Tag>
+    <Tag>oak</Tag>
+    <Price>99.50</Price>
+    <Added>2024-02-01T10:00:00+01:00</Added>
+    <InStock>true</InStock>
+  </Item>
+  <Owner xsi:nil="true"/>
+</Catalog>`
+
+	schema, err := Infer(strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("Infer: %v", err)
+	}
+	if schema.TargetNamespace != "http://example.com/catalog" || schema.ElementFormDefault != "qualified" {
+		t.Errorf("namespace = %q %q, want the qualified namespace of the root", schema.TargetNamespace, schema.ElementFormDefault)
+	}
+	catalog := schema.Elements[0]
+	if len(catalog.Attributes) != 1 || catalog.Attributes[0].Type != "xs:integer" || catalog.Attributes[0].Use != "required" {
+		t.Errorf("Catalog attributes = %+v, want the required integer version", catalog.Attributes)
+	}
+	item, owner := catalog.Children[0], catalog.Children[1]
+	if item.MaxOccurs != "unbounded" || item.MinOccurs != "" || !owner.Nillable {
+		t.Errorf("Item = %+v, Owner = %+v, want a repeating Item and a nillable Owner", item, owner)
+	}
+
+	// Children found in later instances keep their place among the others
+	var names, types []string
+	for _, child := range item.Children {
+		names = append(names, child.Name)
+		types = append(types, child.Type)
+	}
+	if want := []string{"Name", "Tag", "Price", "Added", "InStock"}; !slices.Equal(names, want) {
+		t.Errorf("Item children = %v, want %v", names, want)
+	}
+	if want := []string{"xs:string", "xs:string", "xs:decimal", "xs:string", "xs:boolean"}; !slices.Equal(types, want) {
+		t.Errorf("Item types = %v, want %v", types, want)
+	}
+	if tag := item.Children[1]; tag.MinOccurs != "0" || tag.MaxOccurs != "unbounded" {
+		t.Errorf("Tag = %+v, want an optional repeating element", tag)
+	}
+	// Numbers with leading zeros are identifiers
+	if code := item.Attributes[1]; code.Name != "code" || code.Type != "xs:string" || code.Use == "required" {
+		t.Errorf("code = %+v, want an optional string", code)
+	}
+
+	for value, want := range map[string]string{
+		"<a>2024-01-31</a>":           "xs:date",
+		"<a>2024-01-31T08:00:00Z</a>": "xs:dateTime",
+		"<a>-3</a>":                   "xs:integer",
+		"<a>1234567890123456</a>":     "xs:string",
+		"<a/>":                        "xs:string",
+	} {
+		schema, err := Infer(strings.NewReader(value))
+		if err != nil || schema.Elements[0].Type != want {
+			t.Errorf("Infer(%s) = %+v, %v, want %s", value, schema.Elements, err, want)
+		}
+	}
+
+	for _, invalid := range []string{"", "<!-- no element -->", "<a><b></a>"} {
+		if _, err := Infer(strings.NewReader(invalid)); err == nil {
+			t.Errorf("Infer(%q): expected an error", invalid)
+		}
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	schema, err := Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="StatusType">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="open"/>
+      <xs:enumeration value="closed"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Status" type="StatusType"/>
+        <xs:element name="Note" type="xs:string" maxOccurs="unbounded"/>
+        <xs:element name="Line" maxOccurs="unbounded">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="Sku" type="xs:string"/>
+              <xs:element name="Quantity" type="xs:integer"/>
+            </xs:sequence>
+            <xs:attribute name="unit">
+              <xs:simpleType>
+                <xs:restriction base="xs:string">
+                  <xs:enumeration value="kg"/>
+                  <xs:enumeration value="pcs"/>
+                  <xs:enumeration value="m"/>
+                </xs:restriction>
+              </xs:simpleType>
+            </xs:attribute>
+          </xs:complexType>
+        </xs:element>
+      </xs:sequence>
+      <xs:attribute name="id" type="xs:string"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	want := Statistics{
+		Elements:          6,
+		Attributes:        2,
+		MaxDepth:          3,
+		Types:             3, // StatusType, xs:string and xs:integer
+		Enumerations:      2,
+		EnumerationValues: 5,
+		Repeating:         2,
+		RepeatingGroups:   1,
+	}
+	if got := schema.Statistics(); got != want {
+		t.Errorf("Statistics() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseEncodings(t *testing.T) {
+	content := `<?xml version="1.0" encoding="%s"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Größe">
+    <xs:simpleType>
+      <xs:restriction base="xs:string">
+        <xs:enumeration value="Märklin"/>
+        <xs:enumeration value="€uro"/>
+      </xs:restriction>
+    </xs:simpleType>
+  </xs:element>
+</xs:schema>`
+
+	// Helper function to encode content in a single byte encoding, euro sign included
+	singleByte := func(encoding string, euro byte) []byte {
+		var data []byte
+		for _, r := range fmt.Sprintf(content, encoding) {
+			if r == '€' {
+				data = append(data, euro)
+			} else {
+				data = append(data, byte(r))
+			}
+		}
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{"UTF-8 with BOM", append([]byte{0xEF, 0xBB, 0xBF}, fmt.Sprintf(content, "UTF-8")...), []string{"Märklin", "€uro"}},
+		{"UTF-16LE with BOM", encodeUTF16(fmt.Sprintf(content, "UTF-16"), false), []string{"Märklin", "€uro"}},
+		{"UTF-16BE with BOM", encodeUTF16(fmt.Sprintf(content, "UTF-16"), true), []string{"Märklin", "€uro"}},
+		{"ISO-8859-1", singleByte("ISO-8859-1", 0xA4), []string{"Märklin", "¤uro"}},
+		{"windows-1252", singleByte("windows-1252", 0x80), []string{"Märklin", "€uro"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := Parse(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(schema.Elements) != 1 || schema.Elements[0].Name != "Größe" {
+				t.Fatalf("got elements %+v, want Größe", schema.Elements)
+			}
+			if got := schema.Elements[0].Enumerations(); !slices.Equal(got, tt.want) {
+				t.Errorf("got enumerations %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Parse(strings.NewReader(fmt.Sprintf(content, "EBCDIC"))); err == nil || !strings.Contains(err.Error(), `unsupported encoding "EBCDIC"`) {
+		t.Errorf("got error %v, want unsupported encoding", err)
+	}
+
+	// DTDs are decoded before their declarations are read
+	schema, err := ParseDTD(bytes.NewReader(encodeUTF16("<!ELEMENT Größe (#PCDATA)>", false)))
+	if err != nil {
+		t.Fatalf("ParseDTD: %v", err)
+	}
+	if len(schema.Elements) != 1 || schema.Elements[0].Name != "Größe" {
+		t.Errorf("got DTD elements %+v, want Größe", schema.Elements)
+	}
+}
+
+// Helper function to encode text of the Basic Multilingual Plane as UTF-16, with the byte
+// order mark
+func encodeUTF16(text string, bigEndian bool) []byte {
+	data := []byte{0xFF, 0xFE}
+	if bigEndian {
+		data = []byte{0xFE, 0xFF}
+	}
+	for _, r := range text {
+		if bigEndian {
+			data = append(data, byte(r>>8), byte(r))
+		} else {
+			data = append(data, byte(r), byte(r>>8))
+		}
+	}
+	return data
+}
+
+func TestFetchCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="Order%d" type="xs:string"/></xs:schema>`, requests)
+	}))
+	location := server.URL + "/order.xsd"
+
+	// Helper function to parse the schema with the fetcher, returning the name of its element
+	parse := func(fetcher Fetcher) string {
+		t.Helper()
+		schema, err := Parser{Fetcher: fetcher}.ParseFile(location)
+		if err != nil {
+			t.Fatalf("ParseFile: %v", err)
+		}
+		return schema.Elements[0].Name
+	}
+
+	fetcher := Fetcher{CacheDir: t.TempDir()}
+	if got := parse(fetcher); got != "Order1" {
+		t.Errorf("first fetch = %s, want Order1", got)
+	}
+	if got := parse(fetcher); got != "Order1" || requests != 1 {
+		t.Errorf("cached fetch = %s after %d requests, want Order1 after 1", got, requests)
+	}
+	fetcher.Refresh = true
+	if got := parse(fetcher); got != "Order2" || requests != 2 {
+		t.Errorf("refreshed fetch = %s after %d requests, want Order2 after 2", got, requests)
+	}
+
+	// Cached documents are read without the server, for offline runs
+	server.Close()
+	fetcher.Refresh = false
+	if got := parse(fetcher); got != "Order2" {
+		t.Errorf("offline fetch = %s, want Order2", got)
+	}
+	if _, err := (Parser{}).ParseFile(location); err == nil {
+		t.Errorf("uncached fetch succeeded offline")
+	}
+}
+
+// Expired copies are revalidated with their ETag, and failed responses are not cached
+func TestFetchCacheRevalidation(t *testing.T) {
+	requests, revalidated, version, status := 0, 0, 1, http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		etag := fmt.Sprintf(`"v%d"`, version)
+		if r.Header.Get("If-None-Match") == etag {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprintf(w, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="Order%d" type="xs:string"/></xs:schema>`, version)
+	}))
+	defer server.Close()
+	location := server.URL + "/order.xsd"
+
+	// Helper function to parse the schema with the fetcher, returning the name of its element
+	parse := func(fetcher Fetcher) string {
+		t.Helper()
+		schema, err := Parser{Fetcher: fetcher}.ParseFile(location)
+		if err != nil {
+			t.Fatalf("ParseFile: %v", err)
+		}
+		return schema.Elements[0].Name
+	}
+
+	fetcher := Fetcher{CacheDir: t.TempDir()}
+	status = http.StatusInternalServerError
+	if _, err := (Parser{Fetcher: fetcher}).ParseFile(location); err == nil {
+		t.Fatalf("fetch of a failed response succeeded")
+	}
+	if files, _ := os.ReadDir(fetcher.CacheDir); len(files) != 0 {
+		t.Errorf("failed response was cached: %v", files)
+	}
+
+	status = http.StatusOK
+	if got := parse(fetcher); got != "Order1" {
+		t.Errorf("first fetch = %s, want Order1", got)
+	}
+	fetcher.CacheTTL = time.Nanosecond
+	if got := parse(fetcher); got != "Order1" || revalidated != 1 {
+		t.Errorf("revalidated fetch = %s after %d revalidations, want Order1 after 1", got, revalidated)
+	}
+	version = 2
+	if got := parse(fetcher); got != "Order2" {
+		t.Errorf("fetch of a changed document = %s, want Order2", got)
+	}
+
+	// Copies are used as is within their TTL
+	fetcher.CacheTTL = time.Hour
+	version, count := 3, requests
+	if got := parse(fetcher); got != "Order2" || requests != count {
+		t.Errorf("fetch within the TTL = %s after %d requests, want Order2 without any", got, requests-count)
+	}
+}
+
+func TestRedefine(t *testing.T) {
+	dir := t.TempDir()
+	base := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/order" xmlns="http://example.com/order">
+  <xs:complexType name="AddressType">
+    <xs:sequence>
+      <xs:element name="Street" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:integer">
+      <xs:maxInclusive value="999"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:group name="NameGroup">
+    <xs:sequence>
+      <xs:element name="First" type="xs:string"/>
+    </xs:sequence>
+  </xs:group>
+  <xs:attributeGroup name="Common">
+    <xs:attribute name="id" type="xs:string"/>
+  </xs:attributeGroup>
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Address" type="AddressType"/>
+        <xs:element name="Code" type="Code"/>
+        <xs:group ref="NameGroup"/>
+      </xs:sequence>
+      <xs:attributeGroup ref="Common"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+	if err := os.WriteFile(filepath.Join(dir, "base.xsd"), []byte(base), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Redefinitions derive from, or refer to, the definitions they replace
+	redefine := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/order" xmlns:o="http://example.com/order">
+  <xs:redefine schemaLocation="base.xsd">
+    <xs:complexType name="AddressType">
+      <xs:complexContent>
+        <xs:extension base="o:AddressType">
+          <xs:sequence>
+            <xs:element name="Country" type="xs:string"/>
+          </xs:sequence>
+        </xs:extension>
+      </xs:complexContent>
+    </xs:complexType>
+    <xs:simpleType name="Code">
+      <xs:restriction base="o:Code">
+        <xs:maxInclusive value="99"/>
+      </xs:restriction>
+    </xs:simpleType>
+    <xs:group name="NameGroup">
+      <xs:sequence>
+        <xs:group ref="o:NameGroup"/>
+        <xs:element name="Last" type="xs:string"/>
+      </xs:sequence>
+    </xs:group>
+    <xs:attributeGroup name="Common">
+      <xs:attributeGroup ref="o:Common"/>
+      <xs:attribute name="version" type="xs:string"/>
+    </xs:attributeGroup>
+  </xs:redefine>
+</xs:schema>`
+	if err := os.WriteFile(filepath.Join(dir, "redefine.xsd"), []byte(redefine), 0644); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := ParseFile(filepath.Join(dir, "redefine.xsd"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(schema.Elements) != 1 || len(schema.Unresolved) > 0 || len(schema.Unsupported) > 0 {
+		t.Fatalf("got %d elements, unresolved %v, unsupported %v, want Order alone", len(schema.Elements), schema.Unresolved, schema.Unsupported)
+	}
+	order := schema.Elements[0]
+	if got := elementNames(order.Children); !slices.Equal(got, []string{"Address", "Code", "First", "Last"}) {
+		t.Errorf("Order children = %v, want Address, Code, First and Last", got)
+	}
+	if got := elementNames(order.Children[0].Children); !slices.Equal(got, []string{"Street", "Country"}) {
+		t.Errorf("Address children = %v, want Street and Country", got)
+	}
+	if code := order.Children[1]; code.BaseType() != "xs:integer" || code.SimpleType.Restriction.MaxInclusive.Value != "99" {
+		t.Errorf("Code: base type %s, maxInclusive %+v, want xs:integer up to 99", code.BaseType(), code.SimpleType.Restriction.MaxInclusive)
+	}
+	if len(order.Attributes) != 2 || order.Attributes[0].Name != "version" || order.Attributes[1].Name != "id" {
+		t.Errorf("Order attributes = %+v, want version and the id of the original", order.Attributes)
+	}
+
+	// Overrides replace the definitions, elements included
+	override := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/order">
+  <xs:override schemaLocation="base.xsd">
+    <xs:complexType name="AddressType">
+      <xs:sequence>
+        <xs:element name="City" type="xs:string"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:override>
+</xs:schema>`
+	if err := os.WriteFile(filepath.Join(dir, "override.xsd"), []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+	schema, err = ParseFile(filepath.Join(dir, "override.xsd"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if got := elementNames(schema.Elements[0].Children[0].Children); !slices.Equal(got, []string{"City"}) {
+		t.Errorf("overridden Address children = %v, want City", got)
+	}
+}
+
+// Helper function to list the names of elements
+func elementNames(elements []Element) []string {
+	var names []string
+	for _, element := range elements {
+		names = append(names, element.Name)
+	}
+	return names
+}
+
+func TestParseFileContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/order.xsd" {
+			io.WriteString(w, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:include schemaLocation="types.xsd"/>
+  <xs:element name="Order" type="xs:string"/>
+</xs:schema>`)
+			// The imports are fetched once the caller has given up
+			cancel()
+			return
+		}
+		io.WriteString(w, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"/>`)
+	}))
+	defer server.Close()
+
+	if _, err := (Parser{}).ParseFileContext(ctx, server.URL+"/order.xsd"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ParseFileContext after cancellation: error %v, want context.Canceled", err)
+	}
+	if _, err := (Parser{}).ParseFileContext(context.Background(), server.URL+"/order.xsd"); err != nil {
+		t.Errorf("ParseFileContext: %v", err)
+	}
+}
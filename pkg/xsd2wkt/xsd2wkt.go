@@ -0,0 +1,113 @@
+// Package xsd2wkt converts an XSD into a Workato schema and a Mustache XML message template
+// in a single call, for services embedding the conversion. The settings are given as
+// functional options, and the context cancels the fetching of remote imports.
+package xsd2wkt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/peaz/xsd2wkt/pkg/template/mustache"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Result holds the outputs of a conversion
+type Result struct {
+	Schema   xsd.Schema      // Resolved element tree of the document root
+	Fields   []workato.Field // Fields of the Workato schema
+	Template string          // Mustache template of the XML message
+}
+
+// Settings of a conversion, set by the options
+type settings struct {
+	parser xsd.Parser
+	opts   workato.Options
+}
+
+// Option sets a setting of a conversion
+type Option func(*settings)
+
+// Function to set the element nesting depth at which recursive types stop being expanded,
+// xsd.DefaultMaxDepth by default
+func WithMaxDepth(depth int) Option {
+	return func(s *settings) { s.parser.MaxDepth = depth }
+}
+
+// Function to set the strategy naming nested fields: workato.NamingFlat (the default),
+// workato.NamingNested or workato.NamingPath
+func WithNaming(naming string) Option {
+	return func(s *settings) { s.opts.Naming = naming }
+}
+
+// Function to set the custom type rules, which take precedence over workato.DefaultTypeMap
+func WithTypeMap(typeMap workato.TypeMap) Option {
+	return func(s *settings) { s.opts.TypeMap = typeMap }
+}
+
+// Function to set the prefix of the fields generated from XML attributes, @ by default
+func WithAttributePrefix(prefix string) Option {
+	return func(s *settings) { s.opts.AttributePrefix = prefix }
+}
+
+// Function to set the global element used as the document root, the first one by default
+func WithRoot(name string) Option {
+	return func(s *settings) { s.parser.Root = name }
+}
+
+// Function to set the fetcher reading the documents from URLs, such as one authenticating
+// its requests
+func WithFetcher(fetcher xsd.Fetcher) Option {
+	return func(s *settings) { s.parser.Fetcher = fetcher }
+}
+
+// Function to set the logger receiving the documents loaded and the elements resolved
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *settings) { s.parser.Logger = logger }
+}
+
+// Function to apply the options on top of the defaults
+func newSettings(options []Option) settings {
+	s := settings{opts: workato.Options{AttributePrefix: "@"}}
+	for _, option := range options {
+		option(&s)
+	}
+	return s
+}
+
+// Function to convert the XSD file, or HTTP(S) URL, at location. Includes and imports are
+// resolved against its location.
+func Convert(ctx context.Context, location string, options ...Option) (Result, error) {
+	s := newSettings(options)
+	schema, err := s.parser.ParseFileContext(ctx, location)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to parse %s: %w", location, err)
+	}
+	return s.generate(schema)
+}
+
+// Function to convert an XSD document read from r. Relative includes and imports are
+// resolved against the working directory.
+func ConvertReader(ctx context.Context, r io.Reader, options ...Option) (Result, error) {
+	s := newSettings(options)
+	schema, err := s.parser.ParseContext(ctx, r)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to parse XSD: %w", err)
+	}
+	return s.generate(schema)
+}
+
+// Function to generate the outputs of the root element of a parsed schema
+func (s settings) generate(schema xsd.Schema) (Result, error) {
+	schema, err := schema.SelectRoot(s.parser.Root)
+	if err != nil {
+		return Result{}, err
+	}
+	fields, err := workato.Generate(schema, s.opts)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to generate Workato schema: %w", err)
+	}
+	return Result{Schema: schema, Fields: fields, Template: mustache.Generate(schema, mustache.Options{Options: s.opts})}, nil
+}
@@ -0,0 +1,65 @@
+package xsd2wkt
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+)
+
+// Fixtures and goldens of the command line, which Convert must reproduce with its defaults
+var cliTestdata = filepath.Join("..", "..", "src", "xsd2wkt", "testdata")
+
+// Helper function to read a golden file of the command line
+func readGolden(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(cliTestdata, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestConvert(t *testing.T) {
+	result, err := Convert(context.Background(), filepath.Join(cliTestdata, "imports.xsd"))
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	if want := readGolden(t, "imports.template"); result.Template != want {
+		t.Errorf("template mismatch\n--- got ---\n%s\n--- want ---\n%s", result.Template, want)
+	}
+	fields, err := json.MarshalIndent(result.Fields, "", "  ")
+	if err != nil {
+		t.Fatalf("marshaling fields: %v", err)
+	}
+	if want := readGolden(t, "imports-schema.json"); string(fields) != want {
+		t.Errorf("Workato schema mismatch\n--- got ---\n%s\n--- want ---\n%s", fields, want)
+	}
+
+	// The options apply like the flags of the command line
+	result, err = Convert(context.Background(), filepath.Join(cliTestdata, "nested.xsd"), WithNaming(workato.NamingNested))
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	if street := result.Fields[0].Properties[1].Properties[1].Properties[0].Name; street != "Street" {
+		t.Errorf("Street field = %s, want Street", street)
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	ctx := context.Background()
+	if _, err := ConvertReader(ctx, strings.NewReader("<xs:schema")); err == nil || !strings.Contains(err.Error(), "failed to parse XSD") {
+		t.Errorf("ConvertReader of a malformed XSD: error %v, want a parse error", err)
+	}
+	missing := filepath.Join(t.TempDir(), "missing.xsd")
+	if _, err := Convert(ctx, missing); err == nil || !strings.Contains(err.Error(), "failed to parse "+missing) {
+		t.Errorf("Convert of a missing file: error %v, want a parse error", err)
+	}
+	if _, err := Convert(ctx, filepath.Join(cliTestdata, "nested.xsd"), WithRoot("Invoice")); err == nil {
+		t.Error("Convert with an undeclared root: expected an error")
+	}
+}
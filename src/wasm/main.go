@@ -0,0 +1,94 @@
+//go:build js && wasm
+
+// Command wasm exposes the converter to JavaScript, so that it can run entirely in the
+// browser. Build it with:
+//
+//	GOOS=js GOARCH=wasm go build -o xsd2wkt.wasm ./src/wasm
+//
+// and load it with xsd2wkt.js next to Go's wasm_exec.js.
+package main
+
+import (
+	"strings"
+	"syscall/js"
+
+	"github.com/peaz/xsd2wkt/pkg/template/liquid"
+	"github.com/peaz/xsd2wkt/pkg/template/mustache"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+func main() {
+	js.Global().Set("xsd2wktConvert", js.FuncOf(convert))
+	select {} // Keep the functions available to JavaScript
+}
+
+// Function to convert the XSD text of args[0] with the options object of args[1], whose
+// optional properties are attrPrefix, root, engine (mustache or liquid), naming (flat,
+// nested or path), case (original, camel, snake or pascal) and inferControls. Returns an object with the Workato schema JSON and the template, or
+// with an error message.
+func convert(this js.Value, args []js.Value) any {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return map[string]any{"error": "convert expects the XSD text as its first argument"}
+	}
+	options := js.Undefined()
+	if len(args) > 1 {
+		options = args[1]
+	}
+
+	opts := workato.Options{
+		AttributePrefix: stringOption(options, "attrPrefix", "@"),
+		InferControls:   options.Type() == js.TypeObject && options.Get("inferControls").Truthy(),
+		Naming:          stringOption(options, "naming", workato.NamingFlat),
+		Case:            stringOption(options, "case", workato.CaseOriginal),
+	}
+	if opts.Naming != workato.NamingFlat && opts.Naming != workato.NamingNested && opts.Naming != workato.NamingPath {
+		return map[string]any{"error": "naming must be one of flat, nested or path"}
+	}
+	switch opts.Case {
+	case workato.CaseOriginal, workato.CaseCamel, workato.CaseSnake, workato.CasePascal:
+	default:
+		return map[string]any{"error": "case must be one of original, camel, snake or pascal"}
+	}
+
+	schema, err := xsd.Parse(strings.NewReader(args[0].String()))
+	if err != nil {
+		return map[string]any{"error": "Error parsing XSD: " + err.Error()}
+	}
+	schema, err = schema.SelectRoot(stringOption(options, "root", ""))
+	if err != nil {
+		return map[string]any{"error": "Error selecting root element: " + err.Error()}
+	}
+
+	fields, err := workato.Generate(schema, opts)
+	if err != nil {
+		return map[string]any{"error": "Error generating Workato Schema: " + err.Error()}
+	}
+	schemaJSON, err := workato.Marshal(fields)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+
+	var template string
+	switch engine := stringOption(options, "engine", "mustache"); engine {
+	case "mustache":
+		template = mustache.Generate(schema, mustache.Options{Options: opts})
+	case "liquid":
+		template = liquid.Generate(schema, liquid.Options{Options: opts})
+	default:
+		return map[string]any{"error": "engine must be one of mustache or liquid"}
+	}
+
+	return map[string]any{"schema": string(schemaJSON), "template": template}
+}
+
+// Helper function to read a string property of the options object, or its default
+func stringOption(options js.Value, name, defaultValue string) string {
+	if options.Type() != js.TypeObject {
+		return defaultValue
+	}
+	if value := options.Get(name); value.Type() == js.TypeString {
+		return value.String()
+	}
+	return defaultValue
+}
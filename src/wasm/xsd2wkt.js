@@ -0,0 +1,20 @@
+// Bridge loading xsd2wkt.wasm in the browser. Requires Go's wasm_exec.js, copied from
+// "$(go env GOROOT)/lib/wasm/wasm_exec.js" (misc/wasm before Go 1.24).
+//
+//   const converter = await loadXsd2wkt("xsd2wkt.wasm");
+//   const { schema, template } = converter.convert(xsdText, { engine: "liquid" });
+async function loadXsd2wkt(url) {
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiateStreaming(fetch(url), go.importObject);
+  go.run(instance); // Runs until the page is closed
+  return {
+    // Returns the parsed Workato schema and the template, or throws on conversion errors
+    convert(xsd, options = {}) {
+      const result = globalThis.xsd2wktConvert(xsd, options);
+      if (result.error) {
+        throw new Error(result.error);
+      }
+      return { schema: JSON.parse(result.schema), template: result.template };
+    },
+  };
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+)
+
+// Config holds the settings of a -config file
+type Config struct {
+	Root          string                           `json:"root,omitempty"` // Global element used as the document root, unless -root is given
+	Fields        map[string]workato.FieldOverride `json:"fields"`         // Keyed by element path, such as Order/Line/Sku or Order/@id
+	Types         map[string]workato.TypeRule      `json:"types"`          // Keyed by XSD type name, wildcard pattern or /regular expression/
+	Abbreviations map[string]string                `json:"abbreviations"`  // Words spelled out in labels, such as Msg: Message
+}
+
+// Function to load a JSON or YAML configuration file, chosen by its extension
+func loadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	// YAML documents are converted to JSON so that both formats decode alike
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		document, err := parseYAML(data)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+		}
+		if data, err = json.Marshal(document); err != nil {
+			return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+		}
+	}
+
+	var config Config
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Function to load a -labels dictionary of Workato labels keyed by element path, such as
+// Order/Customer: Kunde. CSV files have the path and the label in their first two columns,
+// with an optional path,label header; JSON and YAML files are a single mapping.
+func loadLabels(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read labels: %w", err)
+	}
+
+	labels := make(map[string]string)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		reader.Comment = '#'
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid labels %s: %w", path, err)
+		}
+		for i, record := range records {
+			if i == 0 && len(record) >= 2 && strings.EqualFold(record[0], "path") && strings.EqualFold(record[1], "label") {
+				continue
+			}
+			if len(record) < 2 {
+				return nil, fmt.Errorf("invalid labels %s: line %d: expected a path and a label", path, i+1)
+			}
+			labels[strings.TrimSpace(record[0])] = strings.TrimSpace(record[1])
+		}
+	case ".yaml", ".yml":
+		document, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid labels %s: %w", path, err)
+		}
+		for key, value := range document {
+			label, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid labels %s: label of %s is not a string", path, key)
+			}
+			labels[key] = label
+		}
+	default:
+		if err := json.Unmarshal(data, &labels); err != nil {
+			return nil, fmt.Errorf("invalid labels %s: %w", path, err)
+		}
+	}
+	return labels, nil
+}
+
+// Function to apply a -labels dictionary to the field overrides, its labels replacing those
+// of the config. Only the labels of the Workato schema change, names are kept as they are.
+func applyLabels(overrides map[string]workato.FieldOverride, labels map[string]string) map[string]workato.FieldOverride {
+	overrides = maps.Clone(overrides)
+	if overrides == nil {
+		overrides = make(map[string]workato.FieldOverride)
+	}
+	for path, label := range labels {
+		override := overrides[path]
+		override.Label = label
+		overrides[path] = override
+	}
+	return overrides
+}
+
+// Function to save a configuration file, as JSON or YAML chosen by its extension like
+// loadConfig, so that it can be loaded again with -config
+func saveConfig(path string, config Config) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		var document map[string]any
+		if err := json.Unmarshal(data, &document); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		var sb strings.Builder
+		writeYAML(&sb, document, 0)
+		data = []byte(sb.String())
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// Function to write nested mappings of strings and booleans in the subset of YAML read by
+// parseYAML, with sorted keys. Empty mappings are left out.
+func writeYAML(sb *strings.Builder, mapping map[string]any, indent int) {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		line := strings.Repeat(" ", indent) + yamlKey(key) + ":"
+		switch value := mapping[key].(type) {
+		case map[string]any:
+			if len(value) > 0 {
+				sb.WriteString(line + "\n")
+				writeYAML(sb, value, indent+2)
+			}
+		case string:
+			sb.WriteString(line + " " + strconv.Quote(value) + "\n")
+		case bool:
+			sb.WriteString(line + " " + strconv.FormatBool(value) + "\n")
+		}
+	}
+}
+
+// Helper function to quote a key that would not read back as the same plain key
+func yamlKey(key string) string {
+	if key == "" || strings.ContainsAny(key[:1], "\"'#-[{") || strings.Contains(key, ": ") ||
+		strings.Contains(key, " #") || strings.HasSuffix(key, ":") || strings.TrimSpace(key) != key {
+		return "'" + key + "'"
+	}
+	return key
+}
+
+// Function to parse the subset of YAML used by config files: nested mappings of plain or
+// quoted scalars, with comments. Sequences, anchors and multi-line scalars are not supported.
+func parseYAML(data []byte) (map[string]any, error) {
+	type level struct {
+		indent  int
+		mapping map[string]any
+	}
+	root := make(map[string]any)
+	stack := []level{{-1, root}}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(stripYAMLComment(line), " \t\r")
+		content := strings.TrimLeft(line, " ")
+		if content == "" || content == "---" {
+			continue
+		}
+		if strings.HasPrefix(content, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		if strings.HasPrefix(content, "- ") || content == "-" {
+			return nil, fmt.Errorf("line %d: sequences are not supported", i+1)
+		}
+
+		indent := len(line) - len(content)
+		for indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		key, value, err := splitYAMLEntry(content)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		mapping := stack[len(stack)-1].mapping
+		if value == "" {
+			child := make(map[string]any)
+			mapping[key] = child
+			stack = append(stack, level{indent, child})
+			continue
+		}
+		if mapping[key], err = yamlScalar(value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	return root, nil
+}
+
+// Helper function to remove a trailing comment, ignoring # characters inside quotes
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// Helper function to split a "key: value" entry, where the key may be quoted
+func splitYAMLEntry(content string) (string, string, error) {
+	var key, rest string
+	if content[0] == '"' || content[0] == '\'' {
+		end := strings.IndexByte(content[1:], content[0])
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+		key, rest = content[1:end+1], content[end+2:]
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", fmt.Errorf("expected \":\" after key %q", key)
+		}
+		rest = rest[1:]
+	} else {
+		i := strings.Index(content, ": ")
+		switch {
+		case i >= 0:
+			key, rest = content[:i], content[i+1:]
+		case strings.HasSuffix(content, ":"):
+			key = strings.TrimSuffix(content, ":")
+		default:
+			return "", "", fmt.Errorf("expected \"key: value\"")
+		}
+	}
+	return strings.TrimSpace(key), strings.TrimSpace(rest), nil
+}
+
+// Helper function to convert a YAML scalar to a string, boolean or nil
+func yamlScalar(value string) (any, error) {
+	switch {
+	case value[0] == '"':
+		return strconv.Unquote(value)
+	case value[0] == '\'':
+		if len(value) < 2 || value[len(value)-1] != '\'' {
+			return nil, fmt.Errorf("unterminated quoted value")
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	case value[0] == '[' || value[0] == '{':
+		return nil, fmt.Errorf("flow collections are not supported")
+	}
+	switch value {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case "null", "~":
+		return nil, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peaz/xsd2wkt/pkg/template/mustache"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+func TestConfigOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mapping.yaml")
+	err := os.WriteFile(path, []byte(`# Overrides for the catalog schema
+fields:
+  Catalog/Product:
+    name: Products
+    label: "Products # in stock"
+  Catalog/Product/@discontinued:
+    exclude: true
+  Catalog/Product/@id:
+    type: string
+    optional: true
+  'Catalog/Publisher/Name':
+    control_type: text-area
+abbreviations:
+  id: Identifier
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	opts := workato.Options{AttributePrefix: "@", Overrides: config.Fields, Abbreviations: config.Abbreviations}
+
+	schema, err := xsd.ParseFile(filepath.Join("testdata", "attributes.xsd"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	fields, err := workato.Generate(schema, opts)
+	if err != nil {
+		t.Fatalf("workato.Generate: %v", err)
+	}
+
+	products := fields[0].Properties[1]
+	if products.Name != "Products" || products.Label != "Products # in stock" {
+		t.Errorf("Product field = %s (%s), want the name and label overrides", products.Name, products.Label)
+	}
+	if len(products.Properties) != 2 {
+		t.Fatalf("Product properties = %+v, want @Product_id and Product_Title only", products.Properties)
+	}
+	if id := products.Properties[0]; id.Type != "string" || !id.Optional {
+		t.Errorf("@Product_id = %s, optional %v, want an optional string", id.Type, id.Optional)
+	}
+	if label := products.Properties[0].Label; label != "Product Identifier" {
+		t.Errorf("@Product_id label = %q, want the abbreviation spelled out", label)
+	}
+	if name := fields[0].Properties[2].Properties[1]; name.ControlType != "text-area" {
+		t.Errorf("Publisher_Name control_type = %q, want text-area", name.ControlType)
+	}
+
+	template := mustache.Generate(schema, mustache.Options{Options: opts})
+	if !strings.Contains(template, "{{#Catalog.Products}}") || strings.Contains(template, "discontinued") {
+		t.Errorf("template does not apply the overrides:\n%s", template)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	cases := map[string]string{
+		"unknown.json":  `{"fields": {"Order": {"rename": "x"}}}`,
+		"sequence.yaml": "fields:\n  - Order\n",
+		"entry.yaml":    "fields\n",
+	}
+	for name, content := range cases {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadConfig(path); err == nil {
+			t.Errorf("loadConfig(%s): expected an error", name)
+		}
+	}
+}
+
+func TestLabels(t *testing.T) {
+	files := map[string]string{
+		"labels.csv": "path,label\nCatalog/Product,Produkte\n\"Catalog/Product/@id\",\"Produkt-ID, intern\"\n",
+		"labels.yaml": `# German labels of the catalog
+Catalog/Product: Produkte
+'Catalog/Product/@id': "Produkt-ID, intern"
+`,
+		"labels.json": `{"Catalog/Product": "Produkte", "Catalog/Product/@id": "Produkt-ID, intern"}`,
+	}
+	schema, err := xsd.ParseFile(filepath.Join("testdata", "attributes.xsd"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			labels, err := loadLabels(path)
+			if err != nil {
+				t.Fatalf("loadLabels: %v", err)
+			}
+			// The labels replace that of the config, which keeps its other settings
+			overrides := map[string]workato.FieldOverride{"Catalog/Product": {Name: "Products", Label: "Products"}}
+			opts := workato.Options{AttributePrefix: "@", Overrides: applyLabels(overrides, labels)}
+			fields, err := workato.Generate(schema, opts)
+			if err != nil {
+				t.Fatalf("workato.Generate: %v", err)
+			}
+
+			products := fields[0].Properties[1]
+			if products.Name != "Products" || products.Label != "Produkte" {
+				t.Errorf("Product field = %s (%s), want Products (Produkte)", products.Name, products.Label)
+			}
+			if id := products.Properties[0]; id.Name != "@Product_id" || id.Label != "Produkt-ID, intern" {
+				t.Errorf("@Product_id field = %s (%s), want the translated label only", id.Name, id.Label)
+			}
+			if overrides["Catalog/Product"].Label != "Products" {
+				t.Errorf("applyLabels changed the config overrides")
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "labels.csv")
+	if err := os.WriteFile(path, []byte("Catalog/Product\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadLabels(path); err == nil {
+		t.Errorf("loadLabels: expected an error for a row without label")
+	}
+}
+
+// The table of -print-typemap, with the built-in rules only and with those of a config
+func TestPrintTypeMap(t *testing.T) {
+	var out bytes.Buffer
+	if err := workato.PrintTypeMap(&out, workato.DefaultTypeMap, workato.TypeMap{}); err != nil {
+		t.Fatalf("PrintTypeMap: %v", err)
+	}
+	checkGolden(t, filepath.Join("testdata", "typemap.txt"), out.Bytes())
+
+	path := filepath.Join(t.TempDir(), "mapping.yaml")
+	err := os.WriteFile(path, []byte(`types:
+  xs:date:
+    type: string
+  "xs:*Integer":
+    type: string
+    control_type: number
+  tns:AmountType:
+    type: number
+    control_type: currency
+  /^tns:.*Code$/:
+    type: string
+    control_type: select
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	typeMap, err := workato.NewTypeMap(config.Types)
+	if err != nil {
+		t.Fatalf("NewTypeMap: %v", err)
+	}
+	out.Reset()
+	if err := workato.PrintTypeMap(&out, workato.DefaultTypeMap, typeMap); err != nil {
+		t.Fatalf("PrintTypeMap: %v", err)
+	}
+	checkGolden(t, filepath.Join("testdata", "typemap-config.txt"), out.Bytes())
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Function to run the diff subcommand, reporting the Workato fields added, removed, retyped
+// or re-cardinalized between two versions of an XSD. Like diff(1), exits with exitFailure
+// when there are changes.
+func runDiff(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: xsd2wkt diff [flags] old.xsd new.xsd")
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", "text", "Output format: text or json")
+	rootElement := flags.String("root", "", "Name of the global element to use as the document root")
+	attributePrefix := flags.String("attr-prefix", "@", "Prefix for Workato fields generated from XML attributes")
+	naming := flags.String("naming", workato.NamingFlat, "Naming of nested fields: flat (Parent_Child), nested (Child) or path (Root_Parent_Child)")
+	caseConversion := flags.String("case", workato.CaseOriginal, "Case of Workato field names: original, camel, snake or pascal")
+	maxDepth := flags.Int("max-depth", xsd.DefaultMaxDepth, "Element nesting depth at which recursive types stop being expanded")
+	logging := addLogFlags(flags)
+	fetching := addFetchFlags(flags)
+	flags.Parse(args)
+
+	log, err := logging.logger(os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitUsage)
+	}
+	switch {
+	case flags.NArg() != 2:
+		log.Error("diff takes the old and the new XSD files")
+		os.Exit(exitUsage)
+	case *format != "text" && *format != "json":
+		log.Error("-format must be one of text or json")
+		os.Exit(exitUsage)
+	case !validNaming(*naming):
+		log.Error("-naming must be one of flat, nested or path")
+		os.Exit(exitUsage)
+	case !validCase(*caseConversion):
+		log.Error("-case must be one of original, camel, snake or pascal")
+		os.Exit(exitUsage)
+	}
+
+	parser := xsd.Parser{MaxDepth: *maxDepth, Root: *rootElement, Logger: log}
+	if err := fetching.configure(&parser, flags.Arg(0)); err != nil {
+		log.Error(err.Error())
+		os.Exit(exitUsage)
+	}
+	opts := workato.Options{AttributePrefix: *attributePrefix, Naming: *naming, Case: *caseConversion}
+
+	var versions [2][]workato.Field
+	for i, inputFile := range flags.Args() {
+		fields, err := schemaFields(parser, inputFile, *rootElement, opts)
+		if err != nil {
+			log.Error(err.Error(), "file", inputFile)
+			os.Exit(exitCode(err))
+		}
+		versions[i] = fields
+	}
+
+	changes := workato.Diff(versions[0], versions[1])
+	if *format == "json" {
+		if changes == nil {
+			changes = []workato.Change{}
+		}
+		output, err := json.MarshalIndent(changes, "", "  ")
+		if err == nil {
+			_, err = fmt.Println(string(output))
+		}
+		if err != nil {
+			log.Error("failed to write diff: " + err.Error())
+			os.Exit(exitWrite)
+		}
+	} else if err := workato.PrintDiff(os.Stdout, changes); err != nil {
+		log.Error("failed to write diff: " + err.Error())
+		os.Exit(exitWrite)
+	}
+	if len(changes) > 0 {
+		os.Exit(exitFailure)
+	}
+}
+
+// Function to parse an XSD and generate the Workato fields of its root element
+func schemaFields(parser xsd.Parser, inputFile, rootElement string, opts workato.Options) ([]workato.Field, error) {
+	schema, err := parseFile(parser, inputFile)
+	if err != nil {
+		return nil, parseError(fmt.Errorf("failed to parse %s: %w", documentKind(inputFile), err))
+	}
+	schema, err = schema.SelectRoot(rootElement)
+	if err != nil {
+		return nil, withExitCode(exitUsage, fmt.Errorf("failed to select root element: %w", err))
+	}
+	return workato.Generate(schema, opts)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Exit codes of the command, so that scripts can tell failures apart
+const (
+	exitFailure     = 1 // Any other failure, such as an invalid document or a failed verification
+	exitUsage       = 2 // Invalid flags, configuration or input paths
+	exitParse       = 3 // An input file could not be read or parsed
+	exitImport      = 4 // An included or imported schema document could not be loaded
+	exitWrite       = 5 // An output could not be written
+	exitUnsupported = 6 // The outputs were written, but unsupported constructs were skipped
+)
+
+// Error carrying the exit code of the failure it reports
+type exitError struct {
+	code int
+	err  error
+}
+
+// Function to format an exit error as the error it wraps
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+// Function to return the error wrapped by an exit error
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// Helper function to attach an exit code to an error, keeping nil errors nil
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// Helper function to attach the exit code of a parse error, telling the documents that could
+// not be loaded through an include or import apart from the input itself
+func parseError(err error) error {
+	var importError *xsd.ImportError
+	if errors.As(err, &importError) {
+		return withExitCode(exitImport, err)
+	}
+	return withExitCode(exitParse, err)
+}
+
+// Function to get the exit code of an error: 0 for nil, the attached code if any, and
+// exitFailure otherwise
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return exitFailure
+}
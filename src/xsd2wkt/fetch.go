@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Flags of the fetcher reading schemas from HTTP(S) URLs and of the catalog remapping them
+type fetchFlags struct {
+	basicAuth   *string
+	bearerToken *string
+	authHost    *string
+	timeout     *time.Duration
+	catalog     *string
+	noCache     *bool
+	cacheTTL    *time.Duration
+	refresh     *bool
+}
+
+// Function to register the fetcher flags on a flag set
+func addFetchFlags(flags *flag.FlagSet) fetchFlags {
+	return fetchFlags{
+		basicAuth:   flags.String("basic-auth", "", "Credentials for schemas fetched from URLs, as user:password"),
+		bearerToken: flags.String("bearer-token", "", "Bearer token for schemas fetched from URLs"),
+		authHost:    flags.String("auth-host", "", "Host the credentials are sent to (defaults to the host of an -i URL, otherwise every host)"),
+		timeout:     flags.Duration("timeout", xsd.DefaultFetchTimeout, "Timeout of each request fetching a schema from a URL"),
+		catalog:     flags.String("catalog", "", "OASIS XML catalog remapping the schemaLocations of includes and imports, e.g. to local copies"),
+		noCache:     flags.Bool("no-cache", false, "Fetch schemas from URLs on every run instead of reading the copies cached in the user cache directory"),
+		cacheTTL:    flags.Duration("cache-ttl", xsd.DefaultCacheTTL, "Time cached schemas are used before being revalidated with their ETag or Last-Modified date"),
+		refresh:     flags.Bool("refresh", false, "Fetch schemas from URLs again, updating their cached copies"),
+	}
+}
+
+// Function to configure a parser with the fetcher and the catalog selected by the flags
+func (f fetchFlags) configure(parser *xsd.Parser, input string) error {
+	fetcher, err := f.fetcher(input)
+	if err != nil {
+		return err
+	}
+	parser.Fetcher = fetcher
+	if *f.catalog != "" {
+		if parser.Catalog, err = xsd.LoadCatalog(*f.catalog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Function to create the fetcher selected by the flags for the input
+func (f fetchFlags) fetcher(input string) (xsd.Fetcher, error) {
+	fetcher := xsd.Fetcher{Timeout: *f.timeout, BearerToken: *f.bearerToken, AuthHost: *f.authHost}
+	if *f.basicAuth != "" {
+		if fetcher.BearerToken != "" {
+			return xsd.Fetcher{}, fmt.Errorf("-basic-auth and -bearer-token cannot be used together")
+		}
+		var found bool
+		fetcher.Username, fetcher.Password, found = strings.Cut(*f.basicAuth, ":")
+		if !found {
+			return xsd.Fetcher{}, fmt.Errorf("-basic-auth must be given as user:password")
+		}
+	}
+	if fetcher.AuthHost == "" && isURL(input) {
+		inputURL, err := url.Parse(input)
+		if err != nil {
+			return xsd.Fetcher{}, fmt.Errorf("invalid URL %s: %w", input, err)
+		}
+		fetcher.AuthHost = inputURL.Hostname()
+	}
+	if *f.noCache {
+		if *f.refresh {
+			return xsd.Fetcher{}, fmt.Errorf("-no-cache and -refresh cannot be used together")
+		}
+		return fetcher, nil
+	}
+	// The cache is skipped when the system has no cache directory, such as without $HOME
+	if dir, err := os.UserCacheDir(); err == nil {
+		fetcher.CacheDir = filepath.Join(dir, "xsd2wkt")
+		fetcher.CacheTTL, fetcher.Refresh = *f.cacheTTL, *f.refresh
+	}
+	return fetcher, nil
+}
+
+// Helper function to check whether an input is an HTTP(S) URL
+func isURL(input string) bool {
+	return strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/jsonschema"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Function to run the json2wkt subcommand, converting a JSON Schema document, such as the
+// contract of a partner API, into a Workato schema
+func runJSON2wkt(args []string) {
+	flags := flag.NewFlagSet("json2wkt", flag.ExitOnError)
+	inputFile := flags.String("i", "", "Path to the JSON Schema file")
+	attributePrefix := flags.String("attr-prefix", "@", "Prefix of the properties to read as XML attributes, none if empty")
+	outputDir := flags.String("o", "", "Directory to write the outputs to (defaults to the input file's directory)")
+	naming := flags.String("naming", workato.NamingNested, "Naming of nested fields: nested (the property name), flat (Parent_Child) or path (Root_Parent_Child)")
+	maxDepth := flags.Int("max-depth", xsd.DefaultMaxDepth, "Nesting depth at which recursive $refs stop being expanded")
+	logging := addLogFlags(flags)
+	flags.Parse(args)
+
+	log, err := logging.logger(os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitUsage)
+	}
+	if *inputFile == "" {
+		log.Error("-i is required")
+		os.Exit(exitUsage)
+	}
+	if !validNaming(*naming) {
+		log.Error("-naming must be one of flat, nested or path")
+		os.Exit(exitUsage)
+	}
+	opts := workato.Options{AttributePrefix: *attributePrefix, Naming: *naming}
+
+	document, err := jsonschema.ReadFile(*inputFile)
+	if err != nil {
+		log.Error("failed to read JSON Schema: " + err.Error())
+		os.Exit(exitParse)
+	}
+	schema, err := jsonschema.ToSchema(document, xsd.Parser{MaxDepth: *maxDepth, Logger: log}, jsonschema.Options{AttributePrefix: *attributePrefix})
+	if err != nil {
+		log.Error("failed to convert JSON Schema: " + err.Error())
+		os.Exit(exitParse)
+	}
+	for _, rename := range workato.Renames(schema, opts) {
+		schema.Warnings = append(schema.Warnings, rename.String())
+	}
+	for _, warning := range schema.Warnings {
+		log.Warn(warning)
+	}
+	fields, err := workato.Generate(schema, opts)
+	if err != nil {
+		log.Error("failed to generate Workato schema: " + err.Error())
+		os.Exit(exitFailure)
+	}
+
+	// order.schema.json and order.json both become order-schema.json
+	dir := *outputDir
+	if dir == "" {
+		dir = filepath.Dir(*inputFile)
+	}
+	baseName := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(*inputFile), filepath.Ext(*inputFile)), ".schema")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Error("failed to create output directory: " + err.Error())
+		os.Exit(exitWrite)
+	}
+	schemaFile := filepath.Join(dir, baseName+"-schema.json")
+	if err := workato.WriteFile(fields, schemaFile); err != nil {
+		log.Error("failed to write schema file: " + err.Error())
+		os.Exit(exitWrite)
+	}
+	log.Info("Workato Schema generated successfully", "file", schemaFile)
+
+	if err := unsupportedError(schema.Unsupported); err != nil {
+		log.Error(err.Error())
+		os.Exit(exitCode(err))
+	}
+}
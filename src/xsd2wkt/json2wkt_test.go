@@ -0,0 +1,99 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/peaz/xsd2wkt/pkg/jsonschema"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Converting a generated JSON Schema to a Workato schema must give the Workato schema
+// generated from the XSD
+func TestJSON2wktRoundTrip(t *testing.T) {
+	cases := []string{
+		"flat",
+		"nested",
+		"repeating",
+		"mixed_types",
+		"named_types",
+		"namespaces",
+		"imports",
+		"attributes",
+		"choice",
+		"all",
+		"groups",
+		"enumerations",
+		"documentation",
+		"substitution",
+		"defaults",
+		"nillable",
+		"unsupported",
+		"mixed_content",
+	}
+
+	for _, name := range cases {
+		t.Run(name, func(t *testing.T) {
+			document, err := jsonschema.ReadFile(filepath.Join("testdata", name+"-jsonschema.json"))
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			schema, err := jsonschema.ToSchema(document, xsd.Parser{}, jsonschema.Options{AttributePrefix: testOptions.AttributePrefix})
+			if err != nil {
+				t.Fatalf("ToSchema: %v", err)
+			}
+
+			got, err := workato.Generate(schema, testOptions)
+			if err != nil {
+				t.Fatalf("workato.Generate: %v", err)
+			}
+			want, err := workato.ReadFile(filepath.Join("testdata", name+"-schema.json"))
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip mismatch\n--- got ---\n%+v\n--- want ---\n%+v", got, want)
+			}
+		})
+	}
+}
+
+// A partner contract using named schemas, composition, nullable and recursive properties,
+// converted with the defaults of json2wkt
+func TestGoldenJSON2wkt(t *testing.T) {
+	document, err := jsonschema.ReadFile(filepath.Join("testdata", "contract.schema.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	schema, err := jsonschema.ToSchema(document, xsd.Parser{MaxDepth: 3}, jsonschema.Options{AttributePrefix: testOptions.AttributePrefix})
+	if err != nil {
+		t.Fatalf("ToSchema: %v", err)
+	}
+	want := []string{"xs:any in the complexType of element metadata is not supported and was skipped"}
+	if !slices.Equal(schema.Unsupported, want) {
+		t.Errorf("unsupported = %v, want %v", schema.Unsupported, want)
+	}
+
+	opts := testOptions
+	opts.Naming = workato.NamingNested
+	fields, err := workato.Generate(schema, opts)
+	if err != nil {
+		t.Fatalf("workato.Generate: %v", err)
+	}
+	// Fields keep the names of the properties of the contract
+	var customer []string
+	for _, field := range fields[slices.IndexFunc(fields, func(field workato.Field) bool { return field.Name == "customer" })].Properties {
+		customer = append(customer, field.Name)
+	}
+	if want := []string{"@id", "name", "email", "vip"}; !slices.Equal(customer, want) {
+		t.Errorf("customer fields = %v, want %v", customer, want)
+	}
+	schemaJSON, err := workato.Marshal(fields)
+	if err != nil {
+		t.Fatalf("workato.Marshal: %v", err)
+	}
+	checkGolden(t, filepath.Join("testdata", "contract-schema.json"), schemaJSON)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Logging flags shared by the command and its subcommands
+type logFlags struct {
+	verbose     *bool
+	veryVerbose *bool
+	quiet       *bool
+	format      *string
+}
+
+// Function to register the logging flags on a flag set
+func addLogFlags(flags *flag.FlagSet) logFlags {
+	return logFlags{
+		verbose:     flags.Bool("v", false, "Verbose: also log the schema documents loaded"),
+		veryVerbose: flags.Bool("vv", false, "Very verbose: also log every element resolved"),
+		quiet:       flags.Bool("quiet", false, "Only log errors"),
+		format:      flags.String("log-format", "text", "Format of log messages: text or json"),
+	}
+}
+
+// Function to create the logger selected by the flags, writing to w
+func (f logFlags) logger(w io.Writer) (*slog.Logger, error) {
+	level := slog.LevelInfo
+	switch {
+	case *f.quiet:
+		level = slog.LevelError
+	case *f.veryVerbose:
+		level = xsd.LevelTrace
+	case *f.verbose:
+		level = slog.LevelDebug
+	}
+
+	switch *f.format {
+	case "text":
+		return slog.New(&textHandler{w: w, level: level, mu: &sync.Mutex{}}), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level, ReplaceAttr: replaceTraceLevel})), nil
+	}
+	return nil, fmt.Errorf("-log-format must be one of text or json")
+}
+
+// Helper function to name the trace level in JSON records, which slog calls DEBUG-4
+func replaceTraceLevel(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.LevelKey && attr.Value.Any() == xsd.LevelTrace {
+		attr.Value = slog.StringValue("TRACE")
+	}
+	return attr
+}
+
+// Handler writing records as plain lines: the message, prefixed with its level unless it
+// is informational, followed by its attributes as key=value pairs
+type textHandler struct {
+	w     io.Writer
+	level slog.Level
+	attrs []slog.Attr
+	mu    *sync.Mutex // Shared by the handlers derived with WithAttrs
+}
+
+// Function to check whether records of a level are written
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+// Function to write a record as a single line
+func (h *textHandler) Handle(_ context.Context, record slog.Record) error {
+	var sb strings.Builder
+	switch {
+	case record.Level >= slog.LevelError:
+		sb.WriteString("Error: ")
+	case record.Level >= slog.LevelWarn:
+		sb.WriteString("Warning: ")
+	case record.Level < slog.LevelDebug:
+		sb.WriteString("Trace: ")
+	case record.Level < slog.LevelInfo:
+		sb.WriteString("Debug: ")
+	}
+	sb.WriteString(record.Message)
+
+	writeAttr := func(attr slog.Attr) bool {
+		value := attr.Value.String()
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		sb.WriteString(" " + attr.Key + "=" + value)
+		return true
+	}
+	for _, attr := range h.attrs {
+		writeAttr(attr)
+	}
+	record.Attrs(writeAttr)
+	sb.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, sb.String())
+	return err
+}
+
+// Function to create a handler adding attributes to every record
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := *h
+	derived.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &derived
+}
+
+// Function to create a handler for a group of attributes; groups are not used by the
+// command, so their attributes are written without qualification
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	return h
+}
@@ -1,229 +1,1456 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
 	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/peaz/xsd2wkt/pkg/avro"
+	"github.com/peaz/xsd2wkt/pkg/gostructs"
+	"github.com/peaz/xsd2wkt/pkg/jsonschema"
+	"github.com/peaz/xsd2wkt/pkg/openapi"
+	"github.com/peaz/xsd2wkt/pkg/profile"
+	"github.com/peaz/xsd2wkt/pkg/sdk"
+	"github.com/peaz/xsd2wkt/pkg/template/liquid"
+	"github.com/peaz/xsd2wkt/pkg/template/mustache"
+	"github.com/peaz/xsd2wkt/pkg/template/skeleton"
+	"github.com/peaz/xsd2wkt/pkg/template/soap"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
 )
 
-// XSD structure to hold parsed data
-type XSD struct {
-	Elements []Element `xml:"element"`
+func main() {
+	// Subcommands come before the flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "wkt2xsd":
+			runWkt2xsd(os.Args[2:])
+			return
+		case "json2wkt":
+			runJSON2wkt(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "validate":
+			runValidate(os.Args[2:])
+			return
+		case "diff":
+			runDiff(os.Args[2:])
+			return
+		}
+	}
+
+	// Command line flag for input file
+	var inputFiles inputList
+	flag.Var(&inputFiles, "i", "Path or HTTP(S) URL of the XSD or WSDL file, a directory, a glob pattern, or - to read from stdin. Repeat to convert several")
+	var sampleFiles inputList
+	flag.Var(&sampleFiles, "infer", "Path or HTTP(S) URL of a sample XML document to infer the schema from when no XSD exists, a glob pattern, or - to read from stdin. Repeat to convert several")
+	printTypeMapFlag := flag.Bool("print-typemap", false, "Print the effective XSD to Workato type map and exit")
+	attributePrefix := flag.String("attr-prefix", "@", "Prefix for Workato fields generated from XML attributes")
+	rootElement := flag.String("root", "", "Name of the global element to use as the document root")
+	outputDir := flag.String("o", "", "Directory to write the outputs to (defaults to the input file's directory)")
+	templateName := flag.String("template-name", "", "File name of the generated template (defaults to <input>.template, or <input>.liquid for Liquid)")
+	stdinName := flag.String("name", "", "Base name of the outputs when reading from stdin, such as order for order.template")
+	schemaName := flag.String("schema-name", "", "File name of the generated Workato schema (defaults to <input>-schema.json)")
+	outputPattern := flag.String("output-pattern", "", "Pattern of the output file names, with the {name} of the input, the {kind} of output and its {ext}ension, such as {name}-{kind}.{ext} or {kind}/{name}.{ext}")
+	operationDirs := flag.Bool("operation-dirs", false, "Write the outputs of each WSDL operation to a folder named after it: <op>-request.template, <op>-request-schema.json and <op>-response-schema.json")
+	stdoutMode := flag.String("stdout", "", "Print outputs to stdout instead of writing files: schema, template or both")
+	format := flag.String("format", "workato", "Schema format to generate: workato, jsonschema, both (workato and jsonschema), avro, openapi (YAML), openapi-json, sdk (Ruby object_definitions for the Workato Connector SDK), gostructs (Go types with xml and json tags), fieldlist (CSV) or fieldlist-md")
+	emit := flag.String("emit", "", "Comma separated outputs to generate in place of -format: template, schema, jsonschema, avro, openapi, openapi-json, sdk, gostructs, fieldlist, fieldlist-md, sample-xml, sample-json and/or rules")
+	templateEngine := flag.String("template-engine", "mustache", "Template syntax to generate: mustache or liquid")
+	skeletonFile := flag.String("template-skeleton", "", "File the generated template is injected into, in place of its {{> body}} placeholder, with the XML declaration at {{> declaration}}")
+	configFile := flag.String("config", "", "YAML or JSON file with per-field overrides and custom type mapping rules")
+	labelsFile := flag.String("labels", "", "CSV, YAML or JSON file mapping element paths to the labels of their Workato fields, such as a translation for business users")
+	sampleXML := flag.Bool("sample-xml", false, "Also write <input>-sample.xml, the template rendered with placeholder values")
+	sampleJSON := flag.Bool("sample-json", false, "Also write <input>-sample.json, placeholder input data keyed by Workato field names")
+	validationRules := flag.Bool("validation-rules", false, "Also write <input>-rules.json, the required fields and facets for a connector to validate its input before rendering the template")
+	inferControls := flag.Bool("infer-controls", false, "Infer control_types from XSD types and field names, e.g. checkbox for xs:boolean or email for *Email fields")
+	splitAt := flag.Int("split-at", 0, "Split the Workato schema into numbered files of at most this many fields each, with a manifest describing the parts. 0 writes a single file")
+	sortFieldsFlag := flag.Bool("sort-fields", false, "Sort the fields of the Workato schema alphabetically; the template keeps the XSD sequence order")
+	collapseBelow := flag.String("collapse-below", "", "Collapse the content of the elements at this depth, such as 4, or path pattern, such as **/Extension, into a single field of raw XML written as is by the template")
+	maxDepth := flag.Int("max-depth", xsd.DefaultMaxDepth, "Element nesting depth at which recursive types stop being expanded")
+	watchFlag := flag.Bool("watch", false, "Keep running and convert the inputs again whenever they change, printing the changes of the Workato fields")
+	watchInterval := flag.Duration("watch-interval", defaultWatchInterval, "Interval between two checks of the inputs in -watch mode")
+	goPackage := flag.String("go-package", "", "With -format gostructs, package name of the Go types (defaults to the root element name in lower case)")
+	sdkAction := flag.Bool("sdk-action", false, "With -format sdk, also generate an action whose execute block posts the template rendered with the input")
+	merge := flag.Bool("merge", false, "Combine the root elements of the inputs into a single Workato schema, prefixed by namespace")
+	flattenXSD := flag.String("flatten-xsd", "", "Also write the resolved schema to this file as a single XSD, with its includes and imports inlined, to share it without its dependencies")
+	dryRun := flag.Bool("dry-run", false, "Print the files that would be written and a tree of the Workato fields, without writing anything")
+	noProvenance := flag.Bool("no-provenance", false, "Leave out the note of the tool version, input file name, input SHA-256 and generation time in the template, the Workato schema and the JSON Schema")
+	statsFlag := flag.Bool("stats", false, "Print statistics of the schema, such as its number of elements, depth, types, enumerations and repeating groups, without writing anything")
+	verifyFlag := flag.Bool("verify", false, "Render the template with sample data and validate the result against the XSD")
+	naming := flag.String("naming", workato.NamingFlat, "Naming of nested fields: flat (Parent_Child), nested (Child) or path (Root_Parent_Child)")
+	profileName := flag.String("profile", "", "Apply the conventions of a family of XSDs: "+strings.Join(profile.Names(), ", ")+". sap-idoc names IDoc segments after themselves and writes their SEGMENT and BEGIN attributes as constants")
+	caseConversion := flag.String("case", workato.CaseOriginal, "Case of Workato field names: original, camel, snake or pascal. Template tags keep the XML names")
+	namespacePrefix := flag.String("namespace-prefix", "", "Write the qualified elements of the template with this prefix for the target namespace, and prefixes of their own for imported namespaces, declared on the root")
+	unqualified := flag.Bool("unqualified", false, "Write the template without namespaces, for legacy endpoints that do not expect them")
+	soapVersion := flag.String("soap", "", "Wrap the template in a SOAP envelope: 1.1 or 1.2")
+	soapAction := flag.String("soap-action", "", "SOAP action of the operation, noted in the template for the HTTP request headers")
+	bodyNamespace := flag.String("body-namespace", "", "Namespace of the SOAP body content (defaults to the target namespace of the schema)")
+	concurrency := flag.Int("concurrency", runtime.NumCPU(), "Number of input files converted at the same time in batch mode; -dry-run, -stats and -stdout convert one at a time")
+	reportFile := flag.String("report", "", "Also write a JSON summary of the run to this file: counts of elements and types, warnings, unresolved references and output files per input")
+	interactive := flag.Bool("interactive", false, "Choose the root and the fields to include, their labels and optionality in a wizard, then write the outputs and a config file capturing the choices")
+	var includes, excludes []string
+	flag.Func("include", "Only generate the elements matching this path pattern, such as Order/Line or **/Amount, with their ancestors and content. Repeat to keep several", func(pattern string) error {
+		includes = append(includes, pattern)
+		return nil
+	})
+	flag.Func("exclude", "Leave out the elements matching this path pattern, such as Order/*/Extension, with their content. Repeat to leave out several", func(pattern string) error {
+		excludes = append(excludes, pattern)
+		return nil
+	})
+	logging := addLogFlags(flag.CommandLine)
+	fetching := addFetchFlags(flag.CommandLine)
+	flag.Parse()
+
+	opts := workato.Options{AttributePrefix: *attributePrefix, InferControls: *inferControls, Naming: *naming, Case: *caseConversion, SortFields: *sortFieldsFlag}
+
+	// Diagnostics go to stderr, leaving stdout to the outputs printed in stdout and dry-run modes
+	log, err := logging.logger(os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitUsage)
+	}
+
+	if *stdoutMode != "" && *stdoutMode != "schema" && *stdoutMode != "template" && *stdoutMode != "both" {
+		log.Error("-stdout must be one of schema, template or both")
+		os.Exit(exitUsage)
+	}
+
+	switch *format {
+	case "workato", "jsonschema", "both", "avro", "openapi", "openapi-json", "sdk", "gostructs", "fieldlist", "fieldlist-md":
+	default:
+		log.Error("-format must be one of workato, jsonschema, both, avro, openapi, openapi-json, sdk, gostructs, fieldlist or fieldlist-md")
+		os.Exit(exitUsage)
+	}
+	formats, emitTemplate := schemaFormats(*format), true
+	if *emit != "" {
+		if *format != "workato" {
+			log.Error("-emit and -format cannot be used together")
+			os.Exit(exitUsage)
+		}
+		var err error
+		if formats, emitTemplate, err = parseEmit(*emit, sampleXML, sampleJSON, validationRules); err != nil {
+			log.Error("invalid -emit: " + err.Error())
+			os.Exit(exitUsage)
+		}
+	}
+	if *sdkAction && !slices.Contains(formats, "sdk") {
+		log.Error("-sdk-action requires -format sdk")
+		os.Exit(exitUsage)
+	}
+	if *goPackage != "" && !slices.Contains(formats, "gostructs") {
+		log.Error("-go-package requires -format gostructs")
+		os.Exit(exitUsage)
+	}
+
+	templateExtension := ".template"
+	switch *templateEngine {
+	case "mustache":
+	case "liquid":
+		templateExtension = ".liquid"
+	default:
+		log.Error("-template-engine must be one of mustache or liquid")
+		os.Exit(exitUsage)
+	}
+
+	if !validNaming(*naming) {
+		log.Error("-naming must be one of flat, nested or path")
+		os.Exit(exitUsage)
+	}
+	if !validCase(*caseConversion) {
+		log.Error("-case must be one of original, camel, snake or pascal")
+		os.Exit(exitUsage)
+	}
+	var schemaProfile profile.Profile
+	if *profileName != "" {
+		if schemaProfile, err = profile.Lookup(*profileName); err != nil {
+			log.Error("-profile: " + err.Error())
+			os.Exit(exitUsage)
+		}
+	}
+
+	var templateSkeleton string
+	if *skeletonFile != "" {
+		data, err := os.ReadFile(*skeletonFile)
+		if err != nil {
+			log.Error("failed to read template skeleton: " + err.Error())
+			os.Exit(exitUsage)
+		}
+		if err := skeleton.Validate(string(data)); err != nil {
+			log.Error(fmt.Sprintf("invalid template skeleton %s: %v", *skeletonFile, err))
+			os.Exit(exitUsage)
+		}
+		templateSkeleton = string(data)
+	}
+
+	if *outputPattern != "" {
+		if err := validateOutputPattern(*outputPattern); err != nil {
+			log.Error("invalid -output-pattern: " + err.Error())
+			os.Exit(exitUsage)
+		}
+	}
+
+	switch {
+	case *namespacePrefix != "" && *unqualified:
+		log.Error("-namespace-prefix and -unqualified cannot be combined")
+		os.Exit(exitUsage)
+	case *namespacePrefix != "" && !namespacePrefixName.MatchString(*namespacePrefix):
+		log.Error(fmt.Sprintf("-namespace-prefix %q is not a valid XML namespace prefix", *namespacePrefix))
+		os.Exit(exitUsage)
+	}
+
+	soapOptions := soap.Options{Version: *soapVersion, Action: *soapAction, BodyNamespace: *bodyNamespace}
+	if err := soapOptions.Validate(); err != nil {
+		log.Error("-soap, -soap-action and -body-namespace: " + err.Error())
+		os.Exit(exitUsage)
+	}
+
+	if opts.Filter, err = workato.NewPathFilter(includes, excludes); err != nil {
+		log.Error("-include and -exclude: " + err.Error())
+		os.Exit(exitUsage)
+	}
+
+	if *maxDepth < 1 {
+		log.Error("-max-depth must be at least 1")
+		os.Exit(exitUsage)
+	}
+	var collapse workato.CollapseLimit
+	if *collapseBelow != "" {
+		if collapse, err = workato.NewCollapseLimit(*collapseBelow); err != nil {
+			log.Error("-collapse-below: " + err.Error())
+			os.Exit(exitUsage)
+		}
+	}
+	if *concurrency < 1 {
+		log.Error("-concurrency must be at least 1")
+		os.Exit(exitUsage)
+	}
+	if *splitAt < 0 || *splitAt == 1 {
+		log.Error("-split-at must be at least 2, or 0 not to split")
+		os.Exit(exitUsage)
+	}
+
+	var config Config
+	if *configFile != "" {
+		config, err = loadConfig(*configFile)
+		if err != nil {
+			log.Error(err.Error())
+			os.Exit(exitUsage)
+		}
+		opts.Overrides, opts.Abbreviations = config.Fields, config.Abbreviations
+		if *rootElement == "" {
+			*rootElement = config.Root
+		}
+		if opts.TypeMap, err = workato.NewTypeMap(config.Types); err != nil {
+			log.Error(fmt.Sprintf("invalid config %s: %v", *configFile, err))
+			os.Exit(exitUsage)
+		}
+	}
+	if *labelsFile != "" {
+		labels, err := loadLabels(*labelsFile)
+		if err != nil {
+			log.Error(err.Error())
+			os.Exit(exitUsage)
+		}
+		opts.Overrides = applyLabels(opts.Overrides, labels)
+	}
+
+	if *printTypeMapFlag {
+		if err := workato.PrintTypeMap(os.Stdout, workato.DefaultTypeMap, opts.TypeMap); err != nil {
+			log.Error("failed to print type map: " + err.Error())
+			os.Exit(exitWrite)
+		}
+		return
+	}
+
+	c := converter{
+		opts: opts,
+		outputs: outputConfig{
+			dir:               *outputDir,
+			templateName:      *templateName,
+			templateExtension: templateExtension,
+			schemaName:        *schemaName,
+			pattern:           *outputPattern,
+		},
+		operationDirs:   *operationDirs,
+		templateEngine:  *templateEngine,
+		soap:            soapOptions,
+		skeleton:        templateSkeleton,
+		rootElement:     *rootElement,
+		stdoutMode:      *stdoutMode,
+		formats:         formats,
+		noTemplate:      !emitTemplate,
+		sdkAction:       *sdkAction,
+		goPackage:       *goPackage,
+		splitAt:         *splitAt,
+		collapse:        collapse,
+		namespacePrefix: *namespacePrefix,
+		unqualified:     *unqualified,
+		sampleXML:       *sampleXML,
+		sampleJSON:      *sampleJSON,
+		rules:           *validationRules,
+		verify:          *verifyFlag,
+		dryRun:          *dryRun,
+		flattenXSD:      *flattenXSD,
+		stats:           *statsFlag,
+		provenance:      !*noProvenance,
+		profile:         schemaProfile,
+		parser:          xsd.Parser{MaxDepth: *maxDepth, Root: *rootElement, Logger: log},
+		log:             log,
+	}
+	if *reportFile != "" {
+		c.report = &reporter{}
+	}
+	// Write the report, if any, then exit with the code of the run
+	exit := func(code int) {
+		if err := c.report.write(*reportFile, code); err != nil {
+			log.Error(err.Error())
+			if code == 0 {
+				code = exitCode(err)
+			}
+		}
+		os.Exit(code)
+	}
+
+	// Samples are converted like the schemas given with -i, from the schema inferred from them
+	if len(sampleFiles) > 0 {
+		switch {
+		case len(inputFiles) > 0:
+			log.Error("-infer cannot be combined with -i")
+			os.Exit(exitUsage)
+		case *merge || *interactive:
+			log.Error("-infer cannot be used with -merge or -interactive")
+			os.Exit(exitUsage)
+		}
+		inputFiles, c.infer = sampleFiles, true
+	}
+
+	// Piped input stands for -i -; outputs of stdin need a name unless they are only printed
+	if len(inputFiles) == 0 && stdinPiped() {
+		inputFiles = inputList{stdinInput}
+	}
+	switch {
+	case len(inputFiles) == 0:
+		log.Error("-i or -infer is required")
+		os.Exit(exitUsage)
+	case slices.Contains(inputFiles, stdinInput) && len(inputFiles) > 1:
+		log.Error("-i - cannot be combined with other inputs")
+		os.Exit(exitUsage)
+	case inputFiles[0] == stdinInput && *stdinName == "" && *stdoutMode == "" && !*dryRun && !*statsFlag:
+		log.Error("-name is required when reading from stdin, unless -stdout, -dry-run or -stats is used")
+		os.Exit(exitUsage)
+	case *statsFlag && (*dryRun || *stdoutMode != ""):
+		log.Error("-stats cannot be used with -dry-run or -stdout")
+		os.Exit(exitUsage)
+	}
+	if err := fetching.configure(&c.parser, inputFiles[0]); err != nil {
+		log.Error(err.Error())
+		os.Exit(exitUsage)
+	}
+	c.stdin, c.stdinName = os.Stdin, *stdinName
+	if c.stdinName == "" {
+		c.stdinName = "stdin"
+	}
+
+	var inputs []string
+	for _, inputFile := range inputFiles {
+		files, err := expandInputs(inputFile)
+		if err != nil {
+			log.Error(err.Error())
+			os.Exit(exitUsage)
+		}
+		inputs = append(inputs, files...)
+	}
+	if *interactive {
+		switch {
+		case len(inputs) != 1 || inputFiles[0] == stdinInput || strings.HasSuffix(strings.ToLower(inputs[0]), ".wsdl"):
+			log.Error("-interactive requires a single XSD input file")
+			os.Exit(exitUsage)
+		case *watchFlag || *merge || *dryRun || *statsFlag || *stdoutMode != "" || *reportFile != "":
+			log.Error("-interactive cannot be used with -watch, -merge, -dry-run, -stats, -stdout or -report")
+			os.Exit(exitUsage)
+		}
+		// The choices are saved to the config they started from, or else next to the outputs
+		savedConfig := *configFile
+		if savedConfig == "" {
+			savedConfig = c.outputs.file(inputs[0], "", "-config.yaml")
+		}
+		if err := c.runWizard(inputs[0], config, savedConfig, os.Stdin, os.Stdout); err != nil {
+			log.Error(err.Error())
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if *watchFlag {
+		switch {
+		case *merge || *dryRun || *statsFlag || *stdoutMode != "" || *reportFile != "" || inputFiles[0] == stdinInput:
+			log.Error("-watch cannot be used with -merge, -dry-run, -stats, -stdout, -report or stdin")
+			os.Exit(exitUsage)
+		case *watchInterval <= 0:
+			log.Error("-watch-interval must be positive")
+			os.Exit(exitUsage)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		log.Info("Watching for changes, press Ctrl+C to stop")
+		c.watch(ctx, inputFiles, *watchInterval, os.Stdout)
+		return
+	}
+	if *merge {
+		err := c.merge(inputs)
+		c.report.converted(strings.Join(inputs, ", "), err)
+		if err != nil {
+			printSkipped(os.Stderr, []string{strings.Join(inputs, ", ")}, []error{err})
+			log.Error(err.Error())
+		}
+		exit(exitCode(err))
+	}
+	if len(inputs) == 1 {
+		err := c.convert(inputs[0])
+		c.report.converted(inputs[0], err)
+		if err != nil {
+			printSkipped(os.Stderr, inputs, []error{err})
+			log.Error(err.Error())
+		}
+		exit(exitCode(err))
+	}
+
+	// Batch mode: keep going after per-file errors, summarize at the end and exit with the
+	// code of the first failure
+	if c.outputs.templateName != "" || c.outputs.schemaName != "" || c.flattenXSD != "" {
+		log.Error("-template-name, -schema-name and -flatten-xsd cannot be used with multiple input files")
+		os.Exit(exitUsage)
+	}
+	results := c.convertBatch(inputs, *concurrency)
+	printSkipped(os.Stderr, inputs, results)
+	if err := printBatchSummary(os.Stderr, inputs, results); err != nil {
+		log.Error("failed to print summary: " + err.Error())
+	}
+	for _, result := range results {
+		if result != nil {
+			exit(exitCode(result))
+		}
+	}
+	exit(0)
 }
 
-// Add Type field to Element struct
-type Element struct {
-	Name     string    `xml:"name,attr"`
-	Type     string    `xml:"type,attr"`
-	Children []Element `xml:"complexType>sequence>element"`
+// Converter holding the settings shared by every input file of a run
+type converter struct {
+	opts            workato.Options
+	outputs         outputConfig
+	rootElement     string
+	stdoutMode      string                // schema, template or both to print instead of writing files
+	templateEngine  string                // Template syntax: mustache or liquid
+	soap            soap.Options          // Envelope wrapping the template, if any
+	skeleton        string                // Skeleton the template is injected into, if any
+	formats         []string              // Schema formats to generate: workato, jsonschema, avro, openapi, openapi-json, sdk, gostructs, fieldlist and/or fieldlist-md
+	sdkAction       bool                  // Whether the SDK snippet includes an action posting the rendered template
+	goPackage       string                // Package name of the Go types, "" to name it after the root
+	splitAt         int                   // Largest number of fields of each Workato schema file, 0 for a single file
+	collapse        workato.CollapseLimit // Elements whose content is collapsed into a field of raw XML
+	namespacePrefix string                // Prefix of the qualified elements of the template, "" to declare the target namespace as the default one
+	unqualified     bool                  // Whether to write the template without namespaces
+	sampleXML       bool                  // Whether to write a sample XML document rendered from the template
+	sampleJSON      bool                  // Whether to write sample input data matching the Workato schema
+	rules           bool                  // Whether to write the validation rules of the fields
+	verify          bool                  // Whether to validate the rendered sample against the schema
+	dryRun          bool                  // Whether to print the files and fields that would be generated instead of writing them
+	flattenXSD      string                // File to write the resolved schema to as a single self-contained XSD, if set
+	stats           bool                  // Whether to print the statistics of the schema instead of writing the outputs
+	operationDirs   bool                  // Whether the outputs of WSDL operations go to a folder per operation
+	noTemplate      bool                  // Whether to leave out the template, for responses that are parsed rather than rendered
+	provenance      bool                  // Whether to note the provenance of the outputs written to files
+	note            string                // Provenance of the outputs of the input being converted, "" for none
+	infer           bool                  // Whether the inputs are sample XML documents to infer the schema from
+	profile         profile.Profile       // Conventions of the family of the XSD applied before generating, if any
+	stdin           io.Reader             // Source of the document when the input is "-"
+	stdinName       string                // Base name of the outputs of the document read from stdin
+	report          *reporter             // Summary of the run written by -report, if any
+	parser          xsd.Parser
+	log             *slog.Logger // Destination of status messages
 }
 
-// Function to parse the XSD file
-func parseXSD(filePath string) (XSD, error) {
-	data, err := os.ReadFile(filePath)
+// Function to convert a single XSD, WSDL, DTD or RELAX NG input file, or sample document
+// with -infer, or the document read from stdin when inputFile is "-"
+func (c converter) convert(inputFile string) error {
+	if inputFile == stdinInput {
+		return c.convertStdin()
+	}
+	note, err := c.provenanceOf(inputFile)
 	if err != nil {
-		return XSD{}, fmt.Errorf("failed to read file: %w", err)
+		return parseError(err)
+	}
+	c.note = note
+	if c.infer {
+		schema, err := c.parser.InferFile(inputFile)
+		if err != nil {
+			return parseError(fmt.Errorf("failed to infer schema from sample: %w", err))
+		}
+		return c.emitRoot(schema, inputFile)
 	}
 
-	//fmt.Println(string(data)) // Add this line to debug the content of the XSD file
+	// WSDL files produce a request and a response pair of outputs per operation
+	if strings.HasSuffix(strings.ToLower(inputFile), ".wsdl") {
+		if c.outputs.templateName != "" || c.outputs.schemaName != "" || c.flattenXSD != "" {
+			return withExitCode(exitUsage, fmt.Errorf("-template-name, -schema-name and -flatten-xsd cannot be used with WSDL input"))
+		}
+		operations, err := c.parser.ParseWSDLFile(inputFile)
+		if err != nil {
+			return parseError(fmt.Errorf("failed to parse WSDL: %w", err))
+		}
+		return c.emitOperations(operations, inputFile)
+	}
 
-	var xsd XSD
-	err = xml.Unmarshal(data, &xsd)
+	// Parse the XSD, DTD or RELAX NG file
+	schema, err := parseFile(c.parser, inputFile)
 	if err != nil {
-		return XSD{}, fmt.Errorf("failed to unmarshal XML: %w", err)
+		return parseError(fmt.Errorf("failed to parse %s: %w", documentKind(inputFile), err))
 	}
-	return xsd, nil
+	return c.emitRoot(schema, inputFile)
 }
 
-// Function to generate Mustache template recursively
-func generateTemplate(xsd XSD) string {
-	var sb strings.Builder
-	sb.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+// Function to parse an XSD, DTD or RELAX NG file into a schema, by its extension
+func parseFile(parser xsd.Parser, inputFile string) (xsd.Schema, error) {
+	switch documentKind(inputFile) {
+	case "DTD":
+		return parser.ParseDTDFile(inputFile)
+	case "RELAX NG":
+		return parser.ParseRelaxNGFile(inputFile)
+	}
+	return parser.ParseFile(inputFile)
+}
 
-	// Check if there are any elements
-	if len(xsd.Elements) == 0 {
-		return sb.String() // Return an empty template if no elements are found
+// Function to parse an XSD, DTD or RELAX NG document read from stdin, by its content
+func parseData(parser xsd.Parser, data []byte) (xsd.Schema, error) {
+	switch dataKind(data) {
+	case "DTD":
+		return parser.ParseDTD(bytes.NewReader(data))
+	case "RELAX NG":
+		return parser.ParseRelaxNG(bytes.NewReader(data))
 	}
+	return parser.Parse(bytes.NewReader(data))
+}
 
-	if len(xsd.Elements[0].Children) > 0 {
-		sb.WriteString("{{#" + xsd.Elements[0].Name + "}}\n")
+// Helper function to name the kind of schema document of an input file, by its extension
+func documentKind(inputFile string) string {
+	switch strings.ToLower(filepath.Ext(inputFile)) {
+	case ".dtd":
+		return "DTD"
+	case ".rng", ".rnc":
+		return "RELAX NG"
 	}
+	return "XSD"
+}
 
-	sb.WriteString("<" + xsd.Elements[0].Name + ">\n")
-	for _, element := range xsd.Elements {
-		generateElementTemplate(&sb, element, "")
+// Helper function to name the kind of schema document read from stdin, by its first markup.
+// Only the XML syntax of RELAX NG is recognized.
+func dataKind(data []byte) string {
+	decoder := xsd.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "XSD"
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Space == xsd.RelaxNGNamespace {
+				return "RELAX NG"
+			}
+			return "XSD"
+		case xml.Directive:
+			// DTDs start with declarations such as <!ELEMENT
+			keyword, _, _ := strings.Cut(strings.TrimSpace(string(token)), " ")
+			if keyword == "ELEMENT" || keyword == "ATTLIST" || keyword == "ENTITY" {
+				return "DTD"
+			}
+		}
 	}
-	sb.WriteString("</" + xsd.Elements[0].Name + ">\n")
+}
 
-	if len(xsd.Elements[0].Children) > 0 {
-		sb.WriteString("{{/" + xsd.Elements[0].Name + "}}\n")
+// Function to convert the XSD, WSDL, DTD or RELAX NG document read from stdin. The outputs are named after
+// -name, and relative schemaLocations are resolved against the working directory.
+func (c converter) convertStdin() error {
+	data, err := io.ReadAll(c.stdin)
+	if err != nil {
+		return withExitCode(exitParse, fmt.Errorf("failed to read stdin: %w", err))
+	}
+	// The extension only stands in for the one of an input file, so that names with dots are kept
+	inputFile := c.stdinName + ".xsd"
+	if c.provenance {
+		if c.note, err = provenanceNote("stdin", bytes.NewReader(data), time.Now()); err != nil {
+			return parseError(err)
+		}
+	}
+	if c.infer {
+		schema, err := c.parser.Infer(bytes.NewReader(data))
+		if err != nil {
+			return parseError(fmt.Errorf("failed to infer schema from sample: %w", err))
+		}
+		return c.emitRoot(schema, inputFile)
 	}
 
-	return sb.String()
+	if isWSDL(data) {
+		if c.outputs.templateName != "" || c.outputs.schemaName != "" || c.flattenXSD != "" {
+			return withExitCode(exitUsage, fmt.Errorf("-template-name, -schema-name and -flatten-xsd cannot be used with WSDL input"))
+		}
+		operations, err := c.parser.ParseWSDL(bytes.NewReader(data))
+		if err != nil {
+			return parseError(fmt.Errorf("failed to parse WSDL: %w", err))
+		}
+		return c.emitOperations(operations, inputFile)
+	}
+
+	schema, err := parseData(c.parser, data)
+	if err != nil {
+		return parseError(fmt.Errorf("failed to parse %s: %w", dataKind(data), err))
+	}
+	return c.emitRoot(schema, inputFile)
 }
 
-// Recursive function to generate template for each element
-func generateElementTemplate(sb *strings.Builder, element Element, parentName string) {
-	if parentName != "" {
-		sb.WriteString("{{#" + parentName + "_" + element.Name + "}}\n")
-		sb.WriteString("<" + element.Name + ">\n")
+// Helper function to check whether a document is a WSDL one, whose root is wsdl:definitions
+func isWSDL(data []byte) bool {
+	decoder := xsd.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "definitions"
+		}
 	}
+}
 
-	for _, child := range element.Children {
-		if len(child.Children) > 0 { // Check if the child has its own children (complex type)
-			generateElementTemplate(sb, child, element.Name) // Recursive call for nested elements
-		} else {
-			sb.WriteString("<" + child.Name + ">{{" + element.Name + "_" + child.Name + "}}</" + child.Name + ">\n")
+// Function to emit the outputs of the root element of a parsed XSD
+func (c converter) emitRoot(schema xsd.Schema, inputFile string) error {
+	if c.flattenXSD != "" {
+		if err := c.writeFlattened(schema); err != nil {
+			return err
+		}
+	}
+	schema, err := schema.SelectRoot(c.rootElement)
+	if err != nil {
+		return withExitCode(exitUsage, fmt.Errorf("failed to select root element: %w", err))
+	}
+
+	if err := c.emit(schema, inputFile, ""); err != nil {
+		return err
+	}
+	return unsupportedError(schema.Unsupported)
+}
+
+// Function to write the resolved schema, with every global element of the input, as a
+// single XSD without includes nor imports, or to print where it would be written
+func (c converter) writeFlattened(schema xsd.Schema) error {
+	if c.dryRun {
+		fmt.Fprintln(os.Stdout, "Would write:", c.flattenXSD)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.flattenXSD), 0755); err != nil {
+		return withExitCode(exitWrite, fmt.Errorf("failed to create output directory: %w", err))
+	}
+	if err := writeXSDFile(schema, c.flattenXSD); err != nil {
+		return withExitCode(exitWrite, fmt.Errorf("failed to write flattened XSD: %w", err))
+	}
+	c.log.Info("Flattened XSD generated successfully", "file", c.flattenXSD)
+	return nil
+}
+
+// Function to emit a request and a response pair of outputs per WSDL operation
+func (c converter) emitOperations(operations []xsd.Operation, inputFile string) error {
+	var unsupported []string
+	for _, operation := range operations {
+		request, response := c, c
+		prefix := "-" + operation.Name
+		// In a folder per operation, outputs are named after the operation alone, and the
+		// response, parsed by Workato rather than rendered, only needs its schema
+		if c.operationDirs {
+			dir := filepath.Join(filepath.Dir(c.outputs.file(inputFile, "", "")), operation.Name)
+			request.outputs.dir, request.outputs.baseName = dir, operation.Name
+			response.outputs, response.noTemplate = request.outputs, true
+			prefix = ""
+		}
+		if err := request.emit(*operation.Request, inputFile, prefix+"-request"); err != nil {
+			return err
+		}
+		unsupported = append(unsupported, operation.Request.Unsupported...)
+		if operation.Response != nil {
+			if err := response.emit(*operation.Response, inputFile, prefix+"-response"); err != nil {
+				return err
+			}
+			unsupported = append(unsupported, operation.Response.Unsupported...)
 		}
 	}
+	return unsupportedError(unsupported)
+}
 
-	if parentName != "" {
-		sb.WriteString("</" + element.Name + ">\n")
-		sb.WriteString("{{/" + parentName + "_" + element.Name + "}}\n")
+// Helper function to report the constructs skipped while converting an input, whose outputs
+// were written but do not cover the whole schema. Returns nil when nothing was skipped.
+func unsupportedError(unsupported []string) error {
+	if len(unsupported) == 0 {
+		return nil
 	}
+	return withExitCode(exitUnsupported, &skippedError{unsupported})
 }
 
-// Define the structure for the Workato schema
-type WorkatoField struct {
-	Name        string         `json:"name"`
-	Label       string         `json:"label,omitempty"`
-	Type        string         `json:"type,omitempty"`
-	Of          string         `json:"of,omitempty"`
-	Optional    bool           `json:"optional,omitempty"`
-	ControlType string         `json:"control_type,omitempty"`
-	Properties  []WorkatoField `json:"properties,omitempty"`
+// Error listing the warnings about the constructs skipped while converting an input
+type skippedError struct {
+	warnings []string
 }
 
-// Function to generate Workato Schema JSON
-func generateWorkatoSchema(xsd XSD) ([]WorkatoField, error) {
-	var fields []WorkatoField
+// Function to format a skipped error with the number of constructs skipped
+func (e *skippedError) Error() string {
+	return fmt.Sprintf("%d unsupported constructs were skipped, the outputs are incomplete", len(e.warnings))
+}
 
-	for _, element := range xsd.Elements {
-		workatoField := WorkatoField{
-			Name:     element.Name,
-			Label:    element.Name,
-			Type:     mapXSDTypeToWorkatoType(element.Type), // Assuming element.Type is available
-			Optional: true,                                  // Set to true or false based on your logic
+// Function to print, at the end of a run, the constructs skipped while converting each
+// input, so that they are not lost among the other messages. Nothing is printed when no
+// construct was skipped.
+func printSkipped(w io.Writer, inputs []string, results []error) {
+	var sb strings.Builder
+	for i, result := range results {
+		var skipped *skippedError
+		if !errors.As(result, &skipped) {
+			continue
 		}
+		sb.WriteString("  " + inputs[i] + ":\n")
+		for _, warning := range skipped.warnings {
+			sb.WriteString("    " + warning + "\n")
+		}
+	}
+	if sb.Len() > 0 {
+		io.WriteString(w, "Skipped constructs, the outputs do not cover them:\n"+sb.String())
+	}
+}
+
+// Function to write the outputs of a schema to files named after the input file, or print
+// them in stdout mode. The profile, if any, is applied first so that every output follows it.
+func (c converter) emit(schema xsd.Schema, inputFile, suffix string) error {
+	schema, c.opts = c.profile.Apply(c.collapse.Apply(schema), c.opts)
+	schema.NamespacePrefix, schema.Unqualified = c.namespacePrefix, c.unqualified
 
-		// If the element has children, treat it as an object with properties
-		if len(element.Children) > 0 {
-			workatoField.Type = "array"
-			workatoField.Of = "object"
-			workatoField.Properties = generateWorkatoSchemaForChildren(element.Children, workatoField.Name)
+	// Fields renamed to avoid collisions are warned about, and reported, with the parser warnings
+	for _, rename := range workato.Renames(schema, c.opts) {
+		schema.Warnings = append(slices.Clip(schema.Warnings), rename.String())
+	}
+	for _, warning := range schema.Warnings {
+		c.log.Warn(warning)
+	}
+	var err error
+	var outputs []string
+	switch {
+	case c.stats:
+		err = c.printStats(os.Stdout, schema, inputFile)
+	case c.dryRun:
+		err = c.printPlan(os.Stdout, schema, inputFile, suffix)
+	case c.stdoutMode != "":
+		err = c.printOutputs(os.Stdout, schema)
+	default:
+		if err = c.writeOutputs(schema, inputFile, suffix); err == nil {
+			outputs = c.outputFiles(schema, inputFile, suffix)
 		}
+	}
+	c.report.emitted(schema, c.opts, outputs)
+	if err != nil || !c.verify {
+		return err
+	}
 
-		fields = append(fields, workatoField)
+	errs, err := verify(schema, c.opts)
+	if err != nil {
+		return fmt.Errorf("failed to verify outputs: %w", err)
 	}
+	for _, e := range errs {
+		c.log.Error(e.Message, "path", e.Path)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("verification failed, the rendered sample is not valid against the XSD")
+	}
+	c.log.Info("Verification passed: the rendered sample is valid against the XSD")
+	return nil
+}
 
-	return fields, nil
+// Function to check that the outputs fit together: the Mustache template, rendered with
+// sample data keyed by the Workato field names, must give a document valid against the
+// schema. Returns the violations of the rendered document.
+func verify(schema xsd.Schema, opts workato.Options) ([]xsd.ValidationError, error) {
+	template := mustache.Generate(schema, mustache.Options{Options: opts})
+	document, err := mustache.Render(template, workato.Sample(schema, opts))
+	if err != nil {
+		return nil, fmt.Errorf("rendering the template: %w", err)
+	}
+	return xsd.Validate(schema, strings.NewReader(document))
 }
 
-// Helper function to map XSD types to Workato types
-func mapXSDTypeToWorkatoType(xsdType string) string {
-	switch xsdType {
-	case "xs:string":
-		return "string"
-	case "xs:dateTime":
-		return "date_time"
-	case "xs:boolean":
-		return "boolean"
-	case "xs:integer":
-		return "integer"
-	case "xs:float", "xs:double", "xs:decimal":
-		return "number"
-	default:
-		return "string" // Default to string if type is unknown
+// Values of the repeatable -i flag
+type inputList []string
+
+// Function to format the inputs for the flag usage
+func (inputs *inputList) String() string {
+	return strings.Join(*inputs, ", ")
+}
+
+// Function to add an input given with -i
+func (inputs *inputList) Set(value string) error {
+	*inputs = append(*inputs, value)
+	return nil
+}
+
+// Input argument reading the document from stdin
+const stdinInput = "-"
+
+// Helper function to check whether stdin is a pipe or a file rather than a terminal
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// Function to expand the -i argument into input files. Directories expand to the XSD and
+// WSDL files they contain, and arguments with wildcards are expanded as glob patterns.
+func expandInputs(input string) ([]string, error) {
+	if isURL(input) {
+		return []string{input}, nil
 	}
+	if info, err := os.Stat(input); err == nil && info.IsDir() {
+		entries, err := os.ReadDir(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory: %w", err)
+		}
+		var files []string
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if !entry.IsDir() && slices.Contains([]string{".xsd", ".wsdl", ".dtd", ".rng", ".rnc"}, ext) {
+				files = append(files, filepath.Join(input, entry.Name()))
+			}
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no XSD, WSDL, DTD or RELAX NG files found in %s", input)
+		}
+		return files, nil
+	}
+
+	if strings.ContainsAny(input, "*?[") {
+		files, err := filepath.Glob(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", input, err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no files match %s", input)
+		}
+		return files, nil
+	}
+
+	return []string{input}, nil
+}
+
+// Function to convert several input files with a pool of workers, returning the result of
+// each input in the order of inputs. Messages are logged with the file they are about, and
+// the report gets the inputs in order whatever the order they finish in. Outputs printed
+// to stdout would interleave, so dry-run, stats and stdout modes convert one file at a time.
+func (c converter) convertBatch(inputs []string, concurrency int) []error {
+	if c.dryRun || c.stats || c.stdoutMode != "" {
+		concurrency = 1
+	}
+	results := make([]error, len(inputs))
+	reports := make([]*reporter, len(inputs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(inputs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				inputConverter := c
+				inputConverter.log = c.log.With("file", inputs[i])
+				if c.report != nil {
+					inputConverter.report = &reporter{}
+				}
+				results[i] = inputConverter.convert(inputs[i])
+				inputConverter.report.converted(inputs[i], results[i])
+				reports[i] = inputConverter.report
+				if results[i] != nil {
+					inputConverter.log.Error(results[i].Error())
+				}
+			}
+		}()
+	}
+	for i := range inputs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, report := range reports {
+		c.report.include(report)
+	}
+	return results
 }
 
-// Function to generate Workato Schema for child elements
-func generateWorkatoSchemaForChildren(children []Element, parent string) []WorkatoField {
-	var properties []WorkatoField
-	var fieldName = ""
-	for _, child := range children {
-		if parent == "" {
-			fieldName = child.Name
+// Function to print a summary table of a batch run
+func printBatchSummary(w io.Writer, inputs []string, results []error) error {
+	failed := 0
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tSTATUS\tDETAIL")
+	for i, input := range inputs {
+		if results[i] != nil {
+			failed++
+			fmt.Fprintf(tw, "%s\tFAILED\t%v\n", input, results[i])
 		} else {
-			fieldName = parent + "_" + child.Name
+			fmt.Fprintf(tw, "%s\tOK\t\n", input)
 		}
-		workatoField := WorkatoField{
-			Name:     fieldName,
-			Label:    fieldName,
-			Type:     mapXSDTypeToWorkatoType(child.Type),
-			Optional: true, // Set to true or false based on your logic
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d succeeded, %d failed\n", len(inputs)-failed, failed)
+	return err
+}
+
+// Function to get the schema formats generated with -format, both standing for the Workato
+// schema and the JSON Schema
+func schemaFormats(format string) []string {
+	if format == "both" {
+		return []string{"workato", "jsonschema"}
+	}
+	return []string{format}
+}
+
+// Function to select the outputs listed by -emit, such as template,schema,sample-xml: the
+// schema formats, among which schema stands for the Workato schema, whether the template is
+// generated, and the samples and validation rules, which turn on their flags
+func parseEmit(list string, sampleXML, sampleJSON, rules *bool) ([]string, bool, error) {
+	var formats []string
+	template := false
+	for _, output := range strings.Split(list, ",") {
+		switch output = strings.TrimSpace(output); output {
+		case "template":
+			template = true
+		case "sample-xml":
+			*sampleXML = true
+		case "sample-json":
+			*sampleJSON = true
+		case "rules":
+			*rules = true
+		case "schema", "jsonschema", "avro", "openapi", "openapi-json", "sdk", "gostructs", "fieldlist", "fieldlist-md":
+			if output == "schema" {
+				output = "workato"
+			}
+			if !slices.Contains(formats, output) {
+				formats = append(formats, output)
+			}
+		default:
+			return nil, false, fmt.Errorf("unknown output %q, expected template, schema, jsonschema, avro, openapi, openapi-json, sdk, gostructs, fieldlist, fieldlist-md, sample-xml, sample-json or rules", output)
 		}
+	}
+	return formats, template, nil
+}
+
+// Function to check whether a schema format is generated
+func (c converter) emits(format string) bool {
+	return slices.Contains(c.formats, format)
+}
 
-		// If the child has its own children, treat it as an object
-		if len(child.Children) > 0 {
-			workatoField.Type = "array"
-			workatoField.Of = "object"
-			workatoField.Properties = generateWorkatoSchemaForChildren(child.Children, child.Name)
+// Output locations for the generated files
+type outputConfig struct {
+	dir               string // Directory for the outputs, defaults to the directory of the input
+	templateName      string // File name of the template, defaults to <input name><templateExtension>
+	templateExtension string // Extension of the default template file name, defaults to .template
+	schemaName        string // File name of the Workato schema, defaults to <input name>-schema.json
+	baseName          string // Base name of the outputs, defaults to the name of the input
+	pattern           string // Pattern of the output file names, such as {name}-{kind}.{ext}, or "" for the default names
+}
+
+// Pattern of the namespace prefixes accepted by -namespace-prefix, XML names without colons
+var namespacePrefixName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9._-]*$`)
+
+// Placeholders of the output file name pattern
+var outputPlaceholder = regexp.MustCompile(`\{[^{}]*\}`)
+
+// Function to check that an output file name pattern only has known placeholders, among
+// which {name} and {kind}, without which the outputs of an input, or of several inputs,
+// would overwrite each other
+func validateOutputPattern(pattern string) error {
+	for _, placeholder := range outputPlaceholder.FindAllString(pattern, -1) {
+		if placeholder != "{name}" && placeholder != "{kind}" && placeholder != "{ext}" {
+			return fmt.Errorf("unknown placeholder %s, expected {name}, {kind} or {ext}", placeholder)
 		}
+	}
+	if !strings.Contains(pattern, "{name}") || !strings.Contains(pattern, "{kind}") {
+		return fmt.Errorf("pattern %q must contain {name} and {kind}", pattern)
+	}
+	return nil
+}
 
-		properties = append(properties, workatoField)
+// Function to compute the path of an output named after the input file, with the suffix
+// and extension appended to its base name. The suffix distinguishes several outputs
+// generated from the same input, such as WSDL operations.
+func (config outputConfig) file(inputFile, suffix, extension string) string {
+	// Outputs of a URL are named after the last segment of its path, in the working directory
+	if isURL(inputFile) {
+		if inputURL, err := url.Parse(inputFile); err == nil {
+			inputFile = path.Base(inputURL.Path)
+		}
+	}
+	dir := config.dir
+	if dir == "" {
+		dir = filepath.Dir(inputFile)
+	}
+	baseName := strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))
+	if config.baseName != "" {
+		baseName = config.baseName
 	}
-	return properties
+	if config.pattern != "" && extension != "" {
+		return filepath.Join(dir, filepath.FromSlash(config.patternName(baseName+suffix, extension)))
+	}
+	return filepath.Join(dir, baseName+suffix+extension)
+}
+
+// Function to name an output after the pattern, from its base name and the extension of its
+// default file name: the kind and extension of -schema.json are schema and json, and the
+// template extensions, such as .liquid, are of the template kind
+func (config outputConfig) patternName(name, extension string) string {
+	kind, ext, _ := strings.Cut(strings.TrimPrefix(extension, "-"), ".")
+	if strings.HasPrefix(extension, ".") {
+		kind, ext = "template", strings.TrimPrefix(extension, ".")
+	}
+	return strings.NewReplacer("{name}", name, "{kind}", kind, "{ext}", ext).Replace(config.pattern)
+}
+
+// Function to compute the template and schema file paths for an input file, applying
+// the file name overrides
+func (config outputConfig) paths(inputFile, suffix string) (string, string) {
+	templateExtension := config.templateExtension
+	if templateExtension == "" {
+		templateExtension = ".template"
+	}
+	templateFile := config.file(inputFile, suffix, templateExtension)
+	if config.templateName != "" {
+		templateFile = filepath.Join(filepath.Dir(templateFile), config.templateName)
+	}
+	schemaFile := config.file(inputFile, suffix, "-schema.json")
+	if config.schemaName != "" {
+		schemaFile = filepath.Join(filepath.Dir(schemaFile), config.schemaName)
+	}
+	return templateFile, schemaFile
 }
 
-// Function to write the Workato Schema to a JSON file
-func writeWorkatoSchemaToFile(schema []WorkatoField, outputFile string) error {
-	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+// Function to generate the template and the schemas selected by the output format and
+// write them next to each other
+func (c converter) writeOutputs(schema xsd.Schema, inputFile, suffix string) error {
+	templateFile, schemaFile := c.outputs.paths(inputFile, suffix)
+	// Output file name patterns may put the outputs of each kind in a directory of its own
+	for _, file := range c.outputFiles(schema, inputFile, suffix) {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to create output directory: %w", err))
+		}
+	}
+
+	// Write the template to a file
+	if !c.noTemplate {
+		err := os.WriteFile(templateFile, []byte(c.withProvenance(c.generateTemplate(schema))), 0644)
+		if err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write template file: %w", err))
+		}
+		c.log.Info("Template generated successfully", "file", templateFile)
+	}
+
+	if c.sampleXML {
+		sampleFile := c.outputs.file(inputFile, suffix, "-sample.xml")
+		// The sample document does not depend on the template engine, so render the Mustache one
+		template := mustache.Generate(schema, mustache.Options{Options: c.opts})
+		sample, err := mustache.Render(template, workato.Sample(schema, c.opts))
+		if err != nil {
+			return fmt.Errorf("failed to render sample XML: %w", err)
+		}
+		if err := os.WriteFile(sampleFile, []byte(sample), 0644); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write sample XML file: %w", err))
+		}
+		c.log.Info("Sample XML generated successfully", "file", sampleFile)
+	}
+
+	if c.sampleJSON {
+		sampleFile := c.outputs.file(inputFile, suffix, "-sample.json")
+		sample, err := json.MarshalIndent(workato.Sample(schema, c.opts), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to generate sample JSON: %w", err)
+		}
+		if err := os.WriteFile(sampleFile, sample, 0644); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write sample JSON file: %w", err))
+		}
+		c.log.Info("Sample JSON generated successfully", "file", sampleFile)
+	}
+
+	if c.rules {
+		rulesFile := c.outputs.file(inputFile, suffix, "-rules.json")
+		if err := workato.WriteValidationRules(workato.ValidationRules(schema, c.opts), rulesFile); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write validation rules: %w", err))
+		}
+		c.log.Info("Validation rules generated successfully", "file", rulesFile)
+	}
+
+	if c.emits("workato") {
+		// Generate Workato Schema
+		workatoSchema, err := workato.Generate(schema, c.opts)
+		if err != nil {
+			return fmt.Errorf("failed to generate Workato Schema: %w", err)
+		}
+
+		// Write the Workato Schema to a file, or to several when split
+		if err := c.writeSchema(c.withFieldProvenance(workatoSchema), schemaFile); err != nil {
+			return err
+		}
+	}
+
+	if c.emits("jsonschema") {
+		jsonSchemaFile := c.outputs.file(inputFile, suffix, "-jsonschema.json")
+		document := jsonschema.Generate(schema, c.jsonSchemaOptions())
+		document.Comment = c.note
+		schemaJSON, err := jsonschema.Marshal(document)
+		if err != nil {
+			return fmt.Errorf("failed to generate JSON Schema: %w", err)
+		}
+		if err := os.WriteFile(jsonSchemaFile, schemaJSON, 0644); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write JSON Schema to file: %w", err))
+		}
+		c.log.Info("JSON Schema generated successfully", "file", jsonSchemaFile)
+	}
+
+	if c.emits("avro") {
+		avroFile := c.outputs.file(inputFile, suffix, "-avro.avsc")
+		avroJSON, err := avro.Marshal(avro.Generate(schema, c.avroOptions()))
+		if err != nil {
+			return fmt.Errorf("failed to generate Avro schema: %w", err)
+		}
+		if err := os.WriteFile(avroFile, avroJSON, 0644); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write Avro schema to file: %w", err))
+		}
+		c.log.Info("Avro schema generated successfully", "file", avroFile)
+	}
+
+	for _, format := range []string{"openapi", "openapi-json"} {
+		if !c.emits(format) {
+			continue
+		}
+		document, extension, err := c.generateOpenAPI(schema, format)
+		if err != nil {
+			return fmt.Errorf("failed to generate OpenAPI components: %w", err)
+		}
+		openAPIFile := c.outputs.file(inputFile, suffix, extension)
+		if err := os.WriteFile(openAPIFile, document, 0644); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write OpenAPI components to file: %w", err))
+		}
+		c.log.Info("OpenAPI components generated successfully", "file", openAPIFile)
+	}
+
+	if c.emits("sdk") {
+		connectorFile := c.outputs.file(inputFile, suffix, "-connector.rb")
+		snippet, err := sdk.Generate(schema, c.sdkOptions())
+		if err != nil {
+			return fmt.Errorf("failed to generate connector snippet: %w", err)
+		}
+		if err := os.WriteFile(connectorFile, []byte(snippet), 0644); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write connector snippet to file: %w", err))
+		}
+		c.log.Info("Connector snippet generated successfully", "file", connectorFile)
+	}
+
+	if c.emits("gostructs") {
+		typesFile := c.outputs.file(inputFile, suffix, "-types.go")
+		source, err := gostructs.Generate(schema, c.goStructsOptions())
+		if err != nil {
+			return fmt.Errorf("failed to generate Go types: %w", err)
+		}
+		if err := os.WriteFile(typesFile, source, 0644); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write Go types to file: %w", err))
+		}
+		c.log.Info("Go types generated successfully", "file", typesFile)
+	}
+
+	for _, format := range []string{"fieldlist", "fieldlist-md"} {
+		if !c.emits(format) {
+			continue
+		}
+		fieldList, extension, err := c.generateFieldList(schema, format)
+		if err != nil {
+			return fmt.Errorf("failed to generate field list: %w", err)
+		}
+		fieldListFile := c.outputs.file(inputFile, suffix, extension)
+		if err := os.WriteFile(fieldListFile, fieldList, 0644); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write field list to file: %w", err))
+		}
+		c.log.Info("Field list generated successfully", "file", fieldListFile)
+	}
+	return nil
+}
+
+// Function to print the files writeOutputs would write and the tree of the Workato fields
+// they describe, without writing anything
+func (c converter) printPlan(w io.Writer, schema xsd.Schema, inputFile, suffix string) error {
+	fields, err := workato.Generate(schema, c.opts)
 	if err != nil {
-		return fmt.Errorf("error marshaling schema to JSON: %w", err)
+		return fmt.Errorf("failed to generate Workato Schema: %w", err)
 	}
 
-	err = os.WriteFile(outputFile, schemaJSON, 0644)
+	for _, file := range c.outputFiles(schema, inputFile, suffix) {
+		fmt.Fprintln(w, "Would write:", file)
+	}
+	return workato.PrintTree(w, fields)
+}
+
+// Function to print the statistics of a schema and the number of fields of its Workato
+// schema, without writing anything
+func (c converter) printStats(w io.Writer, schema xsd.Schema, inputFile string) error {
+	fields, err := workato.Generate(schema, c.opts)
 	if err != nil {
-		return fmt.Errorf("error writing schema to file: %w", err)
+		return fmt.Errorf("failed to generate Workato Schema: %w", err)
 	}
 
+	stats := schema.Statistics()
+	root := ""
+	if len(schema.Elements) > 0 {
+		root = schema.Elements[0].Name
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Statistics of %s (%s):\n", root, inputFile)
+	fmt.Fprintf(&sb, "  Elements:          %d\n", stats.Elements)
+	fmt.Fprintf(&sb, "  Attributes:        %d\n", stats.Attributes)
+	fmt.Fprintf(&sb, "  Max depth:         %d\n", stats.MaxDepth)
+	fmt.Fprintf(&sb, "  Distinct types:    %d\n", stats.Types)
+	fmt.Fprintf(&sb, "  Enumerations:      %d (%d values)\n", stats.Enumerations, stats.EnumerationValues)
+	fmt.Fprintf(&sb, "  Repeating:         %d (%d groups)\n", stats.Repeating, stats.RepeatingGroups)
+	fmt.Fprintf(&sb, "  Workato fields:    %d\n", workato.CountFields(fields))
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return withExitCode(exitWrite, err)
+	}
 	return nil
 }
 
-func main() {
-	// Command line flag for input file
-	inputFile := flag.String("i", "", "Path to the XSD file")
-	flag.Parse()
+// Function to list the files writeOutputs writes for a schema, as selected by the output format
+func (c converter) outputFiles(schema xsd.Schema, inputFile, suffix string) []string {
+	templateFile, schemaFile := c.outputs.paths(inputFile, suffix)
+	var files []string
+	if !c.noTemplate {
+		files = append(files, templateFile)
+	}
+	if c.sampleXML {
+		files = append(files, c.outputs.file(inputFile, suffix, "-sample.xml"))
+	}
+	if c.sampleJSON {
+		files = append(files, c.outputs.file(inputFile, suffix, "-sample.json"))
+	}
+	if c.rules {
+		files = append(files, c.outputs.file(inputFile, suffix, "-rules.json"))
+	}
+	if c.emits("workato") {
+		// The number of parts of a split schema depends on its fields
+		fields, _ := workato.Generate(schema, c.opts)
+		files = append(files, c.schemaFiles(fields, schemaFile)...)
+	}
+	if c.emits("jsonschema") {
+		files = append(files, c.outputs.file(inputFile, suffix, "-jsonschema.json"))
+	}
+	if c.emits("avro") {
+		files = append(files, c.outputs.file(inputFile, suffix, "-avro.avsc"))
+	}
+	if c.emits("openapi") {
+		files = append(files, c.outputs.file(inputFile, suffix, "-openapi.yaml"))
+	}
+	if c.emits("openapi-json") {
+		files = append(files, c.outputs.file(inputFile, suffix, "-openapi.json"))
+	}
+	if c.emits("sdk") {
+		files = append(files, c.outputs.file(inputFile, suffix, "-connector.rb"))
+	}
+	if c.emits("gostructs") {
+		files = append(files, c.outputs.file(inputFile, suffix, "-types.go"))
+	}
+	if c.emits("fieldlist") {
+		files = append(files, c.outputs.file(inputFile, suffix, "-fields.csv"))
+	}
+	if c.emits("fieldlist-md") {
+		files = append(files, c.outputs.file(inputFile, suffix, "-fields.md"))
+	}
+	return files
+}
 
-	// Parse the XSD file
-	xsd, err := parseXSD(*inputFile)
-	if err != nil {
-		fmt.Println("Error parsing XSD:", err)
-		return
+// Function to print the template and/or the schemas selected by the output format to w,
+// as selected by the stdout mode (schema, template or both)
+func (c converter) printOutputs(w io.Writer, schema xsd.Schema) error {
+	kind := c.stdoutMode
+	if kind == "template" || kind == "both" {
+		if _, err := io.WriteString(w, c.generateTemplate(schema)); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write template: %w", err))
+		}
 	}
 
-	// Generate Mustache template
-	template := generateTemplate(xsd)
+	if (kind == "schema" || kind == "both") && c.emits("workato") {
+		workatoSchema, err := workato.Generate(schema, c.opts)
+		if err != nil {
+			return fmt.Errorf("failed to generate Workato Schema: %w", err)
+		}
+		schemaJSON, err := workato.Marshal(workatoSchema)
+		if err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write Workato Schema: %w", err))
+		}
+		if _, err := w.Write(append(schemaJSON, '\n')); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write Workato Schema: %w", err))
+		}
+	}
 
-	// Output file path: change the extension to .template
-	templateOutputFile := strings.TrimSuffix(strings.ToLower(*inputFile), ".xsd") + ".template" // Updated
+	if (kind == "schema" || kind == "both") && c.emits("jsonschema") {
+		schemaJSON, err := jsonschema.Marshal(jsonschema.Generate(schema, c.jsonSchemaOptions()))
+		if err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write JSON Schema: %w", err))
+		}
+		if _, err := w.Write(append(schemaJSON, '\n')); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write JSON Schema: %w", err))
+		}
+	}
 
-	// Write the template to a file
-	err = os.WriteFile(templateOutputFile, []byte(template), 0644)
-	if err != nil {
-		fmt.Println("Error writing template file:", err)
-		return
+	if (kind == "schema" || kind == "both") && c.emits("avro") {
+		avroJSON, err := avro.Marshal(avro.Generate(schema, c.avroOptions()))
+		if err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write Avro schema: %w", err))
+		}
+		if _, err := w.Write(append(avroJSON, '\n')); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write Avro schema: %w", err))
+		}
 	}
-	fmt.Println("Template generated successfully:", templateOutputFile)
 
-	// Generate Workato Schema
-	workatoSchema, err := generateWorkatoSchema(xsd)
-	if err != nil {
-		fmt.Println("Error generating Workato Schema:", err)
-		return
+	for _, format := range []string{"openapi", "openapi-json"} {
+		if (kind != "schema" && kind != "both") || !c.emits(format) {
+			continue
+		}
+		document, _, err := c.generateOpenAPI(schema, format)
+		if err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write OpenAPI components: %w", err))
+		}
+		if _, err := w.Write(append(document, '\n')); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write OpenAPI components: %w", err))
+		}
 	}
 
-	// Write the Workato Schema to a file
-	workatoSchemaJSONoutputFile := strings.TrimSuffix(strings.ToLower(*inputFile), ".xsd") + "-schema.json" // Updated
-	err = writeWorkatoSchemaToFile(workatoSchema, workatoSchemaJSONoutputFile)
-	if err != nil {
-		fmt.Println("Error writing Workato Schema to file:", err)
-		return
+	if (kind == "schema" || kind == "both") && c.emits("sdk") {
+		snippet, err := sdk.Generate(schema, c.sdkOptions())
+		if err != nil {
+			return fmt.Errorf("failed to generate connector snippet: %w", err)
+		}
+		if _, err := io.WriteString(w, snippet); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write connector snippet: %w", err))
+		}
+	}
+
+	if (kind == "schema" || kind == "both") && c.emits("gostructs") {
+		source, err := gostructs.Generate(schema, c.goStructsOptions())
+		if err != nil {
+			return fmt.Errorf("failed to generate Go types: %w", err)
+		}
+		if _, err := w.Write(source); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write Go types: %w", err))
+		}
+	}
+
+	for _, format := range []string{"fieldlist", "fieldlist-md"} {
+		if (kind != "schema" && kind != "both") || !c.emits(format) {
+			continue
+		}
+		fieldList, _, err := c.generateFieldList(schema, format)
+		if err != nil {
+			return fmt.Errorf("failed to generate field list: %w", err)
+		}
+		if _, err := w.Write(fieldList); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write field list: %w", err))
+		}
+	}
+	return nil
+}
+
+// Function to generate the template of a schema with the selected template engine
+func (c converter) generateTemplate(schema xsd.Schema) string {
+	if c.templateEngine == "liquid" {
+		return liquid.Generate(schema, liquid.Options{Options: c.opts, SOAP: c.soap, Skeleton: c.skeleton})
 	}
+	return mustache.Generate(schema, mustache.Options{Options: c.opts, SOAP: c.soap, Skeleton: c.skeleton})
+}
+
+// Helper function to derive the JSON Schema options from the Workato options, so that
+// attribute properties carry the same prefix
+func (c converter) jsonSchemaOptions() jsonschema.Options {
+	return jsonschema.Options{AttributePrefix: c.opts.AttributePrefix}
+}
 
-	fmt.Println("Workato Schema generated successfully:", workatoSchemaJSONoutputFile)
+// Function to generate the OpenAPI components of a schema, in YAML or in JSON with the
+// openapi-json format, along with the ending of their file name
+func (c converter) generateOpenAPI(schema xsd.Schema, format string) ([]byte, string, error) {
+	document := openapi.Generate(schema, openapi.Options{AttributePrefix: c.opts.AttributePrefix})
+	if format == "openapi-json" {
+		data, err := openapi.Marshal(document)
+		return data, "-openapi.json", err
+	}
+	data, err := openapi.MarshalYAML(document)
+	return data, "-openapi.yaml", err
+}
+
+// Function to generate the field list of a schema, as CSV or as a Markdown table with the
+// fieldlist-md format, along with the ending of its file name
+func (c converter) generateFieldList(schema xsd.Schema, format string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	entries := workato.FieldList(schema, c.opts)
+	if format == "fieldlist-md" {
+		err := workato.WriteFieldListMarkdown(&buf, entries)
+		return buf.Bytes(), "-fields.md", err
+	}
+	err := workato.WriteFieldListCSV(&buf, entries)
+	return buf.Bytes(), "-fields.csv", err
+}
+
+// Helper function to derive the SDK snippet options from the Workato options, so that the
+// object definitions name the fields as the template placeholders do
+func (c converter) sdkOptions() sdk.Options {
+	return sdk.Options{Options: c.opts, Action: c.sdkAction, SOAP: c.soap, Skeleton: c.skeleton}
+}
+
+// Helper function to derive the Avro options from the Workato options, so that attribute
+// fields carry the same prefix, as far as Avro names allow
+func (c converter) avroOptions() avro.Options {
+	return avro.Options{AttributePrefix: c.opts.AttributePrefix}
+}
+
+// Helper function to derive the Go types options from the Workato options, so that the JSON
+// names of attribute fields carry the same prefix
+func (c converter) goStructsOptions() gostructs.Options {
+	return gostructs.Options{Package: c.goPackage, AttributePrefix: c.opts.AttributePrefix}
+}
+
+// Helper function to check the value of the -naming flag
+func validNaming(naming string) bool {
+	return naming == workato.NamingFlat || naming == workato.NamingNested || naming == workato.NamingPath
+}
+
+// Helper function to check the value of the -case flag
+func validCase(conversion string) bool {
+	switch conversion {
+	case workato.CaseOriginal, workato.CaseCamel, workato.CaseSnake, workato.CasePascal:
+		return true
+	}
+	return false
 }
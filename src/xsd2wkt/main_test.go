@@ -0,0 +1,1160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/peaz/xsd2wkt/pkg/avro"
+	"github.com/peaz/xsd2wkt/pkg/gostructs"
+	"github.com/peaz/xsd2wkt/pkg/jsonschema"
+	"github.com/peaz/xsd2wkt/pkg/openapi"
+	"github.com/peaz/xsd2wkt/pkg/profile"
+	"github.com/peaz/xsd2wkt/pkg/sdk"
+	"github.com/peaz/xsd2wkt/pkg/template/liquid"
+	"github.com/peaz/xsd2wkt/pkg/template/mustache"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// Options matching the command line defaults
+var testOptions = workato.Options{AttributePrefix: "@"}
+
+// Helper function to compare output against a golden file, rewriting it with -update
+func checkGolden(t *testing.T, goldenFile string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(goldenFile, got, 0644); err != nil {
+			t.Fatalf("updating golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatalf("reading golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch\n--- got ---\n%s\n--- want ---\n%s", goldenFile, got, want)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	cases := []string{
+		"flat",
+		"nested",
+		"repeating",
+		"mixed_types",
+		"named_types",
+		"namespaces",
+		"imports",
+		"attributes",
+		"choice",
+		"all",
+		"enumerations",
+		"documentation",
+		"substitution",
+		"polymorphism",
+		"lists_unions",
+		"defaults",
+		"nillable",
+		"groups",
+		"unsupported",
+		"recursion",
+		"mixed_content",
+	}
+
+	for _, name := range cases {
+		t.Run(name, func(t *testing.T) {
+			schema, err := xsd.ParseFile(filepath.Join("testdata", name+".xsd"))
+			if err != nil {
+				t.Fatalf("ParseFile: %v", err)
+			}
+			// Substitution group members are global elements too; the first one is the root
+			if schema, err = schema.SelectRoot(schema.Elements[0].Name); err != nil {
+				t.Fatalf("SelectRoot: %v", err)
+			}
+
+			template := mustache.Generate(schema, mustache.Options{Options: testOptions})
+			checkGolden(t, filepath.Join("testdata", name+".template"), []byte(template))
+
+			liquidTemplate := liquid.Generate(schema, liquid.Options{Options: testOptions})
+			checkGolden(t, filepath.Join("testdata", name+".liquid"), []byte(liquidTemplate))
+
+			sample, err := mustache.Render(template, workato.Sample(schema, testOptions))
+			if err != nil {
+				t.Fatalf("mustache.Render: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+"-sample.xml"), []byte(sample))
+			if errs, err := verify(schema, testOptions); err != nil || len(errs) > 0 {
+				t.Errorf("verify: %v %v", err, errs)
+			}
+
+			sampleJSON, err := json.MarshalIndent(workato.Sample(schema, testOptions), "", "  ")
+			if err != nil {
+				t.Fatalf("marshaling sample: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+"-sample.json"), sampleJSON)
+
+			fields, err := workato.Generate(schema, testOptions)
+			if err != nil {
+				t.Fatalf("workato.Generate: %v", err)
+			}
+			schemaJSON, err := json.MarshalIndent(fields, "", "  ")
+			if err != nil {
+				t.Fatalf("marshaling schema: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+"-schema.json"), schemaJSON)
+
+			jsonSchema, err := jsonschema.Marshal(jsonschema.Generate(schema, jsonschema.Options{AttributePrefix: "@"}))
+			if err != nil {
+				t.Fatalf("jsonschema.Marshal: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+"-jsonschema.json"), jsonSchema)
+
+			avroSchema, err := avro.Marshal(avro.Generate(schema, avro.Options{AttributePrefix: "@"}))
+			if err != nil {
+				t.Fatalf("avro.Marshal: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+"-avro.avsc"), avroSchema)
+
+			components, err := openapi.MarshalYAML(openapi.Generate(schema, openapi.Options{AttributePrefix: "@"}))
+			if err != nil {
+				t.Fatalf("openapi.MarshalYAML: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+"-openapi.yaml"), components)
+
+			snippet, err := sdk.Generate(schema, sdk.Options{Options: testOptions, Action: true})
+			if err != nil {
+				t.Fatalf("sdk.Generate: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+"-connector.rb"), []byte(snippet))
+
+			source, err := gostructs.Generate(schema, gostructs.Options{Package: "contract", AttributePrefix: "@"})
+			if err != nil {
+				t.Fatalf("gostructs.Generate: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+"-types.go"), source)
+		})
+	}
+}
+
+// Every naming strategy and case conversion must name the fields alike in the schema, the template and the
+// sample data, so that the sample renders to the same document and the schema converts back
+func TestNamingStrategies(t *testing.T) {
+	strategies := []struct {
+		naming, conversion, street string
+	}{
+		{workato.NamingNested, "", "Street"},
+		{workato.NamingPath, "", "Order_Customer_Address_Street"},
+		{workato.NamingFlat, workato.CaseSnake, "address_street"},
+		{workato.NamingPath, workato.CaseCamel, "orderCustomerAddressStreet"},
+	}
+	for _, strategy := range strategies {
+		opts := testOptions
+		opts.Naming, opts.Case = strategy.naming, strategy.conversion
+		for _, name := range []string{"nested", "attributes", "choice", "polymorphism", "lists_unions"} {
+			t.Run(strategy.naming+strategy.conversion+"/"+name, func(t *testing.T) {
+				schema, err := xsd.ParseFile(filepath.Join("testdata", name+".xsd"))
+				if err != nil {
+					t.Fatalf("ParseFile: %v", err)
+				}
+
+				template := mustache.Generate(schema, mustache.Options{Options: opts})
+				sample, err := mustache.Render(template, workato.Sample(schema, opts))
+				if err != nil {
+					t.Fatalf("mustache.Render: %v", err)
+				}
+				checkGolden(t, filepath.Join("testdata", name+"-sample.xml"), []byte(sample))
+
+				fields, err := workato.Generate(schema, opts)
+				if err != nil {
+					t.Fatalf("workato.Generate: %v", err)
+				}
+				// Converted names cannot be mapped back to the element names
+				if opts.Case == "" {
+					got, err := workato.Generate(workato.ToSchema(fields, opts), opts)
+					if err != nil {
+						t.Fatalf("workato.Generate: %v", err)
+					}
+					if !reflect.DeepEqual(got, fields) {
+						t.Errorf("round trip mismatch\n--- got ---\n%+v\n--- want ---\n%+v", got, fields)
+					}
+				}
+
+				if name == "nested" {
+					if got := fields[0].Properties[1].Properties[1].Properties[0].Name; got != strategy.street {
+						t.Errorf("Street field = %s, want %s", got, strategy.street)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestGoldenWSDL(t *testing.T) {
+	operations, err := xsd.ParseWSDLFile(filepath.Join("testdata", "orders.wsdl"))
+	if err != nil {
+		t.Fatalf("ParseWSDLFile: %v", err)
+	}
+	if len(operations) != 2 {
+		t.Fatalf("got %d operations, want 2", len(operations))
+	}
+	if operations[1].Response != nil {
+		t.Errorf("one-way operation %s has a response", operations[1].Name)
+	}
+
+	for _, operation := range operations {
+		messages := map[string]*xsd.Schema{"request": operation.Request, "response": operation.Response}
+		for kind, schema := range messages {
+			if schema == nil {
+				continue
+			}
+			name := "orders-" + operation.Name + "-" + kind
+			checkGolden(t, filepath.Join("testdata", name+".template"), []byte(mustache.Generate(*schema, mustache.Options{Options: testOptions})))
+
+			fields, err := workato.Generate(*schema, testOptions)
+			if err != nil {
+				t.Fatalf("workato.Generate: %v", err)
+			}
+			schemaJSON, err := json.MarshalIndent(fields, "", "  ")
+			if err != nil {
+				t.Fatalf("marshaling schema: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+"-schema.json"), schemaJSON)
+		}
+	}
+}
+
+// Inputs converted through the XSD element model, with the constructs they skip
+func TestGoldenFrontEnds(t *testing.T) {
+	cases := []struct {
+		input           string
+		infer           bool // Whether the input is a sample to infer the schema from
+		wantUnsupported []string
+	}{
+		{"order.dtd", false, nil},
+		{"order.rnc", false, nil},
+		{"order.xml", true, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			parse := parseFile
+			if tc.infer {
+				parse = xsd.Parser.InferFile
+			}
+			schema, err := parse(xsd.Parser{}, filepath.Join("testdata", tc.input))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if schema, err = schema.SelectRoot(""); err != nil {
+				t.Fatalf("SelectRoot: %v", err)
+			}
+			if !slices.Equal(schema.Unsupported, tc.wantUnsupported) {
+				t.Errorf("unsupported = %v, want %v", schema.Unsupported, tc.wantUnsupported)
+			}
+
+			name := strings.Replace(tc.input, ".", "-", 1)
+			template := mustache.Generate(schema, mustache.Options{Options: testOptions})
+			checkGolden(t, filepath.Join("testdata", name+".template"), []byte(template))
+			fields, err := workato.Generate(schema, testOptions)
+			if err != nil {
+				t.Fatalf("workato.Generate: %v", err)
+			}
+			schemaJSON, err := json.MarshalIndent(fields, "", "  ")
+			if err != nil {
+				t.Fatalf("marshaling schema: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+"-schema.json"), schemaJSON)
+			if errs, err := verify(schema, testOptions); err != nil || len(errs) > 0 {
+				t.Errorf("verify: %v %v", err, errs)
+			}
+		})
+	}
+}
+
+// Profiles adapt the outputs to the conventions of a family of XSDs
+func TestGoldenProfiles(t *testing.T) {
+	cases := []struct {
+		input   string
+		profile string
+	}{
+		{"idoc.xsd", profile.SAPIDoc},
+		{"pain.001.xsd", profile.ISO20022},
+		{"camt.053.xsd", profile.ISO20022},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			selected, err := profile.Lookup(tc.profile)
+			if err != nil {
+				t.Fatalf("Lookup: %v", err)
+			}
+			schema, err := parseFile(xsd.Parser{}, filepath.Join("testdata", tc.input))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if schema, err = schema.SelectRoot(""); err != nil {
+				t.Fatalf("SelectRoot: %v", err)
+			}
+			schema, opts := selected.Apply(schema, testOptions)
+
+			name := strings.TrimSuffix(tc.input, filepath.Ext(tc.input))
+			template := mustache.Generate(schema, mustache.Options{Options: opts})
+			checkGolden(t, filepath.Join("testdata", name+".template"), []byte(template))
+			fields, err := workato.Generate(schema, opts)
+			if err != nil {
+				t.Fatalf("workato.Generate: %v", err)
+			}
+			schemaJSON, err := json.MarshalIndent(fields, "", "  ")
+			if err != nil {
+				t.Fatalf("marshaling schema: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+"-schema.json"), schemaJSON)
+			rulesJSON, err := json.MarshalIndent(workato.ValidationRules(schema, opts), "", "  ")
+			if err != nil {
+				t.Fatalf("marshaling validation rules: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+"-rules.json"), rulesJSON)
+			if errs, err := verify(schema, opts); err != nil || len(errs) > 0 {
+				t.Errorf("verify: %v %v", err, errs)
+			}
+		})
+	}
+}
+
+// The overrides of a config keep precedence over those of the sap-idoc profile
+func TestProfileOverrides(t *testing.T) {
+	selected, err := profile.Lookup(profile.SAPIDoc)
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	schema, err := parseFile(xsd.Parser{}, filepath.Join("testdata", "idoc.xsd"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	opts := testOptions
+	opts.Overrides = map[string]workato.FieldOverride{
+		"ORDERS05/IDOC/E1EDKA1": {Label: "Partners"},
+		"ORDERS05/IDOC/E1EDP01": {Name: "Items"},
+	}
+	_, opts = selected.Apply(schema, opts)
+
+	if got := opts.FieldName("ORDERS05/IDOC/E1EDKA1"); got != "E1EDKA1" {
+		t.Errorf("FieldName(E1EDKA1) = %q, want E1EDKA1", got)
+	}
+	if got := opts.Overrides["ORDERS05/IDOC/E1EDKA1"].Label; got != "Partners" {
+		t.Errorf("label of E1EDKA1 = %q, want Partners", got)
+	}
+	if got := opts.FieldName("ORDERS05/IDOC/E1EDP01"); got != "Items" {
+		t.Errorf("FieldName(E1EDP01) = %q, want Items", got)
+	}
+	if _, err := profile.Lookup("sap"); err == nil {
+		t.Error("Lookup(sap) succeeded, want an error")
+	}
+}
+
+// The abbreviations and control types of a config keep precedence over those of the
+// iso20022 profile
+func TestISO20022Overrides(t *testing.T) {
+	selected, err := profile.Lookup(profile.ISO20022)
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	schema, err := parseFile(xsd.Parser{}, filepath.Join("testdata", "camt.053.xsd"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	opts := testOptions
+	opts.Abbreviations = map[string]string{"Stmt": "Account Statement"}
+	opts.Overrides = map[string]workato.FieldOverride{
+		"Document/BkToCstmrStmt/Stmt/Ntry/Amt/#text": {ControlType: "number"},
+	}
+	_, opts = selected.Apply(schema, opts)
+
+	if got := opts.Label("GrpHdr_MsgId"); got != "Group Header Message Identification" {
+		t.Errorf("Label(GrpHdr_MsgId) = %q, want Group Header Message Identification", got)
+	}
+	if got := opts.Label("Stmt_Ntry"); got != "Account Statement Entry" {
+		t.Errorf("Label(Stmt_Ntry) = %q, want Account Statement Entry", got)
+	}
+	if got := opts.Overrides["Document/BkToCstmrStmt/Stmt/Bal/Amt/#text"]; got.ControlType != "currency" || got.Type != "number" {
+		t.Errorf("override of the balance amount = %+v, want a currency number", got)
+	}
+	if got := opts.Overrides["Document/BkToCstmrStmt/Stmt/Ntry/Amt/#text"].ControlType; got != "number" {
+		t.Errorf("control_type of the entry amount = %q, want number", got)
+	}
+}
+
+func TestOutputPaths(t *testing.T) {
+	cases := []struct {
+		input        string
+		config       outputConfig
+		suffix       string
+		wantTemplate string
+		wantSchema   string
+	}{
+		{"Schemas/Order.XSD", outputConfig{}, "", "Schemas/Order.template", "Schemas/Order-schema.json"},
+		{"Schemas/Order.XSD", outputConfig{dir: "out"}, "", "out/Order.template", "out/Order-schema.json"},
+		{"Schemas/Order.XSD", outputConfig{templateName: "order.mustache", schemaName: "order.json"}, "", "Schemas/order.mustache", "Schemas/order.json"},
+		{"Schemas/Order.XSD", outputConfig{dir: "out"}, "-Get-request", "out/Order-Get-request.template", "out/Order-Get-request-schema.json"},
+		{"https://example.com/schemas/Order.XSD?version=2", outputConfig{}, "", "Order.template", "Order-schema.json"},
+		{"Schemas/Order.XSD", outputConfig{pattern: "{name}-{kind}.{ext}"}, "", "Schemas/Order-template.template", "Schemas/Order-schema.json"},
+		{"Schemas/Order.XSD", outputConfig{dir: "Out", templateExtension: ".liquid", pattern: "{kind}/{name}.{ext}"}, "-Get-request", "Out/template/Order-Get-request.liquid", "Out/schema/Order-Get-request.json"},
+		{"Schemas/Order.XSD", outputConfig{schemaName: "order.json", pattern: "{name}.{kind}.{ext}"}, "", "Schemas/Order.template.template", "Schemas/order.json"},
+	}
+
+	for _, c := range cases {
+		gotTemplate, gotSchema := c.config.paths(c.input, c.suffix)
+		if gotTemplate != filepath.FromSlash(c.wantTemplate) || gotSchema != filepath.FromSlash(c.wantSchema) {
+			t.Errorf("%s %+v: paths = %s, %s, want %s, %s", c.input, c.config, gotTemplate, gotSchema, c.wantTemplate, c.wantSchema)
+		}
+	}
+
+	if got := (outputConfig{pattern: "{kind}/{name}.{ext}"}).file("Order.xsd", "", "-sample.xml"); got != filepath.FromSlash("sample/Order.xml") {
+		t.Errorf("file of the sample = %s, want sample/Order.xml", got)
+	}
+	for pattern, wantErr := range map[string]bool{"{name}-{kind}.{ext}": false, "{kind}/{name}": false, "{name}.{ext}": true, "{name}-{type}.{ext}": true} {
+		if err := validateOutputPattern(pattern); (err != nil) != wantErr {
+			t.Errorf("validateOutputPattern(%q) = %v, want error %v", pattern, err, wantErr)
+		}
+	}
+}
+
+func TestExpandInputs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.xsd", "b.XSD", "c.wsdl", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := expandInputs(dir)
+	if err != nil {
+		t.Fatalf("expandInputs(dir): %v", err)
+	}
+	if len(files) != 3 {
+		t.Errorf("expandInputs(dir) = %v, want the 3 schema files", files)
+	}
+
+	files, err = expandInputs(filepath.Join(dir, "*.xsd"))
+	if err != nil {
+		t.Fatalf("expandInputs(glob): %v", err)
+	}
+	if len(files) != 1 || filepath.Base(files[0]) != "a.xsd" {
+		t.Errorf("expandInputs(glob) = %v, want [a.xsd]", files)
+	}
+
+	if _, err := expandInputs(filepath.Join(dir, "*.json")); err == nil {
+		t.Error("expandInputs with no matches: expected an error")
+	}
+
+	files, err = expandInputs("single.xsd")
+	if err != nil || len(files) != 1 || files[0] != "single.xsd" {
+		t.Errorf("expandInputs(file) = %v, %v, want [single.xsd]", files, err)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	schemas := map[string]string{
+		"import.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:import namespace="http://example.com/missing" schemaLocation="missing.xsd"/>
+  <xs:element name="Order" type="xs:string"/>
+</xs:schema>`,
+		"any_attribute.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Id" type="xs:string"/>
+      </xs:sequence>
+      <xs:anyAttribute processContents="lax"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`,
+		"malformed.xsd": `<xs:schema`,
+		"blocker":       "",
+	}
+	for name, content := range schemas {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		input, outputDir string
+		want             int
+	}{
+		{filepath.Join("testdata", "flat.xsd"), dir, 0},
+		{filepath.Join(dir, "missing.xsd"), dir, exitParse},
+		{filepath.Join(dir, "malformed.xsd"), dir, exitParse},
+		{filepath.Join(dir, "import.xsd"), dir, exitImport},
+		{filepath.Join("testdata", "flat.xsd"), filepath.Join(dir, "blocker", "out"), exitWrite},
+		{filepath.Join(dir, "any_attribute.xsd"), dir, exitUnsupported},
+	}
+	for _, c := range cases {
+		conv := converter{
+			opts:    testOptions,
+			outputs: outputConfig{dir: c.outputDir},
+			formats: []string{"workato"},
+			log:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}
+		if got := exitCode(conv.convert(c.input)); got != c.want {
+			t.Errorf("convert(%s) exit code = %d, want %d", filepath.Base(c.input), got, c.want)
+		}
+	}
+}
+
+func TestConvertStdin(t *testing.T) {
+	for _, input := range []string{"flat.xsd", "orders.wsdl"} {
+		data, err := os.ReadFile(filepath.Join("testdata", input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		conv := converter{
+			opts:      testOptions,
+			outputs:   outputConfig{dir: dir},
+			formats:   []string{"workato"},
+			stdin:     bytes.NewReader(data),
+			stdinName: "order.v2",
+			log:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}
+		if err := conv.convert(stdinInput); err != nil {
+			t.Fatalf("%s: convert: %v", input, err)
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, "order.v2*.template"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The WSDL has a request and response of one operation and the request of a one-way one
+		want := 1
+		if filepath.Ext(input) == ".wsdl" {
+			want = 3
+		}
+		if len(files) != want {
+			t.Errorf("%s: templates %v, want %d named after -name", input, files, want)
+		}
+	}
+}
+
+func TestOperationDirs(t *testing.T) {
+	dir := t.TempDir()
+	conv := converter{
+		opts:          testOptions,
+		outputs:       outputConfig{dir: dir},
+		formats:       []string{"workato"},
+		operationDirs: true,
+		log:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if err := conv.convert(filepath.Join("testdata", "orders.wsdl")); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			relative, _ := filepath.Rel(dir, path)
+			files = append(files, filepath.ToSlash(relative))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Responses are parsed rather than rendered, so they get no template
+	want := []string{
+		"CancelOrder/CancelOrder-request-schema.json",
+		"CancelOrder/CancelOrder-request.template",
+		"GetOrder/GetOrder-request-schema.json",
+		"GetOrder/GetOrder-request.template",
+		"GetOrder/GetOrder-response-schema.json",
+	}
+	if !slices.Equal(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	conv := converter{
+		opts:    testOptions,
+		outputs: outputConfig{dir: dir},
+		formats: []string{"workato"},
+		log:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	inputs := []string{filepath.Join("testdata", "namespaces.xsd"), filepath.Join("testdata", "imports.xsd"), filepath.Join("testdata", "flat.xsd")}
+	if err := conv.merge(inputs); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	fields, err := workato.ReadFile(filepath.Join(dir, mergedSchemaName))
+	if err != nil {
+		t.Fatalf("reading merged schema: %v", err)
+	}
+	var names []string
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+	// imports.xsd declares the prefix ord, while namespaces.xsd only has a default namespace
+	// whose prefix is derived from its URI, and flat.xsd has no namespace
+	if want := []string{"inventory_Inventory", "ord_Shipment", "Customer"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("top-level fields = %v, want %v", names, want)
+	}
+	template, err := os.ReadFile(filepath.Join(dir, "imports.template"))
+	if err != nil {
+		t.Fatalf("reading template: %v", err)
+	}
+	if !bytes.Contains(template, []byte("{{ord_Shipment.")) {
+		t.Errorf("template does not refer to the prefixed root:\n%s", template)
+	}
+
+	if err := conv.merge([]string{inputs[2], inputs[2]}); exitCode(err) != exitUsage {
+		t.Errorf("merging colliding roots: error %v, want a usage error", err)
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	dir := t.TempDir()
+	conv := converter{
+		opts:       testOptions,
+		outputs:    outputConfig{dir: dir},
+		formats:    []string{"workato", "jsonschema"},
+		provenance: true,
+		log:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	input := filepath.Join("testdata", "flat.xsd")
+	if err := conv.convert(input); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+
+	// The SHA-256 of the input tells whether the outputs are stale
+	data, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	note, err := provenanceNote(input, bytes.NewReader(data), time.Date(2024, 1, 31, 13, 45, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("provenanceNote: %v", err)
+	}
+	prefix := "Generated by xsd2wkt " + toolVersion() + " from flat.xsd (sha256 "
+	if !strings.HasPrefix(note, prefix) || !strings.HasSuffix(note, ") at 2024-01-31T13:45:00Z") {
+		t.Errorf("note = %q, want %q...", note, prefix)
+	}
+	hash := strings.TrimSuffix(strings.Fields(note)[7], ")")
+
+	template, err := os.ReadFile(filepath.Join(dir, "flat.template"))
+	if err != nil {
+		t.Fatalf("reading template: %v", err)
+	}
+	lines := strings.SplitN(string(template), "\n", 3)
+	if !strings.HasPrefix(lines[0], "<?xml") || !strings.HasPrefix(lines[1], "{{! "+prefix+hash+") at ") {
+		t.Errorf("template does not note its provenance after the XML declaration:\n%s", template)
+	}
+	document, err := jsonschema.ReadFile(filepath.Join(dir, "flat-jsonschema.json"))
+	if err != nil {
+		t.Fatalf("reading JSON Schema: %v", err)
+	}
+	if !strings.HasPrefix(document.Comment, prefix+hash) {
+		t.Errorf("JSON Schema $comment = %q, want the provenance", document.Comment)
+	}
+
+	// The Workato schema stays an array of fields, noting the provenance in the hint of its root
+	fields, err := workato.ReadFile(filepath.Join(dir, "flat-schema.json"))
+	if err != nil {
+		t.Fatalf("reading Workato schema: %v", err)
+	}
+	if len(fields) != 1 || !strings.Contains(fields[0].Hint, prefix+hash+") at ") || strings.Contains(fields[0].Properties[0].Hint, "Generated by") {
+		t.Errorf("Workato schema = %+v, want the provenance in the hint of the root field only", fields)
+	}
+	// and wkt2xsd does not take the note for documentation
+	if root := workato.ToSchema(fields, testOptions).Elements[0]; root.Annotation.Text() != "" {
+		t.Errorf("wkt2xsd documentation = %q, want none", root.Annotation.Text())
+	}
+
+	// The comment renders to nothing, leaving the document as without it
+	schema, err := xsd.ParseFile(input)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	rendered, err := mustache.Render(string(template), workato.Sample(schema, testOptions))
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.Contains(rendered, "Generated by") {
+		t.Errorf("rendered document keeps the provenance:\n%s", rendered)
+	}
+
+	conv.provenance = false
+	if err := conv.convert(input); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if template, _ := os.ReadFile(filepath.Join(dir, "flat.template")); bytes.Contains(template, []byte("Generated by")) {
+		t.Errorf("template notes its provenance with -no-provenance:\n%s", template)
+	}
+	if schema, _ := os.ReadFile(filepath.Join(dir, "flat-schema.json")); bytes.Contains(schema, []byte("Generated by")) {
+		t.Errorf("Workato schema notes its provenance with -no-provenance:\n%s", schema)
+	}
+}
+
+func TestSplitAt(t *testing.T) {
+	dir := t.TempDir()
+	conv := converter{
+		opts:    testOptions,
+		outputs: outputConfig{dir: dir},
+		formats: []string{"workato"},
+		splitAt: 5,
+	}
+	var logged bytes.Buffer
+	conv.log = slog.New(slog.NewTextHandler(&logged, nil))
+	if err := conv.convert(filepath.Join("testdata", "nested.xsd")); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "nested-schema-manifest.json"))
+	if err != nil {
+		t.Fatalf("reading manifest: %v", err)
+	}
+	var manifest SplitManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("decoding manifest: %v", err)
+	}
+	schema, err := xsd.ParseFile(filepath.Join("testdata", "nested.xsd"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	whole, err := workato.Generate(schema, testOptions)
+	if err != nil {
+		t.Fatalf("workato.Generate: %v", err)
+	}
+	if len(manifest.Parts) < 2 || manifest.Fields != workato.CountFields(whole) {
+		t.Fatalf("manifest = %+v, want several parts of the %d fields", manifest, workato.CountFields(whole))
+	}
+	for i, part := range manifest.Parts {
+		if want := fmt.Sprintf("nested-schema-%d.json", i+1); part.File != want {
+			t.Errorf("part %d file = %s, want %s", i+1, part.File, want)
+		}
+		fields, err := workato.ReadFile(filepath.Join(dir, part.File))
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		if count := workato.CountFields(fields); count > 5 || count != part.Fields {
+			t.Errorf("part %s has %d fields, want at most 5 and the %d of the manifest", part.File, count, part.Fields)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "nested-schema.json")); !os.IsNotExist(err) {
+		t.Errorf("the whole schema was written next to its parts: %v", err)
+	}
+
+	// The log names the files written rather than the whole schema
+	if !strings.Contains(logged.String(), "manifest="+filepath.Join(dir, "nested-schema-manifest.json")) ||
+		!strings.Contains(logged.String(), filepath.Join(dir, "nested-schema-1.json")) ||
+		strings.Contains(logged.String(), "file="+filepath.Join(dir, "nested-schema.json")) {
+		t.Errorf("log does not name the parts and the manifest:\n%s", logged.String())
+	}
+}
+
+// Writer safe for concurrent use, collecting the output of a goroutine
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	original, err := os.ReadFile(filepath.Join("testdata", "flat.xsd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "customer.xsd")
+	if err := os.WriteFile(input, original, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv := converter{
+		opts:    testOptions,
+		formats: []string{"workato"},
+		log:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var output syncBuffer
+	done := make(chan struct{})
+	go func() {
+		conv.watch(ctx, []string{dir}, 10*time.Millisecond, &output)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Helper function to wait for a condition checked by the watcher's progress
+	waitFor := func(what string, condition func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); !condition(); time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s, output:\n%s", what, output.String())
+			}
+		}
+	}
+	waitFor("the first conversion", func() bool {
+		_, err := os.Stat(filepath.Join(dir, "customer-schema.json"))
+		return err == nil
+	})
+
+	changed := bytes.Replace(original, []byte(`<xs:element name="Email" type="xs:string"/>`),
+		[]byte(`<xs:element name="Email" type="xs:string" minOccurs="0"/>
+        <xs:element name="Phone" type="xs:string"/>`), 1)
+	if err := os.WriteFile(input, changed, 0644); err != nil {
+		t.Fatal(err)
+	}
+	want := input + `:
+~ Customer/Customer_Email: required -> optional
++ Customer/Customer_Phone: string, required
+2 changes: 1 added, 0 removed, 0 retyped, 1 re-cardinalized
+`
+	waitFor("the changes", func() bool { return output.String() == want })
+}
+
+func TestWizard(t *testing.T) {
+	dir := t.TempDir()
+	conv := converter{
+		opts:    testOptions,
+		outputs: outputConfig{dir: dir},
+		formats: []string{"workato"},
+		log:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	configFile := filepath.Join(dir, "repeating-config.yaml")
+	// Fields: 1 Invoice, 2 InvoiceNumber, 3 Note, 4 Tag, 5 Header, 6 IssuedAt, 7 Approver, 8 Name, ...
+	commands := "toggle 3 5\nlabel 2 Invoice number\noptional 2\ntoggle 99\nsave\n"
+	var output bytes.Buffer
+	if err := conv.runWizard(filepath.Join("testdata", "repeating.xsd"), Config{}, configFile, strings.NewReader(commands), &output); err != nil {
+		t.Fatalf("runWizard: %v", err)
+	}
+	if !strings.Contains(output.String(), " 7 [ ]     Approver  array of object, optional") {
+		t.Errorf("the content of excluded Header is not listed as excluded:\n%s", output.String())
+	}
+	if !strings.Contains(output.String(), "invalid field number 99") {
+		t.Errorf("invalid field number not reported:\n%s", output.String())
+	}
+
+	config, err := loadConfig(configFile)
+	if err != nil {
+		t.Fatalf("loading saved config: %v", err)
+	}
+	optional := true
+	want := Config{Root: "Invoice", Fields: map[string]workato.FieldOverride{
+		"Invoice/Note":          {Exclude: true},
+		"Invoice/Header":        {Exclude: true},
+		"Invoice/InvoiceNumber": {Label: "Invoice number", Optional: &optional},
+	}}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("saved config = %+v, want %+v", config, want)
+	}
+
+	fields, err := workato.ReadFile(filepath.Join(dir, "repeating-schema.json"))
+	if err != nil {
+		t.Fatalf("reading schema: %v", err)
+	}
+	var names []string
+	for _, field := range fields[0].Properties {
+		names = append(names, field.Name)
+	}
+	if want := []string{"Invoice_InvoiceNumber", "Invoice_Tag", "Invoice_Line"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("fields = %v, want %v", names, want)
+	}
+}
+
+func TestReport(t *testing.T) {
+	dir := t.TempDir()
+	conv := converter{
+		opts:    testOptions,
+		outputs: outputConfig{dir: dir},
+		formats: []string{"workato"},
+		report:  &reporter{},
+		log:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	inputs := []string{
+		filepath.Join("testdata", "flat.xsd"),
+		filepath.Join("testdata", "unsupported.xsd"),
+		filepath.Join(dir, "missing.xsd"),
+	}
+	for _, input := range inputs {
+		conv.report.converted(input, conv.convert(input))
+	}
+	reportFile := filepath.Join(dir, "report.json")
+	if err := conv.report.write(reportFile, exitUnsupported); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("reading report: %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("parsing report: %v", err)
+	}
+	if report.ExitCode != exitUnsupported || len(report.Inputs) != len(inputs) {
+		t.Fatalf("report = %+v, want exit code %d and %d inputs", report, exitUnsupported, len(inputs))
+	}
+	var statuses []string
+	for _, input := range report.Inputs {
+		statuses = append(statuses, input.Status)
+	}
+	if want := []string{"ok", "incomplete", "failed"}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+
+	flat := report.Inputs[0].Schemas[0]
+	if flat.Elements == 0 || flat.Fields == 0 || len(flat.Warnings) != 0 {
+		t.Errorf("flat.xsd = %+v, want elements and fields without warnings", flat)
+	}
+	want := []string{filepath.Join(dir, "flat.template"), filepath.Join(dir, "flat-schema.json")}
+	if !reflect.DeepEqual(flat.Outputs, want) {
+		t.Errorf("outputs = %v, want %v", flat.Outputs, want)
+	}
+	if unsupported := report.Inputs[1].Schemas[0].Unsupported; len(unsupported) == 0 {
+		t.Errorf("unsupported.xsd reports no skipped construct")
+	}
+	if failed := report.Inputs[2]; failed.ExitCode != exitParse || failed.Error == "" || len(failed.Schemas) != 0 {
+		t.Errorf("missing.xsd = %+v, want a parse failure without schemas", failed)
+	}
+}
+
+func TestConvertBatch(t *testing.T) {
+	dir := t.TempDir()
+	conv := converter{
+		opts:    testOptions,
+		outputs: outputConfig{dir: dir},
+		formats: []string{"workato"},
+		report:  &reporter{},
+		log:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	var inputs []string
+	for _, name := range []string{"flat", "nested", "repeating", "unsupported", "choice", "attributes"} {
+		inputs = append(inputs, filepath.Join("testdata", name+".xsd"))
+	}
+	results := conv.convertBatch(inputs, 4)
+
+	for i, input := range inputs {
+		want := 0
+		if strings.Contains(input, "unsupported") {
+			want = exitUnsupported
+		}
+		if got := exitCode(results[i]); got != want {
+			t.Errorf("%s: exit code = %d, want %d", input, got, want)
+		}
+		// Every input gets the same outputs as when converted alone
+		name := strings.TrimSuffix(filepath.Base(input), ".xsd")
+		got, err := os.ReadFile(filepath.Join(dir, name+"-schema.json"))
+		if err != nil {
+			t.Fatalf("reading schema: %v", err)
+		}
+		checkGolden(t, filepath.Join("testdata", name+"-schema.json"), got)
+		if reported := conv.report.report.Inputs[i].Input; reported != input {
+			t.Errorf("report input %d = %s, want %s", i, reported, input)
+		}
+	}
+}
+
+func TestEmit(t *testing.T) {
+	var sampleXML, sampleJSON, rules bool
+	formats, template, err := parseEmit("schema, jsonschema,sample-xml,openapi-json,schema", &sampleXML, &sampleJSON, &rules)
+	if err != nil {
+		t.Fatalf("parseEmit: %v", err)
+	}
+	if want := []string{"workato", "jsonschema", "openapi-json"}; !slices.Equal(formats, want) || template || !sampleXML || sampleJSON || rules {
+		t.Errorf("parseEmit = %v, template %v, sample-xml %v, sample-json %v, rules %v, want %v and the sample XML only", formats, template, sampleXML, sampleJSON, rules, want)
+	}
+	if _, _, err := parseEmit("template,xsd", &sampleXML, &sampleJSON, &rules); err == nil {
+		t.Errorf("parseEmit accepted an unknown output")
+	}
+
+	dir := t.TempDir()
+	conv := converter{
+		opts:       testOptions,
+		outputs:    outputConfig{dir: dir},
+		formats:    formats,
+		noTemplate: !template,
+		sampleXML:  sampleXML,
+		log:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if err := conv.convert(filepath.Join("testdata", "flat.xsd")); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+	}
+	want := []string{"flat-jsonschema.json", "flat-openapi.json", "flat-sample.xml", "flat-schema.json"}
+	if !slices.Equal(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+}
+
+func TestFlattenXSD(t *testing.T) {
+	dir := t.TempDir()
+	flattened := filepath.Join(dir, "flat", "imports-flat.xsd")
+	conv := converter{
+		opts:       testOptions,
+		outputs:    outputConfig{dir: dir},
+		formats:    []string{"workato"},
+		flattenXSD: flattened,
+		log:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	input := filepath.Join("testdata", "imports.xsd")
+	if err := conv.convert(input); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+
+	// The flattened XSD stands alone and generates the same Workato schema
+	data, err := os.ReadFile(flattened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "xs:import") || strings.Contains(string(data), "xs:include") {
+		t.Errorf("flattened XSD still refers to other documents:\n%s", data)
+	}
+	schema, err := xsd.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("parsing flattened XSD: %v", err)
+	}
+	got, err := workato.Generate(schema, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, err := xsd.ParseFile(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := workato.Generate(original, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Workato schema of the flattened XSD differs:\n%+v\nwant:\n%+v", got, want)
+	}
+
+	if err := conv.convert(filepath.Join("testdata", "orders.wsdl")); exitCode(err) != exitUsage {
+		t.Errorf("flattening a WSDL: error %v, want a usage error", err)
+	}
+}
+
+// The sample data must satisfy the facets of the schema, so that -verify accepts the
+// document rendered from it
+func TestVerifyFacets(t *testing.T) {
+	schema, err := xsd.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Quantity">
+          <xs:simpleType>
+            <xs:restriction base="xs:int">
+              <xs:minInclusive value="10"/>
+              <xs:maxInclusive value="99"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Discount">
+          <xs:simpleType>
+            <xs:restriction base="xs:decimal">
+              <xs:minExclusive value="5"/>
+              <xs:totalDigits value="3"/>
+              <xs:fractionDigits value="1"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Rate">
+          <xs:simpleType>
+            <xs:restriction base="xs:decimal">
+              <xs:minExclusive value="0.25"/>
+              <xs:maxExclusive value="0.5"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Offset">
+          <xs:simpleType>
+            <xs:restriction base="xs:integer">
+              <xs:maxExclusive value="-10"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Reference">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:minLength value="20"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="Country">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:length value="2"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="BIC">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:pattern value="[A-Z]{6}[A-Z2-9][A-NP-Z0-9]([A-Z0-9]{3}){0,1}"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("xsd.Parse: %v", err)
+	}
+
+	sample := workato.Sample(schema, testOptions)["Order"].(map[string]any)
+	want := map[string]any{
+		"Order_Quantity":  "10",
+		"Order_Discount":  6.0,
+		"Order_Rate":      0.375,
+		"Order_Offset":    int64(-11),
+		"Order_Reference": "Sample Referencexxxx",
+		"Order_Country":   "Sa",
+		"Order_BIC":       "AAAAAAAA",
+	}
+	if !reflect.DeepEqual(sample, want) {
+		t.Errorf("sample = %v, want %v", sample, want)
+	}
+	if errs, err := verify(schema, testOptions); err != nil || len(errs) > 0 {
+		t.Errorf("verify: %v %v", err, errs)
+	}
+}
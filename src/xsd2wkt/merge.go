@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// File name of the combined Workato schema, unless set with -schema-name
+const mergedSchemaName = "merged-schema.json"
+
+// Characters replaced by underscores in prefixes derived from namespace URIs
+var prefixReplacer = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// Function to combine the root elements of several XSDs into a single Workato schema, with
+// one top-level object per input named with the prefix of its target namespace. Each input
+// still gets its own template, whose root placeholder refers to the prefixed field.
+func (c converter) merge(inputs []string) error {
+	switch {
+	case !slices.Equal(c.formats, []string{"workato"}) || c.noTemplate || c.sampleXML || c.sampleJSON || c.rules || c.verify || c.stats || c.flattenXSD != "":
+		return withExitCode(exitUsage, fmt.Errorf("-merge only generates the Workato schema and templates, so it cannot be used with -format, -emit, -sample-xml, -sample-json, -validation-rules, -verify, -stats or -flatten-xsd"))
+	case c.stdoutMode != "" && c.stdoutMode != "schema":
+		return withExitCode(exitUsage, fmt.Errorf("-merge can only print the combined schema, with -stdout schema"))
+	case c.outputs.templateName != "" && len(inputs) > 1:
+		return withExitCode(exitUsage, fmt.Errorf("-template-name cannot be used with multiple input files"))
+	}
+
+	var merged []workato.Field
+	var templateFiles, templates []string
+	var schemas []xsd.Schema
+	var inputOpts []workato.Options
+	var unsupported []string
+	owners := make(map[string]string) // Input of each top-level field, to report collisions
+	for _, inputFile := range inputs {
+		schema, err := c.parseSchema(inputFile)
+		if err != nil {
+			return err
+		}
+		for _, warning := range schema.Warnings {
+			c.log.Warn(warning, "file", inputFile)
+		}
+		unsupported = append(unsupported, schema.Unsupported...)
+
+		opts := c.opts
+		rootName := mergedFieldName(schema, opts)
+		if owner, ok := owners[rootName]; ok {
+			return withExitCode(exitUsage, fmt.Errorf("the roots of %s and %s are both named %s, rename one with a field override in -config", owner, inputFile, rootName))
+		}
+		owners[rootName] = inputFile
+		opts.Overrides = make(map[string]workato.FieldOverride, len(c.opts.Overrides)+1)
+		for path, override := range c.opts.Overrides {
+			opts.Overrides[path] = override
+		}
+		override := opts.Overrides[schema.Elements[0].Name]
+		override.Name = rootName
+		opts.Overrides[schema.Elements[0].Name] = override
+
+		fields, err := workato.Generate(schema, opts)
+		if err != nil {
+			return fmt.Errorf("failed to generate Workato Schema: %w", err)
+		}
+		merged = append(merged, fields...)
+		for _, rename := range workato.Renames(schema, opts) {
+			c.log.Warn(rename.String(), "file", inputFile)
+			schema.Warnings = append(slices.Clip(schema.Warnings), rename.String())
+		}
+
+		inputConverter := c
+		inputConverter.opts = opts
+		templateFile, _ := c.outputs.paths(inputFile, "")
+		if inputFile == stdinInput {
+			templateFile, _ = c.outputs.paths(c.stdinName+".xsd", "")
+		}
+		templateFiles = append(templateFiles, templateFile)
+		templates = append(templates, inputConverter.generateTemplate(schema))
+		schemas, inputOpts = append(schemas, schema), append(inputOpts, opts)
+	}
+
+	schemaFile := filepath.Join(c.outputs.dir, mergedSchemaName)
+	if c.outputs.schemaName != "" {
+		schemaFile = filepath.Join(c.outputs.dir, c.outputs.schemaName)
+	}
+
+	switch {
+	case c.dryRun:
+		var sb strings.Builder
+		for _, file := range append(templateFiles, c.schemaFiles(merged, schemaFile)...) {
+			sb.WriteString("Would write: " + file + "\n")
+		}
+		if _, err := io.WriteString(os.Stdout, sb.String()); err != nil {
+			return withExitCode(exitWrite, err)
+		}
+		if err := workato.PrintTree(os.Stdout, merged); err != nil {
+			return withExitCode(exitWrite, err)
+		}
+	case c.stdoutMode != "":
+		schemaJSON, err := workato.Marshal(merged)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stdout.Write(append(schemaJSON, '\n')); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write Workato Schema: %w", err))
+		}
+	default:
+		for i, templateFile := range templateFiles {
+			if err := os.MkdirAll(filepath.Dir(templateFile), 0755); err != nil {
+				return withExitCode(exitWrite, fmt.Errorf("failed to create output directory: %w", err))
+			}
+			if err := os.WriteFile(templateFile, []byte(templates[i]), 0644); err != nil {
+				return withExitCode(exitWrite, fmt.Errorf("failed to write template file: %w", err))
+			}
+			c.log.Info("Template generated successfully", "file", templateFile)
+		}
+		if err := os.MkdirAll(filepath.Dir(schemaFile), 0755); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to create output directory: %w", err))
+		}
+		if err := c.writeSchema(merged, schemaFile); err != nil {
+			return err
+		}
+		c.log.Info("Merged Workato Schema generated successfully", "file", schemaFile)
+	}
+
+	// Every input is reported with its template and the merged schema written for it
+	for i, schema := range schemas {
+		var outputs []string
+		if !c.dryRun && c.stdoutMode == "" {
+			outputs = append([]string{templateFiles[i]}, c.schemaFiles(merged, schemaFile)...)
+		}
+		c.report.emitted(schema, inputOpts[i], outputs)
+	}
+	return unsupportedError(unsupported)
+}
+
+// Function to parse an XSD input, or the one read from stdin, and select its root element
+func (c converter) parseSchema(inputFile string) (xsd.Schema, error) {
+	if strings.HasSuffix(strings.ToLower(inputFile), ".wsdl") {
+		return xsd.Schema{}, withExitCode(exitUsage, fmt.Errorf("WSDL inputs cannot be merged: %s", inputFile))
+	}
+	var schema xsd.Schema
+	var err error
+	if inputFile == stdinInput {
+		var data []byte
+		if data, err = io.ReadAll(c.stdin); err != nil {
+			return xsd.Schema{}, withExitCode(exitParse, fmt.Errorf("failed to read stdin: %w", err))
+		}
+		schema, err = parseData(c.parser, data)
+	} else {
+		schema, err = parseFile(c.parser, inputFile)
+	}
+	if err != nil {
+		return xsd.Schema{}, parseError(fmt.Errorf("failed to parse %s %s: %w", documentKind(inputFile), inputFile, err))
+	}
+	if schema, err = schema.SelectRoot(c.rootElement); err != nil {
+		return xsd.Schema{}, withExitCode(exitUsage, fmt.Errorf("failed to select root element of %s: %w", inputFile, err))
+	}
+	return schema, nil
+}
+
+// Function to name the top-level field of a merged schema's root: the root's field name,
+// prefixed with the prefix of the target namespace unless the name is overridden
+func mergedFieldName(schema xsd.Schema, opts workato.Options) string {
+	root := schema.Elements[0]
+	if override := opts.Overrides[root.Name]; override.Name != "" {
+		return override.Name
+	}
+	prefix := namespacePrefix(schema)
+	if prefix == "" {
+		return opts.FieldName(root.Name)
+	}
+	name := prefix + "_" + root.Name
+	if opts.Case != "" {
+		name = workato.ConvertCase(name, opts.Case)
+	}
+	return name
+}
+
+// Helper function to get the prefix of the target namespace of a schema: the one it declares,
+// or else the last segment of the namespace URI, such as orders for http://example.com/orders
+func namespacePrefix(schema xsd.Schema) string {
+	if schema.TargetNamespace == "" {
+		return ""
+	}
+	if prefix := schema.PrefixFor(schema.TargetNamespace); prefix != "" {
+		return prefix
+	}
+	namespace := strings.TrimRight(schema.TargetNamespace, "/#")
+	segment := namespace[strings.LastIndexAny(namespace, "/:")+1:]
+	return strings.Trim(prefixReplacer.ReplaceAllString(segment, "_"), "_")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+)
+
+// Version of the tool, set when building a release with -ldflags "-X main.version=v1.2.3"
+var version string
+
+// Function to get the version of the tool: the one set at build time, or else the version
+// of the module when installed with go install
+func toolVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "devel"
+}
+
+// Function to describe where the outputs of an input come from, for reviewers to tell
+// whether checked-in outputs are stale: the version of the tool, the name and SHA-256 of the
+// input and the time of the generation, such as "Generated by xsd2wkt v1.2.3 from order.xsd
+// (sha256 9f86d0...) at 2024-01-31T13:45:00Z"
+func provenanceNote(source string, content io.Reader, generated time.Time) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", source, err)
+	}
+	return fmt.Sprintf("Generated by xsd2wkt %s from %s (sha256 %s) at %s",
+		toolVersion(), path.Base(strings.ReplaceAll(source, "\\", "/")), hex.EncodeToString(hash.Sum(nil)), generated.UTC().Format(time.RFC3339)), nil
+}
+
+// Function to get the provenance note of an input file, or HTTP(S) URL, read again to be
+// hashed. Returns "" when provenance is turned off with -no-provenance.
+func (c converter) provenanceOf(inputFile string) (string, error) {
+	if !c.provenance {
+		return "", nil
+	}
+	document, err := c.parser.Fetcher.Open(inputFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	defer document.Close()
+	return provenanceNote(inputFile, document, time.Now())
+}
+
+// Function to add the provenance note as the last sentence of the hints of the root fields of
+// a Workato schema, which Workato reads as a plain array of fields with no room for a comment
+func (c converter) withFieldProvenance(fields []workato.Field) []workato.Field {
+	if c.note == "" {
+		return fields
+	}
+	for i := range fields {
+		hint := fields[i].Hint
+		if hint != "" && !strings.HasSuffix(hint, ".") {
+			hint += "."
+		}
+		if hint != "" {
+			hint += " "
+		}
+		fields[i].Hint = hint + c.note
+	}
+	return fields
+}
+
+// Function to add the provenance note to a template as a comment of its syntax, right after
+// the XML declaration, which must stay first
+func (c converter) withProvenance(template string) string {
+	if c.note == "" {
+		return template
+	}
+	comment := "{{! " + c.note + " }}\n"
+	if c.templateEngine == "liquid" {
+		comment = "{% comment %}" + c.note + "{% endcomment %}\n"
+	}
+	if strings.HasPrefix(template, "<?xml") {
+		end := strings.Index(template, "?>") + len("?>")
+		return template[:end] + "\n" + comment + strings.TrimPrefix(template[end:], "\n")
+	}
+	return comment + template
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Report is the machine-readable summary of a run written by -report, for CI jobs to fail
+// or annotate pull requests on the quality of the conversion
+type Report struct {
+	ExitCode int           `json:"exit_code"` // Exit code of the run
+	Inputs   []InputReport `json:"inputs"`
+}
+
+// InputReport summarizes the conversion of an input file
+type InputReport struct {
+	Input    string         `json:"input"`
+	Status   string         `json:"status"` // ok, incomplete when constructs were skipped, or failed
+	ExitCode int            `json:"exit_code"`
+	Error    string         `json:"error,omitempty"`
+	Schemas  []SchemaReport `json:"schemas"` // The root element, or the messages of every WSDL operation
+}
+
+// SchemaReport describes a converted schema and the outputs generated from it
+type SchemaReport struct {
+	Root         string   `json:"root"`
+	Elements     int      `json:"elements"`      // Elements of the tree of the root, including nested ones
+	Attributes   int      `json:"attributes"`    // Attributes of the tree of the root
+	Fields       int      `json:"fields"`        // Fields of the Workato schema, including nested ones
+	ComplexTypes int      `json:"complex_types"` // Named complexTypes declared in the input document
+	SimpleTypes  int      `json:"simple_types"`  // Named simpleTypes declared in the input document
+	Warnings     []string `json:"warnings"`
+	Unsupported  []string `json:"unsupported"` // Warnings about skipped constructs
+	Unresolved   []string `json:"unresolved"`  // Types and groups referenced but not declared
+	Outputs      []string `json:"outputs"`     // Files written, none in dry-run and stdout modes
+}
+
+// Collector of the report of a run, shared by the copies of the converter converting one
+// input at a time. The methods of a nil reporter do nothing, so that runs without -report
+// need no checks.
+type reporter struct {
+	report  Report
+	schemas []SchemaReport // Schemas emitted for the input being converted
+}
+
+// Function to record a schema emitted for the input being converted
+func (r *reporter) emitted(schema xsd.Schema, opts workato.Options, outputs []string) {
+	if r == nil {
+		return
+	}
+	entry := SchemaReport{
+		ComplexTypes: len(schema.ComplexTypes),
+		SimpleTypes:  len(schema.SimpleTypes),
+		Warnings:     nonNil(schema.Warnings),
+		Unsupported:  nonNil(schema.Unsupported),
+		Unresolved:   nonNil(schema.Unresolved),
+		Outputs:      nonNil(outputs),
+	}
+	if len(schema.Elements) > 0 {
+		entry.Root = schema.Elements[0].Name
+	}
+	stats := schema.Statistics()
+	entry.Elements, entry.Attributes = stats.Elements, stats.Attributes
+	if fields, err := workato.Generate(schema, opts); err == nil {
+		entry.Fields = workato.CountFields(fields)
+	}
+	r.schemas = append(r.schemas, entry)
+}
+
+// Function to record the result of the conversion of an input, with the schemas emitted for it
+func (r *reporter) converted(input string, err error) {
+	if r == nil {
+		return
+	}
+	entry := InputReport{Input: input, Status: "ok", ExitCode: exitCode(err), Schemas: nonNil(r.schemas)}
+	var skipped *skippedError
+	switch {
+	case errors.As(err, &skipped):
+		entry.Status = "incomplete"
+	case err != nil:
+		entry.Status = "failed"
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	r.report.Inputs = append(r.report.Inputs, entry)
+	r.schemas = nil
+}
+
+// Function to add the inputs recorded by another reporter, such as the one of a worker
+func (r *reporter) include(other *reporter) {
+	if r == nil || other == nil {
+		return
+	}
+	r.report.Inputs = append(r.report.Inputs, other.report.Inputs...)
+}
+
+// Function to write the report as indented JSON, with the exit code of the run
+func (r *reporter) write(path string, code int) error {
+	if r == nil {
+		return nil
+	}
+	r.report.ExitCode = code
+	data, err := json.MarshalIndent(r.report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return withExitCode(exitWrite, fmt.Errorf("failed to write report: %w", err))
+	}
+	return nil
+}
+
+// Helper function to write empty lists as [] rather than null in the report
+func nonNil[T any](list []T) []T {
+	if list == nil {
+		return []T{}
+	}
+	return list
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/peaz/xsd2wkt/pkg/template/liquid"
+	"github.com/peaz/xsd2wkt/pkg/template/mustache"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Largest request body accepted by the server, including imported schemas
+const maxRequestSize = 32 << 20
+
+// Timeouts of the server, so that slow or idle clients do not hold connections forever
+const (
+	serveReadTimeout  = 30 * time.Second
+	serveWriteTimeout = 60 * time.Second
+	serveIdleTimeout  = 120 * time.Second
+)
+
+// Function to run the serve subcommand, exposing the conversion as a REST API
+func runServe(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	port := flags.Int("p", 8080, "Port to listen on")
+	maxBody := flags.Int64("max-body", maxRequestSize, "Largest request body accepted, in bytes, including imported schemas")
+	maxDepth := flags.Int("max-depth", xsd.DefaultMaxDepth, "Element nesting depth at which recursive types stop being expanded")
+	attributePrefix := flags.String("attr-prefix", "@", "Prefix for Workato fields generated from XML attributes")
+	configFile := flags.String("config", "", "YAML or JSON file with per-field overrides and custom type mapping rules")
+	inferControls := flags.Bool("infer-controls", false, "Infer control_types from XSD types and field names")
+	naming := flags.String("naming", workato.NamingFlat, "Naming of nested fields: flat (Parent_Child), nested (Child) or path (Root_Parent_Child)")
+	caseConversion := flags.String("case", workato.CaseOriginal, "Case of Workato field names: original, camel, snake or pascal. Template tags keep the XML names")
+	logging := addLogFlags(flags)
+	flags.Parse(args)
+
+	log, err := logging.logger(os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitUsage)
+	}
+	if !validNaming(*naming) {
+		log.Error("-naming must be one of flat, nested or path")
+		os.Exit(exitUsage)
+	}
+	if !validCase(*caseConversion) {
+		log.Error("-case must be one of original, camel, snake or pascal")
+		os.Exit(exitUsage)
+	}
+	if *maxBody < 1 {
+		log.Error("-max-body must be at least 1")
+		os.Exit(exitUsage)
+	}
+	if *maxDepth < 1 {
+		log.Error("-max-depth must be at least 1")
+		os.Exit(exitUsage)
+	}
+	opts := workato.Options{AttributePrefix: *attributePrefix, InferControls: *inferControls, Naming: *naming, Case: *caseConversion}
+	if *configFile != "" {
+		config, err := loadConfig(*configFile)
+		if err != nil {
+			log.Error(err.Error())
+			os.Exit(exitUsage)
+		}
+		opts.Overrides, opts.Abbreviations = config.Fields, config.Abbreviations
+		if opts.TypeMap, err = workato.NewTypeMap(config.Types); err != nil {
+			log.Error(fmt.Sprintf("invalid config %s: %v", *configFile, err))
+			os.Exit(exitUsage)
+		}
+	}
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", *port),
+		Handler:           logRequests(newServer(opts, serveLimits{maxBody: *maxBody, maxDepth: *maxDepth}), log),
+		ReadHeaderTimeout: serveReadTimeout,
+		ReadTimeout:       serveReadTimeout,
+		WriteTimeout:      serveWriteTimeout,
+		IdleTimeout:       serveIdleTimeout,
+	}
+	log.Info("Listening", "address", server.Addr)
+	if err := server.ListenAndServe(); err != nil {
+		log.Error(err.Error())
+		os.Exit(exitFailure)
+	}
+}
+
+// Function to wrap a handler so that every request is logged at debug level
+func logRequests(handler http.Handler, log *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Debug("request", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Response of POST /convert
+type convertResponse struct {
+	Schema   []workato.Field `json:"schema"`
+	Template string          `json:"template"`
+	Warnings []string        `json:"warnings"`
+}
+
+// Error response of the API
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Limits on the requests of the API
+type serveLimits struct {
+	maxBody  int64 // Largest request body accepted, in bytes
+	maxDepth int   // Element nesting depth at which recursive types stop being expanded
+}
+
+// Function to create the HTTP handler of the API
+func newServer(opts workato.Options, limits serveLimits) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /convert", func(w http.ResponseWriter, r *http.Request) {
+		handleConvert(w, r, opts, limits)
+	})
+	return mux
+}
+
+// Function to handle POST /convert. The body is either the XSD document itself, or a
+// multipart form whose "schema" file is the XSD and whose other files are the schemas it
+// includes or imports, each named by its schemaLocation relative to the XSD. The root
+// and engine query parameters select the root element and the template syntax.
+func handleConvert(w http.ResponseWriter, r *http.Request, opts workato.Options, limits serveLimits) {
+	r.Body = http.MaxBytesReader(w, r.Body, limits.maxBody)
+
+	engine := r.URL.Query().Get("engine")
+	if engine != "" && engine != "mustache" && engine != "liquid" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"engine must be one of mustache or liquid"})
+		return
+	}
+
+	schema, err := readRequestSchema(r, limits.maxDepth)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+		return
+	}
+	schema, err = schema.SelectRoot(r.URL.Query().Get("root"))
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{err.Error()})
+		return
+	}
+
+	fields, err := workato.Generate(schema, opts)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{err.Error()})
+		return
+	}
+	response := convertResponse{
+		Schema:   fields,
+		Warnings: append(unmappedTypeWarnings(schema, opts), schema.Warnings...),
+	}
+	if engine == "liquid" {
+		response.Template = liquid.Generate(schema, liquid.Options{Options: opts})
+	} else {
+		response.Template = mustache.Generate(schema, mustache.Options{Options: opts})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Function to parse the schema of a conversion request. The files of the request are laid
+// out in a temporary directory so that schemaLocations resolve, and only within it: the
+// schemas are untrusted, so includes and imports of other files or of URLs are rejected.
+func readRequestSchema(r *http.Request, maxDepth int) (xsd.Schema, error) {
+	dir, err := os.MkdirTemp("", "xsd2wkt-")
+	if err != nil {
+		return xsd.Schema{}, err
+	}
+	defer os.RemoveAll(dir)
+
+	schemaFile := filepath.Join(dir, "schema.xsd")
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if err := writeRequestFiles(r, dir); err != nil {
+			return xsd.Schema{}, err
+		}
+	} else if err := writePart(schemaFile, r.Body); err != nil {
+		return xsd.Schema{}, err
+	}
+
+	parser := xsd.Parser{MaxDepth: maxDepth, Fetcher: xsd.Fetcher{RootDir: dir}}
+	schema, err := parser.ParseFile(schemaFile)
+	if err != nil {
+		return xsd.Schema{}, fmt.Errorf("invalid XSD: %w", err)
+	}
+	return schema, nil
+}
+
+// Function to save the files of a multipart request to dir, the "schema" file as schema.xsd
+// and the others by their relative path
+func writeRequestFiles(r *http.Request, dir string) error {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return err
+	}
+	hasSchema := false
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid multipart body: %w", err)
+		}
+
+		name := part.FormName()
+		if name == "schema" {
+			name = "schema.xsd"
+			hasSchema = true
+		}
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid file name %q: must be a relative path", name)
+		}
+		if err := writePart(filepath.Join(dir, name), part); err != nil {
+			return err
+		}
+	}
+	if !hasSchema {
+		return fmt.Errorf("multipart body has no schema file")
+	}
+	return nil
+}
+
+// Helper function to save a multipart file to path
+func writePart(path string, part io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, part); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Function to list the leaf elements and attributes whose XSD type has no mapping and
+// therefore defaults to string
+func unmappedTypeWarnings(schema xsd.Schema, opts workato.Options) []string {
+	warnings := []string{}
+	var walk func(element xsd.Element, path string)
+	walk = func(element xsd.Element, path string) {
+		for _, attribute := range element.Attributes {
+			walk(attribute.AsElement(), workato.ChildPath(path, "@"+attribute.Name))
+		}
+		if element.Text != nil {
+			walk(*element.Text, workato.TextPath(path))
+		}
+		for _, child := range element.Children {
+			walk(child, workato.ChildPath(path, child.Name))
+		}
+		// XML content passed through is a string by design
+		if !element.IsLeaf() || element.BaseType() == "" || element.IsPassThrough() {
+			return
+		}
+		if _, ok := workato.DefaultTypeMap[element.BaseType()]; ok {
+			return
+		}
+		for _, xsdType := range []string{element.Type, element.BaseType()} {
+			if _, _, ok := opts.TypeMap.Lookup(xsdType); ok && xsdType != "" {
+				return
+			}
+		}
+		warnings = append(warnings, fmt.Sprintf("%s: type %s is not mapped, defaulting to string", path, element.BaseType()))
+	}
+	for _, element := range schema.Elements {
+		walk(element, element.Name)
+	}
+	return warnings
+}
+
+// Helper function to write a JSON response
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		fmt.Fprintln(os.Stderr, "Error writing response:", err)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Helper function to post a request to the API and decode its JSON response
+func postConvert(t *testing.T, url, contentType string, body []byte, response any) int {
+	t.Helper()
+	return postConvertWith(t, serveLimits{maxBody: maxRequestSize, maxDepth: xsd.DefaultMaxDepth}, url, contentType, body, response)
+}
+
+// Helper function to post a request to the API with the limits of the server
+func postConvertWith(t *testing.T, limits serveLimits, url, contentType string, body []byte, response any) int {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	request.Header.Set("Content-Type", contentType)
+	newServer(testOptions, limits).ServeHTTP(recorder, request)
+	if err := json.Unmarshal(recorder.Body.Bytes(), response); err != nil {
+		t.Fatalf("decoding response %s: %v", recorder.Body, err)
+	}
+	return recorder.Code
+}
+
+func TestServeConvert(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "nested.xsd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response convertResponse
+	if code := postConvert(t, "/convert", "application/xml", body, &response); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "nested.template"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Template != string(want) {
+		t.Errorf("template mismatch\n--- got ---\n%s\n--- want ---\n%s", response.Template, want)
+	}
+	if len(response.Schema) == 0 || response.Warnings == nil {
+		t.Errorf("response = %+v, want the schema fields and a warnings list", response)
+	}
+
+	var failure errorResponse
+	if code := postConvert(t, "/convert", "application/xml", []byte("<xs:schema"), &failure); code != http.StatusBadRequest || failure.Error == "" {
+		t.Errorf("invalid XSD: status = %d, error = %q, want 400 with an error", code, failure.Error)
+	}
+}
+
+func TestServeConvertMultipart(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for field, file := range map[string]string{
+		"schema":                  "imports.xsd",
+		"imports/order-types.xsd": "imports/order-types.xsd",
+		"imports/common.xsd":      "imports/common.xsd",
+	} {
+		content, err := os.ReadFile(filepath.Join("testdata", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		part, err := writer.CreateFormFile(field, filepath.Base(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(content)
+	}
+	writer.Close()
+
+	var response convertResponse
+	if code := postConvert(t, "/convert?engine=liquid", writer.FormDataContentType(), body.Bytes(), &response); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "imports.liquid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Template != string(want) {
+		t.Errorf("template mismatch\n--- got ---\n%s\n--- want ---\n%s", response.Template, want)
+	}
+
+	// Files may not be written outside of the temporary directory
+	body.Reset()
+	writer = multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("../escape.xsd", "escape.xsd")
+	part.Write([]byte("<xs:schema/>"))
+	writer.Close()
+	var failure errorResponse
+	if code := postConvert(t, "/convert", writer.FormDataContentType(), body.Bytes(), &failure); code != http.StatusBadRequest || !strings.Contains(failure.Error, "relative path") {
+		t.Errorf("escaping file name: status = %d, error = %q, want 400", code, failure.Error)
+	}
+}
+
+func TestServeConvertConfined(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"/>`)
+	}))
+	defer server.Close()
+	secret := filepath.Join(t.TempDir(), "secret.xsd")
+	if err := os.WriteFile(secret, []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"/>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Schemas of requests may not read server files nor send requests
+	for _, location := range []string{secret, "../secret.xsd", "imports/../../secret.xsd", server.URL + "/order.xsd"} {
+		body := fmt.Sprintf(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:include schemaLocation=%q/><xs:element name="Order" type="xs:string"/></xs:schema>`, location)
+		var failure errorResponse
+		if code := postConvert(t, "/convert", "application/xml", []byte(body), &failure); code != http.StatusBadRequest || !strings.Contains(failure.Error, "only files within the schema directory") {
+			t.Errorf("include of %s: status = %d, error = %q, want 400", location, code, failure.Error)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("server received %d requests, want none", requests)
+	}
+
+	body, err := os.ReadFile(filepath.Join("testdata", "nested.xsd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failure errorResponse
+	if code := postConvertWith(t, serveLimits{maxBody: 64, maxDepth: xsd.DefaultMaxDepth}, "/convert", "application/xml", body, &failure); code != http.StatusBadRequest || !strings.Contains(failure.Error, "too large") {
+		t.Errorf("large body: status = %d, error = %q, want 400", code, failure.Error)
+	}
+}
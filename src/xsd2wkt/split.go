@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+)
+
+// SplitManifest describes a Workato schema split by -split-at into numbered files, whose
+// data together is that of the whole schema
+type SplitManifest struct {
+	SplitAt int         `json:"split_at"` // Largest number of fields of each part
+	Fields  int         `json:"fields"`   // Number of fields of the whole schema
+	Parts   []SplitPart `json:"parts"`
+}
+
+// SplitPart describes a single file of a split Workato schema
+type SplitPart struct {
+	File   string   `json:"file"`   // Name of the file, in the directory of the manifest
+	Fields int      `json:"fields"` // Number of fields of the part, including the objects holding them
+	Paths  []string `json:"paths"`  // Field paths whose whole content is in the part
+}
+
+// Function to split fields as set by -split-at, into a single part when they fit or no
+// limit is set
+func (c converter) splitFields(fields []workato.Field) []workato.Part {
+	if c.splitAt == 0 || workato.CountFields(fields) <= c.splitAt {
+		return []workato.Part{{Fields: fields}}
+	}
+	return workato.Split(fields, c.splitAt)
+}
+
+// Function to list the files the Workato schema fields are written to: schemaFile, or the
+// numbered parts followed by their manifest when the schema is split
+func (c converter) schemaFiles(fields []workato.Field, schemaFile string) []string {
+	parts := c.splitFields(fields)
+	if len(parts) == 1 {
+		return []string{schemaFile}
+	}
+	var files []string
+	for i := range parts {
+		files = append(files, partFile(schemaFile, strconv.Itoa(i+1)))
+	}
+	return append(files, partFile(schemaFile, "manifest"))
+}
+
+// Function to write the Workato schema fields to schemaFile, or, when they are more than
+// -split-at, to numbered parts such as order-schema-1.json with a manifest describing them,
+// logging the files written
+func (c converter) writeSchema(fields []workato.Field, schemaFile string) error {
+	parts := c.splitFields(fields)
+	if len(parts) == 1 {
+		if err := workato.WriteFile(fields, schemaFile); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write Workato Schema to file: %w", err))
+		}
+		c.log.Info("Workato Schema generated successfully", "file", schemaFile)
+		return nil
+	}
+
+	manifest := SplitManifest{SplitAt: c.splitAt, Fields: workato.CountFields(fields)}
+	var files []string
+	for i, part := range parts {
+		file := partFile(schemaFile, strconv.Itoa(i+1))
+		if err := workato.WriteFile(part.Fields, file); err != nil {
+			return withExitCode(exitWrite, fmt.Errorf("failed to write Workato Schema to file: %w", err))
+		}
+		files = append(files, file)
+		manifest.Parts = append(manifest.Parts, SplitPart{File: filepath.Base(file), Fields: workato.CountFields(part.Fields), Paths: part.Paths})
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to generate split manifest: %w", err)
+	}
+	manifestFile := partFile(schemaFile, "manifest")
+	if err := os.WriteFile(manifestFile, append(data, '\n'), 0644); err != nil {
+		return withExitCode(exitWrite, fmt.Errorf("failed to write split manifest: %w", err))
+	}
+	c.log.Info("Workato Schema generated successfully", "manifest", manifestFile, "parts", strings.Join(files, ", "))
+	return nil
+}
+
+// Helper function to name a file of a split schema after the schema file, such as
+// order-schema-2.json for the second part of order-schema.json
+func partFile(schemaFile, name string) string {
+	extension := filepath.Ext(schemaFile)
+	return strings.TrimSuffix(schemaFile, extension) + "-" + name + extension
+}
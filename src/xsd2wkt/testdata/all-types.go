@@ -0,0 +1,20 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Contact holds the Contact element.
+type Contact struct {
+	XMLName   xml.Name       `xml:"Contact" json:"-"`
+	FirstName string         `xml:"FirstName" json:"FirstName"`
+	LastName  string         `xml:"LastName" json:"LastName"`
+	Phone     string         `xml:"Phone,omitempty" json:"Phone,omitempty"`
+	Address   ContactAddress `xml:"Address" json:"Address"`
+}
+
+// ContactAddress holds the Address element.
+type ContactAddress struct {
+	City string `xml:"City" json:"City"`
+	Zip  string `xml:"Zip" json:"Zip"`
+}
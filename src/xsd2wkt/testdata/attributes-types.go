@@ -0,0 +1,26 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Catalog holds the Catalog element.
+type Catalog struct {
+	XMLName   xml.Name         `xml:"Catalog" json:"-"`
+	Version   string           `xml:"version,attr" json:"@version"`
+	Product   []CatalogProduct `xml:"Product" json:"Product"`
+	Publisher CatalogPublisher `xml:"Publisher" json:"Publisher"`
+}
+
+// CatalogProduct holds the Product element.
+type CatalogProduct struct {
+	Id           int64  `xml:"id,attr" json:"@id"`
+	Discontinued *bool  `xml:"discontinued,attr,omitempty" json:"@discontinued,omitempty"`
+	Title        string `xml:"Title" json:"Title"`
+}
+
+// CatalogPublisher holds the Publisher element.
+type CatalogPublisher struct {
+	Code string `xml:"code,attr,omitempty" json:"@code,omitempty"`
+	Name string `xml:"Name" json:"Name"`
+}
@@ -0,0 +1,27 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Payment holds the Payment element.
+type Payment struct {
+	XMLName   xml.Name     `xml:"Payment" json:"-"`
+	Amount    float64      `xml:"Amount" json:"Amount"`
+	IBAN      string       `xml:"IBAN,omitempty" json:"IBAN,omitempty"`
+	Card      *PaymentCard `xml:"Card,omitempty" json:"Card,omitempty"`
+	Reference string       `xml:"Reference" json:"Reference"`
+	Payer     PaymentPayer `xml:"Payer" json:"Payer"`
+}
+
+// PaymentCard holds the Card element.
+type PaymentCard struct {
+	Number string `xml:"Number" json:"Number"`
+	Expiry string `xml:"Expiry" json:"Expiry"`
+}
+
+// PaymentPayer holds the Payer element.
+type PaymentPayer struct {
+	Person       string `xml:"Person,omitempty" json:"Person,omitempty"`
+	Organisation string `xml:"Organisation,omitempty" json:"Organisation,omitempty"`
+}
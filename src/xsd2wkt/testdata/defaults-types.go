@@ -0,0 +1,16 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Invoice holds the Invoice element.
+type Invoice struct {
+	XMLName       xml.Name `xml:"Invoice" json:"-"`
+	SchemaVersion string   `xml:"schemaVersion,attr,omitempty" json:"@schemaVersion,omitempty"`
+	Status        string   `xml:"status,attr,omitempty" json:"@status,omitempty"`
+	Version       string   `xml:"Version" json:"Version"`
+	Currency      string   `xml:"Currency" json:"Currency"`
+	Quantity      int64    `xml:"Quantity" json:"Quantity"`
+	Total         float64  `xml:"Total" json:"Total"`
+}
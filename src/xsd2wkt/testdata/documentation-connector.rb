@@ -0,0 +1,133 @@
+  object_definitions: {
+    employee: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "Employee",
+            label: "Employee",
+            type: "object",
+            optional: false,
+            hint: "An employee record.",
+            properties: [
+              {
+                name: "@Employee_status",
+                label: "Employee Status",
+                type: "string",
+                optional: true,
+                hint: "Employment status"
+              },
+              {
+                name: "Employee_EmployeeId",
+                label: "Employee Employee Id",
+                type: "string",
+                optional: false,
+                hint: "Unique identifier assigned by the HR system"
+              },
+              {
+                name: "Employee_Department",
+                label: "Employee Department",
+                type: "string",
+                optional: false,
+                hint: "Cost center code of the department. Max 6 characters"
+              },
+              {
+                name: "Employee_HiredAt",
+                label: "Employee Hired At",
+                type: "date_time",
+                optional: false
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_employee: {
+      title: "Send Employee",
+      input_fields: lambda do |object_definitions|
+        object_definitions["employee"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <Employee{{#Employee.@Employee_status}} status="{{.}}"{{/Employee.@Employee_status}}>
+          <EmployeeId>{{Employee.Employee_EmployeeId}}</EmployeeId>
+          <Department>{{Employee.Employee_Department}}</Department>
+          {{! Employee_HiredAt is written as YYYY-MM-DDThh:mm:ss±hh:mm, format datapills of other formats with .strftime('%Y-%m-%dT%H:%M:%S%:z') }}
+          <HiredAt>{{Employee.Employee_HiredAt}}</HiredAt>
+          </Employee>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/employee")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
@@ -0,0 +1,17 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Employee holds the Employee element. An employee record.
+type Employee struct {
+	XMLName xml.Name `xml:"Employee" json:"-"`
+	// Employment status
+	Status string `xml:"status,attr,omitempty" json:"@status,omitempty"`
+	// Unique identifier assigned by the HR system
+	EmployeeId string `xml:"EmployeeId" json:"EmployeeId"`
+	// Cost center code of the department. Max 6 characters
+	Department string `xml:"Department" json:"Department"`
+	HiredAt    string `xml:"HiredAt" json:"HiredAt"`
+}
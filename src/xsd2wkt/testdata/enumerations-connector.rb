@@ -0,0 +1,136 @@
+  object_definitions: {
+    order_status: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "OrderStatus",
+            label: "Order Status",
+            type: "object",
+            optional: false,
+            properties: [
+              {
+                name: "@OrderStatus_channel",
+                label: "Order Status Channel",
+                type: "string",
+                optional: true,
+                control_type: "select",
+                options: [["web", "web"], ["store", "store"]]
+              },
+              {
+                name: "OrderStatus_OrderId",
+                label: "Order Status Order Id",
+                type: "string",
+                optional: false
+              },
+              {
+                name: "OrderStatus_Status",
+                label: "Order Status Status",
+                type: "string",
+                optional: false,
+                control_type: "select",
+                options: [["OPEN", "OPEN"], ["SHIPPED", "SHIPPED"], ["CANCELLED", "CANCELLED"]]
+              },
+              {
+                name: "OrderStatus_Priority",
+                label: "Order Status Priority",
+                type: "integer",
+                optional: false,
+                control_type: "select",
+                options: [["1", "1"], ["2", "2"], ["3", "3"]]
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_order_status: {
+      title: "Send Order Status",
+      input_fields: lambda do |object_definitions|
+        object_definitions["order_status"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <OrderStatus{{#OrderStatus.@OrderStatus_channel}} channel="{{.}}"{{/OrderStatus.@OrderStatus_channel}}>
+          <OrderId>{{OrderStatus.OrderStatus_OrderId}}</OrderId>
+          {{! OrderStatus_Status must be one of: OPEN, SHIPPED, CANCELLED }}
+          <Status>{{OrderStatus.OrderStatus_Status}}</Status>
+          {{! OrderStatus_Priority must be one of: 1, 2, 3 }}
+          <Priority>{{OrderStatus.OrderStatus_Priority}}</Priority>
+          </OrderStatus>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/order_status")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
@@ -0,0 +1,14 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// OrderStatus holds the OrderStatus element.
+type OrderStatus struct {
+	XMLName  xml.Name `xml:"OrderStatus" json:"-"`
+	Channel  string   `xml:"channel,attr,omitempty" json:"@channel,omitempty"`
+	OrderId  string   `xml:"OrderId" json:"OrderId"`
+	Status   string   `xml:"Status" json:"Status"`
+	Priority int64    `xml:"Priority" json:"Priority"`
+}
@@ -0,0 +1,13 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Customer holds the Customer element.
+type Customer struct {
+	XMLName xml.Name `xml:"Customer" json:"-"`
+	Id      int64    `xml:"Id" json:"Id"`
+	Name    string   `xml:"Name" json:"Name"`
+	Email   string   `xml:"Email" json:"Email"`
+}
@@ -0,0 +1,25 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Shipment holds the Shipment element.
+type Shipment struct {
+	XMLName     xml.Name         `xml:"http://example.com/shipping Shipment" json:"-"`
+	CreatedBy   string           `xml:"createdBy,attr" json:"@createdBy"`
+	Version     *int64           `xml:"version,attr,omitempty" json:"@version,omitempty"`
+	Id          string           `xml:"Id" json:"Id"`
+	Sender      string           `xml:"Sender" json:"Sender"`
+	Recipient   string           `xml:"Recipient" json:"Recipient"`
+	CarrierCode string           `xml:"CarrierCode,omitempty" json:"CarrierCode,omitempty"`
+	CarrierName string           `xml:"CarrierName,omitempty" json:"CarrierName,omitempty"`
+	Parcel      []ShipmentParcel `xml:"Parcel" json:"Parcel"`
+}
+
+// ShipmentParcel holds the Parcel element.
+type ShipmentParcel struct {
+	Fragile *bool    `xml:"fragile,attr,omitempty" json:"@fragile,omitempty"`
+	Weight  float64  `xml:"Weight" json:"Weight"`
+	Length  *float64 `xml:"Length,omitempty" json:"Length,omitempty"`
+}
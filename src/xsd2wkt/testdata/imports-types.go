@@ -0,0 +1,25 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Shipment holds the Shipment element.
+type Shipment struct {
+	XMLName     xml.Name            `xml:"http://example.com/orders Shipment" json:"-"`
+	Order       ShipmentOrder       `xml:"Order" json:"Order"`
+	Destination ShipmentDestination `xml:"Destination" json:"Destination"`
+}
+
+// ShipmentOrder holds the Order element.
+type ShipmentOrder struct {
+	OrderNumber string  `xml:"OrderNumber" json:"OrderNumber"`
+	Total       float64 `xml:"Total" json:"Total"`
+}
+
+// ShipmentDestination holds the Destination element.
+type ShipmentDestination struct {
+	City string `xml:"City" json:"City"`
+	// Max 2 characters
+	Country string `xml:"Country" json:"Country"`
+}
@@ -0,0 +1,16 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Garment holds the Garment element.
+type Garment struct {
+	XMLName      xml.Name `xml:"Garment" json:"-"`
+	Sizes        string   `xml:"Sizes" json:"Sizes"`
+	Measurements string   `xml:"Measurements" json:"Measurements"`
+	// Accepts values of any of the types SizeCode, xs:integer
+	FitSize string `xml:"FitSize" json:"FitSize"`
+	// Accepts values of any of the types xs:date, xs:string
+	Launch string `xml:"Launch" json:"Launch"`
+}
@@ -0,0 +1,174 @@
+  object_definitions: {
+    section: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "section",
+            label: "Section",
+            type: "object",
+            optional: false,
+            properties: [
+              {
+                name: "@section_ID",
+                label: "Section ID",
+                type: "string",
+                optional: true
+              },
+              {
+                name: "section_title",
+                label: "Section Title",
+                type: "string",
+                optional: false
+              },
+              {
+                name: "section_text",
+                label: "Section Text",
+                type: "object",
+                optional: false,
+                hint: "Narrative text, with runs of styled content",
+                properties: [
+                  {
+                    name: "text_text",
+                    label: "Text Text",
+                    type: "string",
+                    optional: true
+                  },
+                  {
+                    name: "text_content",
+                    label: "Text Content",
+                    type: "array",
+                    of: "object",
+                    optional: true,
+                    properties: [
+                      {
+                        name: "@content_styleCode",
+                        label: "Content Style Code",
+                        type: "string",
+                        optional: true
+                      },
+                      {
+                        name: "content_text",
+                        label: "Content Text",
+                        type: "string",
+                        optional: true
+                      },
+                      {
+                        name: "content_sup",
+                        label: "Content Sup",
+                        type: "string",
+                        optional: true
+                      }
+                    ]
+                  },
+                  {
+                    name: "text_footnote",
+                    label: "Text Footnote",
+                    type: "string",
+                    optional: true
+                  }
+                ]
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_section: {
+      title: "Send section",
+      input_fields: lambda do |object_definitions|
+        object_definitions["section"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <section{{#section.@section_ID}} ID="{{.}}"{{/section.@section_ID}}>
+          <title>{{section.section_title}}</title>
+          <text mediaType="text/x-hl7-text+xml">{{section.section_text.text_text}}
+          {{#section.section_text.text_content}}
+          <content{{#@content_styleCode}} styleCode="{{.}}"{{/@content_styleCode}}>{{content_text}}
+          {{#content_sup}}
+          <sup>{{content_sup}}</sup>
+          {{/content_sup}}
+          </content>
+          {{/section.section_text.text_content}}
+          {{#section.section_text.text_footnote}}
+          <footnote>{{section.section_text.text_footnote}}</footnote>
+          {{/section.section_text.text_footnote}}
+          </text>
+          </section>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/section")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
@@ -0,0 +1,29 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Section holds the section element.
+type Section struct {
+	XMLName xml.Name `xml:"section" json:"-"`
+	ID      string   `xml:"ID,attr,omitempty" json:"@ID,omitempty"`
+	Title   string   `xml:"title" json:"title"`
+	// Narrative text, with runs of styled content
+	Text SectionText `xml:"text" json:"text"`
+}
+
+// SectionText holds the text element. Narrative text, with runs of styled content
+type SectionText struct {
+	MediaType string               `xml:"mediaType,attr,omitempty" json:"@mediaType,omitempty"`
+	Text      string               `xml:",chardata" json:"#text"`
+	Content   []SectionTextContent `xml:"content,omitempty" json:"content,omitempty"`
+	Footnote  string               `xml:"footnote,omitempty" json:"footnote,omitempty"`
+}
+
+// SectionTextContent holds the content element.
+type SectionTextContent struct {
+	StyleCode string `xml:"styleCode,attr,omitempty" json:"@styleCode,omitempty"`
+	Text      string `xml:",chardata" json:"#text"`
+	Sup       string `xml:"sup,omitempty" json:"sup,omitempty"`
+}
@@ -0,0 +1,151 @@
+  object_definitions: {
+    payment: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "Payment",
+            label: "Payment",
+            type: "object",
+            optional: false,
+            properties: [
+              {
+                name: "Payment_Reference",
+                label: "Payment Reference",
+                type: "string",
+                optional: false
+              },
+              {
+                name: "Payment_CreatedAt",
+                label: "Payment Created At",
+                type: "date_time",
+                optional: false
+              },
+              {
+                name: "Payment_Confirmed",
+                label: "Payment Confirmed",
+                type: "boolean",
+                optional: false
+              },
+              {
+                name: "Payment_Attempts",
+                label: "Payment Attempts",
+                type: "integer",
+                optional: false
+              },
+              {
+                name: "Payment_Amount",
+                label: "Payment Amount",
+                type: "number",
+                optional: false
+              },
+              {
+                name: "Payment_Rate",
+                label: "Payment Rate",
+                type: "number",
+                optional: false
+              },
+              {
+                name: "Payment_Unknown",
+                label: "Payment Unknown",
+                type: "string",
+                optional: false
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_payment: {
+      title: "Send Payment",
+      input_fields: lambda do |object_definitions|
+        object_definitions["payment"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <Payment>
+          <Reference>{{Payment.Payment_Reference}}</Reference>
+          {{! Payment_CreatedAt is written as YYYY-MM-DDThh:mm:ss±hh:mm, format datapills of other formats with .strftime('%Y-%m-%dT%H:%M:%S%:z') }}
+          <CreatedAt>{{Payment.Payment_CreatedAt}}</CreatedAt>
+          <Confirmed>{{Payment.Payment_Confirmed}}</Confirmed>
+          <Attempts>{{Payment.Payment_Attempts}}</Attempts>
+          <Amount>{{Payment.Payment_Amount}}</Amount>
+          <Rate>{{Payment.Payment_Rate}}</Rate>
+          <Unknown>{{Payment.Payment_Unknown}}</Unknown>
+          </Payment>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/payment")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
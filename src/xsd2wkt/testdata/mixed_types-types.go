@@ -0,0 +1,17 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Payment holds the Payment element.
+type Payment struct {
+	XMLName   xml.Name `xml:"Payment" json:"-"`
+	Reference string   `xml:"Reference" json:"Reference"`
+	CreatedAt string   `xml:"CreatedAt" json:"CreatedAt"`
+	Confirmed bool     `xml:"Confirmed" json:"Confirmed"`
+	Attempts  int64    `xml:"Attempts" json:"Attempts"`
+	Amount    float64  `xml:"Amount" json:"Amount"`
+	Rate      float64  `xml:"Rate" json:"Rate"`
+	Unknown   string   `xml:"Unknown" json:"Unknown"`
+}
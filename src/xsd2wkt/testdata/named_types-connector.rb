@@ -0,0 +1,159 @@
+  object_definitions: {
+    purchase_order: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "PurchaseOrder",
+            label: "Purchase Order",
+            type: "object",
+            optional: false,
+            properties: [
+              {
+                name: "PurchaseOrder_OrderDate",
+                label: "Purchase Order Order Date",
+                type: "date_time",
+                optional: false
+              },
+              {
+                name: "PurchaseOrder_ShipTo",
+                label: "Purchase Order Ship To",
+                type: "object",
+                optional: false,
+                properties: [
+                  {
+                    name: "ShipTo_Street",
+                    label: "Ship To Street",
+                    type: "string",
+                    optional: false
+                  },
+                  {
+                    name: "ShipTo_PostalCode",
+                    label: "Ship To Postal Code",
+                    type: "string",
+                    optional: false,
+                    hint: "Max 10 characters"
+                  }
+                ]
+              },
+              {
+                name: "PurchaseOrder_BillTo",
+                label: "Purchase Order Bill To",
+                type: "object",
+                optional: false,
+                properties: [
+                  {
+                    name: "BillTo_Street",
+                    label: "Bill To Street",
+                    type: "string",
+                    optional: false
+                  },
+                  {
+                    name: "BillTo_PostalCode",
+                    label: "Bill To Postal Code",
+                    type: "string",
+                    optional: false,
+                    hint: "Max 10 characters"
+                  }
+                ]
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_purchase_order: {
+      title: "Send Purchase Order",
+      input_fields: lambda do |object_definitions|
+        object_definitions["purchase_order"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <tns:PurchaseOrder xmlns:tns="http://example.com/orders">
+          {{! PurchaseOrder_OrderDate is written as YYYY-MM-DDThh:mm:ss±hh:mm, format datapills of other formats with .strftime('%Y-%m-%dT%H:%M:%S%:z') }}
+          <OrderDate>{{PurchaseOrder.PurchaseOrder_OrderDate}}</OrderDate>
+          <ShipTo>
+          <Street>{{PurchaseOrder.PurchaseOrder_ShipTo.ShipTo_Street}}</Street>
+          <PostalCode>{{PurchaseOrder.PurchaseOrder_ShipTo.ShipTo_PostalCode}}</PostalCode>
+          </ShipTo>
+          <BillTo>
+          <Street>{{PurchaseOrder.PurchaseOrder_BillTo.BillTo_Street}}</Street>
+          <PostalCode>{{PurchaseOrder.PurchaseOrder_BillTo.BillTo_PostalCode}}</PostalCode>
+          </BillTo>
+          </tns:PurchaseOrder>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/purchase_order")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
@@ -0,0 +1,27 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// PurchaseOrder holds the PurchaseOrder element.
+type PurchaseOrder struct {
+	XMLName   xml.Name            `xml:"http://example.com/orders PurchaseOrder" json:"-"`
+	OrderDate string              `xml:"OrderDate" json:"OrderDate"`
+	ShipTo    PurchaseOrderShipTo `xml:"ShipTo" json:"ShipTo"`
+	BillTo    PurchaseOrderBillTo `xml:"BillTo" json:"BillTo"`
+}
+
+// PurchaseOrderShipTo holds the ShipTo element.
+type PurchaseOrderShipTo struct {
+	Street string `xml:"Street" json:"Street"`
+	// Max 10 characters
+	PostalCode string `xml:"PostalCode" json:"PostalCode"`
+}
+
+// PurchaseOrderBillTo holds the BillTo element.
+type PurchaseOrderBillTo struct {
+	Street string `xml:"Street" json:"Street"`
+	// Max 10 characters
+	PostalCode string `xml:"PostalCode" json:"PostalCode"`
+}
@@ -0,0 +1,147 @@
+  object_definitions: {
+    inventory: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "Inventory",
+            label: "Inventory",
+            type: "object",
+            optional: false,
+            properties: [
+              {
+                name: "Inventory_Warehouse",
+                label: "Inventory Warehouse",
+                type: "string",
+                optional: false
+              },
+              {
+                name: "Inventory_CountedAt",
+                label: "Inventory Counted At",
+                type: "date_time",
+                optional: false
+              },
+              {
+                name: "Inventory_Item",
+                label: "Inventory Item",
+                type: "object",
+                optional: false,
+                properties: [
+                  {
+                    name: "Item_Sku",
+                    label: "Item Sku",
+                    type: "string",
+                    optional: false
+                  },
+                  {
+                    name: "Item_OnHand",
+                    label: "Item On Hand",
+                    type: "integer",
+                    optional: false
+                  },
+                  {
+                    name: "Item_Active",
+                    label: "Item Active",
+                    type: "boolean",
+                    optional: false
+                  }
+                ]
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_inventory: {
+      title: "Send Inventory",
+      input_fields: lambda do |object_definitions|
+        object_definitions["inventory"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <Inventory xmlns="http://example.com/inventory">
+          <Warehouse>{{Inventory.Inventory_Warehouse}}</Warehouse>
+          {{! Inventory_CountedAt is written as YYYY-MM-DDThh:mm:ss±hh:mm, format datapills of other formats with .strftime('%Y-%m-%dT%H:%M:%S%:z') }}
+          <CountedAt>{{Inventory.Inventory_CountedAt}}</CountedAt>
+          <Item>
+          <Sku>{{Inventory.Inventory_Item.Item_Sku}}</Sku>
+          <OnHand>{{Inventory.Inventory_Item.Item_OnHand}}</OnHand>
+          <Active>{{Inventory.Inventory_Item.Item_Active}}</Active>
+          </Item>
+          </Inventory>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/inventory")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
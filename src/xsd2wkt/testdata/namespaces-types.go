@@ -0,0 +1,20 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Inventory holds the Inventory element.
+type Inventory struct {
+	XMLName   xml.Name      `xml:"http://example.com/inventory Inventory" json:"-"`
+	Warehouse string        `xml:"Warehouse" json:"Warehouse"`
+	CountedAt string        `xml:"CountedAt" json:"CountedAt"`
+	Item      InventoryItem `xml:"Item" json:"Item"`
+}
+
+// InventoryItem holds the Item element.
+type InventoryItem struct {
+	Sku    string `xml:"Sku" json:"Sku"`
+	OnHand int64  `xml:"OnHand" json:"OnHand"`
+	Active bool   `xml:"Active" json:"Active"`
+}
@@ -0,0 +1,185 @@
+  object_definitions: {
+    order: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "Order",
+            label: "Order",
+            type: "object",
+            optional: false,
+            properties: [
+              {
+                name: "Order_OrderId",
+                label: "Order Order Id",
+                type: "string",
+                optional: false
+              },
+              {
+                name: "Order_Customer",
+                label: "Order Customer",
+                type: "object",
+                optional: false,
+                properties: [
+                  {
+                    name: "Customer_Name",
+                    label: "Customer Name",
+                    type: "string",
+                    optional: false
+                  },
+                  {
+                    name: "Customer_Address",
+                    label: "Customer Address",
+                    type: "object",
+                    optional: false,
+                    properties: [
+                      {
+                        name: "Address_Street",
+                        label: "Address Street",
+                        type: "string",
+                        optional: false
+                      },
+                      {
+                        name: "Address_City",
+                        label: "Address City",
+                        type: "string",
+                        optional: false
+                      },
+                      {
+                        name: "Address_Geo",
+                        label: "Address Geo",
+                        type: "object",
+                        optional: true,
+                        properties: [
+                          {
+                            name: "Geo_Coordinates",
+                            label: "Geo Coordinates",
+                            type: "object",
+                            optional: false,
+                            properties: [
+                              {
+                                name: "Coordinates_Latitude",
+                                label: "Coordinates Latitude",
+                                type: "number",
+                                optional: false
+                              },
+                              {
+                                name: "Coordinates_Longitude",
+                                label: "Coordinates Longitude",
+                                type: "number",
+                                optional: false
+                              }
+                            ]
+                          }
+                        ]
+                      }
+                    ]
+                  }
+                ]
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_order: {
+      title: "Send Order",
+      input_fields: lambda do |object_definitions|
+        object_definitions["order"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <Order>
+          <OrderId>{{Order.Order_OrderId}}</OrderId>
+          <Customer>
+          <Name>{{Order.Order_Customer.Customer_Name}}</Name>
+          <Address>
+          <Street>{{Order.Order_Customer.Customer_Address.Address_Street}}</Street>
+          <City>{{Order.Order_Customer.Customer_Address.Address_City}}</City>
+          {{#Order.Order_Customer.Customer_Address.Address_Geo}}
+          <Geo>
+          <Coordinates>
+          <Latitude>{{Order.Order_Customer.Customer_Address.Address_Geo.Geo_Coordinates.Coordinates_Latitude}}</Latitude>
+          <Longitude>{{Order.Order_Customer.Customer_Address.Address_Geo.Geo_Coordinates.Coordinates_Longitude}}</Longitude>
+          </Coordinates>
+          </Geo>
+          {{/Order.Order_Customer.Customer_Address.Address_Geo}}
+          </Address>
+          </Customer>
+          </Order>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/order")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
@@ -0,0 +1,36 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Order holds the Order element.
+type Order struct {
+	XMLName  xml.Name      `xml:"Order" json:"-"`
+	OrderId  string        `xml:"OrderId" json:"OrderId"`
+	Customer OrderCustomer `xml:"Customer" json:"Customer"`
+}
+
+// OrderCustomer holds the Customer element.
+type OrderCustomer struct {
+	Name    string               `xml:"Name" json:"Name"`
+	Address OrderCustomerAddress `xml:"Address" json:"Address"`
+}
+
+// OrderCustomerAddress holds the Address element.
+type OrderCustomerAddress struct {
+	Street string                   `xml:"Street" json:"Street"`
+	City   string                   `xml:"City" json:"City"`
+	Geo    *OrderCustomerAddressGeo `xml:"Geo,omitempty" json:"Geo,omitempty"`
+}
+
+// OrderCustomerAddressGeo holds the Geo element.
+type OrderCustomerAddressGeo struct {
+	Coordinates OrderCustomerAddressGeoCoordinates `xml:"Coordinates" json:"Coordinates"`
+}
+
+// OrderCustomerAddressGeoCoordinates holds the Coordinates element.
+type OrderCustomerAddressGeoCoordinates struct {
+	Latitude  float64 `xml:"Latitude" json:"Latitude"`
+	Longitude float64 `xml:"Longitude" json:"Longitude"`
+}
@@ -0,0 +1,158 @@
+  object_definitions: {
+    customer: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "Customer",
+            label: "Customer",
+            type: "object",
+            optional: false,
+            properties: [
+              {
+                name: "Customer_Name",
+                label: "Customer Name",
+                type: "string",
+                optional: false
+              },
+              {
+                name: "Customer_BirthDate",
+                label: "Customer Birth Date",
+                type: "date",
+                optional: true
+              },
+              {
+                name: "Customer_Address",
+                label: "Customer Address",
+                type: "object",
+                optional: true,
+                properties: [
+                  {
+                    name: "Address_Street",
+                    label: "Address Street",
+                    type: "string",
+                    optional: false
+                  },
+                  {
+                    name: "Address_City",
+                    label: "Address City",
+                    type: "string",
+                    optional: false
+                  }
+                ]
+              },
+              {
+                name: "Customer_Note",
+                label: "Customer Note",
+                type: "array",
+                of: "string",
+                optional: true
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_customer: {
+      title: "Send Customer",
+      input_fields: lambda do |object_definitions|
+        object_definitions["customer"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <Customer>
+          <Name>{{Customer.Customer_Name}}</Name>
+          {{#Customer.Customer_BirthDate}}
+          {{! Customer_BirthDate is written as YYYY-MM-DD, format datapills of other formats with .strftime('%Y-%m-%d') }}
+          <BirthDate>{{Customer.Customer_BirthDate}}</BirthDate>
+          {{/Customer.Customer_BirthDate}}
+          {{^Customer.Customer_BirthDate}}
+          <BirthDate xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/>
+          {{/Customer.Customer_BirthDate}}
+          {{#Customer.Customer_Address}}
+          <Address>
+          <Street>{{Customer.Customer_Address.Address_Street}}</Street>
+          <City>{{Customer.Customer_Address.Address_City}}</City>
+          </Address>
+          {{/Customer.Customer_Address}}
+          {{^Customer.Customer_Address}}
+          <Address xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/>
+          {{/Customer.Customer_Address}}
+          {{#Customer.Customer_Note}}<Note>{{.}}</Note>{{/Customer.Customer_Note}}
+          </Customer>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/customer")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
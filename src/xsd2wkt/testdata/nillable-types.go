@@ -0,0 +1,20 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Customer holds the Customer element.
+type Customer struct {
+	XMLName   xml.Name         `xml:"Customer" json:"-"`
+	Name      string           `xml:"Name" json:"Name"`
+	BirthDate string           `xml:"BirthDate,omitempty" json:"BirthDate,omitempty"`
+	Address   *CustomerAddress `xml:"Address,omitempty" json:"Address,omitempty"`
+	Note      []string         `xml:"Note,omitempty" json:"Note,omitempty"`
+}
+
+// CustomerAddress holds the Address element.
+type CustomerAddress struct {
+	Street string `xml:"Street" json:"Street"`
+	City   string `xml:"City" json:"City"`
+}
@@ -0,0 +1,261 @@
+  object_definitions: {
+    drawing: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "Drawing",
+            label: "Drawing",
+            type: "object",
+            optional: false,
+            properties: [
+              {
+                name: "Drawing_Title",
+                label: "Drawing Title",
+                type: "string",
+                optional: false
+              },
+              {
+                name: "Drawing_Shape",
+                label: "Drawing Shape",
+                type: "array",
+                of: "object",
+                optional: false,
+                properties: [
+                  {
+                    name: "Shape_type",
+                    label: "Shape Type",
+                    type: "string",
+                    optional: false,
+                    control_type: "select",
+                    hint: "Concrete type of Shape, written as xsi:type. Populate the object of the same name.",
+                    options: [["Circle", "Circle"], ["Square", "Square"]]
+                  },
+                  {
+                    name: "Shape_Circle",
+                    label: "Shape Circle",
+                    type: "object",
+                    optional: true,
+                    hint: "A circle around the origin",
+                    properties: [
+                      {
+                        name: "Circle_Color",
+                        label: "Circle Color",
+                        type: "string",
+                        optional: false
+                      },
+                      {
+                        name: "Circle_Radius",
+                        label: "Circle Radius",
+                        type: "number",
+                        optional: false
+                      }
+                    ]
+                  },
+                  {
+                    name: "Shape_Square",
+                    label: "Shape Square",
+                    type: "object",
+                    optional: true,
+                    properties: [
+                      {
+                        name: "@Square_sides",
+                        label: "Square Sides",
+                        type: "integer",
+                        optional: true
+                      },
+                      {
+                        name: "Square_Color",
+                        label: "Square Color",
+                        type: "string",
+                        optional: false
+                      },
+                      {
+                        name: "Square_Side",
+                        label: "Square Side",
+                        type: "number",
+                        optional: false
+                      }
+                    ]
+                  }
+                ]
+              },
+              {
+                name: "Drawing_Background",
+                label: "Drawing Background",
+                type: "object",
+                optional: true,
+                properties: [
+                  {
+                    name: "Background_type",
+                    label: "Background Type",
+                    type: "string",
+                    optional: false,
+                    control_type: "select",
+                    hint: "Concrete type of Background, written as xsi:type. Populate the object of the same name.",
+                    options: [["Circle", "Circle"], ["Square", "Square"]]
+                  },
+                  {
+                    name: "Background_Circle",
+                    label: "Background Circle",
+                    type: "object",
+                    optional: true,
+                    hint: "A circle around the origin",
+                    properties: [
+                      {
+                        name: "Background_Circle_Color",
+                        label: "Background Circle Color",
+                        type: "string",
+                        optional: false
+                      },
+                      {
+                        name: "Background_Circle_Radius",
+                        label: "Background Circle Radius",
+                        type: "number",
+                        optional: false
+                      }
+                    ]
+                  },
+                  {
+                    name: "Background_Square",
+                    label: "Background Square",
+                    type: "object",
+                    optional: true,
+                    properties: [
+                      {
+                        name: "@Background_Square_sides",
+                        label: "Background Square Sides",
+                        type: "integer",
+                        optional: true
+                      },
+                      {
+                        name: "Background_Square_Color",
+                        label: "Background Square Color",
+                        type: "string",
+                        optional: false
+                      },
+                      {
+                        name: "Background_Square_Side",
+                        label: "Background Square Side",
+                        type: "number",
+                        optional: false
+                      }
+                    ]
+                  }
+                ]
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_drawing: {
+      title: "Send Drawing",
+      input_fields: lambda do |object_definitions|
+        object_definitions["drawing"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <Drawing xmlns="http://example.com/drawing">
+          <Title>{{Drawing.Drawing_Title}}</Title>
+          {{#Drawing.Drawing_Shape}}
+          <Shape xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="{{Shape_type}}"{{#Shape_Square}}{{#Shape_Square.@Square_sides}} sides="{{.}}"{{/Shape_Square.@Square_sides}}{{/Shape_Square}}>
+          {{#Shape_Circle}}
+          <Color>{{Shape_Circle.Circle_Color}}</Color>
+          <Radius>{{Shape_Circle.Circle_Radius}}</Radius>
+          {{/Shape_Circle}}
+          {{#Shape_Square}}
+          <Color>{{Shape_Square.Square_Color}}</Color>
+          <Side>{{Shape_Square.Square_Side}}</Side>
+          {{/Shape_Square}}
+          </Shape>
+          {{/Drawing.Drawing_Shape}}
+          {{#Drawing.Drawing_Background}}
+          <Background xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="{{Drawing.Drawing_Background.Background_type}}"{{#Drawing.Drawing_Background.Background_Square}}{{#Drawing.Drawing_Background.Background_Square.@Background_Square_sides}} sides="{{.}}"{{/Drawing.Drawing_Background.Background_Square.@Background_Square_sides}}{{/Drawing.Drawing_Background.Background_Square}}>
+          {{#Drawing.Drawing_Background.Background_Circle}}
+          <Color>{{Drawing.Drawing_Background.Background_Circle.Background_Circle_Color}}</Color>
+          <Radius>{{Drawing.Drawing_Background.Background_Circle.Background_Circle_Radius}}</Radius>
+          {{/Drawing.Drawing_Background.Background_Circle}}
+          {{#Drawing.Drawing_Background.Background_Square}}
+          <Color>{{Drawing.Drawing_Background.Background_Square.Background_Square_Color}}</Color>
+          <Side>{{Drawing.Drawing_Background.Background_Square.Background_Square_Side}}</Side>
+          {{/Drawing.Drawing_Background.Background_Square}}
+          </Background>
+          {{/Drawing.Drawing_Background}}
+          </Drawing>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/drawing")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
@@ -0,0 +1,33 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Drawing holds the Drawing element.
+type Drawing struct {
+	XMLName    xml.Name           `xml:"http://example.com/drawing Drawing" json:"-"`
+	Title      string             `xml:"Title" json:"Title"`
+	Shape      []DrawingShape     `xml:"Shape" json:"Shape"`
+	Background *DrawingBackground `xml:"Background,omitempty" json:"Background,omitempty"`
+}
+
+// DrawingShape holds the Shape element.
+type DrawingShape struct {
+	// Name of the alternative type of the content, such as Circle
+	XsiType string   `xml:"http://www.w3.org/2001/XMLSchema-instance type,attr,omitempty" json:"@xsi:type,omitempty"`
+	Color   string   `xml:"Color,omitempty" json:"Color,omitempty"`
+	Radius  *float64 `xml:"Radius,omitempty" json:"Radius,omitempty"`
+	Sides   *int64   `xml:"sides,attr,omitempty" json:"@sides,omitempty"`
+	Side    *float64 `xml:"Side,omitempty" json:"Side,omitempty"`
+}
+
+// DrawingBackground holds the Background element.
+type DrawingBackground struct {
+	// Name of the alternative type of the content, such as Circle
+	XsiType string   `xml:"http://www.w3.org/2001/XMLSchema-instance type,attr,omitempty" json:"@xsi:type,omitempty"`
+	Color   string   `xml:"Color,omitempty" json:"Color,omitempty"`
+	Radius  *float64 `xml:"Radius,omitempty" json:"Radius,omitempty"`
+	Sides   *int64   `xml:"sides,attr,omitempty" json:"@sides,omitempty"`
+	Side    *float64 `xml:"Side,omitempty" json:"Side,omitempty"`
+}
@@ -0,0 +1,364 @@
+  object_definitions: {
+    catalog: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "Catalog",
+            label: "Catalog",
+            type: "object",
+            optional: false,
+            properties: [
+              {
+                name: "Catalog_Name",
+                label: "Catalog Name",
+                type: "string",
+                optional: false
+              },
+              {
+                name: "Catalog_Category",
+                label: "Catalog Category",
+                type: "array",
+                of: "object",
+                optional: false,
+                properties: [
+                  {
+                    name: "@Category_code",
+                    label: "Category Code",
+                    type: "string",
+                    optional: false
+                  },
+                  {
+                    name: "Category_Title",
+                    label: "Category Title",
+                    type: "string",
+                    optional: false
+                  },
+                  {
+                    name: "Category_Subcategory",
+                    label: "Category Subcategory",
+                    type: "array",
+                    of: "object",
+                    optional: true,
+                    properties: [
+                      {
+                        name: "@Subcategory_code",
+                        label: "Subcategory Code",
+                        type: "string",
+                        optional: false
+                      },
+                      {
+                        name: "Subcategory_Title",
+                        label: "Subcategory Title",
+                        type: "string",
+                        optional: false
+                      },
+                      {
+                        name: "Subcategory_Subcategory",
+                        label: "Subcategory Subcategory",
+                        type: "array",
+                        of: "object",
+                        optional: true,
+                        properties: [
+                          {
+                            name: "@Subcategory_Subcategory_code",
+                            label: "Subcategory Subcategory Code",
+                            type: "string",
+                            optional: false
+                          },
+                          {
+                            name: "Subcategory_Subcategory_Title",
+                            label: "Subcategory Subcategory Title",
+                            type: "string",
+                            optional: false
+                          },
+                          {
+                            name: "Subcategory_Subcategory_Subcategory",
+                            label: "Subcategory Subcategory Subcategory",
+                            type: "array",
+                            of: "object",
+                            optional: true,
+                            properties: [
+                              {
+                                name: "@Subcategory_Subcategory_Subcategory_code",
+                                label: "Subcategory Subcategory Subcategory Code",
+                                type: "string",
+                                optional: false
+                              },
+                              {
+                                name: "Subcategory_Subcategory_Subcategory_Title",
+                                label: "Subcategory Subcategory Subcategory Title",
+                                type: "string",
+                                optional: false
+                              },
+                              {
+                                name: "Subcategory_Subcategory_Subcategory_Subcategory",
+                                label: "Subcategory Subcategory Subcategory Subcategory",
+                                type: "array",
+                                of: "object",
+                                optional: true,
+                                properties: [
+                                  {
+                                    name: "@Subcategory_Subcategory_Subcategory_Subcategory_code",
+                                    label: "Subcategory Subcategory Subcategory Subcategory Code",
+                                    type: "string",
+                                    optional: false
+                                  },
+                                  {
+                                    name: "Subcategory_Subcategory_Subcategory_Subcategory_Title",
+                                    label: "Subcategory Subcategory Subcategory Subcategory Title",
+                                    type: "string",
+                                    optional: false
+                                  },
+                                  {
+                                    name: "Subcategory_Subcategory_Subcategory_Subcategory_Subcategory",
+                                    label: "Subcategory Subcategory Subcategory Subcategory Subcategory",
+                                    type: "array",
+                                    of: "object",
+                                    optional: true,
+                                    properties: [
+                                      {
+                                        name: "@Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_code",
+                                        label: "Subcategory Subcategory Subcategory Subcategory Subcategory Code",
+                                        type: "string",
+                                        optional: false
+                                      },
+                                      {
+                                        name: "Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Title",
+                                        label: "Subcategory Subcategory Subcategory Subcategory Subcategory Title",
+                                        type: "string",
+                                        optional: false
+                                      },
+                                      {
+                                        name: "Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory",
+                                        label: "Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory",
+                                        type: "array",
+                                        of: "object",
+                                        optional: true,
+                                        properties: [
+                                          {
+                                            name: "@Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_code",
+                                            label: "Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Code",
+                                            type: "string",
+                                            optional: false
+                                          },
+                                          {
+                                            name: "Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Title",
+                                            label: "Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Title",
+                                            type: "string",
+                                            optional: false
+                                          },
+                                          {
+                                            name: "Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory",
+                                            label: "Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory",
+                                            type: "array",
+                                            of: "object",
+                                            optional: true,
+                                            properties: [
+                                              {
+                                                name: "@Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_code",
+                                                label: "Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Code",
+                                                type: "string",
+                                                optional: false
+                                              },
+                                              {
+                                                name: "Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Title",
+                                                label: "Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Title",
+                                                type: "string",
+                                                optional: false
+                                              },
+                                              {
+                                                name: "Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory",
+                                                label: "Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory",
+                                                type: "array",
+                                                of: "object",
+                                                optional: true,
+                                                properties: [
+                                                  {
+                                                    name: "@Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_code",
+                                                    label: "Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Code",
+                                                    type: "string",
+                                                    optional: false
+                                                  },
+                                                  {
+                                                    name: "Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Title",
+                                                    label: "Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Title",
+                                                    type: "string",
+                                                    optional: false
+                                                  },
+                                                  {
+                                                    name: "Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory",
+                                                    label: "Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory Subcategory",
+                                                    type: "array",
+                                                    of: "object",
+                                                    optional: true,
+                                                    hint: "Recursive content truncated at the maximum depth"
+                                                  }
+                                                ]
+                                              }
+                                            ]
+                                          }
+                                        ]
+                                      }
+                                    ]
+                                  }
+                                ]
+                              }
+                            ]
+                          }
+                        ]
+                      }
+                    ]
+                  }
+                ]
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_catalog: {
+      title: "Send Catalog",
+      input_fields: lambda do |object_definitions|
+        object_definitions["catalog"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <Catalog>
+          <Name>{{Catalog.Catalog_Name}}</Name>
+          {{#Catalog.Catalog_Category}}
+          {{! The required attribute code of Category is written even when empty }}
+          <Category code="{{@Category_code}}">
+          <Title>{{Category_Title}}</Title>
+          {{#Category_Subcategory}}
+          {{! The required attribute code of Subcategory is written even when empty }}
+          <Subcategory code="{{@Subcategory_code}}">
+          <Title>{{Subcategory_Title}}</Title>
+          {{#Subcategory_Subcategory}}
+          {{! The required attribute code of Subcategory is written even when empty }}
+          <Subcategory code="{{@Subcategory_Subcategory_code}}">
+          <Title>{{Subcategory_Subcategory_Title}}</Title>
+          {{#Subcategory_Subcategory_Subcategory}}
+          {{! The required attribute code of Subcategory is written even when empty }}
+          <Subcategory code="{{@Subcategory_Subcategory_Subcategory_code}}">
+          <Title>{{Subcategory_Subcategory_Subcategory_Title}}</Title>
+          {{#Subcategory_Subcategory_Subcategory_Subcategory}}
+          {{! The required attribute code of Subcategory is written even when empty }}
+          <Subcategory code="{{@Subcategory_Subcategory_Subcategory_Subcategory_code}}">
+          <Title>{{Subcategory_Subcategory_Subcategory_Subcategory_Title}}</Title>
+          {{#Subcategory_Subcategory_Subcategory_Subcategory_Subcategory}}
+          {{! The required attribute code of Subcategory is written even when empty }}
+          <Subcategory code="{{@Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_code}}">
+          <Title>{{Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Title}}</Title>
+          {{#Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory}}
+          {{! The required attribute code of Subcategory is written even when empty }}
+          <Subcategory code="{{@Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_code}}">
+          <Title>{{Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Title}}</Title>
+          {{#Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory}}
+          {{! The required attribute code of Subcategory is written even when empty }}
+          <Subcategory code="{{@Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_code}}">
+          <Title>{{Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Title}}</Title>
+          {{#Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory}}
+          {{! The required attribute code of Subcategory is written even when empty }}
+          <Subcategory code="{{@Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_code}}">
+          <Title>{{Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Title}}</Title>
+          {{#Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory}}
+          <Subcategory>
+          {{! Subcategory is recursive, its content was truncated at the maximum depth }}
+          </Subcategory>
+          {{/Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory}}
+          </Subcategory>
+          {{/Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory}}
+          </Subcategory>
+          {{/Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory}}
+          </Subcategory>
+          {{/Subcategory_Subcategory_Subcategory_Subcategory_Subcategory_Subcategory}}
+          </Subcategory>
+          {{/Subcategory_Subcategory_Subcategory_Subcategory_Subcategory}}
+          </Subcategory>
+          {{/Subcategory_Subcategory_Subcategory_Subcategory}}
+          </Subcategory>
+          {{/Subcategory_Subcategory_Subcategory}}
+          </Subcategory>
+          {{/Subcategory_Subcategory}}
+          </Subcategory>
+          {{/Category_Subcategory}}
+          </Category>
+          {{/Catalog.Catalog_Category}}
+          </Catalog>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/catalog")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
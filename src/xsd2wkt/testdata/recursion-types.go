@@ -0,0 +1,19 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Catalog holds the Catalog element.
+type Catalog struct {
+	XMLName  xml.Name          `xml:"Catalog" json:"-"`
+	Name     string            `xml:"Name" json:"Name"`
+	Category []CatalogCategory `xml:"Category" json:"Category"`
+}
+
+// CatalogCategory holds the Category element.
+type CatalogCategory struct {
+	Code        string            `xml:"code,attr" json:"@code"`
+	Title       string            `xml:"Title" json:"Title"`
+	Subcategory []CatalogCategory `xml:"Subcategory,omitempty" json:"Subcategory,omitempty"`
+}
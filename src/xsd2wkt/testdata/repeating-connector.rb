@@ -0,0 +1,190 @@
+  object_definitions: {
+    invoice: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "Invoice",
+            label: "Invoice",
+            type: "object",
+            optional: false,
+            properties: [
+              {
+                name: "Invoice_InvoiceNumber",
+                label: "Invoice Invoice Number",
+                type: "string",
+                optional: false
+              },
+              {
+                name: "Invoice_Note",
+                label: "Invoice Note",
+                type: "string",
+                optional: true
+              },
+              {
+                name: "Invoice_Tag",
+                label: "Invoice Tag",
+                type: "array",
+                of: "string",
+                optional: true
+              },
+              {
+                name: "Invoice_Header",
+                label: "Invoice Header",
+                type: "object",
+                optional: false,
+                properties: [
+                  {
+                    name: "Header_IssuedAt",
+                    label: "Header Issued At",
+                    type: "date_time",
+                    optional: false
+                  },
+                  {
+                    name: "Header_Approver",
+                    label: "Header Approver",
+                    type: "array",
+                    of: "object",
+                    optional: true,
+                    properties: [
+                      {
+                        name: "Approver_Name",
+                        label: "Approver Name",
+                        type: "string",
+                        optional: false
+                      }
+                    ]
+                  }
+                ]
+              },
+              {
+                name: "Invoice_Line",
+                label: "Invoice Line",
+                type: "array",
+                of: "object",
+                optional: false,
+                properties: [
+                  {
+                    name: "Line_Sku",
+                    label: "Line Sku",
+                    type: "string",
+                    optional: false
+                  },
+                  {
+                    name: "Line_Quantity",
+                    label: "Line Quantity",
+                    type: "integer",
+                    optional: false
+                  }
+                ]
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_invoice: {
+      title: "Send Invoice",
+      input_fields: lambda do |object_definitions|
+        object_definitions["invoice"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <Invoice>
+          <InvoiceNumber>{{Invoice.Invoice_InvoiceNumber}}</InvoiceNumber>
+          {{#Invoice.Invoice_Note}}
+          <Note>{{Invoice.Invoice_Note}}</Note>
+          {{/Invoice.Invoice_Note}}
+          {{#Invoice.Invoice_Tag}}<Tag>{{.}}</Tag>{{/Invoice.Invoice_Tag}}
+          <Header>
+          {{! Header_IssuedAt is written as YYYY-MM-DDThh:mm:ss±hh:mm, format datapills of other formats with .strftime('%Y-%m-%dT%H:%M:%S%:z') }}
+          <IssuedAt>{{Invoice.Invoice_Header.Header_IssuedAt}}</IssuedAt>
+          {{#Invoice.Invoice_Header.Header_Approver}}
+          <Approver>
+          <Name>{{Approver_Name}}</Name>
+          </Approver>
+          {{/Invoice.Invoice_Header.Header_Approver}}
+          </Header>
+          {{#Invoice.Invoice_Line}}
+          <Line>
+          <Sku>{{Line_Sku}}</Sku>
+          <Quantity>{{Line_Quantity}}</Quantity>
+          </Line>
+          {{/Invoice.Invoice_Line}}
+          </Invoice>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/invoice")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
@@ -0,0 +1,32 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Invoice holds the Invoice element.
+type Invoice struct {
+	XMLName       xml.Name      `xml:"Invoice" json:"-"`
+	InvoiceNumber string        `xml:"InvoiceNumber" json:"InvoiceNumber"`
+	Note          string        `xml:"Note,omitempty" json:"Note,omitempty"`
+	Tag           []string      `xml:"Tag,omitempty" json:"Tag,omitempty"`
+	Header        InvoiceHeader `xml:"Header" json:"Header"`
+	Line          []InvoiceLine `xml:"Line" json:"Line"`
+}
+
+// InvoiceHeader holds the Header element.
+type InvoiceHeader struct {
+	IssuedAt string                  `xml:"IssuedAt" json:"IssuedAt"`
+	Approver []InvoiceHeaderApprover `xml:"Approver,omitempty" json:"Approver,omitempty"`
+}
+
+// InvoiceHeaderApprover holds the Approver element.
+type InvoiceHeaderApprover struct {
+	Name string `xml:"Name" json:"Name"`
+}
+
+// InvoiceLine holds the Line element.
+type InvoiceLine struct {
+	Sku      string `xml:"Sku" json:"Sku"`
+	Quantity int64  `xml:"Quantity" json:"Quantity"`
+}
@@ -0,0 +1,178 @@
+  object_definitions: {
+    payment: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "Payment",
+            label: "Payment",
+            type: "object",
+            optional: false,
+            properties: [
+              {
+                name: "Payment_Amount",
+                label: "Payment Amount",
+                type: "number",
+                optional: false
+              },
+              {
+                name: "Payment_Card",
+                label: "Payment Card",
+                type: "object",
+                optional: true,
+                properties: [
+                  {
+                    name: "Card_Number",
+                    label: "Card Number",
+                    type: "string",
+                    optional: false
+                  },
+                  {
+                    name: "Card_Expiry",
+                    label: "Card Expiry",
+                    type: "string",
+                    optional: false
+                  }
+                ]
+              },
+              {
+                name: "Payment_BankTransfer",
+                label: "Payment Bank Transfer",
+                type: "object",
+                optional: true,
+                properties: [
+                  {
+                    name: "BankTransfer_IBAN",
+                    label: "Bank Transfer IBAN",
+                    type: "string",
+                    optional: false
+                  }
+                ]
+              },
+              {
+                name: "Payment_Cheque",
+                label: "Payment Cheque",
+                type: "string",
+                optional: true
+              },
+              {
+                name: "Payment_Remark",
+                label: "Payment Remark",
+                type: "array",
+                of: "string",
+                optional: true
+              },
+              {
+                name: "Payment_InternalRemark",
+                label: "Payment Internal Remark",
+                type: "array",
+                of: "string",
+                optional: true
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_payment: {
+      title: "Send Payment",
+      input_fields: lambda do |object_definitions|
+        object_definitions["payment"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <Payment xmlns="http://example.com/payments">
+          <Amount>{{Payment.Payment_Amount}}</Amount>
+          {{#Payment.Payment_Card}}
+          <Card>
+          <Number>{{Payment.Payment_Card.Card_Number}}</Number>
+          <Expiry>{{Payment.Payment_Card.Card_Expiry}}</Expiry>
+          </Card>
+          {{/Payment.Payment_Card}}
+          {{#Payment.Payment_BankTransfer}}
+          <BankTransfer>
+          <IBAN>{{Payment.Payment_BankTransfer.BankTransfer_IBAN}}</IBAN>
+          </BankTransfer>
+          {{/Payment.Payment_BankTransfer}}
+          {{#Payment.Payment_Cheque}}
+          <Cheque>{{Payment.Payment_Cheque}}</Cheque>
+          {{/Payment.Payment_Cheque}}
+          {{#Payment.Payment_Remark}}<Remark>{{.}}</Remark>{{/Payment.Payment_Remark}}
+          {{#Payment.Payment_InternalRemark}}<InternalRemark>{{.}}</InternalRemark>{{/Payment.Payment_InternalRemark}}
+          </Payment>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/payment")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
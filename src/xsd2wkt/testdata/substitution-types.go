@@ -0,0 +1,27 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Payment holds the Payment element.
+type Payment struct {
+	XMLName        xml.Name             `xml:"http://example.com/payments Payment" json:"-"`
+	Amount         float64              `xml:"Amount" json:"Amount"`
+	Card           *PaymentCard         `xml:"Card,omitempty" json:"Card,omitempty"`
+	BankTransfer   *PaymentBankTransfer `xml:"BankTransfer,omitempty" json:"BankTransfer,omitempty"`
+	Cheque         string               `xml:"Cheque,omitempty" json:"Cheque,omitempty"`
+	Remark         []string             `xml:"Remark,omitempty" json:"Remark,omitempty"`
+	InternalRemark []string             `xml:"InternalRemark,omitempty" json:"InternalRemark,omitempty"`
+}
+
+// PaymentCard holds the Card element.
+type PaymentCard struct {
+	Number string `xml:"Number" json:"Number"`
+	Expiry string `xml:"Expiry" json:"Expiry"`
+}
+
+// PaymentBankTransfer holds the BankTransfer element.
+type PaymentBankTransfer struct {
+	IBAN string `xml:"IBAN" json:"IBAN"`
+}
@@ -0,0 +1,156 @@
+  object_definitions: {
+    envelope: {
+      fields: lambda do |_connection, _config_fields|
+        [
+          {
+            name: "Envelope",
+            label: "Envelope",
+            type: "object",
+            optional: false,
+            properties: [
+              {
+                name: "Envelope_MessageId",
+                label: "Envelope Message Id",
+                type: "string",
+                optional: false,
+                hint: "Key of Envelope: must be present and unique"
+              },
+              {
+                name: "Envelope_Amount",
+                label: "Envelope Amount",
+                type: "object",
+                optional: false,
+                properties: [
+                  {
+                    name: "@Amount_currency",
+                    label: "Amount Currency",
+                    type: "string",
+                    optional: true
+                  },
+                  {
+                    name: "Amount_text",
+                    label: "Amount Text",
+                    type: "number",
+                    optional: false
+                  }
+                ]
+              },
+              {
+                name: "Envelope_Payload",
+                label: "Envelope Payload",
+                type: "object",
+                optional: false,
+                properties: [
+                  {
+                    name: "Payload_Type",
+                    label: "Payload Type",
+                    type: "string",
+                    optional: false
+                  },
+                  {
+                    name: "Payload_any",
+                    label: "Payload Any",
+                    type: "string",
+                    optional: true,
+                    hint: "XML content, written to the document as is"
+                  }
+                ]
+              }
+            ]
+          }
+        ]
+      end
+    }
+  },
+
+  actions: {
+    send_envelope: {
+      title: "Send Envelope",
+      input_fields: lambda do |object_definitions|
+        object_definitions["envelope"]
+      end,
+      execute: lambda do |_connection, input|
+        body = call(:render_mustache, <<~'MUSTACHE', input)
+          <?xml version="1.0" encoding="UTF-8"?>
+          <Envelope>
+          <MessageId>{{Envelope.Envelope_MessageId}}</MessageId>
+          <Amount{{#Envelope.Envelope_Amount.@Amount_currency}} currency="{{.}}"{{/Envelope.Envelope_Amount.@Amount_currency}}>{{Envelope.Envelope_Amount.Amount_text}}</Amount>
+          <Payload>
+          <Type>{{Envelope.Envelope_Payload.Payload_Type}}</Type>
+          {{{Envelope.Envelope_Payload.Payload_any}}}
+          </Payload>
+          {{! xs:anyAttribute in Envelope is not supported, its content was skipped }}
+          </Envelope>
+        MUSTACHE
+        # Replace with the endpoint of the API
+        post("/envelope")
+          .headers("Content-Type": "application/xml")
+          .request_body(body)
+          .response_format_raw
+      end
+    }
+  },
+
+  methods: {
+    render_mustache: lambda do |template, context|
+      # Standalone section and comment tags are removed with their line
+      template = template.gsub(/^[ \t]*(\{\{[#^\/!][^}]*\}\})[ \t]*\n/, '\1')
+      call(:render_mustache_nodes, template, [context])
+    end,
+
+    render_mustache_nodes: lambda do |template, stack|
+      output = ""
+      rest = template
+      while (match = rest.match(/\{\{(\{?)([#^\/!]?)\s*([^}]*?)\s*\}?\}\}/))
+        output << match.pre_match
+        rest = match.post_match
+        raw, kind, name = match[1], match[2], match[3]
+        next if kind == "!"
+
+        value = call(:lookup_mustache, stack, name)
+        if kind == "#" || kind == "^"
+          inner, rest = call(:split_mustache_section, rest, name)
+          empty = value.nil? || value == false || value == "" || (value.is_a?(Array) && value.empty?)
+          if kind == "^"
+            output << call(:render_mustache_nodes, inner, stack) if empty
+          elsif value.is_a?(Array)
+            value.each { |item| output << call(:render_mustache_nodes, inner, stack + [item]) }
+          elsif !empty
+            output << call(:render_mustache_nodes, inner, stack + [value])
+          end
+        else
+          text = value.nil? ? "" : value.to_s
+          if raw.empty?
+            text = text.gsub("&", "&amp;").gsub("<", "&lt;").gsub(">", "&gt;").gsub('"', "&quot;").gsub("'", "&#39;")
+          end
+          output << text
+        end
+      end
+      output << rest
+    end,
+
+    lookup_mustache: lambda do |stack, name|
+      return stack.last if name == "."
+
+      parts = name.split(".")
+      context = stack.reverse.find { |item| item.is_a?(Hash) && item.key?(parts.first) }
+      parts.reduce(context) { |value, part| value.is_a?(Hash) ? value[part] : nil }
+    end,
+
+    split_mustache_section: lambda do |template, name|
+      tag = /\{\{([#^\/])\s*#{Regexp.escape(name)}\s*\}\}/
+      depth = 0
+      position = 0
+      while (match = template.match(tag, position))
+        if match[1] == "/"
+          return [match.pre_match, match.post_match] if depth.zero?
+
+          depth -= 1
+        else
+          depth += 1
+        end
+        position = match.end(0)
+      end
+      error("Section #{name} of the request template is not closed")
+    end
+  }
@@ -0,0 +1,34 @@
+// Code generated by xsd2wkt. DO NOT EDIT.
+
+package contract
+
+import "encoding/xml"
+
+// Envelope holds the Envelope element.
+type Envelope struct {
+	XMLName xml.Name `xml:"Envelope" json:"-"`
+	// Key of Envelope: must be present and unique
+	MessageId string          `xml:"MessageId" json:"MessageId"`
+	Amount    EnvelopeAmount  `xml:"Amount" json:"Amount"`
+	Payload   EnvelopePayload `xml:"Payload" json:"Payload"`
+}
+
+// EnvelopeAmount holds the Amount element.
+type EnvelopeAmount struct {
+	Currency string  `xml:"currency,attr,omitempty" json:"@currency,omitempty"`
+	Value    float64 `xml:",chardata" json:"#text"`
+}
+
+// EnvelopePayload holds the Payload element.
+type EnvelopePayload struct {
+	Type string `xml:"Type" json:"Type"`
+	// XML content, written to the document as is
+	Any []AnyElement `xml:",any" json:"#any,omitempty"`
+}
+
+// AnyElement holds an element whose XML content is passed as is
+type AnyElement struct {
+	XMLName xml.Name   `json:"-"`
+	Attrs   []xml.Attr `xml:",any,attr" json:"-"`
+	Content string     `xml:",innerxml" json:"content"`
+}
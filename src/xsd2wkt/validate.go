@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Function to run the validate subcommand, checking an XML document, such as the output
+// of a generated template, against an XSD. Exits with exitFailure when the document is invalid.
+func runValidate(args []string) {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	inputFile := flags.String("i", "", "Path or HTTP(S) URL of the XSD file")
+	xmlFile := flags.String("x", "", "Path to the XML document to validate")
+	maxDepth := flags.Int("max-depth", xsd.DefaultMaxDepth, "Element nesting depth at which recursive types stop being expanded")
+	logging := addLogFlags(flags)
+	fetching := addFetchFlags(flags)
+	flags.Parse(args)
+
+	log, err := logging.logger(os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitUsage)
+	}
+	if *inputFile == "" || *xmlFile == "" {
+		log.Error("-i and -x are required")
+		os.Exit(exitUsage)
+	}
+
+	parser := xsd.Parser{MaxDepth: *maxDepth, Logger: log}
+	if err := fetching.configure(&parser, *inputFile); err != nil {
+		log.Error(err.Error())
+		os.Exit(exitUsage)
+	}
+	schema, err := parser.ParseFile(*inputFile)
+	if err != nil {
+		err = parseError(fmt.Errorf("failed to parse XSD: %w", err))
+		log.Error(err.Error())
+		os.Exit(exitCode(err))
+	}
+	for _, warning := range schema.Warnings {
+		log.Warn(warning)
+	}
+	document, err := os.ReadFile(*xmlFile)
+	if err != nil {
+		log.Error("failed to read XML: " + err.Error())
+		os.Exit(exitParse)
+	}
+
+	errs, err := xsd.Validate(schema, bytes.NewReader(document))
+	if err != nil {
+		log.Error(err.Error(), "file", *xmlFile)
+		os.Exit(exitParse)
+	}
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Fprintln(os.Stderr, e)
+		}
+		log.Error(fmt.Sprintf("%s is not valid against %s", *xmlFile, *inputFile))
+		os.Exit(exitFailure)
+	}
+	log.Info(*xmlFile + " is valid")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+)
+
+// Interval between two checks of the watched inputs, unless set with -watch-interval
+const defaultWatchInterval = time.Second
+
+// State of a watched input file when it was last converted
+type watchedFile struct {
+	modTime time.Time
+	size    int64
+	fields  []workato.Field // Workato fields of the root element, nil for WSDL inputs or failures
+}
+
+// Function to convert the inputs, then poll them and convert again the files that change,
+// until ctx is done. Directories and glob patterns are expanded again at every check, so
+// that files added later are picked up. The changes of the Workato fields are printed to w.
+func (c converter) watch(ctx context.Context, inputs []string, interval time.Duration, w io.Writer) {
+	watched := make(map[string]watchedFile)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.refresh(inputs, watched, w)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Function to convert the watched files that were added or changed since the last check
+func (c converter) refresh(inputs []string, watched map[string]watchedFile, w io.Writer) {
+	seen := make(map[string]bool)
+	for _, input := range inputs {
+		files, err := expandInputs(input)
+		if err != nil {
+			c.log.Warn(err.Error())
+			continue
+		}
+		for _, file := range files {
+			seen[file] = true
+			info, err := os.Stat(file)
+			if err != nil {
+				if previous, ok := watched[file]; !ok || !previous.modTime.IsZero() {
+					c.log.Warn(err.Error())
+				}
+				watched[file] = watchedFile{}
+				continue
+			}
+			previous, known := watched[file]
+			if known && info.ModTime().Equal(previous.modTime) && info.Size() == previous.size {
+				continue
+			}
+
+			current := watchedFile{modTime: info.ModTime(), size: info.Size()}
+			if err := c.convert(file); err != nil {
+				c.log.Error(err.Error(), "file", file)
+			}
+			if !strings.HasSuffix(strings.ToLower(file), ".wsdl") {
+				current.fields, _ = schemaFields(c.parser, file, c.rootElement, c.opts)
+			}
+			watched[file] = current
+
+			// Only changes are reported; the first conversion of a file is logged by convert
+			if known && previous.fields != nil && current.fields != nil {
+				io.WriteString(w, file+":\n")
+				if err := workato.PrintDiff(w, workato.Diff(previous.fields, current.fields)); err != nil {
+					c.log.Error("failed to print changes: " + err.Error())
+				}
+			}
+		}
+	}
+	for file := range watched {
+		if !seen[file] {
+			c.log.Info("No longer watching removed file", "file", file)
+			delete(watched, file)
+		}
+	}
+}
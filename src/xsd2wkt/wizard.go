@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Commands of the wizard, as printed by help
+const wizardHelp = `Commands, where N is a field number and N... one or more numbers or ranges such as 2-5:
+  toggle N...       Include or exclude the fields and their content (t)
+  required N...     Mark the fields required (r)
+  optional N...     Mark the fields optional (o)
+  reset N...        Drop the choices made for the fields
+  label N [text]    Rename the label of a field, or restore the generated one without text (l)
+  root [name]       Choose the document root among the global elements, or list them
+  save              Write the outputs and the config file, then exit (s)
+  quit              Exit without writing anything (q)
+  help              Show this help (h)
+`
+
+// State of the wizard: the selected root and the choices made so far, as config overrides
+type wizard struct {
+	schema    xsd.Schema
+	root      string
+	overrides map[string]workato.FieldOverride
+	opts      workato.Options
+	entries   []workato.FieldEntry // Fields of the root as last printed, numbered from 1
+}
+
+// Function to run the wizard on an XSD file. The fields of the root element are printed as
+// a numbered tree, and commands read from in include or exclude them, mark them required or
+// optional, rename their labels and choose the root. Saving writes the outputs with these
+// choices, along with the config file at configFile, which -config reuses in later runs.
+func (c converter) runWizard(inputFile string, config Config, configFile string, in io.Reader, out io.Writer) error {
+	// Every global element is resolved, so that the root can be changed
+	c.parser.Root = ""
+	schema, err := parseFile(c.parser, inputFile)
+	if err != nil {
+		return parseError(fmt.Errorf("failed to parse %s: %w", documentKind(inputFile), err))
+	}
+	if len(schema.Elements) == 0 {
+		return withExitCode(exitUsage, fmt.Errorf("failed to select root element: schema declares no global element"))
+	}
+	w := wizard{schema: schema, root: c.rootElement, overrides: maps.Clone(config.Fields), opts: c.opts}
+	if w.overrides == nil {
+		w.overrides = make(map[string]workato.FieldOverride)
+	}
+	if w.root == "" {
+		w.root = schema.Elements[0].Name
+	}
+	if _, err := schema.SelectRoot(w.root); err != nil {
+		return withExitCode(exitUsage, fmt.Errorf("failed to select root element: %w", err))
+	}
+
+	fmt.Fprintln(out, "Type help for the list of commands.")
+	w.print(out)
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			c.log.Info("Input ended, exiting without writing anything")
+			return scanner.Err()
+		}
+		command, arguments, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		arguments = strings.TrimSpace(arguments)
+		switch command {
+		case "":
+			continue
+		case "save", "s":
+			config.Root, config.Fields = w.root, w.overrides
+			if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+				return withExitCode(exitWrite, fmt.Errorf("failed to create output directory: %w", err))
+			}
+			if err := saveConfig(configFile, config); err != nil {
+				return withExitCode(exitWrite, err)
+			}
+			c.log.Info("Config saved successfully", "file", configFile)
+			c.rootElement, c.opts.Overrides = w.root, w.overrides
+			return c.emitRoot(schema, inputFile)
+		case "quit", "q":
+			return nil
+		case "help", "h":
+			fmt.Fprint(out, wizardHelp)
+			continue
+		}
+		if err := w.apply(command, arguments); err != nil {
+			fmt.Fprintln(out, err)
+			continue
+		}
+		w.print(out)
+	}
+}
+
+// Function to apply a command changing the choices, other than save, quit and help
+func (w *wizard) apply(command, arguments string) error {
+	switch command {
+	case "root":
+		if arguments == "" {
+			var names []string
+			for _, element := range w.schema.Elements {
+				names = append(names, element.Name)
+			}
+			return fmt.Errorf("global elements: %s", strings.Join(names, ", "))
+		}
+		if _, err := w.schema.SelectRoot(arguments); err != nil {
+			return err
+		}
+		w.root = arguments
+		return nil
+	case "label", "l":
+		number, label, _ := strings.Cut(arguments, " ")
+		entries, err := w.numbered(number)
+		if err != nil {
+			return err
+		}
+		if len(entries) != 1 {
+			return fmt.Errorf("label takes a single field number")
+		}
+		override := w.overrides[entries[0].XMLPath]
+		override.Label = strings.TrimSpace(label)
+		w.set(entries[0].XMLPath, override)
+		return nil
+	}
+
+	entries, err := w.numbered(arguments)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		override := w.overrides[entry.XMLPath]
+		switch command {
+		case "toggle", "t":
+			override.Exclude = !override.Exclude
+		case "required", "r", "optional", "o":
+			optional := command == "optional" || command == "o"
+			override.Optional = &optional
+		case "reset":
+			override = workato.FieldOverride{}
+		default:
+			return fmt.Errorf("unknown command %s, type help for the list of commands", command)
+		}
+		w.set(entry.XMLPath, override)
+	}
+	return nil
+}
+
+// Function to set the override of the element at path, dropping it when it changes nothing
+func (w *wizard) set(path string, override workato.FieldOverride) {
+	if override == (workato.FieldOverride{}) {
+		delete(w.overrides, path)
+		return
+	}
+	w.overrides[path] = override
+}
+
+// Function to get the fields listed in arguments by their numbers or ranges of numbers
+func (w *wizard) numbered(arguments string) ([]workato.FieldEntry, error) {
+	numbers := strings.FieldsFunc(arguments, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(numbers) == 0 {
+		return nil, fmt.Errorf("expected field numbers, such as 3 or 2-5")
+	}
+	var entries []workato.FieldEntry
+	for _, number := range numbers {
+		first, last, isRange := strings.Cut(number, "-")
+		from, err := strconv.Atoi(first)
+		to := from
+		if err == nil && isRange {
+			to, err = strconv.Atoi(last)
+		}
+		if err != nil || from < 1 || to > len(w.entries) || from > to {
+			return nil, fmt.Errorf("invalid field number %s, expected 1 to %d", number, len(w.entries))
+		}
+		entries = append(entries, w.entries[from-1:to]...)
+	}
+	return entries, nil
+}
+
+// Function to print the numbered tree of the fields of the root, excluded ones included so
+// that they can be toggled back. Fields are indented by depth, with [x] before included ones.
+func (w *wizard) print(out io.Writer) {
+	schema, _ := w.schema.SelectRoot(w.root)
+
+	// Excluded fields are listed with their content, as they would be if included
+	opts := w.opts
+	opts.Overrides = make(map[string]workato.FieldOverride, len(w.overrides))
+	for path, override := range w.overrides {
+		override.Exclude = false
+		opts.Overrides[path] = override
+	}
+	opts = opts.Resolve(schema)
+	w.entries = workato.FieldList(schema, opts)
+
+	width := len(strconv.Itoa(len(w.entries)))
+	for i, entry := range w.entries {
+		included := "x"
+		if w.excluded(entry.XMLPath) {
+			included = " "
+		}
+		segments := strings.Split(entry.XMLPath, "/")
+		required := "optional"
+		if entry.Required {
+			required = "required"
+		}
+		label := w.overrides[entry.XMLPath].Label
+		if label == "" {
+			label = opts.Label(opts.FieldName(entry.XMLPath))
+		}
+		fmt.Fprintf(out, "%*d [%s] %s%s  %s, %s, %q\n", width, i+1, included,
+			strings.Repeat("  ", len(segments)-1), segments[len(segments)-1], entry.Type, required, label)
+	}
+}
+
+// Helper function to check whether the element at path or one of its ancestors is excluded
+func (w *wizard) excluded(path string) bool {
+	segments := strings.Split(path, "/")
+	for i := range segments {
+		if w.overrides[strings.Join(segments[:i+1], "/")].Exclude {
+			return true
+		}
+	}
+	return false
+}
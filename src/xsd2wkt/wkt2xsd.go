@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/peaz/xsd2wkt/pkg/template/mustache"
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Function to run the wkt2xsd subcommand, converting a Workato schema JSON file back
+// into an XSD and, optionally, a Mustache template
+func runWkt2xsd(args []string) {
+	flags := flag.NewFlagSet("wkt2xsd", flag.ExitOnError)
+	inputFile := flags.String("i", "", "Path to the Workato schema JSON file")
+	attributePrefix := flags.String("attr-prefix", "@", "Prefix marking Workato fields generated from XML attributes")
+	outputDir := flags.String("o", "", "Directory to write the outputs to (defaults to the input file's directory)")
+	template := flags.Bool("template", false, "Also generate a Mustache template from the reconstructed XSD")
+	rootElement := flags.String("root", "", "Name of the top-level field to use as the document root of the template")
+	naming := flags.String("naming", workato.NamingFlat, "Naming of nested fields used to generate the schema: flat, nested or path")
+	logging := addLogFlags(flags)
+	flags.Parse(args)
+
+	log, err := logging.logger(os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitUsage)
+	}
+	if !validNaming(*naming) {
+		log.Error("-naming must be one of flat, nested or path")
+		os.Exit(exitUsage)
+	}
+	opts := workato.Options{AttributePrefix: *attributePrefix, Naming: *naming}
+
+	fields, err := workato.ReadFile(*inputFile)
+	if err != nil {
+		log.Error("failed to read Workato Schema: " + err.Error())
+		os.Exit(exitParse)
+	}
+	schema := workato.ToSchema(fields, opts)
+
+	// order-schema.json becomes order-wkt.xsd, so that the XSD and template order-schema.json
+	// was generated from are never overwritten
+	dir := *outputDir
+	if dir == "" {
+		dir = filepath.Dir(*inputFile)
+	}
+	baseName := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(*inputFile), filepath.Ext(*inputFile)), "-schema") + "-wkt"
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Error("failed to create output directory: " + err.Error())
+		os.Exit(exitWrite)
+	}
+
+	xsdFile := filepath.Join(dir, baseName+".xsd")
+	if err := writeXSDFile(schema, xsdFile); err != nil {
+		log.Error("failed to write XSD file: " + err.Error())
+		os.Exit(exitWrite)
+	}
+	log.Info("XSD generated successfully", "file", xsdFile)
+
+	if *template {
+		root, err := schema.SelectRoot(*rootElement)
+		if err != nil {
+			log.Error("failed to select root element: " + err.Error())
+			os.Exit(exitUsage)
+		}
+		templateFile := filepath.Join(dir, baseName+".template")
+		err = os.WriteFile(templateFile, []byte(mustache.Generate(root, mustache.Options{Options: opts})), 0644)
+		if err != nil {
+			log.Error("failed to write template file: " + err.Error())
+			os.Exit(exitWrite)
+		}
+		log.Info("Template generated successfully", "file", templateFile)
+	}
+}
+
+// Function to write a schema to an XSD file
+func writeXSDFile(schema xsd.Schema, outputFile string) error {
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	if err := xsd.Write(file, schema); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
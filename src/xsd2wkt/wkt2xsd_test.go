@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/peaz/xsd2wkt/pkg/workato"
+	"github.com/peaz/xsd2wkt/pkg/xsd"
+)
+
+// Converting a generated Workato schema back to XSD and generating again must give the
+// same Workato schema
+func TestWkt2xsdRoundTrip(t *testing.T) {
+	cases := []string{
+		"flat",
+		"nested",
+		"repeating",
+		"mixed_types",
+		"named_types",
+		"namespaces",
+		"imports",
+		"attributes",
+		"choice",
+		"all",
+		"enumerations",
+		"documentation",
+		"substitution",
+		"polymorphism",
+		"lists_unions",
+		"defaults",
+		"nillable",
+	}
+
+	for _, name := range cases {
+		t.Run(name, func(t *testing.T) {
+			fields, err := workato.ReadFile(filepath.Join("testdata", name+"-schema.json"))
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := xsd.Write(&buf, workato.ToSchema(fields, testOptions)); err != nil {
+				t.Fatalf("xsd.Write: %v", err)
+			}
+			schema, err := xsd.Parse(&buf)
+			if err != nil {
+				t.Fatalf("xsd.Parse: %v\n%s", err, buf.String())
+			}
+
+			got, err := workato.Generate(schema, testOptions)
+			if err != nil {
+				t.Fatalf("workato.Generate: %v", err)
+			}
+			if !reflect.DeepEqual(got, fields) {
+				t.Errorf("round trip mismatch\n--- got ---\n%+v\n--- want ---\n%+v", got, fields)
+			}
+		})
+	}
+}
+
+// Converting a Workato schema next to the XSD and template it was generated from must
+// leave them unchanged
+func TestWkt2xsdKeepsSources(t *testing.T) {
+	dir := t.TempDir()
+	sources := map[string][]byte{}
+	for _, name := range []string{"nested.xsd", "nested.template", "nested-schema.json"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		sources[name] = data
+	}
+
+	runWkt2xsd([]string{"-i", filepath.Join(dir, "nested-schema.json"), "-template", "-quiet"})
+
+	for name, want := range sources {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s was changed", name)
+		}
+	}
+	for _, name := range []string{"nested-wkt.xsd", "nested-wkt.template"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("output %s: %v", name, err)
+		}
+	}
+}